@@ -0,0 +1,442 @@
+package actions
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/aeroideaservices/focus/media/plugin/entity"
+	"github.com/aeroideaservices/focus/services/callbacks"
+	"github.com/aeroideaservices/focus/services/errors"
+)
+
+const (
+	resourceTypeFile   = "file"
+	resourceTypeFolder = "folder"
+)
+
+// Bulk сервис массовых операций над папками и медиа
+type Bulk struct {
+	bulkRepository   BulkRepository
+	folderRepository FolderRepository
+	mediaRepository  MediaRepository
+	storage          FileStorage
+	mediaCallbacks   callbacks.Callbacks
+	folderCallbacks  callbacks.Callbacks
+}
+
+// NewBulk конструктор
+func NewBulk(
+	bulkRepository BulkRepository,
+	folderRepository FolderRepository,
+	mediaRepository MediaRepository,
+	storage FileStorage,
+	mediaCallbacks callbacks.Callbacks,
+	folderCallbacks callbacks.Callbacks,
+) *Bulk {
+	return &Bulk{
+		bulkRepository:   bulkRepository,
+		folderRepository: folderRepository,
+		mediaRepository:  mediaRepository,
+		storage:          storage,
+		mediaCallbacks:   mediaCallbacks,
+		folderCallbacks:  folderCallbacks,
+	}
+}
+
+// Move перемещение нескольких папок и медиа в одну папку
+func (b Bulk) Move(ctx context.Context, dto BulkMove) (*BulkResult, error) {
+	if len(dto.FolderIds) == 0 && len(dto.FileIds) == 0 {
+		return nil, ErrBulkEmpty
+	}
+	if dto.FolderId != nil && !b.folderRepository.Has(ctx, *dto.FolderId) {
+		return nil, ErrFolderNotFound
+	}
+
+	targetPermission := entity.PermissionUpload
+	if len(dto.FolderIds) != 0 {
+		targetPermission = entity.PermissionManage
+	}
+	err := checkFolderAccess(ctx, b.folderRepository, dto.FolderId, targetPermission, ErrFolderNotFound)
+	if err != nil {
+		return nil, err
+	}
+
+	res := newBulkResult()
+	names := make(map[string]struct{}) // имена, которые займут перемещаемые папки и медиа в целевой папке
+	move := BulkMove{FolderId: dto.FolderId}
+	var quotaMoves []quotaMove
+
+	for _, id := range dto.FolderIds {
+		quota, err := b.checkFolderMove(ctx, id, dto.FolderId, names)
+		res.add(id, resourceTypeFolder, err)
+		if err == nil {
+			move.FolderIds = append(move.FolderIds, id)
+			quotaMoves = append(quotaMoves, quota)
+		}
+	}
+
+	for _, id := range dto.FileIds {
+		quota, err := b.checkMediaMove(ctx, id, dto.FolderId, names)
+		res.add(id, resourceTypeFile, err)
+		if err == nil {
+			move.FileIds = append(move.FileIds, id)
+			quotaMoves = append(quotaMoves, quota)
+		}
+	}
+
+	if len(move.FolderIds) == 0 && len(move.FileIds) == 0 {
+		return res, nil
+	}
+
+	// квоты проверяются для всех перемещаемых папок и медиа вместе
+	err = checkFolderQuotaMoves(ctx, b.folderRepository, dto.FolderId, quotaMoves...)
+	if errors.Is(err, ErrFolderQuotaExceeded) {
+		res.fail(append(move.FolderIds, move.FileIds...), err)
+		return res, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var updatedMediaIds []uuid.UUID
+	err = b.bulkRepository.Move(ctx, move, func(medias []*UpdateMediaDto) error {
+		for i, media := range medias {
+			err := b.storage.Move(ctx, media.Filepath, media.NewFilepath)
+			if err != nil {
+				// возвращаем уже перемещенные файлы на место, транзакция будет откачена
+				for _, moved := range medias[:i] {
+					_ = b.storage.Move(ctx, moved.NewFilepath, moved.Filepath)
+				}
+				return errors.NoType.Wrap(err, "error moving media file")
+			}
+			updatedMediaIds = append(updatedMediaIds, media.Id)
+		}
+		return nil
+	})
+	if err != nil {
+		res.fail(append(move.FolderIds, move.FileIds...), err)
+		return res, nil
+	}
+
+	b.goAfterUpdate(move.FolderIds, updatedMediaIds)
+
+	return res, nil
+}
+
+// Delete удаление нескольких папок и медиа
+func (b Bulk) Delete(ctx context.Context, dto BulkDelete) (*BulkResult, error) {
+	if len(dto.FolderIds) == 0 && len(dto.FileIds) == 0 {
+		return nil, ErrBulkEmpty
+	}
+
+	res := newBulkResult()
+	var folderIds, mediaIds []uuid.UUID
+	var keys []string
+
+	for _, id := range dto.FolderIds {
+		if err := b.checkFolder(ctx, id); err != nil {
+			res.add(id, resourceTypeFolder, err)
+			continue
+		}
+		filePaths, err := b.folderRepository.GetFolderMediaFilePaths(ctx, &id)
+		if err != nil {
+			return nil, errors.NoType.Wrap(err, "error getting media file path")
+		}
+		keys = append(keys, filePaths...)
+		folderIds = append(folderIds, id)
+		res.add(id, resourceTypeFolder, nil)
+	}
+
+	for _, id := range dto.FileIds {
+		media, err := b.getMedia(ctx, id)
+		if err != nil {
+			res.add(id, resourceTypeFile, err)
+			continue
+		}
+		keys = append(keys, media.Filepath)
+		mediaIds = append(mediaIds, id)
+		res.add(id, resourceTypeFile, nil)
+	}
+
+	if len(folderIds) == 0 && len(mediaIds) == 0 {
+		return res, nil
+	}
+
+	if len(mediaIds) != 0 {
+		versions, err := b.mediaRepository.ListVersions(ctx, mediaIds...)
+		if err != nil {
+			return nil, errors.NoType.Wrap(err, "error getting media versions")
+		}
+		for _, version := range versions {
+			keys = append(keys, version.Filepath)
+		}
+
+		derivatives, err := b.mediaRepository.ListDerivatives(ctx, mediaIds...)
+		if err != nil {
+			return nil, errors.NoType.Wrap(err, "error getting media derivatives")
+		}
+		for _, derivative := range derivatives {
+			keys = append(keys, derivative.Filepath)
+		}
+
+		streams, err := b.mediaRepository.ListStreams(ctx, mediaIds...)
+		if err != nil {
+			return nil, errors.NoType.Wrap(err, "error getting media streams")
+		}
+		for _, stream := range streams {
+			keys = append(keys, stream.Files...)
+		}
+	}
+
+	err := b.bulkRepository.Delete(ctx, folderIds, mediaIds)
+	if err != nil {
+		res.fail(append(folderIds, mediaIds...), err)
+		return res, nil
+	}
+
+	keys = uniqueKeys(keys)
+	if len(keys) != 0 {
+		err = b.storage.Delete(ctx, keys...)
+		if err != nil {
+			return nil, errors.NoType.Wrap(err, "error deleting media files")
+		}
+	}
+
+	if len(folderIds) != 0 {
+		b.folderCallbacks.GoAfterDelete(folderIds...)
+	}
+	if len(mediaIds) != 0 {
+		b.mediaCallbacks.GoAfterDelete(mediaIds...)
+	}
+
+	return res, nil
+}
+
+// Tag добавление и удаление тегов у медиа, для папок изменяются теги всех вложенных медиа
+func (b Bulk) Tag(ctx context.Context, dto BulkTag) (*BulkResult, error) {
+	if len(dto.FolderIds) == 0 && len(dto.FileIds) == 0 {
+		return nil, ErrBulkEmpty
+	}
+	if len(dto.Add) == 0 && len(dto.Remove) == 0 {
+		return nil, ErrBulkTagsEmpty
+	}
+
+	res := newBulkResult()
+	tag := BulkTag{Add: dto.Add, Remove: dto.Remove}
+
+	for _, id := range dto.FolderIds {
+		if err := b.checkFolder(ctx, id); err != nil {
+			res.add(id, resourceTypeFolder, err)
+			continue
+		}
+		tag.FolderIds = append(tag.FolderIds, id)
+		res.add(id, resourceTypeFolder, nil)
+	}
+
+	for _, id := range dto.FileIds {
+		if _, err := b.getMedia(ctx, id); err != nil {
+			res.add(id, resourceTypeFile, err)
+			continue
+		}
+		tag.FileIds = append(tag.FileIds, id)
+		res.add(id, resourceTypeFile, nil)
+	}
+
+	if len(tag.FolderIds) == 0 && len(tag.FileIds) == 0 {
+		return res, nil
+	}
+
+	updatedMediaIds, err := b.bulkRepository.UpdateTags(ctx, tag)
+	if err != nil {
+		res.fail(append(tag.FolderIds, tag.FileIds...), err)
+		return res, nil
+	}
+
+	b.goAfterUpdate(nil, updatedMediaIds)
+
+	return res, nil
+}
+
+// UpdateFields массовое изменение alt и title медиа
+func (b Bulk) UpdateFields(ctx context.Context, dto BulkUpdateFields) (*BulkResult, error) {
+	res := newBulkResult()
+	var items []BulkMediaFields
+	var mediaIds []uuid.UUID
+
+	for _, item := range dto.Items {
+		if _, err := b.getMedia(ctx, item.Id); err != nil {
+			res.add(item.Id, resourceTypeFile, err)
+			continue
+		}
+		items = append(items, item)
+		mediaIds = append(mediaIds, item.Id)
+		res.add(item.Id, resourceTypeFile, nil)
+	}
+
+	if len(items) == 0 {
+		return res, nil
+	}
+
+	err := b.bulkRepository.UpdateFields(ctx, items...)
+	if err != nil {
+		res.fail(mediaIds, err)
+		return res, nil
+	}
+
+	b.goAfterUpdate(nil, mediaIds)
+
+	return res, nil
+}
+
+// checkFolderMove проверка возможности перемещения папки
+func (b Bulk) checkFolderMove(
+	ctx context.Context, id uuid.UUID, parentId *uuid.UUID, names map[string]struct{},
+) (quotaMove, error) {
+	folder, err := b.folderRepository.Get(ctx, id)
+	if err != nil {
+		return quotaMove{}, err
+	}
+	err = checkFolderAccess(ctx, b.folderRepository, &folder.Id, entity.PermissionManage, ErrFolderNotFound)
+	if err != nil {
+		return quotaMove{}, err
+	}
+	if equalIds(folder.FolderId, parentId) {
+		return quotaMove{}, ErrFolderAlreadyInThisFolder
+	}
+	if parentId != nil {
+		hasSubFolder, err := b.folderRepository.HasSubFolder(ctx, id, parentId)
+		if err != nil {
+			return quotaMove{}, err
+		}
+		if hasSubFolder {
+			return quotaMove{}, ErrFolderRecursiveAttachment
+		}
+	}
+
+	if reserved(names, folder.Name) ||
+		b.folderRepository.HasByFilter(ctx, Filter{Name: folder.Name, FolderId: parentId, WithFolderId: true}) {
+		return quotaMove{}, ErrFolderAlreadyExists
+	}
+
+	statistics, err := b.folderRepository.GetStatistics(ctx, &folder.Id)
+	if err != nil {
+		return quotaMove{}, errors.NoType.Wrap(err, "error getting folder statistics")
+	}
+	names[folder.Name] = struct{}{}
+
+	return quotaMove{SourceFolderId: folder.FolderId, Size: statistics.Size, Files: statistics.Files}, nil
+}
+
+// checkMediaMove проверка возможности перемещения медиа
+func (b Bulk) checkMediaMove(
+	ctx context.Context, id uuid.UUID, folderId *uuid.UUID, names map[string]struct{},
+) (quotaMove, error) {
+	media, err := b.getMedia(ctx, id)
+	if err != nil {
+		return quotaMove{}, err
+	}
+	if equalIds(media.FolderId, folderId) {
+		return quotaMove{}, ErrMediaAlreadyHasSameFolder
+	}
+
+	if reserved(names, media.Filename) ||
+		b.mediaRepository.HasByFilter(ctx, MediaFilter{Filename: media.Filename, FolderId: folderId, WithFolderId: true}) {
+		return quotaMove{}, ErrMediaAlreadyExistsInFolder
+	}
+	names[media.Filename] = struct{}{}
+
+	return quotaMove{SourceFolderId: media.FolderId, Size: media.Size, Files: 1}, nil
+}
+
+// checkFolder проверка существования папки и права на ее изменение вместе с вложенными папками
+func (b Bulk) checkFolder(ctx context.Context, id uuid.UUID) error {
+	if !b.folderRepository.Has(ctx, id) {
+		return ErrFolderNotFound
+	}
+
+	err := checkFolderAccess(ctx, b.folderRepository, &id, entity.PermissionManage, ErrFolderNotFound)
+	if err != nil {
+		return err
+	}
+
+	return checkSubFoldersAccess(ctx, b.folderRepository, id, entity.PermissionManage)
+}
+
+// getMedia получение медиа с проверкой права на его изменение
+func (b Bulk) getMedia(ctx context.Context, id uuid.UUID) (*entity.Media, error) {
+	media, err := b.mediaRepository.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	err = checkFolderAccess(ctx, b.folderRepository, media.FolderId, entity.PermissionManage, ErrMediaNotFound)
+	if err != nil {
+		return nil, err
+	}
+
+	return media, nil
+}
+
+// reserved проверка, что имя уже занято одним из перемещаемых элементов
+func reserved(names map[string]struct{}, name string) bool {
+	_, ok := names[name]
+	return ok
+}
+
+// goAfterUpdate однократный вызов колбеков обновления для всех затронутых папок и медиа
+func (b Bulk) goAfterUpdate(folderIds []uuid.UUID, mediaIds []uuid.UUID) {
+	if len(folderIds) != 0 {
+		b.folderCallbacks.GoAfterUpdate(folderIds...)
+	}
+	if len(mediaIds) != 0 {
+		b.mediaCallbacks.GoAfterUpdate(mediaIds...)
+	}
+}
+
+func equalIds(a, b *uuid.UUID) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func newBulkResult() *BulkResult {
+	return &BulkResult{Items: make([]BulkItemResult, 0)}
+}
+
+// add добавление результата по элементу
+func (r *BulkResult) add(id uuid.UUID, resourceType string, err error) {
+	item := BulkItemResult{Id: id, ResourceType: resourceType, Success: err == nil}
+	if err != nil {
+		item.Error = err.Error()
+	}
+	r.Items = append(r.Items, item)
+}
+
+// fail отметка элементов как не выполненных после отката транзакции
+func (r *BulkResult) fail(ids []uuid.UUID, err error) {
+	for i := range r.Items {
+		for _, id := range ids {
+			if r.Items[i].Id == id {
+				r.Items[i].Success = false
+				r.Items[i].Error = err.Error()
+			}
+		}
+	}
+}
+
+// uniqueKeys удаление повторяющихся ключей хранилища (медиа вложенных папок)
+func uniqueKeys(keys []string) []string {
+	seen := make(map[string]struct{}, len(keys))
+	res := make([]string, 0, len(keys))
+	for _, key := range keys {
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		res = append(res, key)
+	}
+
+	return res
+}
@@ -1,6 +1,7 @@
 package actions
 
 import (
+	"github.com/aeroideaservices/focus/media/plugin/entity"
 	"github.com/aeroideaservices/focus/media/plugin/service/utils"
 	"github.com/aeroideaservices/focus/services/db/db_types/json"
 	"github.com/google/uuid"
@@ -64,6 +65,12 @@ type MediaPreview struct {
 	Url         string         `json:"url"`
 	UpdatedAt   utils.Time     `json:"updatedAt"`
 	FolderId    *uuid.UUID     `json:"folderId"`
+	Tags        []string       `json:"tags"`
+
+	Width      int                `json:"width"`
+	Height     int                `json:"height"`
+	FocalPoint *entity.FocalPoint `json:"focalPoint"`
+	Crops      entity.Crops       `json:"crops"`
 }
 
 type CreateMedia struct {
@@ -106,10 +113,14 @@ type MediaShortList struct {
 }
 
 type MediaShort struct {
-	Id    uuid.UUID `json:"id"`
-	Alt   string    `json:"alt"`
-	Title string    `json:"title"`
-	Url   string    `json:"url"`
+	Id         uuid.UUID          `json:"id"`
+	Alt        string             `json:"alt"`
+	Title      string             `json:"title"`
+	Url        string             `json:"url"`
+	Width      int                `json:"width"`
+	Height     int                `json:"height"`
+	FocalPoint *entity.FocalPoint `json:"focalPoint"`
+	Crops      entity.Crops       `json:"crops"`
 }
 
 type ListMediasShorts struct {
@@ -120,6 +131,210 @@ type GetMedia struct {
 	Id uuid.UUID `json:"id" validate:"required,notBlank"`
 }
 
+// FindMedia поиск медиа по имени файла в папке
+type FindMedia struct {
+	FolderId *uuid.UUID `validate:"omitempty,notBlank"` // FolderId папка, nil - корневая папка
+	Filename string     `validate:"required,min=3"`
+}
+
 type GetFolder struct {
 	Id uuid.UUID `json:"id" validate:"required,notBlank"`
 }
+
+type ReplaceMediaContent struct {
+	Id         uuid.UUID     `validate:"required,notBlank"`
+	Filename   string        `validate:"required,min=3"`
+	Size       int64         `validate:""`
+	File       io.ReadSeeker `validate:"required"`
+	UploadedBy string        `validate:""`
+}
+
+type RevertMediaVersion struct {
+	Id         uuid.UUID `validate:"required,notBlank"`
+	VersionId  uuid.UUID `validate:"required,notBlank"`
+	UploadedBy string    `validate:""`
+}
+
+type MediaVersionsList struct {
+	Items []MediaVersionPreview `json:"items"`
+}
+
+type MediaVersionPreview struct {
+	Id         uuid.UUID      `json:"id"`
+	Filename   string         `json:"filename"`
+	Size       utils.Filesize `json:"size"`
+	Url        string         `json:"url"`
+	UploadedBy string         `json:"uploadedBy"`
+	CreatedAt  utils.Time     `json:"createdAt"`
+}
+
+type BulkMove struct {
+	FolderIds []uuid.UUID `json:"folderIds" validate:"omitempty,max=1000,unique"`
+	FileIds   []uuid.UUID `json:"fileIds" validate:"omitempty,max=1000,unique"`
+	FolderId  *uuid.UUID  `json:"folderId" validate:"omitempty,notBlank"`
+}
+
+type BulkDelete struct {
+	FolderIds []uuid.UUID `json:"folderIds" validate:"omitempty,max=1000,unique"`
+	FileIds   []uuid.UUID `json:"fileIds" validate:"omitempty,max=1000,unique"`
+}
+
+type BulkTag struct {
+	FolderIds []uuid.UUID `json:"folderIds" validate:"omitempty,max=1000,unique"`
+	FileIds   []uuid.UUID `json:"fileIds" validate:"omitempty,max=1000,unique"`
+	Add       []string    `json:"add" validate:"omitempty,max=50,dive,required,notBlank,max=50"`
+	Remove    []string    `json:"remove" validate:"omitempty,max=50,dive,required,notBlank,max=50"`
+}
+
+type BulkUpdateFields struct {
+	Items []BulkMediaFields `json:"items" validate:"required,min=1,max=1000,unique=Id,dive"`
+}
+
+type BulkMediaFields struct {
+	Id    uuid.UUID `json:"id" validate:"required,notBlank"`
+	Alt   *string   `json:"alt" validate:"omitempty,min=3,max=50"`
+	Title *string   `json:"title" validate:"omitempty,min=3,max=50"`
+}
+
+type BulkResult struct {
+	Items []BulkItemResult `json:"items"`
+}
+
+type BulkItemResult struct {
+	Id           uuid.UUID `json:"id"`
+	ResourceType string    `json:"resourceType"`
+	Success      bool      `json:"success"`
+	Error        string    `json:"error,omitempty"`
+}
+
+type FolderPermissionItem struct {
+	Role       string            `json:"role" validate:"required,notBlank,max=255"`
+	Permission entity.Permission `json:"permission" validate:"required,oneof=read upload manage"`
+}
+
+type FolderPermissionsList struct {
+	Permissions []FolderPermissionItem `json:"permissions"`
+	Inherited   []FolderPermissionItem `json:"inherited"`
+}
+
+type SetFolderPermissions struct {
+	Id          uuid.UUID              `json:"id" validate:"required,notBlank"`
+	Permissions []FolderPermissionItem `json:"permissions" validate:"omitempty,max=100,unique=Role,dive"`
+}
+
+type SetFolderQuota struct {
+	Id       uuid.UUID `json:"id" validate:"required,notBlank"`
+	MaxSize  *int64    `json:"maxSize" validate:"omitempty,min=1"`
+	MaxFiles *int64    `json:"maxFiles" validate:"omitempty,min=1"`
+}
+
+type GetStatistics struct {
+	FolderId *uuid.UUID `json:"folderId" validate:"omitempty,notBlank"`
+}
+
+type FolderStatistics struct {
+	Size       utils.Filesize    `json:"size"`
+	Bytes      int64             `json:"bytes"`
+	Files      int64             `json:"files"`
+	MaxSize    *int64            `json:"maxSize"`
+	MaxFiles   *int64            `json:"maxFiles"`
+	Folders    []FolderUsageItem `json:"folders"`
+	Extensions []UsageItem       `json:"extensions"`
+	Months     []UsageItem       `json:"months"`
+}
+
+type FolderUsageItem struct {
+	Id    uuid.UUID      `json:"id"`
+	Name  string         `json:"name"`
+	Size  utils.Filesize `json:"size"`
+	Bytes int64          `json:"bytes"`
+	Files int64          `json:"files"`
+}
+
+type UsageItem struct {
+	Key   string         `json:"key"`
+	Size  utils.Filesize `json:"size"`
+	Bytes int64          `json:"bytes"`
+	Files int64          `json:"files"`
+}
+
+type UpdateMediaFocus struct {
+	Id         uuid.UUID          `validate:"required,notBlank"`
+	FocalPoint *entity.FocalPoint `validate:"omitempty" json:"focalPoint"`
+	Crops      entity.Crops       `validate:"max=20,unique=Name,dive" json:"crops"`
+}
+
+type GetMediaDerivative struct {
+	Id     uuid.UUID `validate:"required,notBlank"`
+	Crop   string    `validate:"omitempty,max=50" form:"crop"`
+	Width  int       `validate:"omitempty,min=1,max=4096" form:"width"`
+	Height int       `validate:"omitempty,min=1,max=4096" form:"height"`
+}
+
+type MediaDerivativePreview struct {
+	Url    string `json:"url"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+}
+
+type MediaSubtitleTrack struct {
+	Language  string     `json:"language"`
+	CuesCount int        `json:"cuesCount"`
+	UpdatedAt utils.Time `json:"updatedAt"`
+}
+
+type MediaSubtitleTrackList struct {
+	Items []MediaSubtitleTrack `json:"items"`
+}
+
+type GetMediaSubtitles struct {
+	Id       uuid.UUID `validate:"required,notBlank"`
+	Language string    `validate:"omitempty,max=35" form:"language"`
+	Format   string    `validate:"required,oneof=vtt srt"`
+}
+
+type UploadMediaSubtitles struct {
+	Id       uuid.UUID `validate:"required,notBlank"`
+	Language string    `validate:"required,notBlank,max=35"`
+	Format   string    `validate:"required,oneof=vtt srt"`
+	File     io.Reader `validate:"required"`
+}
+
+type SaveMediaSubtitles struct {
+	Id       uuid.UUID           `validate:"required,notBlank"`
+	Language string              `validate:"required,notBlank,max=35" json:"language"`
+	Cues     entity.SubtitleCues `validate:"required,min=1" json:"cues"`
+}
+
+type DeleteMediaSubtitles struct {
+	Id       uuid.UUID `validate:"required,notBlank"`
+	Language string    `validate:"required,notBlank,max=35" form:"language"`
+}
+
+type UpdateMediaStreamStatus struct {
+	Id     uuid.UUID           `validate:"required,notBlank"`
+	Status entity.StreamStatus `validate:"required,oneof=processing failed"`
+	Error  string
+}
+
+type UploadMediaStream struct {
+	Id       uuid.UUID              `validate:"required,notBlank"`
+	Dir      string                 `validate:"required"` // Dir локальная директория с мастер-плейлистом, плейлистами и сегментами вариантов
+	Variants []entity.StreamVariant `validate:"required,min=1,dive"`
+}
+
+type MediaStreamPreview struct {
+	Status      entity.StreamStatus         `json:"status"`
+	PlaylistUrl string                      `json:"playlistUrl"`
+	Variants    []MediaStreamVariantPreview `json:"variants"`
+	Error       string                      `json:"error,omitempty"`
+	UpdatedAt   utils.Time                  `json:"updatedAt"`
+}
+
+type MediaStreamVariantPreview struct {
+	Name      string `json:"name"`
+	Width     int    `json:"width"`
+	Height    int    `json:"height"`
+	Bandwidth int    `json:"bandwidth"`
+	Url       string `json:"url"`
+}
@@ -16,6 +16,22 @@ var (
 	ErrMediaNotFound              = errors.NotFound.New("media not found").T("media.not-found")
 	ErrOneOfMediasNotExists       = errors.BadRequest.New("some medias do not exist").T("media.not-exists")
 	ErrMaxFileSize                = errors.BadRequest.New("media file size too large").T("media.file.size")
+	ErrMediaVersionNotFound       = errors.NotFound.New("media version not found").T("media.version.not-found")
+	ErrBulkEmpty                  = errors.BadRequest.New("folder ids or file ids are required").T("media.bulk.empty")
+	ErrBulkTagsEmpty              = errors.BadRequest.New("tags to add or remove are required").T("media.bulk.tags-empty")
+	ErrFolderAccessDenied         = errors.Forbidden.New("access to folder denied").T("folder.access-denied")
+	ErrFolderPermissionUnknown    = errors.BadRequest.New("unknown folder permission").T("folder.permission-unknown")
+	ErrFolderQuotaExceeded        = errors.BadRequest.New("folder quota exceeded").T("folder.quota-exceeded")
+	ErrMediaContentExtMismatch    = errors.BadRequest.New("media content must have the same extension").T("media.content.ext-mismatch")
+	ErrMediaNotImage              = errors.BadRequest.New("media is not an image").T("media.not-image")
+	ErrMediaCropNotFound          = errors.NotFound.New("media crop not found").T("media.crop.not-found")
+	ErrMediaDerivativeNotFound    = errors.NotFound.New("media derivative not found").T("media.derivative.not-found")
+	ErrMediaAltRequired           = errors.BadRequest.New("media alt is required").T("media.alt-required")
+	ErrMediaAspectRatioMismatch   = errors.BadRequest.New("media aspect ratio mismatch").T("media.aspect-ratio-mismatch")
 
 	ErrMediaAlreadyHasSameSubtitles = errors.BadRequest.New("media already has the same subtitles").T("media.update-subtitles-same-subtitles")
+	ErrMediaSubtitlesNotFound       = errors.NotFound.New("media subtitles not found").T("media.subtitles.not-found")
+	ErrMediaSubtitlesFormat         = errors.BadRequest.New("unsupported media subtitles format").T("media.subtitles.format")
+	ErrMediaStreamNotFound          = errors.NotFound.New("media stream not found").T("media.stream.not-found")
+	ErrMediaStreamPlaylistNotFound  = errors.BadRequest.New("media stream master playlist not found").T("media.stream.playlist-not-found")
 )
@@ -46,6 +46,16 @@ func (f Folders) GetAll(ctx context.Context, filter FolderFilter) (*FolderAndMed
 		}
 	}
 
+	err := checkFolderAccess(ctx, f.folderRepository, filter.Filter.FolderId, entity.PermissionRead, ErrFolderNotFound)
+	if err != nil {
+		return nil, err
+	}
+
+	filter.Filter.ExcludeIds, err = deniedFolderIds(ctx, f.folderRepository, entity.PermissionRead)
+	if err != nil {
+		return nil, err
+	}
+
 	list, err := f.folderRepository.GetFoldersAndMedias(ctx, filter)
 	if err != nil {
 		return nil, errors.NoType.Wrap(err, "error getting folders and medias")
@@ -85,6 +95,9 @@ func (f Folders) GetAll(ctx context.Context, filter FolderFilter) (*FolderAndMed
 
 	res.Breadcrumbs = make([]FolderBreadcrumb, 0)
 	for _, folder := range folders {
+		if containsId(filter.Filter.ExcludeIds, folder.Id) {
+			continue
+		}
 		res.Breadcrumbs = append(res.Breadcrumbs, FolderBreadcrumb{
 			Name:     folder.Name,
 			FolderId: pointer(folder.Id),
@@ -98,6 +111,15 @@ func pointer[T any](val T) *T {
 	return &val
 }
 
+func containsId(ids []uuid.UUID, id uuid.UUID) bool {
+	for _, i := range ids {
+		if i == id {
+			return true
+		}
+	}
+	return false
+}
+
 // GetTree получение дерева папок
 func (f Folders) GetTree(ctx context.Context) ([]*FolderResponse, error) {
 	folders, err := f.folderRepository.GetFoldersTree(ctx)
@@ -105,11 +127,31 @@ func (f Folders) GetTree(ctx context.Context) ([]*FolderResponse, error) {
 		return nil, errors.NoType.Wrap(err, "error getting folders tree")
 	}
 
-	return folders, nil
+	denied, err := deniedFolderIds(ctx, f.folderRepository, entity.PermissionRead)
+	if err != nil {
+		return nil, err
+	}
+	if len(denied) == 0 {
+		return folders, nil
+	}
+
+	res := make([]*FolderResponse, 0, len(folders))
+	for _, folder := range folders {
+		if !containsId(denied, folder.Id) {
+			res = append(res, folder)
+		}
+	}
+
+	return res, nil
 }
 
 // Get получение папки
 func (f Folders) Get(ctx context.Context, action GetFolder) (*FolderDetail, error) {
+	err := checkFolderAccess(ctx, f.folderRepository, &action.Id, entity.PermissionRead, ErrFolderNotFound)
+	if err != nil {
+		return nil, err
+	}
+
 	folder, err := f.folderRepository.GetWithSize(ctx, action.Id)
 	if err != nil {
 		return nil, errors.NoType.Wrap(err, "error getting folder by id")
@@ -127,6 +169,11 @@ func (f Folders) Create(ctx context.Context, action CreateFolder) (*uuid.UUID, e
 		}
 	}
 
+	err := checkFolderAccess(ctx, f.folderRepository, action.ParentFolderId, entity.PermissionManage, ErrFolderNotFound)
+	if err != nil {
+		return nil, err
+	}
+
 	hasFolder := f.folderRepository.HasByFilter(ctx, Filter{
 		Name:         action.Name,
 		FolderId:     action.ParentFolderId,
@@ -142,7 +189,7 @@ func (f Folders) Create(ctx context.Context, action CreateFolder) (*uuid.UUID, e
 		Name:     action.Name,
 		FolderId: action.ParentFolderId,
 	}
-	err := f.folderRepository.Create(ctx, folder)
+	err = f.folderRepository.Create(ctx, folder)
 	if err != nil {
 		return nil, errors.NoType.Wrap(err, "error creating folder")
 	}
@@ -158,6 +205,11 @@ func (f Folders) Rename(ctx context.Context, action RenameFolder) error {
 	if err != nil {
 		return errors.NoType.Wrap(err, "error getting folder by id")
 	}
+
+	err = checkFolderAccess(ctx, f.folderRepository, &folder.Id, entity.PermissionManage, ErrFolderNotFound)
+	if err != nil {
+		return err
+	}
 	if folder.Name == action.Name {
 		return ErrFolderAlreadyHasSameName
 	}
@@ -206,6 +258,11 @@ func (f Folders) Move(ctx context.Context, action MoveFolder) error {
 	if err != nil {
 		return errors.NoType.Wrap(err, "error getting folder by id")
 	}
+
+	err = checkFolderAccess(ctx, f.folderRepository, &folder.Id, entity.PermissionManage, ErrFolderNotFound)
+	if err != nil {
+		return err
+	}
 	if folder.FolderId == action.ParentFolderId {
 		return ErrFolderAlreadyInThisFolder
 	}
@@ -217,6 +274,11 @@ func (f Folders) Move(ctx context.Context, action MoveFolder) error {
 		}
 	}
 
+	err = checkFolderAccess(ctx, f.folderRepository, action.ParentFolderId, entity.PermissionManage, ErrFolderNotFound)
+	if err != nil {
+		return err
+	}
+
 	hasSubFolder, err := f.folderRepository.HasSubFolder(ctx, action.Id, action.ParentFolderId)
 	if hasSubFolder {
 		return ErrFolderRecursiveAttachment
@@ -231,6 +293,17 @@ func (f Folders) Move(ctx context.Context, action MoveFolder) error {
 		return ErrFolderAlreadyExists
 	}
 
+	statistics, err := f.folderRepository.GetStatistics(ctx, &folder.Id)
+	if err != nil {
+		return errors.NoType.Wrap(err, "error getting folder statistics")
+	}
+	err = checkFolderQuota(
+		ctx, f.folderRepository, action.ParentFolderId, folder.FolderId, statistics.Size, statistics.Files,
+	)
+	if err != nil {
+		return err
+	}
+
 	folder.FolderId = action.ParentFolderId
 	err = f.folderRepository.Update(ctx, folder)
 	if err != nil {
@@ -269,6 +342,16 @@ func (f Folders) Delete(ctx context.Context, action GetFolder) error {
 		return ErrFolderNotFound
 	}
 
+	err := checkFolderAccess(ctx, f.folderRepository, &action.Id, entity.PermissionManage, ErrFolderNotFound)
+	if err != nil {
+		return err
+	}
+	// вложенные папки удаляются вместе с папкой
+	err = checkSubFoldersAccess(ctx, f.folderRepository, action.Id, entity.PermissionManage)
+	if err != nil {
+		return err
+	}
+
 	filePaths, err := f.folderRepository.GetFolderMediaFilePaths(ctx, &action.Id)
 	if err != nil {
 		return errors.NoType.Wrap(err, "error getting media file path")
@@ -289,3 +372,78 @@ func (f Folders) Delete(ctx context.Context, action GetFolder) error {
 
 	return nil
 }
+
+// GetPermissions получение собственных и унаследованных прав папки
+func (f Folders) GetPermissions(ctx context.Context, action GetFolder) (*FolderPermissionsList, error) {
+	if !f.folderRepository.Has(ctx, action.Id) {
+		return nil, ErrFolderNotFound
+	}
+
+	err := checkFolderAccess(ctx, f.folderRepository, &action.Id, entity.PermissionManage, ErrFolderNotFound)
+	if err != nil {
+		return nil, err
+	}
+
+	permissions, err := f.folderRepository.GetPermissions(ctx, action.Id)
+	if err != nil {
+		return nil, errors.NoType.Wrap(err, "error getting folder permissions")
+	}
+
+	res := &FolderPermissionsList{
+		Permissions: folderPermissionItems(permissions),
+		Inherited:   make([]FolderPermissionItem, 0),
+	}
+	if len(permissions) != 0 {
+		return res, nil
+	}
+
+	inherited, err := f.folderRepository.GetEffectivePermissions(ctx, action.Id)
+	if err != nil {
+		return nil, errors.NoType.Wrap(err, "error getting folder inherited permissions")
+	}
+	res.Inherited = folderPermissionItems(inherited)
+
+	return res, nil
+}
+
+// SetPermissions замена прав папки, пустой список включает наследование прав родительской папки
+func (f Folders) SetPermissions(ctx context.Context, action SetFolderPermissions) error {
+	if !f.folderRepository.Has(ctx, action.Id) {
+		return ErrFolderNotFound
+	}
+
+	err := checkFolderAccess(ctx, f.folderRepository, &action.Id, entity.PermissionManage, ErrFolderNotFound)
+	if err != nil {
+		return err
+	}
+
+	permissions := make([]entity.FolderPermission, len(action.Permissions))
+	for i, item := range action.Permissions {
+		if !item.Permission.Includes(entity.PermissionRead) {
+			return ErrFolderPermissionUnknown
+		}
+		permissions[i] = entity.FolderPermission{
+			FolderId:   action.Id,
+			Role:       item.Role,
+			Permission: item.Permission,
+		}
+	}
+
+	err = f.folderRepository.SetPermissions(ctx, action.Id, permissions...)
+	if err != nil {
+		return errors.NoType.Wrap(err, "error setting folder permissions")
+	}
+
+	f.GoAfterUpdate(action.Id)
+
+	return nil
+}
+
+func folderPermissionItems(permissions []entity.FolderPermission) []FolderPermissionItem {
+	res := make([]FolderPermissionItem, len(permissions))
+	for i, permission := range permissions {
+		res[i] = FolderPermissionItem{Role: permission.Role, Permission: permission.Permission}
+	}
+
+	return res
+}
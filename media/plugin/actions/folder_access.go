@@ -0,0 +1,123 @@
+package actions
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/aeroideaservices/focus/media/plugin/entity"
+	"github.com/aeroideaservices/focus/services/errors"
+)
+
+// AccessorKey ключ контекста, по которому хранится субъект доступа к медиабиблиотеке.
+// Строковый ключ позволяет передавать субъекта через gin.Context.Set
+const AccessorKey = "focus.media.accessor"
+
+// Accessor субъект доступа к медиабиблиотеке.
+// Если субъект не передан в контексте, права на папки не проверяются
+type Accessor struct {
+	Roles []string // Roles роли пользователя из access_control
+}
+
+// WithAccessor добавление субъекта доступа в контекст
+func WithAccessor(ctx context.Context, accessor *Accessor) context.Context {
+	return context.WithValue(ctx, AccessorKey, accessor)
+}
+
+// AccessorFromContext получение субъекта доступа из контекста
+func AccessorFromContext(ctx context.Context) *Accessor {
+	accessor, _ := ctx.Value(AccessorKey).(*Accessor)
+	return accessor
+}
+
+// permission максимальное право субъекта среди прав папки
+func (a Accessor) permission(permissions []entity.FolderPermission) entity.Permission {
+	var res entity.Permission
+	for _, permission := range permissions {
+		for _, role := range a.Roles {
+			if permission.Role != role {
+				continue
+			}
+			if res == "" || permission.Permission.Includes(res) {
+				res = permission.Permission
+			}
+		}
+	}
+
+	return res
+}
+
+// checkFolderAccess проверка права на папку с учетом наследования.
+// Если нет даже права на просмотр, возвращается notFound, чтобы папка оставалась невидимой
+func checkFolderAccess(
+	ctx context.Context,
+	folderRepository FolderRepository,
+	folderId *uuid.UUID,
+	permission entity.Permission,
+	notFound error,
+) error {
+	accessor := AccessorFromContext(ctx)
+	if accessor == nil || folderId == nil {
+		return nil
+	}
+
+	permissions, err := folderRepository.GetEffectivePermissions(ctx, *folderId)
+	if err != nil {
+		return errors.NoType.Wrap(err, "error getting folder permissions")
+	}
+	if len(permissions) == 0 {
+		return nil
+	}
+
+	granted := accessor.permission(permissions)
+	if !granted.Includes(entity.PermissionRead) {
+		return notFound
+	}
+	if !granted.Includes(permission) {
+		return ErrFolderAccessDenied
+	}
+
+	return nil
+}
+
+// checkSubFoldersAccess проверка права на все вложенные папки с учетом их собственных прав.
+// Нужна для действий над папкой вместе с содержимым: права на саму папку не дают права на вложенные папки
+// с собственными ограничениями
+func checkSubFoldersAccess(
+	ctx context.Context, folderRepository FolderRepository, folderId uuid.UUID, permission entity.Permission,
+) error {
+	denied, err := deniedFolderIds(ctx, folderRepository, permission)
+	if err != nil {
+		return err
+	}
+
+	for i := range denied {
+		if denied[i] == folderId {
+			continue
+		}
+		hasSubFolder, err := folderRepository.HasSubFolder(ctx, folderId, &denied[i])
+		if err != nil {
+			return errors.NoType.Wrap(err, "error checking sub folders permissions")
+		}
+		if hasSubFolder {
+			return ErrFolderAccessDenied
+		}
+	}
+
+	return nil
+}
+
+// deniedFolderIds получение id папок, на которые у субъекта нет переданного права
+func deniedFolderIds(ctx context.Context, folderRepository FolderRepository, permission entity.Permission) ([]uuid.UUID, error) {
+	accessor := AccessorFromContext(ctx)
+	if accessor == nil {
+		return nil, nil
+	}
+
+	ids, err := folderRepository.GetDeniedFolderIds(ctx, accessor.Roles, permission)
+	if err != nil {
+		return nil, errors.NoType.Wrap(err, "error getting denied folders")
+	}
+
+	return ids, nil
+}
@@ -0,0 +1,179 @@
+package actions
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/aeroideaservices/focus/media/plugin/entity"
+	"github.com/aeroideaservices/focus/media/plugin/service/utils"
+	"github.com/aeroideaservices/focus/services/errors"
+)
+
+// checkFolderQuota проверка квот папки и всех ее родительских папок при добавлении медиа.
+// Квоты, общие для исходной и целевой папок (при перемещении), не проверяются, так как использование в них не меняется
+func checkFolderQuota(
+	ctx context.Context,
+	folderRepository FolderRepository,
+	folderId *uuid.UUID,
+	sourceFolderId *uuid.UUID,
+	size int64,
+	files int64,
+) error {
+	return checkFolderQuotaMoves(ctx, folderRepository, folderId, quotaMove{
+		SourceFolderId: sourceFolderId,
+		Size:           size,
+		Files:          files,
+	})
+}
+
+// quotaMove медиа, добавляемые в папку из одной исходной папки
+type quotaMove struct {
+	SourceFolderId *uuid.UUID
+	Size           int64
+	Files          int64
+}
+
+// checkFolderQuotaMoves проверка квот папки и всех ее родительских папок при добавлении медиа из нескольких папок.
+// В каждую квоту засчитываются только медиа, исходная папка которых находится вне папки с квотой
+func checkFolderQuotaMoves(
+	ctx context.Context,
+	folderRepository FolderRepository,
+	folderId *uuid.UUID,
+	moves ...quotaMove,
+) error {
+	if folderId == nil || len(moves) == 0 {
+		return nil
+	}
+
+	usages, err := folderRepository.GetQuotaUsages(ctx, *folderId)
+	if err != nil {
+		return errors.NoType.Wrap(err, "error getting folder quotas")
+	}
+	if len(usages) == 0 {
+		return nil
+	}
+
+	sourceUsages := make([][]FolderQuotaUsage, len(moves))
+	for i, move := range moves {
+		if move.SourceFolderId == nil {
+			continue
+		}
+		sourceUsages[i], err = folderRepository.GetQuotaUsages(ctx, *move.SourceFolderId)
+		if err != nil {
+			return errors.NoType.Wrap(err, "error getting folder quotas")
+		}
+	}
+
+	for _, usage := range usages {
+		var size, files int64
+		for i, move := range moves {
+			if containsQuota(sourceUsages[i], usage.Id) {
+				continue
+			}
+			size += move.Size
+			files += move.Files
+		}
+		if usage.MaxSize != nil && usage.Size+size > *usage.MaxSize {
+			return ErrFolderQuotaExceeded
+		}
+		if usage.MaxFiles != nil && usage.Files+files > *usage.MaxFiles {
+			return ErrFolderQuotaExceeded
+		}
+	}
+
+	return nil
+}
+
+func containsQuota(usages []FolderQuotaUsage, id uuid.UUID) bool {
+	for _, usage := range usages {
+		if usage.Id == id {
+			return true
+		}
+	}
+	return false
+}
+
+// SetQuota изменение квот папки, пустое значение снимает квоту
+func (f Folders) SetQuota(ctx context.Context, action SetFolderQuota) error {
+	if !f.folderRepository.Has(ctx, action.Id) {
+		return ErrFolderNotFound
+	}
+
+	err := checkFolderAccess(ctx, f.folderRepository, &action.Id, entity.PermissionManage, ErrFolderNotFound)
+	if err != nil {
+		return err
+	}
+
+	err = f.folderRepository.SetQuota(ctx, action.Id, action.MaxSize, action.MaxFiles)
+	if err != nil {
+		return errors.NoType.Wrap(err, "error setting folder quota")
+	}
+
+	f.GoAfterUpdate(action.Id)
+
+	return nil
+}
+
+// GetStatistics получение статистики использования хранилища папкой вместе с вложенными папками:
+// по вложенным папкам, по расширениям и по месяцам загрузки
+func (f Folders) GetStatistics(ctx context.Context, action GetStatistics) (*FolderStatistics, error) {
+	res := &FolderStatistics{}
+	if action.FolderId != nil {
+		folder, err := f.folderRepository.Get(ctx, *action.FolderId)
+		if err != nil {
+			return nil, errors.NoType.Wrap(err, "error getting folder by id")
+		}
+		res.MaxSize, res.MaxFiles = folder.MaxSize, folder.MaxFiles
+	}
+
+	err := checkFolderAccess(ctx, f.folderRepository, action.FolderId, entity.PermissionRead, ErrFolderNotFound)
+	if err != nil {
+		return nil, err
+	}
+
+	statistics, err := f.folderRepository.GetStatistics(ctx, action.FolderId)
+	if err != nil {
+		return nil, errors.NoType.Wrap(err, "error getting folder statistics")
+	}
+
+	denied, err := deniedFolderIds(ctx, f.folderRepository, entity.PermissionRead)
+	if err != nil {
+		return nil, err
+	}
+
+	res.Size = utils.Filesize(statistics.Size)
+	res.Bytes = statistics.Size
+	res.Files = statistics.Files
+	res.Folders = make([]FolderUsageItem, 0, len(statistics.Folders))
+	for _, folder := range statistics.Folders {
+		if containsId(denied, folder.Id) {
+			continue
+		}
+		res.Folders = append(res.Folders, FolderUsageItem{
+			Id:    folder.Id,
+			Name:  folder.Name,
+			Size:  utils.Filesize(folder.Size),
+			Bytes: folder.Size,
+			Files: folder.Files,
+		})
+	}
+	res.Extensions = usageItems(statistics.Extensions)
+	res.Months = usageItems(statistics.Months)
+
+	return res, nil
+}
+
+func usageItems(groups []GroupUsage) []UsageItem {
+	res := make([]UsageItem, len(groups))
+	for i, group := range groups {
+		res[i] = UsageItem{
+			Key:   group.Group,
+			Size:  utils.Filesize(group.Size),
+			Bytes: group.Size,
+			Files: group.Files,
+		}
+	}
+
+	return res
+}
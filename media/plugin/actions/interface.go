@@ -15,6 +15,7 @@ type Filter struct {
 	Name         string
 	FolderId     *uuid.UUID `validate:"omitempty,notBlank"`
 	WithFolderId bool
+	ExcludeIds   []uuid.UUID // ExcludeIds папки, которые не попадают в выборку
 }
 
 type FolderDetail struct {
@@ -72,6 +73,48 @@ type FolderRepository interface {
 	GetFoldersTree(ctx context.Context) ([]*FolderResponse, error)
 	GetFoldersAndMedias(ctx context.Context, filter FolderFilter) (*FoldersAndMediasList, error)
 	GetFolderParents(ctx context.Context, filter Filter) ([]FolderResponse, error)
+
+	GetPermissions(ctx context.Context, id uuid.UUID) ([]entity2.FolderPermission, error)
+	GetEffectivePermissions(ctx context.Context, id uuid.UUID) ([]entity2.FolderPermission, error)
+	SetPermissions(ctx context.Context, id uuid.UUID, permissions ...entity2.FolderPermission) error
+	GetDeniedFolderIds(ctx context.Context, roles []string, permission entity2.Permission) ([]uuid.UUID, error)
+
+	GetQuotaUsages(ctx context.Context, id uuid.UUID) ([]FolderQuotaUsage, error)
+	SetQuota(ctx context.Context, id uuid.UUID, maxSize *int64, maxFiles *int64) error
+	GetStatistics(ctx context.Context, folderId *uuid.UUID) (*UsageStatistics, error)
+}
+
+// FolderQuotaUsage квота папки и текущее использование папки вместе с вложенными папками
+type FolderQuotaUsage struct {
+	Id       uuid.UUID
+	MaxSize  *int64
+	MaxFiles *int64
+	Size     int64
+	Files    int64
+}
+
+// UsageStatistics статистика использования хранилища
+type UsageStatistics struct {
+	Size       int64
+	Files      int64
+	Folders    []FolderUsage
+	Extensions []GroupUsage
+	Months     []GroupUsage
+}
+
+// FolderUsage использование хранилища папкой вместе с вложенными папками
+type FolderUsage struct {
+	Id    uuid.UUID
+	Name  string
+	Size  int64
+	Files int64
+}
+
+// GroupUsage использование хранилища группой медиа
+type GroupUsage struct {
+	Group string
+	Size  int64
+	Files int64
 }
 
 type UpdateMediaDto struct {
@@ -106,6 +149,44 @@ type MediaRepository interface {
 	Count(ctx context.Context, filter MediaFilter) (int, error)
 
 	UpdateSubtitles(ctx context.Context, id uuid.UUID, subtitles json.JSONB) error
+
+	ReplaceContent(ctx context.Context, dto ReplaceMediaContentDto) error
+	GetVersion(ctx context.Context, mediaId uuid.UUID, versionId uuid.UUID) (*entity2.MediaVersion, error)
+	ListVersions(ctx context.Context, mediaIds ...uuid.UUID) ([]entity2.MediaVersion, error)
+
+	UpdateFocus(ctx context.Context, id uuid.UUID, focalPoint *entity2.FocalPoint, crops entity2.Crops) error
+	GetDerivative(ctx context.Context, mediaId uuid.UUID, crop string, width int, height int) (*entity2.MediaDerivative, error)
+	CreateDerivative(ctx context.Context, derivative entity2.MediaDerivative) error
+	ListDerivatives(ctx context.Context, mediaIds ...uuid.UUID) ([]entity2.MediaDerivative, error)
+	DeleteDerivatives(ctx context.Context, mediaIds ...uuid.UUID) error
+
+	ListSubtitles(ctx context.Context, mediaId uuid.UUID) ([]entity2.MediaSubtitle, error)
+	GetSubtitles(ctx context.Context, mediaId uuid.UUID, language string) (*entity2.MediaSubtitle, error)
+	SaveSubtitles(ctx context.Context, subtitle entity2.MediaSubtitle) error
+	DeleteSubtitles(ctx context.Context, mediaId uuid.UUID, language string) error
+
+	GetStream(ctx context.Context, mediaId uuid.UUID) (*entity2.MediaStream, error)
+	SaveStream(ctx context.Context, stream entity2.MediaStream) error
+	ListStreams(ctx context.Context, mediaIds ...uuid.UUID) ([]entity2.MediaStream, error)
+	DeleteStreams(ctx context.Context, mediaIds ...uuid.UUID) error
+}
+
+// ReplaceMediaContentDto замена содержимого медиа с сохранением предыдущей версии
+type ReplaceMediaContentDto struct {
+	Id                uuid.UUID            // Id медиа
+	Size              int64                // Size размер нового содержимого
+	Width             int                  // Width ширина нового содержимого, если это изображение
+	Height            int                  // Height высота нового содержимого, если это изображение
+	Version           entity2.MediaVersion // Version версия, в которую сохраняется текущее содержимое
+	RestoredVersionId *uuid.UUID           // RestoredVersionId версия, которая восстанавливается (удаляется из истории)
+}
+
+// BulkRepository репозиторий массовых операций над папками и медиа, каждая операция выполняется в одной транзакции
+type BulkRepository interface {
+	Move(ctx context.Context, dto BulkMove, moveFiles func(medias []*UpdateMediaDto) error) error
+	Delete(ctx context.Context, folderIds []uuid.UUID, mediaIds []uuid.UUID) error
+	UpdateTags(ctx context.Context, dto BulkTag) ([]uuid.UUID, error)
+	UpdateFields(ctx context.Context, items ...BulkMediaFields) error
 }
 
 type UploadFile struct {
@@ -2,6 +2,7 @@ package actions
 
 import (
 	"context"
+	"image"
 	"mime"
 	"path/filepath"
 	"reflect"
@@ -59,6 +60,11 @@ func (m Medias) List(ctx context.Context, dto ListMediasShorts) (*MediaShortList
 			Url:   m.mediaProvider.GetUrlByFilepath(media.Filepath),
 			Alt:   media.Alt,
 			Title: media.Title,
+
+			Width:      media.Width,
+			Height:     media.Height,
+			FocalPoint: media.FocalPoint,
+			Crops:      media.Crops,
 		}
 	}
 
@@ -85,6 +91,11 @@ func (m Medias) Create(ctx context.Context, action CreateMedia) (*uuid.UUID, err
 		}
 	}
 
+	err = checkFolderAccess(ctx, m.folderRepository, action.FolderId, entity.PermissionUpload, ErrFolderNotFound)
+	if err != nil {
+		return nil, err
+	}
+
 	hasMedia, mediaId := m.mediaRepository.HasByFilterWithId(
 		ctx, MediaFilter{
 			FolderId:     action.FolderId,
@@ -92,6 +103,22 @@ func (m Medias) Create(ctx context.Context, action CreateMedia) (*uuid.UUID, err
 			Filename:     action.Filename,
 		},
 	)
+
+	// при повторной загрузке файла с тем же именем его содержимое заменяется
+	addSize, addFiles := action.Size, int64(1)
+	if hasMedia {
+		existing, err := m.mediaRepository.Get(ctx, mediaId)
+		if err != nil {
+			return nil, errors.NoType.Wrap(err, "error getting media by id")
+		}
+		addSize, addFiles = action.Size-existing.Size, 0
+	}
+	err = checkFolderQuota(ctx, m.folderRepository, action.FolderId, nil, addSize, addFiles)
+	if err != nil {
+		return nil, err
+	}
+
+	width, height := utils.ImageSize(action.File)
 	if hasMedia {
 		mediaFilepath := filepath.Join(folderPath, action.Filename)
 		saveMediaFile := &UploadFile{
@@ -104,6 +131,16 @@ func (m Medias) Create(ctx context.Context, action CreateMedia) (*uuid.UUID, err
 			return nil, errors.NoType.Wrap(err, "error uploading media file")
 		}
 
+		err = m.deleteDerivatives(ctx, mediaId)
+		if err != nil {
+			return nil, err
+		}
+
+		err = m.deleteStreams(ctx, mediaId)
+		if err != nil {
+			return nil, err
+		}
+
 		// updateMediaDto := &UpdateMediaDto{
 		// 	Id:       mediaId,
 		// 	Name:     strings.TrimSuffix(action.Filename, filepath.Ext(action.Filename)),
@@ -141,6 +178,8 @@ func (m Medias) Create(ctx context.Context, action CreateMedia) (*uuid.UUID, err
 		Size:     action.Size,
 		Filepath: mediaFilepath,
 		FolderId: action.FolderId,
+		Width:    width,
+		Height:   height,
 	}
 	err = m.mediaRepository.Create(ctx, media)
 	if err != nil {
@@ -199,9 +238,37 @@ func (m Medias) UploadList(ctx context.Context, dto CreateMediasList) ([]uuid.UU
 		}
 	}
 
+	err = checkFolderAccess(ctx, m.folderRepository, dto.FolderId, entity.PermissionUpload, ErrFolderNotFound)
+	if err != nil {
+		return nil, err
+	}
+
 	filenames := make([]string, len(dto.Files))
+	var addSize, addFiles int64
 	for i, file := range dto.Files {
 		filenames[i] = file.Filename
+		hasMedia, mediaId := m.mediaRepository.HasByFilterWithId(ctx, MediaFilter{
+			FolderId:     dto.FolderId,
+			WithFolderId: true,
+			Filename:     file.Filename,
+		})
+		if !hasMedia {
+			addSize += file.Size
+			addFiles++
+			continue
+		}
+
+		// файл с тем же именем заменяется, учитывается только изменение размера, как в Create
+		existing, err := m.mediaRepository.Get(ctx, mediaId)
+		if err != nil {
+			return nil, errors.NoType.Wrap(err, "error getting media by id")
+		}
+		addSize += file.Size - existing.Size
+	}
+
+	err = checkFolderQuota(ctx, m.folderRepository, dto.FolderId, nil, addSize, addFiles)
+	if err != nil {
+		return nil, err
 	}
 
 	//hasMedia := m.mediaRepository.HasByFilter(ctx, MediaFilter{
@@ -214,7 +281,9 @@ func (m Medias) UploadList(ctx context.Context, dto CreateMediasList) ([]uuid.UU
 	//}
 
 	createMediaFiles := make([]UploadFile, len(dto.Files))
+	sizes := make([]image.Point, len(dto.Files))
 	for i, file := range dto.Files {
+		sizes[i].X, sizes[i].Y = utils.ImageSize(file.File)
 		createMediaFiles[i] = UploadFile{
 			Key:         filepath.Join(folderPath, file.Filename),
 			ContentType: mime.TypeByExtension(filepath.Ext(file.Filename)),
@@ -250,6 +319,8 @@ func (m Medias) UploadList(ctx context.Context, dto CreateMediasList) ([]uuid.UU
 					Size:     f.Size,
 					Filepath: createMediaFiles[i].Key,
 					FolderId: dto.FolderId,
+					Width:    sizes[i].X,
+					Height:   sizes[i].Y,
 				},
 			)
 		}
@@ -274,6 +345,11 @@ func (m Medias) Get(ctx context.Context, dto GetMedia) (*MediaPreview, error) {
 		return nil, errors.NoType.Wrap(err, "error getting media by id")
 	}
 
+	err = checkFolderAccess(ctx, m.folderRepository, media.FolderId, entity.PermissionRead, ErrMediaNotFound)
+	if err != nil {
+		return nil, err
+	}
+
 	res := &MediaPreview{
 		Id:          media.Id,
 		Name:        media.Name,
@@ -285,11 +361,32 @@ func (m Medias) Get(ctx context.Context, dto GetMedia) (*MediaPreview, error) {
 		Url:         m.mediaProvider.GetUrlByFilepath(media.Filepath),
 		UpdatedAt:   utils.Time(media.UpdatedAt),
 		FolderId:    media.FolderId,
+		Tags:        media.Tags,
+		Width:       media.Width,
+		Height:      media.Height,
+		FocalPoint:  media.FocalPoint,
+		Crops:       media.Crops,
 	}
 
 	return res, nil
 }
 
+// Find получение медиа по имени файла в папке
+func (m Medias) Find(ctx context.Context, dto FindMedia) (*MediaPreview, error) {
+	hasMedia, mediaId := m.mediaRepository.HasByFilterWithId(
+		ctx, MediaFilter{
+			FolderId:     dto.FolderId,
+			WithFolderId: true,
+			Filename:     dto.Filename,
+		},
+	)
+	if !hasMedia {
+		return nil, ErrMediaNotFound
+	}
+
+	return m.Get(ctx, GetMedia{Id: mediaId})
+}
+
 // Rename переименование медиа
 func (m Medias) Rename(ctx context.Context, dto RenameMedia) error {
 	media, err := m.mediaRepository.Get(ctx, dto.Id)
@@ -300,6 +397,11 @@ func (m Medias) Rename(ctx context.Context, dto RenameMedia) error {
 		return ErrMediaAlreadyHasSameName
 	}
 
+	err = checkFolderAccess(ctx, m.folderRepository, media.FolderId, entity.PermissionManage, ErrMediaNotFound)
+	if err != nil {
+		return err
+	}
+
 	folderPath := ""
 	if media.FolderId != nil {
 		folderPath, err = m.folderRepository.GetFolderPath(ctx, *media.FolderId)
@@ -354,6 +456,11 @@ func (m Medias) Move(ctx context.Context, dto MoveMedia) error {
 		return ErrMediaAlreadyHasSameFolder
 	}
 
+	err = checkFolderAccess(ctx, m.folderRepository, media.FolderId, entity.PermissionManage, ErrMediaNotFound)
+	if err != nil {
+		return err
+	}
+
 	folderPath := ""
 	if dto.FolderId != nil {
 		hasFolder := m.folderRepository.Has(ctx, *dto.FolderId)
@@ -367,6 +474,16 @@ func (m Medias) Move(ctx context.Context, dto MoveMedia) error {
 		}
 	}
 
+	err = checkFolderAccess(ctx, m.folderRepository, dto.FolderId, entity.PermissionUpload, ErrFolderNotFound)
+	if err != nil {
+		return err
+	}
+
+	err = checkFolderQuota(ctx, m.folderRepository, dto.FolderId, media.FolderId, media.Size, 1)
+	if err != nil {
+		return err
+	}
+
 	hasMedia := m.mediaRepository.HasByFilter(
 		ctx, MediaFilter{
 			Filename:     media.Filename,
@@ -409,11 +526,31 @@ func (m Medias) Delete(ctx context.Context, dto GetMedia) error {
 		return errors.NoType.Wrap(err, "error getting media by id")
 	}
 
+	err = checkFolderAccess(ctx, m.folderRepository, media.FolderId, entity.PermissionManage, ErrMediaNotFound)
+	if err != nil {
+		return err
+	}
+
 	err = m.storage.Delete(ctx, media.Filepath)
 	if err != nil {
 		return errors.NoType.Wrap(err, "error deleting media file")
 	}
 
+	err = m.deleteVersionsFiles(ctx, media.Id)
+	if err != nil {
+		return errors.NoType.Wrap(err, "error deleting media versions files")
+	}
+
+	err = m.deleteDerivatives(ctx, media.Id)
+	if err != nil {
+		return err
+	}
+
+	err = m.deleteStreams(ctx, media.Id)
+	if err != nil {
+		return err
+	}
+
 	err = m.mediaRepository.Delete(ctx, dto.Id)
 	if err != nil {
 		return errors.NoType.Wrap(err, "error deleting media")
@@ -442,6 +579,11 @@ func (m Medias) Download(ctx context.Context, dto GetMedia) (string, error) {
 	if err != nil {
 		return "", errors.NoType.Wrap(err, "error getting media by id")
 	}
+
+	err = checkFolderAccess(ctx, m.folderRepository, media.FolderId, entity.PermissionRead, ErrMediaNotFound)
+	if err != nil {
+		return "", err
+	}
 	err = m.storage.DownloadFile(ctx, media.Filepath, media.Filename)
 	if err != nil {
 		return "", errors.NoType.Wrap(err, "error downloading media file")
@@ -457,6 +599,11 @@ func (m Medias) UpdateSubtitles(ctx context.Context, dto UpdateMediaSubtitles) e
 		return errors.NoType.Wrap(err, "error getting media by id")
 	}
 
+	err = checkFolderAccess(ctx, m.folderRepository, media.FolderId, entity.PermissionManage, ErrMediaNotFound)
+	if err != nil {
+		return err
+	}
+
 	err = m.mediaRepository.UpdateSubtitles(ctx, dto.Id, dto.Subtitles)
 	if err != nil {
 		return errors.NoType.Wrap(err, "error updating media")
@@ -0,0 +1,302 @@
+package actions
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"math"
+	"mime"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/aeroideaservices/focus/media/plugin/entity"
+	"github.com/aeroideaservices/focus/media/plugin/service/utils"
+	"github.com/aeroideaservices/focus/services/errors"
+)
+
+const (
+	derivativesDir      = ".derivatives" // derivativesDir директория хранилища, в которой хранятся производные изображения медиа
+	aspectRatioAccuracy = 0.01           // aspectRatioAccuracy допустимое относительное отклонение соотношения сторон
+)
+
+// UpdateFocus изменение точки фокуса и областей кадрирования изображения.
+// Ранее сформированные производные изображения удаляются.
+func (m Medias) UpdateFocus(ctx context.Context, dto UpdateMediaFocus) error {
+	media, err := m.mediaRepository.Get(ctx, dto.Id)
+	if err != nil {
+		return errors.NoType.Wrap(err, "error getting media by id")
+	}
+
+	err = checkFolderAccess(ctx, m.folderRepository, media.FolderId, entity.PermissionManage, ErrMediaNotFound)
+	if err != nil {
+		return err
+	}
+	if media.Width == 0 || media.Height == 0 {
+		return ErrMediaNotImage
+	}
+
+	err = m.mediaRepository.UpdateFocus(ctx, media.Id, dto.FocalPoint, dto.Crops)
+	if err != nil {
+		return errors.NoType.Wrap(err, "error updating media focus")
+	}
+
+	err = m.deleteDerivatives(ctx, media.Id)
+	if err != nil {
+		return err
+	}
+
+	m.GoAfterUpdate(media.Id)
+
+	return nil
+}
+
+// GetDerivative получение производного изображения медиа.
+// Изображение кадрируется по области кадрирования (если указана) и по соотношению сторон запрошенного размера
+// с учетом точки фокуса, затем уменьшается до запрошенного размера. Сформированное изображение сохраняется
+// в хранилище и переиспользуется при повторных запросах.
+func (m Medias) GetDerivative(ctx context.Context, dto GetMediaDerivative) (*MediaDerivativePreview, error) {
+	media, err := m.mediaRepository.Get(ctx, dto.Id)
+	if err != nil {
+		return nil, errors.NoType.Wrap(err, "error getting media by id")
+	}
+
+	err = checkFolderAccess(ctx, m.folderRepository, media.FolderId, entity.PermissionRead, ErrMediaNotFound)
+	if err != nil {
+		return nil, err
+	}
+	if media.Width == 0 || media.Height == 0 {
+		return nil, ErrMediaNotImage
+	}
+
+	var crop *entity.Crop
+	if dto.Crop != "" {
+		crop = media.Crops.Get(dto.Crop)
+		if crop == nil {
+			return nil, ErrMediaCropNotFound
+		}
+	}
+
+	_, width, height := derivativeRect(image.Rect(0, 0, media.Width, media.Height), media.FocalPoint, crop, dto.Width, dto.Height)
+	derivative, err := m.mediaRepository.GetDerivative(ctx, media.Id, dto.Crop, dto.Width, dto.Height)
+	if err == nil {
+		return m.derivativePreview(derivative, width, height), nil
+	}
+	if !errors.Is(err, ErrMediaDerivativeNotFound) {
+		return nil, errors.NoType.Wrap(err, "error getting media derivative")
+	}
+
+	src, format, err := m.downloadImage(ctx, media)
+	if err != nil {
+		return nil, err
+	}
+
+	rect, width, height := derivativeRect(src.Bounds(), media.FocalPoint, crop, dto.Width, dto.Height)
+	buf := &bytes.Buffer{}
+	err = utils.EncodeImage(buf, utils.Resize(src, rect, width, height), format)
+	if err != nil {
+		return nil, errors.NoType.Wrap(err, "error encoding media derivative")
+	}
+
+	id := uuid.New()
+	derivative = &entity.MediaDerivative{
+		Id:       id,
+		MediaId:  media.Id,
+		Crop:     dto.Crop,
+		Width:    dto.Width,
+		Height:   dto.Height,
+		Filepath: path.Join(derivativesDir, media.Id.String(), id.String()+derivativeExt(format)),
+	}
+	err = m.storage.Upload(ctx, &UploadFile{
+		Key:         derivative.Filepath,
+		ContentType: mime.TypeByExtension(derivativeExt(format)),
+		File:        bytes.NewReader(buf.Bytes()),
+	})
+	if err != nil {
+		return nil, errors.NoType.Wrap(err, "error uploading media derivative")
+	}
+
+	err = m.mediaRepository.CreateDerivative(ctx, *derivative)
+	if err != nil {
+		_ = m.storage.Delete(ctx, derivative.Filepath)
+		return nil, errors.NoType.Wrap(err, "error creating media derivative")
+	}
+
+	return m.derivativePreview(derivative, width, height), nil
+}
+
+// CheckAlt проверяет, что у всех медиа заполнен альтернативный текст
+func (m Medias) CheckAlt(ctx context.Context, ids ...uuid.UUID) error {
+	medias, err := m.mediaRepository.GetShortList(ctx, ids)
+	if err != nil {
+		return errors.NoType.Wrap(err, "error getting medias by ids")
+	}
+
+	for _, media := range medias {
+		if strings.TrimSpace(media.Alt) == "" {
+			return ErrMediaAltRequired
+		}
+	}
+
+	return nil
+}
+
+// CheckAspectRatio проверяет, что все медиа являются изображениями с соотношением сторон ratio
+// либо имеют область кадрирования с таким соотношением сторон
+func (m Medias) CheckAspectRatio(ctx context.Context, ratio float64, ids ...uuid.UUID) error {
+	medias, err := m.mediaRepository.GetShortList(ctx, ids)
+	if err != nil {
+		return errors.NoType.Wrap(err, "error getting medias by ids")
+	}
+
+	for _, media := range medias {
+		if media.Width == 0 || media.Height == 0 {
+			return ErrMediaNotImage
+		}
+		if !hasAspectRatio(media, ratio) {
+			return ErrMediaAspectRatioMismatch
+		}
+	}
+
+	return nil
+}
+
+// hasAspectRatio проверяет соотношение сторон изображения и его областей кадрирования
+func hasAspectRatio(media entity.Media, ratio float64) bool {
+	if sameAspectRatio(float64(media.Width)/float64(media.Height), ratio) {
+		return true
+	}
+	for _, crop := range media.Crops {
+		if sameAspectRatio(crop.AspectRatio(media.Width, media.Height), ratio) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func sameAspectRatio(a, b float64) bool {
+	return b > 0 && math.Abs(a-b)/b <= aspectRatioAccuracy
+}
+
+// derivativeRect получение области исходного изображения и итоговых размеров производного изображения.
+// Изображение не увеличивается: если запрошенный размер больше области, он пропорционально уменьшается.
+func derivativeRect(bounds image.Rectangle, focalPoint *entity.FocalPoint, crop *entity.Crop, width, height int) (image.Rectangle, int, int) {
+	rect := bounds
+	if crop != nil {
+		rect = utils.RelativeRect(bounds, crop.X, crop.Y, crop.Width, crop.Height)
+		if rect.Empty() {
+			rect = bounds
+		}
+	}
+
+	if width != 0 && height != 0 {
+		fx, fy := rect.Min.X+rect.Dx()/2, rect.Min.Y+rect.Dy()/2
+		if focalPoint != nil {
+			fx = bounds.Min.X + int(math.Round(focalPoint.X*float64(bounds.Dx())))
+			fy = bounds.Min.Y + int(math.Round(focalPoint.Y*float64(bounds.Dy())))
+		}
+		rect = utils.FocusRect(rect, float64(width)/float64(height), fx, fy)
+	}
+
+	switch {
+	case width == 0 && height == 0:
+		width, height = rect.Dx(), rect.Dy()
+	case width == 0:
+		width = int(math.Round(float64(height) * float64(rect.Dx()) / float64(rect.Dy())))
+	case height == 0:
+		height = int(math.Round(float64(width) * float64(rect.Dy()) / float64(rect.Dx())))
+	}
+
+	if width > rect.Dx() || height > rect.Dy() {
+		scale := math.Min(float64(rect.Dx())/float64(width), float64(rect.Dy())/float64(height))
+		width, height = int(math.Round(float64(width)*scale)), int(math.Round(float64(height)*scale))
+	}
+	if width < 1 {
+		width = 1
+	}
+	if height < 1 {
+		height = 1
+	}
+
+	return rect, width, height
+}
+
+// downloadImage загрузка и декодирование исходного изображения медиа
+func (m Medias) downloadImage(ctx context.Context, media *entity.Media) (image.Image, string, error) {
+	tmp, err := os.CreateTemp("", "focus-media-*"+filepath.Ext(media.Filename))
+	if err != nil {
+		return nil, "", errors.NoType.Wrap(err, "error creating temp file")
+	}
+	tmpName := tmp.Name()
+	_ = tmp.Close()
+	defer os.Remove(tmpName)
+
+	err = m.storage.DownloadFile(ctx, media.Filepath, tmpName)
+	if err != nil {
+		return nil, "", errors.NoType.Wrap(err, "error downloading media file")
+	}
+
+	file, err := os.Open(tmpName)
+	if err != nil {
+		return nil, "", errors.NoType.Wrap(err, "error opening media file")
+	}
+	defer file.Close()
+
+	img, format, err := image.Decode(file)
+	if err != nil {
+		return nil, "", ErrMediaNotImage
+	}
+
+	return img, format, nil
+}
+
+func (m Medias) derivativePreview(derivative *entity.MediaDerivative, width, height int) *MediaDerivativePreview {
+	return &MediaDerivativePreview{
+		Url:    m.mediaProvider.GetUrlByFilepath(derivative.Filepath),
+		Width:  width,
+		Height: height,
+	}
+}
+
+// deleteDerivatives удаление производных изображений медиа из хранилища и базы
+func (m Medias) deleteDerivatives(ctx context.Context, mediaIds ...uuid.UUID) error {
+	derivatives, err := m.mediaRepository.ListDerivatives(ctx, mediaIds...)
+	if err != nil {
+		return errors.NoType.Wrap(err, "error getting media derivatives")
+	}
+	if len(derivatives) == 0 {
+		return nil
+	}
+
+	keys := make([]string, len(derivatives))
+	for i, derivative := range derivatives {
+		keys[i] = derivative.Filepath
+	}
+
+	err = m.storage.Delete(ctx, keys...)
+	if err != nil {
+		return errors.NoType.Wrap(err, "error deleting media derivatives files")
+	}
+
+	err = m.mediaRepository.DeleteDerivatives(ctx, mediaIds...)
+	if err != nil {
+		return errors.NoType.Wrap(err, "error deleting media derivatives")
+	}
+
+	return nil
+}
+
+func derivativeExt(format string) string {
+	switch format {
+	case "jpeg":
+		return ".jpg"
+	case "gif":
+		return ".gif"
+	default:
+		return ".png"
+	}
+}
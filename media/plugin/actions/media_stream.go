@@ -0,0 +1,260 @@
+package actions
+
+import (
+	"context"
+	"io/fs"
+	"mime"
+	"os"
+	"path"
+	"path/filepath"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/aeroideaservices/focus/media/plugin/entity"
+	"github.com/aeroideaservices/focus/media/plugin/service/utils"
+	"github.com/aeroideaservices/focus/services/errors"
+)
+
+const (
+	StreamMasterPlaylist = "master.m3u8" // StreamMasterPlaylist имя мастер-плейлиста потока
+	streamDir            = "hls"         // streamDir директория потока внутри директории производных файлов медиа
+)
+
+// streamContentTypes типы содержимого файлов потока
+var streamContentTypes = map[string]string{
+	".m3u8": "application/vnd.apple.mpegurl",
+	".ts":   "video/mp2t",
+	".m4s":  "video/iso.segment",
+	".mp4":  "video/mp4",
+}
+
+// CreateStream постановка потока медиа в очередь на формирование.
+// Ранее сформированный поток удаляется. Возвращается ссылка на мастер-плейлист,
+// который станет доступен после формирования потока.
+func (m Medias) CreateStream(ctx context.Context, dto GetMedia) (*MediaStreamPreview, error) {
+	media, err := m.mediaRepository.Get(ctx, dto.Id)
+	if err != nil {
+		return nil, errors.NoType.Wrap(err, "error getting media by id")
+	}
+
+	err = checkFolderAccess(ctx, m.folderRepository, media.FolderId, entity.PermissionManage, ErrMediaNotFound)
+	if err != nil {
+		return nil, err
+	}
+
+	err = m.deleteStreams(ctx, media.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	stream := entity.MediaStream{
+		MediaId:   media.Id,
+		Status:    entity.StreamStatusPending,
+		Playlist:  path.Join(streamPath(media.Id), StreamMasterPlaylist),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	err = m.mediaRepository.SaveStream(ctx, stream)
+	if err != nil {
+		return nil, errors.NoType.Wrap(err, "error saving media stream")
+	}
+
+	return m.streamPreview(&stream), nil
+}
+
+// UpdateStreamStatus изменение статуса формирования потока медиа
+func (m Medias) UpdateStreamStatus(ctx context.Context, dto UpdateMediaStreamStatus) error {
+	stream, err := m.mediaRepository.GetStream(ctx, dto.Id)
+	if err != nil {
+		return errors.NoType.Wrap(err, "error getting media stream")
+	}
+
+	stream.Status = dto.Status
+	stream.Error = dto.Error
+	stream.UpdatedAt = time.Now()
+	err = m.mediaRepository.SaveStream(ctx, *stream)
+	if err != nil {
+		return errors.NoType.Wrap(err, "error saving media stream")
+	}
+
+	return nil
+}
+
+// UploadStream загрузка сформированного потока медиа из локальной директории в хранилище.
+// Директория должна содержать мастер-плейлист StreamMasterPlaylist, пути плейлистов вариантов указываются относительно нее.
+func (m Medias) UploadStream(ctx context.Context, dto UploadMediaStream) error {
+	stream, err := m.mediaRepository.GetStream(ctx, dto.Id)
+	if err != nil {
+		return errors.NoType.Wrap(err, "error getting media stream")
+	}
+
+	_, err = os.Stat(filepath.Join(dto.Dir, StreamMasterPlaylist))
+	if err != nil {
+		return ErrMediaStreamPlaylistNotFound
+	}
+
+	prefix := streamPath(stream.MediaId)
+	var keys []string
+	err = filepath.WalkDir(dto.Dir, func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(dto.Dir, name)
+		if err != nil {
+			return err
+		}
+
+		key := path.Join(prefix, filepath.ToSlash(rel))
+		err = m.uploadStreamFile(ctx, key, name)
+		if err != nil {
+			return err
+		}
+		keys = append(keys, key)
+
+		return nil
+	})
+	if err != nil {
+		if len(keys) != 0 {
+			_ = m.storage.Delete(ctx, keys...)
+		}
+		return errors.NoType.Wrap(err, "error uploading media stream")
+	}
+
+	variants := make(entity.StreamVariants, len(dto.Variants))
+	for i, variant := range dto.Variants {
+		variant.Playlist = path.Join(prefix, variant.Playlist)
+		variants[i] = variant
+	}
+
+	stale := make(map[string]bool, len(stream.Files))
+	for _, key := range stream.Files {
+		stale[key] = true
+	}
+	for _, key := range keys {
+		delete(stale, key)
+	}
+
+	stream.Status = entity.StreamStatusReady
+	stream.Playlist = path.Join(prefix, StreamMasterPlaylist)
+	stream.Variants = variants
+	stream.Files = keys
+	stream.Error = ""
+	stream.UpdatedAt = time.Now()
+	err = m.mediaRepository.SaveStream(ctx, *stream)
+	if err != nil {
+		_ = m.storage.Delete(ctx, keys...)
+		return errors.NoType.Wrap(err, "error saving media stream")
+	}
+
+	// файлы предыдущего потока, которые не были перезаписаны
+	if len(stale) != 0 {
+		staleKeys := make([]string, 0, len(stale))
+		for key := range stale {
+			staleKeys = append(staleKeys, key)
+		}
+		err = m.storage.Delete(ctx, staleKeys...)
+		if err != nil {
+			return errors.NoType.Wrap(err, "error deleting media stream files")
+		}
+	}
+
+	m.GoAfterUpdate(stream.MediaId)
+
+	return nil
+}
+
+// GetStream получение потока медиа
+func (m Medias) GetStream(ctx context.Context, dto GetMedia) (*MediaStreamPreview, error) {
+	media, err := m.mediaRepository.Get(ctx, dto.Id)
+	if err != nil {
+		return nil, errors.NoType.Wrap(err, "error getting media by id")
+	}
+
+	err = checkFolderAccess(ctx, m.folderRepository, media.FolderId, entity.PermissionRead, ErrMediaNotFound)
+	if err != nil {
+		return nil, err
+	}
+
+	stream, err := m.mediaRepository.GetStream(ctx, media.Id)
+	if err != nil {
+		return nil, errors.NoType.Wrap(err, "error getting media stream")
+	}
+
+	return m.streamPreview(stream), nil
+}
+
+func (m Medias) uploadStreamFile(ctx context.Context, key string, name string) error {
+	file, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	contentType, ok := streamContentTypes[filepath.Ext(name)]
+	if !ok {
+		contentType = mime.TypeByExtension(filepath.Ext(name))
+	}
+
+	return m.storage.Upload(ctx, &UploadFile{
+		Key:         key,
+		ContentType: contentType,
+		File:        file,
+	})
+}
+
+func (m Medias) streamPreview(stream *entity.MediaStream) *MediaStreamPreview {
+	preview := &MediaStreamPreview{
+		Status:      stream.Status,
+		PlaylistUrl: m.mediaProvider.GetUrlByFilepath(stream.Playlist),
+		Variants:    make([]MediaStreamVariantPreview, len(stream.Variants)),
+		Error:       stream.Error,
+		UpdatedAt:   utils.Time(stream.UpdatedAt),
+	}
+	for i, variant := range stream.Variants {
+		preview.Variants[i] = MediaStreamVariantPreview{
+			Name:      variant.Name,
+			Width:     variant.Width,
+			Height:    variant.Height,
+			Bandwidth: variant.Bandwidth,
+			Url:       m.mediaProvider.GetUrlByFilepath(variant.Playlist),
+		}
+	}
+
+	return preview
+}
+
+// deleteStreams удаление потоков медиа вместе с файлами
+func (m Medias) deleteStreams(ctx context.Context, mediaIds ...uuid.UUID) error {
+	streams, err := m.mediaRepository.ListStreams(ctx, mediaIds...)
+	if err != nil {
+		return errors.NoType.Wrap(err, "error getting media streams")
+	}
+	if len(streams) == 0 {
+		return nil
+	}
+
+	var keys []string
+	for _, stream := range streams {
+		keys = append(keys, stream.Files...)
+	}
+	if len(keys) != 0 {
+		err = m.storage.Delete(ctx, keys...)
+		if err != nil {
+			return errors.NoType.Wrap(err, "error deleting media streams files")
+		}
+	}
+
+	err = m.mediaRepository.DeleteStreams(ctx, mediaIds...)
+	if err != nil {
+		return errors.NoType.Wrap(err, "error deleting media streams")
+	}
+
+	return nil
+}
+
+// streamPath директория потока медиа в хранилище
+func streamPath(mediaId uuid.UUID) string {
+	return path.Join(derivativesDir, mediaId.String(), streamDir)
+}
@@ -0,0 +1,178 @@
+package actions
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/aeroideaservices/focus/media/plugin/entity"
+	"github.com/aeroideaservices/focus/media/plugin/service/subtitles"
+	"github.com/aeroideaservices/focus/media/plugin/service/utils"
+	"github.com/aeroideaservices/focus/services/errors"
+)
+
+// subtitlesInvalidMsg ключ перевода ошибки разбора или проверки субтитров, параметр — описание ошибки
+const subtitlesInvalidMsg = "media.subtitles.invalid"
+
+// MediaSubtitlesFile файл субтитров, выгруженный в одном из поддерживаемых форматов
+type MediaSubtitlesFile struct {
+	Filename    string
+	ContentType string
+	Content     []byte
+}
+
+// ListSubtitles получение списка дорожек субтитров медиа
+func (m Medias) ListSubtitles(ctx context.Context, dto GetMedia) (*MediaSubtitleTrackList, error) {
+	media, err := m.mediaRepository.Get(ctx, dto.Id)
+	if err != nil {
+		return nil, errors.NoType.Wrap(err, "error getting media by id")
+	}
+
+	err = checkFolderAccess(ctx, m.folderRepository, media.FolderId, entity.PermissionRead, ErrMediaNotFound)
+	if err != nil {
+		return nil, err
+	}
+
+	tracks, err := m.mediaRepository.ListSubtitles(ctx, media.Id)
+	if err != nil {
+		return nil, errors.NoType.Wrap(err, "error getting media subtitles")
+	}
+
+	list := &MediaSubtitleTrackList{Items: make([]MediaSubtitleTrack, len(tracks))}
+	for i, track := range tracks {
+		list.Items[i] = MediaSubtitleTrack{
+			Language:  track.Language,
+			CuesCount: len(track.Cues),
+			UpdatedAt: utils.Time(track.UpdatedAt),
+		}
+	}
+
+	return list, nil
+}
+
+// ExportSubtitles выгрузка дорожки субтитров медиа в формате WebVTT или SRT.
+// Если язык не указан, выгружается первая дорожка.
+func (m Medias) ExportSubtitles(ctx context.Context, dto GetMediaSubtitles) (*MediaSubtitlesFile, error) {
+	media, err := m.mediaRepository.Get(ctx, dto.Id)
+	if err != nil {
+		return nil, errors.NoType.Wrap(err, "error getting media by id")
+	}
+
+	err = checkFolderAccess(ctx, m.folderRepository, media.FolderId, entity.PermissionRead, ErrMediaNotFound)
+	if err != nil {
+		return nil, err
+	}
+
+	track, err := m.getSubtitleTrack(ctx, media, dto.Language)
+	if err != nil {
+		return nil, err
+	}
+
+	format := subtitles.Format(dto.Format)
+	buf := &bytes.Buffer{}
+	err = subtitles.Write(format, buf, track.Cues)
+	if err != nil {
+		return nil, errors.NoType.Wrap(err, "error writing media subtitles")
+	}
+
+	name := strings.TrimSuffix(media.Filename, filepath.Ext(media.Filename))
+	return &MediaSubtitlesFile{
+		Filename:    name + "." + track.Language + "." + string(format),
+		ContentType: format.ContentType(),
+		Content:     buf.Bytes(),
+	}, nil
+}
+
+// UploadSubtitles загрузка дорожки субтитров медиа из файла WebVTT или SRT.
+// Существующая дорожка на том же языке заменяется.
+func (m Medias) UploadSubtitles(ctx context.Context, dto UploadMediaSubtitles) error {
+	format := subtitles.Format(dto.Format)
+	if !format.Valid() {
+		return ErrMediaSubtitlesFormat
+	}
+
+	cues, err := subtitles.Parse(format, dto.File)
+	if err != nil {
+		return errors.BadRequest.Wrap(err, "error parsing media subtitles").T(subtitlesInvalidMsg, err.Error())
+	}
+
+	return m.SaveSubtitles(ctx, SaveMediaSubtitles{Id: dto.Id, Language: dto.Language, Cues: cues})
+}
+
+// SaveSubtitles сохранение дорожки субтитров медиа.
+// Существующая дорожка на том же языке заменяется.
+func (m Medias) SaveSubtitles(ctx context.Context, dto SaveMediaSubtitles) error {
+	media, err := m.mediaRepository.Get(ctx, dto.Id)
+	if err != nil {
+		return errors.NoType.Wrap(err, "error getting media by id")
+	}
+
+	err = checkFolderAccess(ctx, m.folderRepository, media.FolderId, entity.PermissionManage, ErrMediaNotFound)
+	if err != nil {
+		return err
+	}
+
+	err = subtitles.Validate(dto.Cues)
+	if err != nil {
+		return errors.BadRequest.Wrap(err, "error validating media subtitles").T(subtitlesInvalidMsg, err.Error())
+	}
+
+	err = m.mediaRepository.SaveSubtitles(ctx, entity.MediaSubtitle{
+		MediaId:   media.Id,
+		Language:  dto.Language,
+		Cues:      dto.Cues,
+		UpdatedAt: time.Now(),
+	})
+	if err != nil {
+		return errors.NoType.Wrap(err, "error saving media subtitles")
+	}
+
+	m.GoAfterUpdate(media.Id)
+
+	return nil
+}
+
+// DeleteSubtitles удаление дорожки субтитров медиа
+func (m Medias) DeleteSubtitles(ctx context.Context, dto DeleteMediaSubtitles) error {
+	media, err := m.mediaRepository.Get(ctx, dto.Id)
+	if err != nil {
+		return errors.NoType.Wrap(err, "error getting media by id")
+	}
+
+	err = checkFolderAccess(ctx, m.folderRepository, media.FolderId, entity.PermissionManage, ErrMediaNotFound)
+	if err != nil {
+		return err
+	}
+
+	err = m.mediaRepository.DeleteSubtitles(ctx, media.Id, dto.Language)
+	if err != nil {
+		return errors.NoType.Wrap(err, "error deleting media subtitles")
+	}
+
+	m.GoAfterUpdate(media.Id)
+
+	return nil
+}
+
+// getSubtitleTrack получение дорожки субтитров на языке language или первой дорожки, если язык не указан
+func (m Medias) getSubtitleTrack(ctx context.Context, media *entity.Media, language string) (*entity.MediaSubtitle, error) {
+	if language != "" {
+		track, err := m.mediaRepository.GetSubtitles(ctx, media.Id, language)
+		if err != nil {
+			return nil, errors.NoType.Wrap(err, "error getting media subtitles")
+		}
+		return track, nil
+	}
+
+	tracks, err := m.mediaRepository.ListSubtitles(ctx, media.Id)
+	if err != nil {
+		return nil, errors.NoType.Wrap(err, "error getting media subtitles")
+	}
+	if len(tracks) == 0 {
+		return nil, ErrMediaSubtitlesNotFound
+	}
+
+	return &tracks[0], nil
+}
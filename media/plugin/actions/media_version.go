@@ -0,0 +1,207 @@
+package actions
+
+import (
+	"context"
+	"mime"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/aeroideaservices/focus/media/plugin/entity"
+	"github.com/aeroideaservices/focus/media/plugin/service/utils"
+	"github.com/aeroideaservices/focus/services/errors"
+)
+
+const (
+	versionsDir = ".versions" // versionsDir директория хранилища, в которой хранятся предыдущие версии медиа
+)
+
+// ReplaceContent замена содержимого медиа с сохранением текущего содержимого в истории версий
+func (m Medias) ReplaceContent(ctx context.Context, dto ReplaceMediaContent) error {
+	if dto.Size > maxFileSize {
+		return ErrMaxFileSize
+	}
+
+	media, err := m.mediaRepository.Get(ctx, dto.Id)
+	if err != nil {
+		return errors.NoType.Wrap(err, "error getting media by id")
+	}
+	if !strings.EqualFold(filepath.Ext(dto.Filename), filepath.Ext(media.Filename)) {
+		return ErrMediaContentExtMismatch
+	}
+
+	err = checkFolderAccess(ctx, m.folderRepository, media.FolderId, entity.PermissionManage, ErrMediaNotFound)
+	if err != nil {
+		return err
+	}
+
+	err = checkFolderQuota(ctx, m.folderRepository, media.FolderId, nil, dto.Size-media.Size, 0)
+	if err != nil {
+		return err
+	}
+
+	width, height := utils.ImageSize(dto.File)
+	version := m.newVersion(media, dto.UploadedBy)
+	err = m.storage.Move(ctx, media.Filepath, version.Filepath)
+	if err != nil {
+		return errors.NoType.Wrap(err, "error moving media file to versions")
+	}
+
+	err = m.storage.Upload(ctx, &UploadFile{
+		Key:         media.Filepath,
+		ContentType: mime.TypeByExtension(filepath.Ext(media.Filename)),
+		File:        dto.File,
+	})
+	if err != nil {
+		_ = m.storage.Move(ctx, version.Filepath, media.Filepath)
+		return errors.NoType.Wrap(err, "error uploading media file")
+	}
+
+	err = m.mediaRepository.ReplaceContent(ctx, ReplaceMediaContentDto{
+		Id:      media.Id,
+		Size:    dto.Size,
+		Width:   width,
+		Height:  height,
+		Version: version,
+	})
+	if err != nil {
+		return errors.NoType.Wrap(err, "error replacing media content")
+	}
+
+	err = m.deleteDerivatives(ctx, media.Id)
+	if err != nil {
+		return err
+	}
+
+	err = m.deleteStreams(ctx, media.Id)
+	if err != nil {
+		return err
+	}
+
+	m.GoAfterUpdate(media.Id)
+
+	return nil
+}
+
+// ListVersions получение истории версий медиа
+func (m Medias) ListVersions(ctx context.Context, dto GetMedia) (*MediaVersionsList, error) {
+	media, err := m.mediaRepository.Get(ctx, dto.Id)
+	if err != nil {
+		return nil, errors.NoType.Wrap(err, "error getting media by id")
+	}
+
+	err = checkFolderAccess(ctx, m.folderRepository, media.FolderId, entity.PermissionRead, ErrMediaNotFound)
+	if err != nil {
+		return nil, err
+	}
+
+	versions, err := m.mediaRepository.ListVersions(ctx, dto.Id)
+	if err != nil {
+		return nil, errors.NoType.Wrap(err, "error getting media versions")
+	}
+
+	res := make([]MediaVersionPreview, len(versions))
+	for i, version := range versions {
+		res[i] = MediaVersionPreview{
+			Id:         version.Id,
+			Filename:   version.Filename,
+			Size:       utils.Filesize(version.Size),
+			Url:        m.mediaProvider.GetUrlByFilepath(version.Filepath),
+			UploadedBy: version.UploadedBy,
+			CreatedAt:  utils.Time(version.CreatedAt),
+		}
+	}
+
+	return &MediaVersionsList{Items: res}, nil
+}
+
+// RevertVersion восстановление версии медиа, текущее содержимое при этом сохраняется в истории версий
+func (m Medias) RevertVersion(ctx context.Context, dto RevertMediaVersion) error {
+	media, err := m.mediaRepository.Get(ctx, dto.Id)
+	if err != nil {
+		return errors.NoType.Wrap(err, "error getting media by id")
+	}
+
+	err = checkFolderAccess(ctx, m.folderRepository, media.FolderId, entity.PermissionManage, ErrMediaNotFound)
+	if err != nil {
+		return err
+	}
+
+	restored, err := m.mediaRepository.GetVersion(ctx, media.Id, dto.VersionId)
+	if err != nil {
+		return errors.NoType.Wrap(err, "error getting media version")
+	}
+
+	version := m.newVersion(media, dto.UploadedBy)
+	err = m.storage.Move(ctx, media.Filepath, version.Filepath)
+	if err != nil {
+		return errors.NoType.Wrap(err, "error moving media file to versions")
+	}
+
+	err = m.storage.Move(ctx, restored.Filepath, media.Filepath)
+	if err != nil {
+		_ = m.storage.Move(ctx, version.Filepath, media.Filepath)
+		return errors.NoType.Wrap(err, "error restoring media file from versions")
+	}
+
+	err = m.mediaRepository.ReplaceContent(ctx, ReplaceMediaContentDto{
+		Id:                media.Id,
+		Size:              restored.Size,
+		Width:             restored.Width,
+		Height:            restored.Height,
+		Version:           version,
+		RestoredVersionId: &restored.Id,
+	})
+	if err != nil {
+		return errors.NoType.Wrap(err, "error replacing media content")
+	}
+
+	err = m.deleteDerivatives(ctx, media.Id)
+	if err != nil {
+		return err
+	}
+
+	err = m.deleteStreams(ctx, media.Id)
+	if err != nil {
+		return err
+	}
+
+	m.GoAfterUpdate(media.Id)
+
+	return nil
+}
+
+// newVersion формирование версии из текущего содержимого медиа
+func (m Medias) newVersion(media *entity.Media, uploadedBy string) entity.MediaVersion {
+	id := uuid.New()
+	return entity.MediaVersion{
+		Id:         id,
+		MediaId:    media.Id,
+		Filename:   media.Filename,
+		Size:       media.Size,
+		Width:      media.Width,
+		Height:     media.Height,
+		Filepath:   path.Join(versionsDir, media.Id.String(), id.String()+filepath.Ext(media.Filename)),
+		UploadedBy: uploadedBy,
+	}
+}
+
+// deleteVersionsFiles удаление файлов версий медиа из хранилища
+func (m Medias) deleteVersionsFiles(ctx context.Context, mediaIds ...uuid.UUID) error {
+	versions, err := m.mediaRepository.ListVersions(ctx, mediaIds...)
+	if err != nil {
+		return errors.NoType.Wrap(err, "error getting media versions")
+	}
+	if len(versions) == 0 {
+		return nil
+	}
+
+	keys := make([]string, len(versions))
+	for i, version := range versions {
+		keys[i] = version.Filepath
+	}
+
+	return m.storage.Delete(ctx, keys...)
+}
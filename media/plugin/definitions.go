@@ -42,6 +42,26 @@ var Definitions = []di.Def{
 		},
 		Name: "focus.media.actions.folder",
 	},
+	{
+		Build: func(ctn di.Container) (interface{}, error) {
+			bulkRepository := ctn.Get("focus.media.repository.bulk").(actions.BulkRepository)
+			folderRepository := ctn.Get("focus.media.repository.folder").(actions.FolderRepository)
+			mediaRepository := ctn.Get("focus.media.repository.media").(actions.MediaRepository)
+			mediaStorage := ctn.Get("focus.media.fileStorage").(actions.FileStorage)
+
+			var mediaCallbacks focsCallbacks.Callbacks
+			if callbacksI, _ := ctn.SafeGet("focus.media.actions.media.callbacks"); callbacksI != nil {
+				mediaCallbacks = callbacksI.(focsCallbacks.Callbacks)
+			}
+			var folderCallbacks focsCallbacks.Callbacks
+			if callbacksI, _ := ctn.SafeGet("focus.media.actions.folders.callbacks"); callbacksI != nil {
+				folderCallbacks = callbacksI.(focsCallbacks.Callbacks)
+			}
+
+			return actions.NewBulk(bulkRepository, folderRepository, mediaRepository, mediaStorage, mediaCallbacks, folderCallbacks), nil
+		},
+		Name: "focus.media.actions.bulk",
+	},
 	{
 		Build: func(ctn di.Container) (interface{}, error) {
 			mediaRepository := ctn.Get("focus.media.repository.media").(actions.MediaRepository)
@@ -12,6 +12,8 @@ type Folder struct {
 	CreatedAt time.Time  `json:"-"`
 	UpdatedAt time.Time  `json:"-"`
 	FolderId  *uuid.UUID `json:"parentFolderId"`
+	MaxSize   *int64     `json:"maxSize"`  // MaxSize квота на размер медиа папки вместе с вложенными папками в байтах
+	MaxFiles  *int64     `json:"maxFiles"` // MaxFiles квота на количество медиа папки вместе с вложенными папками
 	Folder    *Folder    `json:"-" gorm:"constraint:OnDelete:CASCADE"`
 	Medias    []Media    `gorm:"constraint:OnDelete:CASCADE" json:"-"`
 }
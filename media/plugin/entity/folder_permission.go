@@ -0,0 +1,49 @@
+package entity
+
+import (
+	"github.com/google/uuid"
+)
+
+// Permission право доступа к папке, каждое следующее право включает предыдущие
+type Permission string
+
+const (
+	PermissionRead   Permission = "read"   // PermissionRead просмотр папки и ее медиа
+	PermissionUpload Permission = "upload" // PermissionUpload загрузка медиа в папку
+	PermissionManage Permission = "manage" // PermissionManage изменение и удаление папки и ее медиа
+)
+
+var permissionLevels = map[Permission]int{
+	PermissionRead:   1,
+	PermissionUpload: 2,
+	PermissionManage: 3,
+}
+
+// Includes проверка, что право включает переданное
+func (p Permission) Includes(other Permission) bool {
+	return permissionLevels[p] >= permissionLevels[other] && permissionLevels[other] > 0
+}
+
+// Including получение списка прав, включающих переданное
+func (p Permission) Including() []Permission {
+	var res []Permission
+	for permission := range permissionLevels {
+		if permission.Includes(p) {
+			res = append(res, permission)
+		}
+	}
+
+	return res
+}
+
+// FolderPermission право роли на папку, наследуется вложенными папками, у которых нет собственных прав
+type FolderPermission struct {
+	FolderId   uuid.UUID  `gorm:"type:uuid;primaryKey" json:"folderId"`
+	Folder     *Folder    `gorm:"constraint:OnDelete:CASCADE" json:"-"`
+	Role       string     `gorm:"primaryKey" json:"role"`
+	Permission Permission `json:"permission"`
+}
+
+func (FolderPermission) TableName() string {
+	return "folder_permissions"
+}
@@ -1,22 +1,29 @@
 package entity
 
 import (
+	"github.com/aeroideaservices/focus/services/db/db_types/array"
 	"github.com/aeroideaservices/focus/services/db/db_types/json"
 	"github.com/google/uuid"
 	"time"
 )
 
 type Media struct {
-	Id        uuid.UUID  `gorm:"type:uuid;primary_key" json:"id"`
-	Name      string     `json:"name"`
-	Filename  string     `json:"filename"`
-	Alt       string     `json:"alt"`
-	Title     string     `json:"title"`
-	Size      int64      `json:"size"`
-	Filepath  string     `json:"filepath" gorm:"unique_index"`
-	CreatedAt time.Time  `json:"createdAt"`
-	UpdatedAt time.Time  `json:"updatedAt"`
-	FolderId  *uuid.UUID `json:"folderId" gorm:"type:uuid"`
+	Id        uuid.UUID         `gorm:"type:uuid;primary_key" json:"id"`
+	Name      string            `json:"name"`
+	Filename  string            `json:"filename"`
+	Alt       string            `json:"alt"`
+	Title     string            `json:"title"`
+	Size      int64             `json:"size"`
+	Filepath  string            `json:"filepath" gorm:"unique_index"`
+	CreatedAt time.Time         `json:"createdAt"`
+	UpdatedAt time.Time         `json:"updatedAt"`
+	FolderId  *uuid.UUID        `json:"folderId" gorm:"type:uuid"`
+	Tags      array.StringArray `json:"tags" gorm:"type:text[]"`
+
+	Width      int         `json:"width"`                        // Width ширина изображения в пикселях, 0 - не изображение
+	Height     int         `json:"height"`                       // Height высота изображения в пикселях, 0 - не изображение
+	FocalPoint *FocalPoint `json:"focalPoint" gorm:"type:jsonb"` // FocalPoint точка фокуса, учитывается при кадрировании
+	Crops      Crops       `json:"crops" gorm:"type:jsonb"`      // Crops именованные области кадрирования
 
 	Subtitles json.JSONB `json:"subtitles"`
 }
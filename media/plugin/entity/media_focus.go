@@ -0,0 +1,104 @@
+package entity
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// FocalPoint точка фокуса изображения, координаты задаются относительно размеров изображения (от 0 до 1)
+type FocalPoint struct {
+	X float64 `json:"x" validate:"min=0,max=1"`
+	Y float64 `json:"y" validate:"min=0,max=1"`
+}
+
+func (p *FocalPoint) Scan(src any) error {
+	return scanJSON(src, p)
+}
+
+func (p FocalPoint) Value() (driver.Value, error) {
+	return json.Marshal(p)
+}
+
+func (FocalPoint) GormDataType() string {
+	return "jsonb"
+}
+
+// Crop именованная область кадрирования изображения, координаты и размеры задаются относительно размеров изображения (от 0 до 1)
+type Crop struct {
+	Name   string  `json:"name" validate:"required,notBlank,max=50"`
+	X      float64 `json:"x" validate:"min=0,max=1"`
+	Y      float64 `json:"y" validate:"min=0,max=1"`
+	Width  float64 `json:"width" validate:"gt=0,max=1"`
+	Height float64 `json:"height" validate:"gt=0,max=1"`
+}
+
+// AspectRatio соотношение сторон области кадрирования для изображения с переданными размерами
+func (c Crop) AspectRatio(width, height int) float64 {
+	if height == 0 || c.Height == 0 {
+		return 0
+	}
+
+	return c.Width * float64(width) / (c.Height * float64(height))
+}
+
+// Crops список областей кадрирования изображения
+type Crops []Crop
+
+// Get получение области кадрирования по названию
+func (c Crops) Get(name string) *Crop {
+	for i := range c {
+		if c[i].Name == name {
+			return &c[i]
+		}
+	}
+
+	return nil
+}
+
+func (c *Crops) Scan(src any) error {
+	return scanJSON(src, c)
+}
+
+func (c Crops) Value() (driver.Value, error) {
+	if c == nil {
+		return "[]", nil
+	}
+	return json.Marshal(c)
+}
+
+func (Crops) GormDataType() string {
+	return "jsonb"
+}
+
+func scanJSON(src any, dest any) error {
+	switch value := src.(type) {
+	case nil:
+		return nil
+	case []byte:
+		return json.Unmarshal(value, dest)
+	case string:
+		return json.Unmarshal([]byte(value), dest)
+	}
+
+	return fmt.Errorf("cannot scan %T", src)
+}
+
+// MediaDerivative производное изображение медиа (кадрированное и/или уменьшенное), сохраненное в хранилище
+type MediaDerivative struct {
+	Id        uuid.UUID `gorm:"type:uuid;primary_key" json:"id"`
+	MediaId   uuid.UUID `gorm:"type:uuid;index" json:"mediaId"`
+	Media     *Media    `gorm:"constraint:OnDelete:CASCADE" json:"-"`
+	Crop      string    `json:"crop"`
+	Width     int       `json:"width"`
+	Height    int       `json:"height"`
+	Filepath  string    `json:"filepath"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+func (MediaDerivative) TableName() string {
+	return "media_derivatives"
+}
@@ -0,0 +1,81 @@
+package entity
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// StreamStatus статус формирования потока
+type StreamStatus string
+
+const (
+	StreamStatusPending    StreamStatus = "pending"    // StreamStatusPending поток ожидает формирования
+	StreamStatusProcessing StreamStatus = "processing" // StreamStatusProcessing поток формируется
+	StreamStatusReady      StreamStatus = "ready"      // StreamStatusReady поток сформирован
+	StreamStatusFailed     StreamStatus = "failed"     // StreamStatusFailed ошибка формирования потока
+)
+
+// StreamVariant вариант качества потока
+type StreamVariant struct {
+	Name      string `json:"name" validate:"required,max=50"`
+	Width     int    `json:"width" validate:"min=1"`
+	Height    int    `json:"height" validate:"min=1"`
+	Bandwidth int    `json:"bandwidth" validate:"min=1"`
+	Playlist  string `json:"playlist" validate:"required"` // Playlist путь к плейлисту варианта в хранилище
+}
+
+// StreamVariants варианты качества потока
+type StreamVariants []StreamVariant
+
+func (v *StreamVariants) Scan(src any) error {
+	return scanJSON(src, v)
+}
+
+func (v StreamVariants) Value() (driver.Value, error) {
+	if v == nil {
+		return "[]", nil
+	}
+	return json.Marshal(v)
+}
+
+func (StreamVariants) GormDataType() string {
+	return "jsonb"
+}
+
+// Filepaths список путей к файлам в хранилище
+type Filepaths []string
+
+func (f *Filepaths) Scan(src any) error {
+	return scanJSON(src, f)
+}
+
+func (f Filepaths) Value() (driver.Value, error) {
+	if f == nil {
+		return "[]", nil
+	}
+	return json.Marshal(f)
+}
+
+func (Filepaths) GormDataType() string {
+	return "jsonb"
+}
+
+// MediaStream поток адаптивного стриминга (HLS) видео
+type MediaStream struct {
+	MediaId   uuid.UUID      `gorm:"type:uuid;primaryKey" json:"mediaId"`
+	Media     *Media         `gorm:"constraint:OnDelete:CASCADE" json:"-"`
+	Status    StreamStatus   `json:"status"`
+	Playlist  string         `json:"playlist"` // Playlist путь к мастер-плейлисту в хранилище
+	Variants  StreamVariants `gorm:"type:jsonb" json:"variants"`
+	Files     Filepaths      `gorm:"type:jsonb" json:"-"` // Files пути ко всем файлам потока в хранилище
+	Error     string         `json:"error"`
+	CreatedAt time.Time      `json:"createdAt"`
+	UpdatedAt time.Time      `json:"updatedAt"`
+}
+
+func (MediaStream) TableName() string {
+	return "media_streams"
+}
@@ -0,0 +1,75 @@
+package entity
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SubtitleCue фрагмент субтитров, отображаемый в интервале [Start, End).
+// В JSON интервал передается в миллисекундах: startMs, endMs.
+type SubtitleCue struct {
+	Start time.Duration
+	End   time.Duration
+	Text  string
+}
+
+// subtitleCueJSON представление фрагмента субтитров в JSON
+type subtitleCueJSON struct {
+	StartMs int64  `json:"startMs"`
+	EndMs   int64  `json:"endMs"`
+	Text    string `json:"text"`
+}
+
+func (c SubtitleCue) MarshalJSON() ([]byte, error) {
+	return json.Marshal(subtitleCueJSON{
+		StartMs: c.Start.Milliseconds(),
+		EndMs:   c.End.Milliseconds(),
+		Text:    c.Text,
+	})
+}
+
+func (c *SubtitleCue) UnmarshalJSON(data []byte) error {
+	var cue subtitleCueJSON
+	if err := json.Unmarshal(data, &cue); err != nil {
+		return err
+	}
+	c.Start = time.Duration(cue.StartMs) * time.Millisecond
+	c.End = time.Duration(cue.EndMs) * time.Millisecond
+	c.Text = cue.Text
+
+	return nil
+}
+
+// SubtitleCues список фрагментов субтитров
+type SubtitleCues []SubtitleCue
+
+func (c *SubtitleCues) Scan(src any) error {
+	return scanJSON(src, c)
+}
+
+func (c SubtitleCues) Value() (driver.Value, error) {
+	if c == nil {
+		return "[]", nil
+	}
+	return json.Marshal(c)
+}
+
+func (SubtitleCues) GormDataType() string {
+	return "jsonb"
+}
+
+// MediaSubtitle дорожка субтитров медиа на одном языке
+type MediaSubtitle struct {
+	MediaId   uuid.UUID    `gorm:"type:uuid;primaryKey" json:"mediaId"`
+	Media     *Media       `gorm:"constraint:OnDelete:CASCADE" json:"-"`
+	Language  string       `gorm:"primaryKey" json:"language"`
+	Cues      SubtitleCues `gorm:"type:jsonb" json:"cues"`
+	UpdatedAt time.Time    `json:"updatedAt"`
+}
+
+func (MediaSubtitle) TableName() string {
+	return "media_subtitles"
+}
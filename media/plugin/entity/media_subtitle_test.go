@@ -0,0 +1,27 @@
+package entity
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestSubtitleCue_JSON(t *testing.T) {
+	cue := SubtitleCue{Start: 1500 * time.Millisecond, End: 3 * time.Second, Text: "Привет"}
+
+	data, err := json.Marshal(cue)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	if want := `{"startMs":1500,"endMs":3000,"text":"Привет"}`; string(data) != want {
+		t.Errorf("Marshal() = %s, want %s", data, want)
+	}
+
+	var got SubtitleCue
+	if err = json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if got != cue {
+		t.Errorf("Unmarshal() = %+v, want %+v", got, cue)
+	}
+}
@@ -0,0 +1,25 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MediaVersion предыдущая версия файла медиа, сохраненная при замене содержимого
+type MediaVersion struct {
+	Id         uuid.UUID `gorm:"type:uuid;primary_key" json:"id"`
+	MediaId    uuid.UUID `gorm:"type:uuid;index" json:"mediaId"`
+	Media      *Media    `gorm:"constraint:OnDelete:CASCADE" json:"-"`
+	Filename   string    `json:"filename"`
+	Size       int64     `json:"size"`
+	Width      int       `json:"width"`
+	Height     int       `json:"height"`
+	Filepath   string    `json:"filepath"`
+	UploadedBy string    `json:"uploadedBy"` // UploadedBy пользователь, заменивший содержимое медиа
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+func (MediaVersion) TableName() string {
+	return "media_versions"
+}
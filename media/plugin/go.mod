@@ -4,13 +4,17 @@ go 1.19
 
 require (
 	github.com/aeroideaservices/focus/services/callbacks v1.0.0
+	github.com/aeroideaservices/focus/services/db/db_types v1.0.0
 	github.com/aeroideaservices/focus/services/errors v1.0.0
 	github.com/google/uuid v1.3.0
 	github.com/sarulabs/di/v2 v2.4.2
 )
 
 require (
-	github.com/aeroideaservices/focus/services/db/db_types v1.0.0 // indirect
+	github.com/jinzhu/inflection v1.0.0 // indirect
+	github.com/jinzhu/now v1.1.5 // indirect
+	github.com/lib/pq v1.10.6 // indirect
 	github.com/pkg/errors v0.9.1 // indirect
 	github.com/stretchr/testify v1.8.0 // indirect
+	gorm.io/gorm v1.23.8 // indirect
 )
@@ -0,0 +1,259 @@
+package subtitles
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aeroideaservices/focus/media/plugin/entity"
+)
+
+// Format формат файла субтитров
+type Format string
+
+const (
+	VTT Format = "vtt"
+	SRT Format = "srt"
+)
+
+const (
+	vttHeader  = "WEBVTT"
+	cueArrow   = "-->"
+	maxCueTime = 100 * time.Hour
+)
+
+// ContentType получение MIME-типа формата
+func (f Format) ContentType() string {
+	switch f {
+	case VTT:
+		return "text/vtt; charset=utf-8"
+	case SRT:
+		return "application/x-subrip; charset=utf-8"
+	default:
+		return "text/plain; charset=utf-8"
+	}
+}
+
+// Valid проверка, что формат поддерживается
+func (f Format) Valid() bool {
+	return f == VTT || f == SRT
+}
+
+// Parse разбор файла субтитров в формате format
+func Parse(format Format, r io.Reader) (entity.SubtitleCues, error) {
+	if !format.Valid() {
+		return nil, fmt.Errorf("unsupported subtitles format %q", format)
+	}
+
+	blocks, err := readBlocks(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if format == VTT {
+		if len(blocks) == 0 || !isVTTHeader(blocks[0][0]) {
+			return nil, fmt.Errorf("missing %s header", vttHeader)
+		}
+		blocks = blocks[1:]
+	}
+
+	cues := make(entity.SubtitleCues, 0, len(blocks))
+	for _, block := range blocks {
+		cue, ok, err := parseBlock(format, block)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			cues = append(cues, cue)
+		}
+	}
+
+	err = Validate(cues)
+	if err != nil {
+		return nil, err
+	}
+
+	return cues, nil
+}
+
+// Write запись субтитров в формате format
+func Write(format Format, w io.Writer, cues entity.SubtitleCues) error {
+	if !format.Valid() {
+		return fmt.Errorf("unsupported subtitles format %q", format)
+	}
+
+	bw := bufio.NewWriter(w)
+	if format == VTT {
+		_, _ = bw.WriteString(vttHeader + "\n\n")
+	}
+	for i, cue := range cues {
+		if format == SRT {
+			_, _ = bw.WriteString(strconv.Itoa(i+1) + "\n")
+		}
+		_, _ = fmt.Fprintf(bw, "%s %s %s\n%s\n\n",
+			FormatTimestamp(format, cue.Start), cueArrow, FormatTimestamp(format, cue.End), strings.TrimSpace(cue.Text))
+	}
+
+	return bw.Flush()
+}
+
+// Validate проверка фрагментов: время неотрицательное, начало раньше конца, фрагменты упорядочены по началу, текст не пустой
+func Validate(cues entity.SubtitleCues) error {
+	for i, cue := range cues {
+		if cue.Start < 0 || cue.End > maxCueTime {
+			return fmt.Errorf("cue %d: time out of range", i+1)
+		}
+		if cue.Start >= cue.End {
+			return fmt.Errorf("cue %d: start %s must be before end %s",
+				i+1, FormatTimestamp(VTT, cue.Start), FormatTimestamp(VTT, cue.End))
+		}
+		if i > 0 && cue.Start < cues[i-1].Start {
+			return fmt.Errorf("cue %d: cues must be ordered by start time", i+1)
+		}
+		if strings.TrimSpace(cue.Text) == "" {
+			return fmt.Errorf("cue %d: empty text", i+1)
+		}
+	}
+
+	return nil
+}
+
+// FormatTimestamp форматирование времени: "00:01:02.345" для WebVTT и "00:01:02,345" для SRT
+func FormatTimestamp(format Format, d time.Duration) string {
+	ms := d.Milliseconds()
+	separator := "."
+	if format == SRT {
+		separator = ","
+	}
+
+	return fmt.Sprintf("%02d:%02d:%02d%s%03d", ms/3600000, ms/60000%60, ms/1000%60, separator, ms%1000)
+}
+
+// ParseTimestamp разбор времени "hh:mm:ss.ttt" (часы в WebVTT необязательны) или "hh:mm:ss,ttt" для SRT
+func ParseTimestamp(format Format, value string) (time.Duration, error) {
+	separator := "."
+	if format == SRT {
+		separator = ","
+	}
+
+	rest, millis, found := strings.Cut(value, separator)
+	if !found || len(millis) != 3 {
+		return 0, fmt.Errorf("invalid timestamp %q", value)
+	}
+	parts := strings.Split(rest, ":")
+	if len(parts) == 2 && format == VTT {
+		parts = append([]string{"0"}, parts...)
+	}
+	if len(parts) != 3 || len(parts[1]) != 2 || len(parts[2]) != 2 {
+		return 0, fmt.Errorf("invalid timestamp %q", value)
+	}
+
+	var values [4]int
+	for i, part := range append(parts, millis) {
+		v, err := strconv.Atoi(part)
+		if err != nil || v < 0 {
+			return 0, fmt.Errorf("invalid timestamp %q", value)
+		}
+		values[i] = v
+	}
+	if values[1] > 59 || values[2] > 59 {
+		return 0, fmt.Errorf("invalid timestamp %q", value)
+	}
+
+	return time.Duration(values[0])*time.Hour +
+		time.Duration(values[1])*time.Minute +
+		time.Duration(values[2])*time.Second +
+		time.Duration(values[3])*time.Millisecond, nil
+}
+
+// readBlocks чтение файла блоками строк, разделенными пустыми строками
+func readBlocks(r io.Reader) ([][]string, error) {
+	var blocks [][]string
+	var block []string
+
+	scanner := bufio.NewScanner(r)
+	first := true
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if first {
+			line = strings.TrimPrefix(line, "\ufeff")
+			first = false
+		}
+		if strings.TrimSpace(line) == "" {
+			if len(block) != 0 {
+				blocks = append(blocks, block)
+				block = nil
+			}
+			continue
+		}
+		block = append(block, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(block) != 0 {
+		blocks = append(blocks, block)
+	}
+
+	return blocks, nil
+}
+
+// parseBlock разбор блока в фрагмент. Блоки WebVTT без временных меток (NOTE, STYLE, REGION) пропускаются.
+func parseBlock(format Format, block []string) (entity.SubtitleCue, bool, error) {
+	timingIdx := -1
+	for i, line := range block {
+		if strings.Contains(line, cueArrow) {
+			timingIdx = i
+			break
+		}
+	}
+
+	switch {
+	case timingIdx == -1 && format == VTT:
+		return entity.SubtitleCue{}, false, nil
+	case timingIdx == -1 || timingIdx > 1:
+		return entity.SubtitleCue{}, false, fmt.Errorf("invalid cue %q: missing timing", block[0])
+	case format == SRT && timingIdx == 1:
+		if _, err := strconv.Atoi(strings.TrimSpace(block[0])); err != nil {
+			return entity.SubtitleCue{}, false, fmt.Errorf("invalid cue number %q", block[0])
+		}
+	}
+
+	start, end, err := parseTiming(format, block[timingIdx])
+	if err != nil {
+		return entity.SubtitleCue{}, false, err
+	}
+
+	return entity.SubtitleCue{
+		Start: start,
+		End:   end,
+		Text:  strings.Join(block[timingIdx+1:], "\n"),
+	}, true, nil
+}
+
+// parseTiming разбор строки "start --> end [настройки отображения]"
+func parseTiming(format Format, line string) (time.Duration, time.Duration, error) {
+	startValue, rest, _ := strings.Cut(line, cueArrow)
+	fields := strings.Fields(rest)
+	if len(fields) == 0 {
+		return 0, 0, fmt.Errorf("invalid cue timing %q", line)
+	}
+
+	start, err := ParseTimestamp(format, strings.TrimSpace(startValue))
+	if err != nil {
+		return 0, 0, err
+	}
+	end, err := ParseTimestamp(format, fields[0])
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return start, end, nil
+}
+
+func isVTTHeader(line string) bool {
+	return line == vttHeader || strings.HasPrefix(line, vttHeader+" ") || strings.HasPrefix(line, vttHeader+"\t")
+}
@@ -0,0 +1,128 @@
+package subtitles
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aeroideaservices/focus/media/plugin/entity"
+)
+
+var testCues = entity.SubtitleCues{
+	{Start: 1500 * time.Millisecond, End: 4 * time.Second, Text: "Привет"},
+	{Start: time.Hour + 2*time.Minute + 3*time.Second + 45*time.Millisecond, End: time.Hour + 2*time.Minute + 5*time.Second, Text: "Две\nстроки"},
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		format  Format
+		input   string
+		want    entity.SubtitleCues
+		wantErr bool
+	}{
+		{
+			name:   "vtt",
+			format: VTT,
+			input: "\ufeffWEBVTT - title\r\nKind: captions\r\n\r\nNOTE comment\r\n\r\n" +
+				"intro\r\n00:01.500 --> 00:00:04.000 align:start\r\nПривет\r\n\r\n" +
+				"01:02:03.045 --> 01:02:05.000\r\nДве\r\nстроки\r\n",
+			want: testCues,
+		},
+		{
+			name:   "srt",
+			format: SRT,
+			input:  "1\n00:00:01,500 --> 00:00:04,000\nПривет\n\n2\n01:02:03,045 --> 01:02:05,000\nДве\nстроки\n\n",
+			want:   testCues,
+		},
+		{
+			name:    "vtt without header",
+			format:  VTT,
+			input:   "00:01.500 --> 00:04.000\nПривет\n",
+			wantErr: true,
+		},
+		{
+			name:    "srt with vtt timestamps",
+			format:  SRT,
+			input:   "1\n00:00:01.500 --> 00:00:04.000\nПривет\n",
+			wantErr: true,
+		},
+		{
+			name:    "invalid minutes",
+			format:  VTT,
+			input:   "WEBVTT\n\n00:61:00.000 --> 00:62:00.000\nПривет\n",
+			wantErr: true,
+		},
+		{
+			name:    "end before start",
+			format:  SRT,
+			input:   "1\n00:00:04,000 --> 00:00:01,500\nПривет\n",
+			wantErr: true,
+		},
+		{
+			name:    "unordered",
+			format:  SRT,
+			input:   "1\n00:00:04,000 --> 00:00:05,000\nA\n\n2\n00:00:01,000 --> 00:00:02,000\nB\n",
+			wantErr: true,
+		},
+		{
+			name:    "empty text",
+			format:  SRT,
+			input:   "1\n00:00:01,000 --> 00:00:02,000\n",
+			wantErr: true,
+		},
+		{
+			name:    "unknown format",
+			format:  "ass",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.format, strings.NewReader(tt.input))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWrite(t *testing.T) {
+	tests := []struct {
+		name   string
+		format Format
+		want   string
+	}{
+		{
+			name:   "vtt",
+			format: VTT,
+			want:   "WEBVTT\n\n00:00:01.500 --> 00:00:04.000\nПривет\n\n01:02:03.045 --> 01:02:05.000\nДве\nстроки\n\n",
+		},
+		{
+			name:   "srt",
+			format: SRT,
+			want:   "1\n00:00:01,500 --> 00:00:04,000\nПривет\n\n2\n01:02:03,045 --> 01:02:05,000\nДве\nстроки\n\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			if err := Write(tt.format, buf, testCues); err != nil {
+				t.Fatalf("Write() error = %v", err)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("Write() got = %q, want %q", buf.String(), tt.want)
+			}
+
+			cues, err := Parse(tt.format, buf)
+			if err != nil || !reflect.DeepEqual(cues, testCues) {
+				t.Errorf("Parse(Write()) got = %v, %v", cues, err)
+			}
+		})
+	}
+}
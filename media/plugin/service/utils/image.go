@@ -0,0 +1,128 @@
+package utils
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"math"
+)
+
+const jpegQuality = 90
+
+// ImageSize получение размеров изображения, для файлов, не являющихся изображениями, возвращаются нулевые размеры.
+// После чтения файл возвращается в начало.
+func ImageSize(file io.ReadSeeker) (width, height int) {
+	config, _, err := image.DecodeConfig(file)
+	_, _ = file.Seek(0, io.SeekStart)
+	if err != nil {
+		return 0, 0
+	}
+
+	return config.Width, config.Height
+}
+
+// RelativeRect получение прямоугольника внутри bounds по относительным координатам и размерам (от 0 до 1)
+func RelativeRect(bounds image.Rectangle, x, y, width, height float64) image.Rectangle {
+	w, h := float64(bounds.Dx()), float64(bounds.Dy())
+	rect := image.Rect(
+		bounds.Min.X+int(math.Round(x*w)),
+		bounds.Min.Y+int(math.Round(y*h)),
+		bounds.Min.X+int(math.Round((x+width)*w)),
+		bounds.Min.Y+int(math.Round((y+height)*h)),
+	)
+
+	return rect.Intersect(bounds)
+}
+
+// FocusRect получение максимального прямоугольника с соотношением сторон aspect внутри bounds,
+// расположенного так, чтобы точка фокуса (fx, fy) была как можно ближе к его центру
+func FocusRect(bounds image.Rectangle, aspect float64, fx, fy int) image.Rectangle {
+	w, h := bounds.Dx(), bounds.Dy()
+	if aspect <= 0 || w == 0 || h == 0 {
+		return bounds
+	}
+
+	cropW, cropH := w, int(math.Round(float64(w)/aspect))
+	if cropH > h {
+		cropW, cropH = int(math.Round(float64(h)*aspect)), h
+	}
+	cropW, cropH = maxInt(cropW, 1), maxInt(cropH, 1)
+
+	x := clamp(fx-cropW/2, bounds.Min.X, bounds.Max.X-cropW)
+	y := clamp(fy-cropH/2, bounds.Min.Y, bounds.Max.Y-cropH)
+
+	return image.Rect(x, y, x+cropW, y+cropH)
+}
+
+// Resize масштабирование области изображения до размеров width x height.
+// При уменьшении цвет пикселя усредняется по соответствующей области исходного изображения.
+func Resize(src image.Image, rect image.Rectangle, width, height int) image.Image {
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	if rect.Dx() == width && rect.Dy() == height {
+		draw.Draw(dst, dst.Bounds(), src, rect.Min, draw.Src)
+		return dst
+	}
+
+	scaleX := float64(rect.Dx()) / float64(width)
+	scaleY := float64(rect.Dy()) / float64(height)
+	for y := 0; y < height; y++ {
+		y0 := rect.Min.Y + int(float64(y)*scaleY)
+		y1 := maxInt(rect.Min.Y+int(float64(y+1)*scaleY), y0+1)
+		for x := 0; x < width; x++ {
+			x0 := rect.Min.X + int(float64(x)*scaleX)
+			x1 := maxInt(rect.Min.X+int(float64(x+1)*scaleX), x0+1)
+
+			var r, g, b, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					cr, cg, cb, ca := src.At(sx, sy).RGBA()
+					r, g, b, a = r+uint64(cr), g+uint64(cg), b+uint64(cb), a+uint64(ca)
+					n++
+				}
+			}
+			dst.Set(x, y, color.RGBA64{
+				R: uint16(r / n),
+				G: uint16(g / n),
+				B: uint16(b / n),
+				A: uint16(a / n),
+			})
+		}
+	}
+
+	return dst
+}
+
+// EncodeImage кодирование изображения в формат format (jpeg, png, gif), неизвестные форматы кодируются в png
+func EncodeImage(w io.Writer, img image.Image, format string) error {
+	switch format {
+	case "jpeg":
+		return jpeg.Encode(w, img, &jpeg.Options{Quality: jpegQuality})
+	case "gif":
+		return gif.Encode(w, img, nil)
+	default:
+		return png.Encode(w, img)
+	}
+}
+
+func clamp(v, minV, maxV int) int {
+	if v > maxV {
+		v = maxV
+	}
+	if v < minV {
+		v = minV
+	}
+
+	return v
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+
+	return b
+}
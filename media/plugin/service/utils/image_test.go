@@ -0,0 +1,102 @@
+package utils
+
+import (
+	"image"
+	"testing"
+)
+
+func TestFocusRect(t *testing.T) {
+	bounds := image.Rect(0, 0, 400, 200)
+	tests := []struct {
+		name   string
+		aspect float64
+		fx, fy int
+		want   image.Rectangle
+	}{
+		{
+			name:   "square in center",
+			aspect: 1,
+			fx:     200,
+			fy:     100,
+			want:   image.Rect(100, 0, 300, 200),
+		},
+		{
+			name:   "square at left edge",
+			aspect: 1,
+			fx:     10,
+			fy:     100,
+			want:   image.Rect(0, 0, 200, 200),
+		},
+		{
+			name:   "square at right edge",
+			aspect: 1,
+			fx:     390,
+			fy:     10,
+			want:   image.Rect(200, 0, 400, 200),
+		},
+		{
+			name:   "wide",
+			aspect: 4,
+			fx:     200,
+			fy:     180,
+			want:   image.Rect(0, 100, 400, 200),
+		},
+		{
+			name:   "same aspect",
+			aspect: 2,
+			fx:     0,
+			fy:     0,
+			want:   bounds,
+		},
+		{
+			name:   "no aspect",
+			aspect: 0,
+			want:   bounds,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FocusRect(bounds, tt.aspect, tt.fx, tt.fy); got != tt.want {
+				t.Errorf("FocusRect() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRelativeRect(t *testing.T) {
+	bounds := image.Rect(0, 0, 400, 200)
+	tests := []struct {
+		name                string
+		x, y, width, height float64
+		want                image.Rectangle
+	}{
+		{
+			name:   "full",
+			width:  1,
+			height: 1,
+			want:   bounds,
+		},
+		{
+			name:   "quarter",
+			x:      0.5,
+			y:      0.5,
+			width:  0.5,
+			height: 0.5,
+			want:   image.Rect(200, 100, 400, 200),
+		},
+		{
+			name:   "out of bounds",
+			x:      0.75,
+			width:  0.5,
+			height: 1,
+			want:   image.Rect(300, 0, 400, 200),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RelativeRect(bounds, tt.x, tt.y, tt.width, tt.height); got != tt.want {
+				t.Errorf("RelativeRect() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -21,4 +21,11 @@ var Definitions = []di.Def{
 		},
 		Name: "focus.media.repository.media",
 	},
+	{
+		Build: func(ctn di.Container) (interface{}, error) {
+			db := ctn.Get("focus.db").(*gorm.DB)
+			return repositories.NewBulkRepository(db), nil
+		},
+		Name: "focus.media.repository.bulk",
+	},
 }
@@ -5,6 +5,7 @@ go 1.19
 require (
 	github.com/WinterYukky/gorm-extra-clause-plugin v0.1.6
 	github.com/aeroideaservices/focus/media/plugin v1.0.0
+	github.com/aeroideaservices/focus/services/db/db_types v1.0.0
 	github.com/aeroideaservices/focus/services/errors v1.0.0
 	github.com/google/uuid v1.3.0
 	github.com/sarulabs/di/v2 v2.4.2
@@ -13,7 +14,6 @@ require (
 
 require (
 	github.com/aeroideaservices/focus/services/callbacks v1.0.0 // indirect
-	github.com/aeroideaservices/focus/services/db/db_types v1.0.0 // indirect
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/jinzhu/inflection v1.0.0 // indirect
 	github.com/jinzhu/now v1.1.5 // indirect
@@ -0,0 +1,206 @@
+package repositories
+
+import (
+	"context"
+	"strings"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/aeroideaservices/focus/media/plugin/actions"
+	"github.com/aeroideaservices/focus/media/plugin/entity"
+	"github.com/aeroideaservices/focus/services/db/db_types/array"
+	"github.com/aeroideaservices/focus/services/errors"
+)
+
+// bulkRepository репозиторий массовых операций над папками и медиа
+type bulkRepository struct {
+	db *gorm.DB
+}
+
+// NewBulkRepository конструктор
+func NewBulkRepository(db *gorm.DB) actions.BulkRepository {
+	return &bulkRepository{db: db}
+}
+
+// Move перемещение папок и медиа с пересчетом путей всех затронутых медиа.
+// Функция moveFiles вызывается внутри транзакции, ошибка в ней откатывает изменения
+func (r bulkRepository) Move(ctx context.Context, dto actions.BulkMove, moveFiles func(medias []*actions.UpdateMediaDto) error) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if len(dto.FolderIds) != 0 {
+			err := tx.Model(&entity.Folder{}).
+				Where("id IN (?)", dto.FolderIds).
+				Updates(map[string]any{"folder_id": dto.FolderId}).
+				Error
+			if err != nil {
+				return errors.NoType.Wrap(err, "error updating folders parent id")
+			}
+		}
+
+		if len(dto.FileIds) != 0 {
+			err := tx.Model(&entity.Media{}).
+				Where("id IN (?)", dto.FileIds).
+				Updates(map[string]any{"folder_id": dto.FolderId}).
+				Error
+			if err != nil {
+				return errors.NoType.Wrap(err, "error updating medias folder id")
+			}
+		}
+
+		var medias []*actions.UpdateMediaDto
+		err := tx.Raw(
+			`WITH RECURSIVE folder_paths (id, folder_path) AS (
+					SELECT id, name
+					FROM folders
+					WHERE folder_id IS NULL
+					UNION ALL
+					SELECT f.id, fp.folder_path || '/' || f.name
+					FROM folders f
+					INNER JOIN folder_paths fp
+					ON f.folder_id = fp.id
+				), moved_folders (id) AS (
+					SELECT id
+					FROM folders
+					WHERE id IN (?)
+					UNION ALL
+					SELECT f.id
+					FROM folders f
+					INNER JOIN moved_folders mf
+					ON f.folder_id = mf.id
+				)
+				SELECT * FROM (
+					SELECT media.id, media.name, media.filename, media.filepath, media.folder_id,
+						COALESCE(folder_paths.folder_path || '/', '') || media.filename AS new_filepath
+					FROM media
+					LEFT JOIN folder_paths ON folder_paths.id = media.folder_id
+					WHERE media.id IN (?) OR media.folder_id IN (SELECT id FROM moved_folders)
+				) AS m
+				WHERE m.filepath <> m.new_filepath`, emptyIdsToNil(dto.FolderIds), emptyIdsToNil(dto.FileIds),
+		).Scan(&medias).Error
+		if err != nil {
+			return errors.NoType.Wrap(err, "error getting moved medias")
+		}
+		if len(medias) == 0 {
+			return nil
+		}
+
+		updates := make([]*actions.UpdateMediaDto, len(medias))
+		for i, media := range medias {
+			updated := *media
+			updated.Filepath = media.NewFilepath
+			updates[i] = &updated
+		}
+		err = mediaRepository{db: tx}.Update(ctx, updates...)
+		if err != nil {
+			return err
+		}
+
+		return moveFiles(medias)
+	})
+	if err != nil {
+		return errors.NoType.Wrap(err, "error moving folders and medias")
+	}
+
+	return nil
+}
+
+// Delete удаление папок (вместе с вложенными папками и медиа) и медиа
+func (r bulkRepository) Delete(ctx context.Context, folderIds []uuid.UUID, mediaIds []uuid.UUID) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if len(mediaIds) != 0 {
+			err := tx.Where("id IN (?)", mediaIds).Delete(&entity.Media{}).Error
+			if err != nil {
+				return errors.NoType.Wrap(err, "error deleting medias")
+			}
+		}
+
+		if len(folderIds) != 0 {
+			err := tx.Where("id IN (?)", folderIds).Delete(&entity.Folder{}).Error
+			if err != nil {
+				return errors.NoType.Wrap(err, "error deleting folders")
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return errors.NoType.Wrap(err, "error deleting folders and medias")
+	}
+
+	return nil
+}
+
+// UpdateTags добавление и удаление тегов у медиа и у всех медиа вложенных папок
+func (r bulkRepository) UpdateTags(ctx context.Context, dto actions.BulkTag) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	err := r.db.WithContext(ctx).Raw(
+		`WITH RECURSIVE tagged_folders (id) AS (
+				SELECT id
+				FROM folders
+				WHERE id IN (?)
+				UNION ALL
+				SELECT f.id
+				FROM folders f
+				INNER JOIN tagged_folders tf
+				ON f.folder_id = tf.id
+			)
+			UPDATE media
+			SET tags = ARRAY(
+					SELECT DISTINCT t
+					FROM unnest(COALESCE(media.tags, '{}') || ?::text[]) AS t
+					WHERE NOT t = ANY (?::text[])
+					ORDER BY t
+				),
+				updated_at = now()
+			WHERE media.id IN (?) OR media.folder_id IN (SELECT id FROM tagged_folders)
+			RETURNING media.id`,
+		emptyIdsToNil(dto.FolderIds), tagsArray(dto.Add), tagsArray(dto.Remove), emptyIdsToNil(dto.FileIds),
+	).Scan(&ids).Error
+	if err != nil {
+		return nil, errors.NoType.Wrap(err, "error updating medias tags")
+	}
+
+	return ids, nil
+}
+
+// UpdateFields изменение alt и title медиа, незаданные поля не изменяются
+func (r bulkRepository) UpdateFields(ctx context.Context, items ...actions.BulkMediaFields) error {
+	template := make([]string, len(items))
+	values := make([]any, 0, 3*len(items))
+	for i, item := range items {
+		template[i] = "(?::uuid, ?::text, ?::text)"
+		values = append(values, item.Id, item.Alt, item.Title)
+	}
+
+	err := r.db.WithContext(ctx).
+		Exec(
+			"WITH values (id, alt, title)"+
+				" AS (VALUES "+strings.Join(template, ", ")+")"+
+				" UPDATE media"+
+				" SET (alt, title, updated_at) = (COALESCE(v.alt, media.alt), COALESCE(v.title, media.title), now())"+
+				" FROM values as v"+
+				" WHERE v.id = media.id", values...,
+		).Error
+	if err != nil {
+		return errors.NoType.Wrap(err, "error updating medias fields")
+	}
+
+	return nil
+}
+
+// emptyIdsToNil пустой список подставляется в IN как NULL, чтобы условие не выполнялось
+func emptyIdsToNil(ids []uuid.UUID) any {
+	if len(ids) == 0 {
+		return nil
+	}
+	return ids
+}
+
+// tagsArray незаданный список тегов подставляется как пустой массив, а не NULL,
+// иначе NOT t = ANY (NULL) отбрасывает все теги медиа
+func tagsArray(tags []string) array.StringArray {
+	if tags == nil {
+		return array.StringArray{}
+	}
+	return tags
+}
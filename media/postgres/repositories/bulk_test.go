@@ -0,0 +1,69 @@
+package repositories
+
+import (
+	"context"
+	"database/sql/driver"
+	"strings"
+	"testing"
+
+	"github.com/aeroideaservices/focus/media/plugin/actions"
+	"github.com/google/uuid"
+)
+
+func TestBulkRepository_UpdateTags(t *testing.T) {
+	tests := []struct {
+		name       string
+		dto        actions.BulkTag
+		wantAdd    string
+		wantRemove string
+	}{
+		{
+			name:       "add only",
+			dto:        actions.BulkTag{FileIds: []uuid.UUID{uuid.New()}, Add: []string{"news"}},
+			wantAdd:    "{\"news\"}",
+			wantRemove: "{}",
+		},
+		{
+			name:       "remove only",
+			dto:        actions.BulkTag{FileIds: []uuid.UUID{uuid.New()}, Remove: []string{"old"}},
+			wantAdd:    "{}",
+			wantRemove: "{\"old\"}",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, fake := newFakeGorm(t, nil)
+
+			if _, err := NewBulkRepository(db).UpdateTags(context.Background(), tt.dto); err != nil {
+				t.Fatalf("UpdateTags() error = %v", err)
+			}
+
+			queries := fake.Queries()
+			if len(queries) != 1 || !strings.Contains(queries[0].SQL, "UPDATE media") {
+				t.Fatalf("queries = %+v, want one update", queries)
+			}
+			// незаданный список тегов не должен передаваться как NULL: NOT t = ANY (NULL) удаляет все теги
+			args := queries[0].Args
+			if got := arrayValue(t, args[1]); got != tt.wantAdd {
+				t.Errorf("add = %v, want %s", got, tt.wantAdd)
+			}
+			if got := arrayValue(t, args[2]); got != tt.wantRemove {
+				t.Errorf("remove = %v, want %s", got, tt.wantRemove)
+			}
+		})
+	}
+}
+
+func arrayValue(t *testing.T, arg interface{}) interface{} {
+	t.Helper()
+	valuer, ok := arg.(driver.Valuer)
+	if !ok {
+		t.Fatalf("argument %v is not an array", arg)
+	}
+	value, err := valuer.Value()
+	if err != nil {
+		t.Fatalf("Value() error = %v", err)
+	}
+
+	return value
+}
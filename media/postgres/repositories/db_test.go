@@ -0,0 +1,221 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/callbacks"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/logger"
+	"gorm.io/gorm/migrator"
+	"gorm.io/gorm/schema"
+)
+
+// fakeQuery запрос, выполненный через fakeDB
+type fakeQuery struct {
+	SQL  string
+	Args []interface{}
+	Tx   int // Tx номер транзакции, в которой выполнен запрос, начиная с 1, 0 - без транзакции
+}
+
+// fakeRows ответ fakeDB на SELECT
+type fakeRows struct {
+	Columns []string
+	Values  [][]driver.Value
+}
+
+// fakeDB база данных для тестов репозиториев без Postgres: записывает запросы в порядке выполнения
+// и отвечает на SELECT функцией rows, на остальные запросы - одной измененной строкой
+type fakeDB struct {
+	mu      sync.Mutex
+	queries []fakeQuery
+	txs     []string // txs начала и завершения транзакций: BEGIN, COMMIT, ROLLBACK
+	rows    func(query string, args []interface{}) (fakeRows, error)
+}
+
+// newFakeGorm подключение gorm с диалектом, формирующим запросы как драйвер Postgres
+func newFakeGorm(t *testing.T, rows func(query string, args []interface{}) (fakeRows, error)) (*gorm.DB, *fakeDB) {
+	t.Helper()
+	fake := &fakeDB{rows: rows}
+	db, err := gorm.Open(fakeDialector{conn: sql.OpenDB(fake)}, &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("gorm.Open() error = %v", err)
+	}
+
+	return db, fake
+}
+
+// Queries выполненные запросы без управления транзакциями
+func (f *fakeDB) Queries() []fakeQuery {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return append([]fakeQuery(nil), f.queries...)
+}
+
+// Txs начала и завершения транзакций в порядке выполнения
+func (f *fakeDB) Txs() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return append([]string(nil), f.txs...)
+}
+
+func (f *fakeDB) record(query string, args []driver.NamedValue, tx int) []interface{} {
+	values := make([]interface{}, len(args))
+	for i, arg := range args {
+		values[i] = arg.Value
+	}
+	f.mu.Lock()
+	f.queries = append(f.queries, fakeQuery{SQL: query, Args: values, Tx: tx})
+	f.mu.Unlock()
+
+	return values
+}
+
+// recordTx запись события транзакции, возвращает номер транзакции
+func (f *fakeDB) recordTx(event string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.txs = append(f.txs, event)
+	begins := 0
+	for _, tx := range f.txs {
+		if tx == "BEGIN" {
+			begins++
+		}
+	}
+
+	return begins
+}
+
+func (f *fakeDB) Connect(context.Context) (driver.Conn, error) { return &fakeConn{db: f}, nil }
+func (f *fakeDB) Driver() driver.Driver                        { return nil }
+
+type fakeConn struct {
+	db *fakeDB
+	tx int
+}
+
+func (c *fakeConn) Prepare(string) (driver.Stmt, error) { return nil, driver.ErrSkip }
+func (c *fakeConn) Close() error                        { return nil }
+
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c *fakeConn) BeginTx(context.Context, driver.TxOptions) (driver.Tx, error) {
+	c.tx = c.db.recordTx("BEGIN")
+	return fakeTx{conn: c}, nil
+}
+
+func (c *fakeConn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	c.db.record(query, args, c.tx)
+	return driver.RowsAffected(1), nil
+}
+
+func (c *fakeConn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	values := c.db.record(query, args, c.tx)
+	rows := fakeRows{}
+	if c.db.rows != nil {
+		var err error
+		if rows, err = c.db.rows(query, values); err != nil {
+			return nil, err
+		}
+	}
+
+	return &fakeDriverRows{rows: rows}, nil
+}
+
+// CheckNamedValue передача срезов и других значений в запрос без преобразования
+func (c *fakeConn) CheckNamedValue(*driver.NamedValue) error { return nil }
+
+type fakeTx struct {
+	conn *fakeConn
+}
+
+func (t fakeTx) Commit() error {
+	t.conn.tx = 0
+	t.conn.db.recordTx("COMMIT")
+	return nil
+}
+
+func (t fakeTx) Rollback() error {
+	t.conn.tx = 0
+	t.conn.db.recordTx("ROLLBACK")
+	return nil
+}
+
+type fakeDriverRows struct {
+	rows fakeRows
+	next int
+}
+
+func (r *fakeDriverRows) Columns() []string { return r.rows.Columns }
+func (r *fakeDriverRows) Close() error      { return nil }
+
+func (r *fakeDriverRows) Next(dest []driver.Value) error {
+	if r.next >= len(r.rows.Values) {
+		return io.EOF
+	}
+	copy(dest, r.rows.Values[r.next])
+	r.next++
+
+	return nil
+}
+
+type fakeDialector struct {
+	conn *sql.DB
+}
+
+func (d fakeDialector) Name() string { return "postgres" }
+
+func (d fakeDialector) Initialize(db *gorm.DB) error {
+	callbacks.RegisterDefaultCallbacks(db, &callbacks.Config{})
+	db.ConnPool = d.conn
+	return nil
+}
+
+func (d fakeDialector) Migrator(db *gorm.DB) gorm.Migrator {
+	return migrator.Migrator{Config: migrator.Config{DB: db, Dialector: d}}
+}
+
+func (d fakeDialector) DataTypeOf(field *schema.Field) string { return string(field.DataType) }
+
+func (d fakeDialector) DefaultValueOf(*schema.Field) clause.Expression {
+	return clause.Expr{SQL: "DEFAULT"}
+}
+
+func (d fakeDialector) BindVarTo(writer clause.Writer, stmt *gorm.Statement, _ interface{}) {
+	_, _ = writer.WriteString("$" + strconv.Itoa(len(stmt.Vars)))
+}
+
+func (d fakeDialector) QuoteTo(writer clause.Writer, str string) {
+	for i, part := range strings.Split(str, ".") {
+		if i > 0 {
+			_ = writer.WriteByte('.')
+		}
+		_ = writer.WriteByte('"')
+		_, _ = writer.WriteString(part)
+		_ = writer.WriteByte('"')
+	}
+}
+
+func (d fakeDialector) SavePoint(tx *gorm.DB, name string) error {
+	return tx.Exec("SAVEPOINT " + name).Error
+}
+
+func (d fakeDialector) RollbackTo(tx *gorm.DB, name string) error {
+	return tx.Exec("ROLLBACK TO SAVEPOINT " + name).Error
+}
+
+func (d fakeDialector) Explain(sql string, vars ...interface{}) string {
+	return logger.ExplainSQL(sql, nil, `'`, vars...)
+}
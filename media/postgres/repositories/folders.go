@@ -180,6 +180,21 @@ func (r folderRepository) GetFolderMediaFilePaths(ctx context.Context, id *uuid.
 			)
 			SELECT DISTINCT filepath
 			FROM media
+			INNER JOIN parent_folders on parent_folders.id = media.folder_id
+			UNION
+			SELECT media_versions.filepath
+			FROM media_versions
+			INNER JOIN media on media.id = media_versions.media_id
+			INNER JOIN parent_folders on parent_folders.id = media.folder_id
+			UNION
+			SELECT media_derivatives.filepath
+			FROM media_derivatives
+			INNER JOIN media on media.id = media_derivatives.media_id
+			INNER JOIN parent_folders on parent_folders.id = media.folder_id
+			UNION
+			SELECT jsonb_array_elements_text(media_streams.files)
+			FROM media_streams
+			INNER JOIN media on media.id = media_streams.media_id
 			INNER JOIN parent_folders on parent_folders.id = media.folder_id`, id,
 	).Scan(&mediaFilepath).Error
 	if err != nil {
@@ -308,6 +323,7 @@ func (r folderRepository) GetFoldersAndMedias(ctx context.Context, filter action
 	err := r.db.Table(table).
 		WithContext(ctx).
 		Where("fm.folder_id", filter.Filter.FolderId).
+		Scopes(excludeIdsScope("fm", filter.Filter.ExcludeIds)).
 		Order(fmt.Sprintf("%s %s", sort, order)).
 		Limit(filter.Limit).Offset(filter.Offset).
 		Scan(&res.Items).
@@ -319,6 +335,7 @@ func (r folderRepository) GetFoldersAndMedias(ctx context.Context, filter action
 	err = r.db.Table(table).
 		WithContext(ctx).
 		Where("fm.folder_id", filter.Filter.FolderId).
+		Scopes(excludeIdsScope("fm", filter.Filter.ExcludeIds)).
 		Count(&res.Total).
 		Error
 	if err != nil {
@@ -386,3 +403,267 @@ func (r folderRepository) withRecursive(tableName string, folderId *uuid.UUID) f
 		})
 	}
 }
+
+// GetPermissions получение собственных прав папки
+func (r folderRepository) GetPermissions(ctx context.Context, id uuid.UUID) ([]entity.FolderPermission, error) {
+	permissions := make([]entity.FolderPermission, 0)
+	err := r.db.WithContext(ctx).
+		Where("folder_id = ?", id).
+		Order("role").
+		Find(&permissions).
+		Error
+	if err != nil {
+		return nil, errors.NoType.Wrap(err, "error getting folder permissions")
+	}
+
+	return permissions, nil
+}
+
+// GetEffectivePermissions получение прав ближайшей папки в цепочке родителей (включая саму папку), у которой они заданы
+func (r folderRepository) GetEffectivePermissions(ctx context.Context, id uuid.UUID) ([]entity.FolderPermission, error) {
+	var rows []struct {
+		entity.FolderPermission
+		Depth int
+	}
+	table := "tree"
+	err := r.db.WithContext(ctx).
+		Table(table).
+		Scopes(r.withRecursive(table, &id)).
+		Select("fp.folder_id, fp.role, fp.permission, tree.depth").
+		Joins("INNER JOIN folder_permissions fp ON fp.folder_id = tree.id").
+		Order("tree.depth, fp.role").
+		Scan(&rows).
+		Error
+	if err != nil {
+		return nil, errors.NoType.Wrap(err, "error getting folder effective permissions")
+	}
+
+	permissions := make([]entity.FolderPermission, 0)
+	for _, row := range rows {
+		if row.Depth != rows[0].Depth {
+			break
+		}
+		permissions = append(permissions, row.FolderPermission)
+	}
+
+	return permissions, nil
+}
+
+// SetPermissions замена прав папки
+func (r folderRepository) SetPermissions(ctx context.Context, id uuid.UUID, permissions ...entity.FolderPermission) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("folder_id = ?", id).Delete(&entity.FolderPermission{}).Error
+		if err != nil {
+			return errors.NoType.Wrap(err, "error deleting folder permissions")
+		}
+		if len(permissions) == 0 {
+			return nil
+		}
+
+		err = tx.Create(permissions).Error
+		if err != nil {
+			return errors.NoType.Wrap(err, "error creating folder permissions")
+		}
+
+		return nil
+	})
+	if err != nil {
+		return errors.NoType.Wrap(err, "error setting folder permissions")
+	}
+
+	return nil
+}
+
+// GetDeniedFolderIds получение id папок, на которые у ролей нет права с учетом наследования
+func (r folderRepository) GetDeniedFolderIds(ctx context.Context, roles []string, permission entity.Permission) ([]uuid.UUID, error) {
+	var rolesVar any = roles
+	if len(roles) == 0 {
+		rolesVar = nil
+	}
+
+	ids := make([]uuid.UUID, 0)
+	err := r.db.WithContext(ctx).Raw(
+		`WITH RECURSIVE tree (id, acl_folder_id) AS (
+				SELECT f.id, CASE WHEN EXISTS (SELECT 1 FROM folder_permissions fp WHERE fp.folder_id = f.id) THEN f.id END
+				FROM folders f
+				WHERE f.folder_id IS NULL
+				UNION ALL
+				SELECT f.id, CASE WHEN EXISTS (SELECT 1 FROM folder_permissions fp WHERE fp.folder_id = f.id) THEN f.id ELSE t.acl_folder_id END
+				FROM folders f
+				INNER JOIN tree t
+				ON f.folder_id = t.id
+			)
+			SELECT id
+			FROM tree
+			WHERE acl_folder_id IS NOT NULL
+			AND NOT EXISTS (
+				SELECT 1
+				FROM folder_permissions fp
+				WHERE fp.folder_id = tree.acl_folder_id
+				AND fp.role IN (?)
+				AND fp.permission IN (?)
+			)`, rolesVar, permission.Including(),
+	).Scan(&ids).Error
+	if err != nil {
+		return nil, errors.NoType.Wrap(err, "error getting denied folders")
+	}
+
+	return ids, nil
+}
+
+// excludeIdsScope исключение элементов с переданными id
+func excludeIdsScope(table string, ids []uuid.UUID) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if len(ids) == 0 {
+			return db
+		}
+		return db.Where(clause.Not(clause.IN{Column: clause.Column{Table: table, Name: "id"}, Values: uuidValues(ids)}))
+	}
+}
+
+func uuidValues(ids []uuid.UUID) []any {
+	values := make([]any, len(ids))
+	for i := range ids {
+		values[i] = ids[i]
+	}
+
+	return values
+}
+
+// GetQuotaUsages получение квот папки и ее родительских папок вместе с использованием хранилища каждой из них
+func (r folderRepository) GetQuotaUsages(ctx context.Context, id uuid.UUID) ([]actions.FolderQuotaUsage, error) {
+	usages := make([]actions.FolderQuotaUsage, 0)
+	table := "tree"
+	err := r.db.WithContext(ctx).
+		Table(table).
+		Scopes(r.withRecursive(table, &id)).
+		Select("folders.id, folders.max_size, folders.max_files").
+		Joins("INNER JOIN folders ON folders.id = tree.id").
+		Where("folders.max_size IS NOT NULL OR folders.max_files IS NOT NULL").
+		Scan(&usages).
+		Error
+	if err != nil {
+		return nil, errors.NoType.Wrap(err, "error getting folder quotas")
+	}
+
+	for i := range usages {
+		usage := struct {
+			Size  int64
+			Files int64
+		}{}
+		err = r.db.WithContext(ctx).Raw(
+			`WITH RECURSIVE sub_folders (id) AS (
+					SELECT id
+					FROM folders
+					WHERE id = ?
+					UNION ALL
+					SELECT f.id
+					FROM folders f
+					INNER JOIN sub_folders sf
+					ON f.folder_id = sf.id
+				)
+				SELECT COALESCE(SUM(media.size), 0)::bigint AS size, COUNT(media.id) AS files
+				FROM media
+				WHERE media.folder_id IN (SELECT id FROM sub_folders)`, usages[i].Id,
+		).Scan(&usage).Error
+		if err != nil {
+			return nil, errors.NoType.Wrap(err, "error getting folder usage")
+		}
+		usages[i].Size, usages[i].Files = usage.Size, usage.Files
+	}
+
+	return usages, nil
+}
+
+// SetQuota изменение квот папки
+func (r folderRepository) SetQuota(ctx context.Context, id uuid.UUID, maxSize *int64, maxFiles *int64) error {
+	err := r.db.WithContext(ctx).
+		Model(&entity.Folder{}).
+		Where("id = ?", id).
+		Updates(map[string]any{"max_size": maxSize, "max_files": maxFiles}).
+		Error
+	if err != nil {
+		return errors.NoType.Wrap(err, "error updating folder quota")
+	}
+
+	return nil
+}
+
+// GetStatistics получение статистики использования хранилища папкой (или всей медиабиблиотекой)
+func (r folderRepository) GetStatistics(ctx context.Context, folderId *uuid.UUID) (*actions.UsageStatistics, error) {
+	res := &actions.UsageStatistics{
+		Folders:    make([]actions.FolderUsage, 0),
+		Extensions: make([]actions.GroupUsage, 0),
+		Months:     make([]actions.GroupUsage, 0),
+	}
+
+	// медиа папки вместе с вложенными папками, для корня - все медиа
+	subtree := `WITH RECURSIVE sub_folders (id) AS (
+			SELECT id
+			FROM folders
+			WHERE id = @folder
+			UNION ALL
+			SELECT f.id
+			FROM folders f
+			INNER JOIN sub_folders sf
+			ON f.folder_id = sf.id
+		), subtree_media AS (
+			SELECT media.*
+			FROM media
+			WHERE @folder::uuid IS NULL OR media.folder_id IN (SELECT id FROM sub_folders)
+		)`
+	vars := map[string]any{"folder": folderId}
+
+	err := r.db.WithContext(ctx).Raw(
+		subtree+` SELECT COALESCE(SUM(size), 0)::bigint AS size, COUNT(id) AS files FROM subtree_media`, vars,
+	).Scan(res).Error
+	if err != nil {
+		return nil, errors.NoType.Wrap(err, "error getting folder usage")
+	}
+
+	err = r.db.WithContext(ctx).Raw(
+		`WITH RECURSIVE children (id, root_id) AS (
+				SELECT id, id
+				FROM folders
+				WHERE folder_id = @folder OR (@folder::uuid IS NULL AND folder_id IS NULL)
+				UNION ALL
+				SELECT f.id, c.root_id
+				FROM folders f
+				INNER JOIN children c
+				ON f.folder_id = c.id
+			)
+			SELECT folders.id, folders.name, COALESCE(SUM(media.size), 0)::bigint AS size, COUNT(media.id) AS files
+			FROM folders
+			INNER JOIN children ON children.root_id = folders.id
+			LEFT JOIN media ON media.folder_id = children.id
+			GROUP BY folders.id, folders.name
+			ORDER BY size DESC, folders.name`, vars,
+	).Scan(&res.Folders).Error
+	if err != nil {
+		return nil, errors.NoType.Wrap(err, "error getting subfolders usage")
+	}
+
+	err = r.db.WithContext(ctx).Raw(
+		subtree+` SELECT LOWER(COALESCE(SUBSTRING(filename FROM '\.([^./]+)$'), '')) AS "group",
+				COALESCE(SUM(size), 0)::bigint AS size, COUNT(id) AS files
+			FROM subtree_media
+			GROUP BY 1
+			ORDER BY size DESC`, vars,
+	).Scan(&res.Extensions).Error
+	if err != nil {
+		return nil, errors.NoType.Wrap(err, "error getting usage by extension")
+	}
+
+	err = r.db.WithContext(ctx).Raw(
+		subtree+` SELECT TO_CHAR(DATE_TRUNC('month', created_at), 'YYYY-MM') AS "group",
+				COALESCE(SUM(size), 0)::bigint AS size, COUNT(id) AS files
+			FROM subtree_media
+			GROUP BY 1
+			ORDER BY 1`, vars,
+	).Scan(&res.Months).Error
+	if err != nil {
+		return nil, errors.NoType.Wrap(err, "error getting usage by month")
+	}
+
+	return res, nil
+}
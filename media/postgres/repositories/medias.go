@@ -140,7 +140,7 @@ func (r mediaRepository) filterMedia(db *gorm.DB, filter actions.MediaFilter) *g
 func (r mediaRepository) GetShortList(ctx context.Context, ids []uuid.UUID) ([]entity.Media, error) {
 	var entities []entity.Media
 	err := r.db.WithContext(ctx).
-		Select("id, filepath, alt, title").
+		Select("id, filepath, alt, title, width, height, focal_point, crops").
 		Where("id IN (?)", ids).
 		Find(&entities).
 		Error
@@ -208,3 +208,263 @@ func getMediaFilterScopes(filter actions.MediaFilter) gormScope {
 		return db
 	}
 }
+
+// ReplaceContent сохранение версии медиа и обновление размера содержимого
+func (r mediaRepository) ReplaceContent(ctx context.Context, dto actions.ReplaceMediaContentDto) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Create(&dto.Version).Error
+		if err != nil {
+			return errors.NoType.Wrap(err, "error creating media version")
+		}
+
+		if dto.RestoredVersionId != nil {
+			err = tx.Where("id = ?", *dto.RestoredVersionId).Delete(&entity.MediaVersion{}).Error
+			if err != nil {
+				return errors.NoType.Wrap(err, "error deleting restored media version")
+			}
+		}
+
+		err = tx.Model(&entity.Media{}).
+			Where("id = ?", dto.Id).
+			Updates(map[string]any{
+				"size":       dto.Size,
+				"width":      dto.Width,
+				"height":     dto.Height,
+				"updated_at": gorm.Expr("now()"),
+			}).
+			Error
+		if err != nil {
+			return errors.NoType.Wrap(err, "error updating media size")
+		}
+
+		return nil
+	})
+	if err != nil {
+		return errors.NoType.Wrap(err, "error replacing media content")
+	}
+
+	return nil
+}
+
+// GetVersion получение версии медиа
+func (r mediaRepository) GetVersion(ctx context.Context, mediaId uuid.UUID, versionId uuid.UUID) (*entity.MediaVersion, error) {
+	version := &entity.MediaVersion{}
+	err := r.db.WithContext(ctx).
+		Where("id = ? AND media_id = ?", versionId, mediaId).
+		First(version).
+		Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, actions.ErrMediaVersionNotFound
+	}
+	if err != nil {
+		return nil, errors.NoType.Wrap(err, "error getting media version")
+	}
+
+	return version, nil
+}
+
+// ListVersions получение версий медиа, начиная с последней
+func (r mediaRepository) ListVersions(ctx context.Context, mediaIds ...uuid.UUID) ([]entity.MediaVersion, error) {
+	var versions []entity.MediaVersion
+	err := r.db.WithContext(ctx).
+		Where("media_id IN (?)", mediaIds).
+		Order("created_at DESC").
+		Find(&versions).
+		Error
+	if err != nil {
+		return nil, errors.NoType.Wrap(err, "error getting media versions")
+	}
+
+	return versions, nil
+}
+
+// UpdateFocus обновление точки фокуса и областей кадрирования медиа
+func (r mediaRepository) UpdateFocus(ctx context.Context, id uuid.UUID, focalPoint *entity.FocalPoint, crops entity.Crops) error {
+	err := r.db.WithContext(ctx).
+		Model(&entity.Media{}).
+		Where("id = ?", id).
+		Updates(map[string]any{"focal_point": focalPoint, "crops": crops, "updated_at": gorm.Expr("now()")}).
+		Error
+	if err != nil {
+		return errors.NoType.Wrap(err, "error updating media focus")
+	}
+
+	return nil
+}
+
+// GetDerivative получение производного изображения медиа по параметрам формирования
+func (r mediaRepository) GetDerivative(ctx context.Context, mediaId uuid.UUID, crop string, width int, height int) (*entity.MediaDerivative, error) {
+	derivative := &entity.MediaDerivative{}
+	err := r.db.WithContext(ctx).
+		Where("media_id = ? AND crop = ? AND width = ? AND height = ?", mediaId, crop, width, height).
+		First(derivative).
+		Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, actions.ErrMediaDerivativeNotFound
+	}
+	if err != nil {
+		return nil, errors.NoType.Wrap(err, "error getting media derivative")
+	}
+
+	return derivative, nil
+}
+
+// CreateDerivative сохранение производного изображения медиа
+func (r mediaRepository) CreateDerivative(ctx context.Context, derivative entity.MediaDerivative) error {
+	err := r.db.WithContext(ctx).Create(&derivative).Error
+	if err != nil {
+		return errors.NoType.Wrap(err, "error creating media derivative")
+	}
+
+	return nil
+}
+
+// ListDerivatives получение производных изображений медиа
+func (r mediaRepository) ListDerivatives(ctx context.Context, mediaIds ...uuid.UUID) ([]entity.MediaDerivative, error) {
+	var derivatives []entity.MediaDerivative
+	err := r.db.WithContext(ctx).
+		Where("media_id IN (?)", mediaIds).
+		Find(&derivatives).
+		Error
+	if err != nil {
+		return nil, errors.NoType.Wrap(err, "error getting media derivatives")
+	}
+
+	return derivatives, nil
+}
+
+// DeleteDerivatives удаление производных изображений медиа
+func (r mediaRepository) DeleteDerivatives(ctx context.Context, mediaIds ...uuid.UUID) error {
+	err := r.db.WithContext(ctx).
+		Where("media_id IN (?)", mediaIds).
+		Delete(&entity.MediaDerivative{}).
+		Error
+	if err != nil {
+		return errors.NoType.Wrap(err, "error deleting media derivatives")
+	}
+
+	return nil
+}
+
+// ListSubtitles получение дорожек субтитров медиа, упорядоченных по языку
+func (r mediaRepository) ListSubtitles(ctx context.Context, mediaId uuid.UUID) ([]entity.MediaSubtitle, error) {
+	var subtitles []entity.MediaSubtitle
+	err := r.db.WithContext(ctx).
+		Where("media_id = ?", mediaId).
+		Order("language").
+		Find(&subtitles).
+		Error
+	if err != nil {
+		return nil, errors.NoType.Wrap(err, "error getting media subtitles")
+	}
+
+	return subtitles, nil
+}
+
+// GetSubtitles получение дорожки субтитров медиа на языке language
+func (r mediaRepository) GetSubtitles(ctx context.Context, mediaId uuid.UUID, language string) (*entity.MediaSubtitle, error) {
+	subtitle := &entity.MediaSubtitle{}
+	err := r.db.WithContext(ctx).
+		Where("media_id = ? AND language = ?", mediaId, language).
+		First(subtitle).
+		Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, actions.ErrMediaSubtitlesNotFound
+	}
+	if err != nil {
+		return nil, errors.NoType.Wrap(err, "error getting media subtitles")
+	}
+
+	return subtitle, nil
+}
+
+// SaveSubtitles создание или замена дорожки субтитров медиа
+func (r mediaRepository) SaveSubtitles(ctx context.Context, subtitle entity.MediaSubtitle) error {
+	err := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "media_id"}, {Name: "language"}},
+			DoUpdates: clause.AssignmentColumns([]string{"cues", "updated_at"}),
+		}).
+		Create(&subtitle).
+		Error
+	if err != nil {
+		return errors.NoType.Wrap(err, "error saving media subtitles")
+	}
+
+	return nil
+}
+
+// DeleteSubtitles удаление дорожки субтитров медиа на языке language
+func (r mediaRepository) DeleteSubtitles(ctx context.Context, mediaId uuid.UUID, language string) error {
+	res := r.db.WithContext(ctx).
+		Where("media_id = ? AND language = ?", mediaId, language).
+		Delete(&entity.MediaSubtitle{})
+	if res.Error != nil {
+		return errors.NoType.Wrap(res.Error, "error deleting media subtitles")
+	}
+	if res.RowsAffected == 0 {
+		return actions.ErrMediaSubtitlesNotFound
+	}
+
+	return nil
+}
+
+// GetStream получение потока медиа
+func (r mediaRepository) GetStream(ctx context.Context, mediaId uuid.UUID) (*entity.MediaStream, error) {
+	stream := &entity.MediaStream{}
+	err := r.db.WithContext(ctx).
+		Where("media_id = ?", mediaId).
+		First(stream).
+		Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, actions.ErrMediaStreamNotFound
+	}
+	if err != nil {
+		return nil, errors.NoType.Wrap(err, "error getting media stream")
+	}
+
+	return stream, nil
+}
+
+// SaveStream создание или замена потока медиа
+func (r mediaRepository) SaveStream(ctx context.Context, stream entity.MediaStream) error {
+	err := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "media_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"status", "playlist", "variants", "files", "error", "updated_at"}),
+		}).
+		Create(&stream).
+		Error
+	if err != nil {
+		return errors.NoType.Wrap(err, "error saving media stream")
+	}
+
+	return nil
+}
+
+// ListStreams получение потоков медиа
+func (r mediaRepository) ListStreams(ctx context.Context, mediaIds ...uuid.UUID) ([]entity.MediaStream, error) {
+	var streams []entity.MediaStream
+	err := r.db.WithContext(ctx).
+		Where("media_id IN (?)", mediaIds).
+		Find(&streams).
+		Error
+	if err != nil {
+		return nil, errors.NoType.Wrap(err, "error getting media streams")
+	}
+
+	return streams, nil
+}
+
+// DeleteStreams удаление потоков медиа
+func (r mediaRepository) DeleteStreams(ctx context.Context, mediaIds ...uuid.UUID) error {
+	err := r.db.WithContext(ctx).
+		Where("media_id IN (?)", mediaIds).
+		Delete(&entity.MediaStream{}).
+		Error
+	if err != nil {
+		return errors.NoType.Wrap(err, "error deleting media streams")
+	}
+
+	return nil
+}
@@ -25,12 +25,21 @@ var Definitions = []di.Def{
 		},
 		Name: "focus.media.handler.media",
 	},
+	{
+		Build: func(ctn di.Container) (interface{}, error) {
+			bulk := ctn.Get("focus.media.actions.bulk").(*actions.Bulk)
+			validator := ctn.Get("focus.validator").(services.Validator)
+			return handlers.NewBulkHandler(bulk, validator), nil
+		},
+		Name: "focus.media.handler.bulk",
+	},
 	{
 		Build: func(ctn di.Container) (interface{}, error) {
 			confHandler := ctn.Get("focus.media.handler.folder").(*handlers.FolderHandler)
 			optHandler := ctn.Get("focus.media.handler.media").(*handlers.MediaHandler)
+			bulkHandler := ctn.Get("focus.media.handler.bulk").(*handlers.BulkHandler)
 			errorHandler := ctn.Get("focus.errorHandler").(services.ErrorHandler)
-			return NewRouter(confHandler, optHandler, errorHandler), nil
+			return NewRouter(confHandler, optHandler, bulkHandler, errorHandler), nil
 		},
 		Name: "focus.media.router",
 	},
@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/aeroideaservices/focus/media/plugin/actions"
+	"github.com/aeroideaservices/focus/media/rest/services"
+	"github.com/aeroideaservices/focus/services/errors"
+)
+
+// BulkHandler обработчик запросов массовых операций над папками и медиа
+type BulkHandler struct {
+	bulk      *actions.Bulk
+	validator services.Validator
+}
+
+// NewBulkHandler конструктор
+func NewBulkHandler(
+	bulk *actions.Bulk,
+	validator services.Validator,
+) *BulkHandler {
+	return &BulkHandler{
+		bulk:      bulk,
+		validator: validator,
+	}
+}
+
+// Move перемещение нескольких папок и медиа
+func (h BulkHandler) Move(c *gin.Context) {
+	action := actions.BulkMove{}
+	if err := c.ShouldBindJSON(&action); err != nil {
+		_ = c.Error(errors.BadRequest.Wrap(err, "error parsing json"))
+		return
+	}
+
+	if err := h.validator.Validate(c, action); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	res, err := h.bulk.Move(c, action)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, res)
+}
+
+// Delete удаление нескольких папок и медиа
+func (h BulkHandler) Delete(c *gin.Context) {
+	action := actions.BulkDelete{}
+	if err := c.ShouldBindJSON(&action); err != nil {
+		_ = c.Error(errors.BadRequest.Wrap(err, "error parsing json"))
+		return
+	}
+
+	if err := h.validator.Validate(c, action); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	res, err := h.bulk.Delete(c, action)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, res)
+}
+
+// Tag изменение тегов нескольких медиа
+func (h BulkHandler) Tag(c *gin.Context) {
+	action := actions.BulkTag{}
+	if err := c.ShouldBindJSON(&action); err != nil {
+		_ = c.Error(errors.BadRequest.Wrap(err, "error parsing json"))
+		return
+	}
+
+	if err := h.validator.Validate(c, action); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	res, err := h.bulk.Tag(c, action)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, res)
+}
+
+// UpdateFields изменение alt и title нескольких медиа
+func (h BulkHandler) UpdateFields(c *gin.Context) {
+	action := actions.BulkUpdateFields{}
+	if err := c.ShouldBindJSON(&action); err != nil {
+		_ = c.Error(errors.BadRequest.Wrap(err, "error parsing json"))
+		return
+	}
+
+	if err := h.validator.Validate(c, action); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	res, err := h.bulk.UpdateFields(c, action)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, res)
+}
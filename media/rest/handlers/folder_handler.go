@@ -217,3 +217,121 @@ func (h FolderHandler) Move(c *gin.Context) {
 
 	c.JSON(http.StatusNoContent, nil)
 }
+
+// GetPermissions получение прав папки
+func (h FolderHandler) GetPermissions(c *gin.Context) {
+	action := actions.GetFolder{}
+	stringId := c.Param(FolderIdParam)
+	id, err := uuid.Parse(stringId)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	action.Id = id
+
+	err = h.validator.Validate(c, action)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	permissions, err := h.folders.GetPermissions(c, action)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, permissions)
+}
+
+// SetPermissions изменение прав папки
+func (h FolderHandler) SetPermissions(c *gin.Context) {
+	action := actions.SetFolderPermissions{}
+	err := c.ShouldBindJSON(&action)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	stringId := c.Param(FolderIdParam)
+	id, err := uuid.Parse(stringId)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	action.Id = id
+
+	err = h.validator.Validate(c, action)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	err = h.folders.SetPermissions(c, action)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// SetQuota изменение квот папки
+func (h FolderHandler) SetQuota(c *gin.Context) {
+	action := actions.SetFolderQuota{}
+	err := c.ShouldBindJSON(&action)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	stringId := c.Param(FolderIdParam)
+	id, err := uuid.Parse(stringId)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	action.Id = id
+
+	err = h.validator.Validate(c, action)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	err = h.folders.SetQuota(c, action)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// GetStatistics получение статистики использования хранилища
+func (h FolderHandler) GetStatistics(c *gin.Context) {
+	action := actions.GetStatistics{}
+	stringId, hasId := c.GetQuery("folderId")
+	if hasId {
+		id, err := uuid.Parse(stringId)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+		action.FolderId = &id
+	}
+
+	err := h.validator.Validate(c, action)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	statistics, err := h.folders.GetStatistics(c, action)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, statistics)
+}
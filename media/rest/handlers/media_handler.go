@@ -304,3 +304,173 @@ func (h MediaHandler) Move(c *gin.Context) {
 
 	c.JSON(http.StatusNoContent, nil)
 }
+
+// ReplaceContent замена содержимого медиа
+func (h MediaHandler) ReplaceContent(c *gin.Context) {
+	stringMediaId := c.Param(FileIdParam)
+	mediaId, err := uuid.Parse(stringMediaId)
+	if err != nil {
+		_ = c.Error(errors.BadRequest.Wrap(err, "error parsing uuid"))
+		return
+	}
+
+	file, err := c.FormFile("file")
+	if err != nil {
+		_ = c.Error(errors.BadRequest.Wrap(err, "error getting form file"))
+		return
+	}
+
+	fo, err := file.Open()
+	if err != nil {
+		_ = c.Error(errors.BadRequest.Wrap(err, "error opening form file"))
+		return
+	}
+	defer func() { _ = fo.Close() }()
+
+	action := actions.ReplaceMediaContent{
+		Id:         mediaId,
+		Filename:   file.Filename,
+		Size:       file.Size,
+		File:       fo,
+		UploadedBy: c.GetString("user-id"),
+	}
+
+	err = h.validator.Validate(c, action)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	err = h.medias.ReplaceContent(c, action)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// ListVersions получение истории версий медиа
+func (h MediaHandler) ListVersions(c *gin.Context) {
+	stringMediaId := c.Param(FileIdParam)
+	mediaId, err := uuid.Parse(stringMediaId)
+	if err != nil {
+		_ = c.Error(errors.BadRequest.Wrap(err, "error parsing uuid"))
+		return
+	}
+
+	action := actions.GetMedia{Id: mediaId}
+
+	if err := h.validator.Validate(c, action); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	versions, err := h.medias.ListVersions(c, action)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, versions)
+}
+
+// RevertVersion восстановление версии медиа
+func (h MediaHandler) RevertVersion(c *gin.Context) {
+	stringMediaId := c.Param(FileIdParam)
+	mediaId, err := uuid.Parse(stringMediaId)
+	if err != nil {
+		_ = c.Error(errors.BadRequest.Wrap(err, "error parsing uuid"))
+		return
+	}
+
+	stringVersionId := c.Param(VersionIdParam)
+	versionId, err := uuid.Parse(stringVersionId)
+	if err != nil {
+		_ = c.Error(errors.BadRequest.Wrap(err, "error parsing uuid"))
+		return
+	}
+
+	action := actions.RevertMediaVersion{
+		Id:         mediaId,
+		VersionId:  versionId,
+		UploadedBy: c.GetString("user-id"),
+	}
+
+	if err := h.validator.Validate(c, action); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	err = h.medias.RevertVersion(c, action)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// UpdateFocus изменение точки фокуса и областей кадрирования изображения
+func (h MediaHandler) UpdateFocus(c *gin.Context) {
+	stringMediaId := c.Param(FileIdParam)
+	mediaId, err := uuid.Parse(stringMediaId)
+	if err != nil {
+		_ = c.Error(errors.BadRequest.Wrap(err, "error parsing uuid"))
+		return
+	}
+
+	action := actions.UpdateMediaFocus{}
+	err = c.ShouldBindJSON(&action)
+	if err != nil {
+		_ = c.Error(errors.BadRequest.Wrap(err, "error parsing json"))
+		return
+	}
+	action.Id = mediaId
+
+	err = h.validator.Validate(c, action)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	err = h.medias.UpdateFocus(c, action)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// GetDerivative получение производного (кадрированного и уменьшенного) изображения
+func (h MediaHandler) GetDerivative(c *gin.Context) {
+	stringMediaId := c.Param(FileIdParam)
+	mediaId, err := uuid.Parse(stringMediaId)
+	if err != nil {
+		_ = c.Error(errors.BadRequest.Wrap(err, "error parsing uuid"))
+		return
+	}
+
+	action := actions.GetMediaDerivative{}
+	err = c.ShouldBindQuery(&action)
+	if err != nil {
+		_ = c.Error(errors.BadRequest.Wrap(err, "error parsing query"))
+		return
+	}
+	action.Id = mediaId
+
+	err = h.validator.Validate(c, action)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	derivative, err := h.medias.GetDerivative(c, action)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, derivative)
+}
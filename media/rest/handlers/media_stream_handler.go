@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/aeroideaservices/focus/media/plugin/actions"
+	"github.com/aeroideaservices/focus/services/errors"
+)
+
+// GetStream получение статуса и ссылок на плейлисты потока HLS медиа
+func (h MediaHandler) GetStream(c *gin.Context) {
+	stringMediaId := c.Param(FileIdParam)
+	mediaId, err := uuid.Parse(stringMediaId)
+	if err != nil {
+		_ = c.Error(errors.BadRequest.Wrap(err, "error parsing uuid"))
+		return
+	}
+
+	action := actions.GetMedia{Id: mediaId}
+
+	if err := h.validator.Validate(c, action); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	stream, err := h.medias.GetStream(c, action)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, stream)
+}
@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/aeroideaservices/focus/media/plugin/actions"
+	"github.com/aeroideaservices/focus/services/errors"
+)
+
+// ListSubtitles получение списка дорожек субтитров медиа
+func (h MediaHandler) ListSubtitles(c *gin.Context) {
+	stringMediaId := c.Param(FileIdParam)
+	mediaId, err := uuid.Parse(stringMediaId)
+	if err != nil {
+		_ = c.Error(errors.BadRequest.Wrap(err, "error parsing uuid"))
+		return
+	}
+
+	action := actions.GetMedia{Id: mediaId}
+
+	if err := h.validator.Validate(c, action); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	tracks, err := h.medias.ListSubtitles(c, action)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, tracks)
+}
+
+// ExportSubtitles выгрузка дорожки субтитров медиа, формат определяется расширением пути (subtitles.vtt, subtitles.srt)
+func (h MediaHandler) ExportSubtitles(c *gin.Context) {
+	stringMediaId := c.Param(FileIdParam)
+	mediaId, err := uuid.Parse(stringMediaId)
+	if err != nil {
+		_ = c.Error(errors.BadRequest.Wrap(err, "error parsing uuid"))
+		return
+	}
+
+	action := actions.GetMediaSubtitles{}
+	err = c.ShouldBindQuery(&action)
+	if err != nil {
+		_ = c.Error(errors.BadRequest.Wrap(err, "error parsing query"))
+		return
+	}
+	action.Id = mediaId
+	action.Format = strings.TrimPrefix(filepath.Ext(c.FullPath()), ".")
+
+	err = h.validator.Validate(c, action)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	file, err := h.medias.ExportSubtitles(c, action)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.Header("Content-Disposition", mime.FormatMediaType("inline", map[string]string{"filename": file.Filename}))
+	c.Data(http.StatusOK, file.ContentType, file.Content)
+}
+
+// UploadSubtitles загрузка дорожки субтитров медиа из файла WebVTT или SRT, формат определяется расширением файла
+func (h MediaHandler) UploadSubtitles(c *gin.Context) {
+	stringMediaId := c.Param(FileIdParam)
+	mediaId, err := uuid.Parse(stringMediaId)
+	if err != nil {
+		_ = c.Error(errors.BadRequest.Wrap(err, "error parsing uuid"))
+		return
+	}
+
+	file, err := c.FormFile("file")
+	if err != nil {
+		_ = c.Error(errors.BadRequest.Wrap(err, "error getting form file"))
+		return
+	}
+
+	fo, err := file.Open()
+	if err != nil {
+		_ = c.Error(errors.BadRequest.Wrap(err, "error opening form file"))
+		return
+	}
+	defer func() { _ = fo.Close() }()
+
+	action := actions.UploadMediaSubtitles{
+		Id:       mediaId,
+		Language: c.PostForm("language"),
+		Format:   strings.ToLower(strings.TrimPrefix(filepath.Ext(file.Filename), ".")),
+		File:     fo,
+	}
+
+	err = h.validator.Validate(c, action)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	err = h.medias.UploadSubtitles(c, action)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// DeleteSubtitles удаление дорожки субтитров медиа
+func (h MediaHandler) DeleteSubtitles(c *gin.Context) {
+	stringMediaId := c.Param(FileIdParam)
+	mediaId, err := uuid.Parse(stringMediaId)
+	if err != nil {
+		_ = c.Error(errors.BadRequest.Wrap(err, "error parsing uuid"))
+		return
+	}
+
+	action := actions.DeleteMediaSubtitles{}
+	err = c.ShouldBindQuery(&action)
+	if err != nil {
+		_ = c.Error(errors.BadRequest.Wrap(err, "error parsing query"))
+		return
+	}
+	action.Id = mediaId
+
+	err = h.validator.Validate(c, action)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	err = h.medias.DeleteSubtitles(c, action)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
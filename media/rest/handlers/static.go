@@ -1,6 +1,7 @@
 package handlers
 
 const (
-	FolderIdParam = "folder-id"
-	FileIdParam   = "file-id"
+	FolderIdParam  = "folder-id"
+	FileIdParam    = "file-id"
+	VersionIdParam = "version-id"
 )
@@ -10,17 +10,20 @@ import (
 type Router struct {
 	folderHandler *handlers.FolderHandler
 	mediaHandler  *handlers.MediaHandler
+	bulkHandler   *handlers.BulkHandler
 	errorHandler  services.ErrorHandler
 }
 
 // NewRouter конструктор
 func NewRouter(folderHandler *handlers.FolderHandler,
 	mediaHandler *handlers.MediaHandler,
+	bulkHandler *handlers.BulkHandler,
 	errorHandler services.ErrorHandler,
 ) *Router {
 	return &Router{
 		folderHandler: folderHandler,
 		mediaHandler:  mediaHandler,
+		bulkHandler:   bulkHandler,
 		errorHandler:  errorHandler,
 	}
 }
@@ -29,8 +32,10 @@ func NewRouter(folderHandler *handlers.FolderHandler,
 func (r *Router) SetRoutes(group *gin.RouterGroup) {
 	media := group.Group("media")
 	media.Use(r.errorHandler.Handle) // отлов ошибок
+	media.Use(services.SetAccessor)  // права на папки
 
 	media.GET("", r.folderHandler.GetAll)
+	media.GET("statistics", r.folderHandler.GetStatistics)
 
 	folders := media.Group("folders")
 	folders.GET("", r.folderHandler.GetTree)
@@ -41,6 +46,9 @@ func (r *Router) SetRoutes(group *gin.RouterGroup) {
 	folder.DELETE("", r.folderHandler.Delete)
 	folder.PATCH("move", r.folderHandler.Move)
 	folder.PATCH("rename", r.folderHandler.Rename)
+	folder.GET("permissions", r.folderHandler.GetPermissions)
+	folder.PUT("permissions", r.folderHandler.SetPermissions)
+	folder.PUT("quota", r.folderHandler.SetQuota)
 
 	files := media.Group("files")
 	files.POST("", r.mediaHandler.Create)
@@ -52,4 +60,21 @@ func (r *Router) SetRoutes(group *gin.RouterGroup) {
 	file.DELETE("", r.mediaHandler.Delete)
 	file.PATCH("move", r.mediaHandler.Move)
 	file.PATCH("rename", r.mediaHandler.Rename)
+	file.PUT("content", r.mediaHandler.ReplaceContent)
+	file.GET("versions", r.mediaHandler.ListVersions)
+	file.POST("versions/:"+handlers.VersionIdParam+"/revert", r.mediaHandler.RevertVersion)
+	file.PATCH("focus", r.mediaHandler.UpdateFocus)
+	file.GET("derivative", r.mediaHandler.GetDerivative)
+	file.GET("subtitles", r.mediaHandler.ListSubtitles)
+	file.GET("subtitles.vtt", r.mediaHandler.ExportSubtitles)
+	file.GET("subtitles.srt", r.mediaHandler.ExportSubtitles)
+	file.PUT("subtitles", r.mediaHandler.UploadSubtitles)
+	file.DELETE("subtitles", r.mediaHandler.DeleteSubtitles)
+	file.GET("stream", r.mediaHandler.GetStream)
+
+	bulk := media.Group("bulk")
+	bulk.POST("move", r.bulkHandler.Move)
+	bulk.POST("delete", r.bulkHandler.Delete)
+	bulk.POST("tags", r.bulkHandler.Tag)
+	bulk.PATCH("fields", r.bulkHandler.UpdateFields)
 }
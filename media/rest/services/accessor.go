@@ -0,0 +1,19 @@
+package services
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/aeroideaservices/focus/media/plugin/actions"
+)
+
+// SetAccessor передача ролей пользователя, проставленных middleware access_control, в действия медиабиблиотеки.
+// Если роли не проставлены, права на папки не проверяются
+func SetAccessor(c *gin.Context) {
+	if scopes, ok := c.Get("scopes"); ok {
+		if roles, ok := scopes.([]string); ok {
+			c.Set(actions.AccessorKey, &actions.Accessor{Roles: roles})
+		}
+	}
+
+	c.Next()
+}
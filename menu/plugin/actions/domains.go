@@ -56,3 +56,28 @@ func (d Domains) Create(ctx context.Context, action CreateDomain) (*uuid.UUID, e
 
 	return &domain.Id, nil
 }
+
+// Hosts список всех доменов, используется картой сайта для построения карты по доменам
+func (d Domains) Hosts(ctx context.Context) ([]string, error) {
+	total, err := d.domainRepository.Count(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if total == 0 {
+		return []string{}, nil
+	}
+
+	items, err := d.domainRepository.List(ctx, DomainsListQuery{
+		Pagination: Pagination{Limit: int(total)},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	hosts := make([]string, 0, len(items))
+	for _, item := range items {
+		hosts = append(hosts, item.Domain)
+	}
+
+	return hosts, nil
+}
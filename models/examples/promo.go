@@ -7,6 +7,7 @@ import (
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
+	"time"
 )
 
 type Promo struct {
@@ -32,7 +33,7 @@ type Promo struct {
 	Category            *Category            `focus:"title:Категория;view:select;viewExtra:categorySelect;hidden:list" validate:"omitempty,structonly"`
 	Products            []Product            `focus:"title:Товары на детальной странице акции;view:select;viewExtra:selectProducts;hidden:list;many2many:promos_products;joinSort:sort" gorm:"many2many:promos_products" validate:"omitempty,max=50,unique=ID,dive,notBlank,structonly"`
 	ShortDescription    string               `focus:"title:Краткое описание;view:textarea"`
-	PreviewDescription  string               `focus:"title:Описание превью акции;view:wysiwyg;hidden:list"`
+	PreviewDescription  string               `focus:"title:Описание превью акции;view:wysiwyg;sanitize;hidden:list"`
 	Description         string               `focus:"title:Описание акции;view:editorJs;viewExtra:promoEditorJs;hidden:list" validate:"omitempty,json"`
 }
 
@@ -44,6 +45,19 @@ func (Promo) ModelTitle() string {
 	return "Акции"
 }
 
+// SitemapLoc в карту сайта попадают активные акции с детальной страницей
+func (p Promo) SitemapLoc() string {
+	if !p.Active || p.RedirectLink != "" {
+		return ""
+	}
+
+	return "/promos/" + p.Code
+}
+
+func (p Promo) SitemapLastMod() *time.Time {
+	return nil
+}
+
 func (p Promo) AfterCreate(tx *gorm.DB) error {
 	return p.setProductsSort(tx)
 }
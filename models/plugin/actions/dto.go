@@ -138,6 +138,9 @@ type FormField struct {
 	Disabled  bool           `json:"disabled,omitempty"`
 	Step      float64        `json:"step,omitempty"`
 	Precision int            `json:"precision,omitempty"`
+
+	MediaRequireAlt  bool    `json:"mediaRequireAlt,omitempty"`
+	MediaAspectRatio float64 `json:"mediaAspectRatio,omitempty"`
 }
 
 type GetModel struct {
@@ -4,6 +4,7 @@ import (
 	"context"
 	"github.com/aeroideaservices/focus/models/plugin/entity"
 	"github.com/aeroideaservices/focus/models/plugin/focus"
+	"github.com/aeroideaservices/focus/services/sanitizer"
 	"github.com/google/uuid"
 	"os"
 )
@@ -31,6 +32,13 @@ type RepositoryResolver interface {
 
 type MediaService interface {
 	CheckIds(ctx context.Context, ids ...uuid.UUID) error
+	CheckAlt(ctx context.Context, ids ...uuid.UUID) error
+	CheckAspectRatio(ctx context.Context, ratio float64, ids ...uuid.UUID) error
+}
+
+type Sanitizer interface {
+	Has(policyName string) bool
+	Sanitize(policy string, input string) (string, sanitizer.Report, error)
 }
 
 type Validator interface {
@@ -11,6 +11,7 @@ import (
 
 	"github.com/aeroideaservices/focus/models/plugin/focus"
 	"github.com/aeroideaservices/focus/services/errors"
+	"github.com/aeroideaservices/focus/services/sanitizer"
 )
 
 type ModelElements struct {
@@ -18,6 +19,7 @@ type ModelElements struct {
 	repositoryResolver RepositoryResolver
 	mediaService       MediaService
 	validator          Validator
+	sanitizer          Sanitizer
 	callbacks          map[string]callbacks.Callbacks
 }
 
@@ -26,6 +28,7 @@ func NewModelElements(
 	repositoryResolver RepositoryResolver,
 	mediaService MediaService,
 	validator Validator,
+	sanitizer Sanitizer,
 	callbacks map[string]callbacks.Callbacks,
 ) *ModelElements {
 	return &ModelElements{
@@ -33,6 +36,7 @@ func NewModelElements(
 		repositoryResolver: repositoryResolver,
 		mediaService:       mediaService,
 		validator:          validator,
+		sanitizer:          sanitizer,
 		callbacks:          callbacks,
 	}
 }
@@ -178,10 +182,11 @@ func (s ModelElements) Get(ctx context.Context, action GetModelElement) (map[str
 }
 
 // Create создание нового элемента модели.
-func (s ModelElements) Create(ctx context.Context, action CreateModelElement) (pkey any, err error) {
+// Возвращает отчет об очистке html в полях с политикой очистки.
+func (s ModelElements) Create(ctx context.Context, action CreateModelElement) (pkey any, report sanitizer.Report, err error) {
 	model := s.modelsRegistry.GetModel(action.ModelCode)
 	if model == nil {
-		return nil, errors.NotFound.Newf("model with code \"%s\" not found", action.ModelCode)
+		return nil, report, errors.NotFound.Newf("model with code \"%s\" not found", action.ModelCode)
 	}
 
 	// Генерируем новый первичный ключ
@@ -192,59 +197,71 @@ func (s ModelElements) Create(ctx context.Context, action CreateModelElement) (p
 		return !slices.Contains(field.Disabled, focus.CreateView) || field == field.Model.PrimaryKey
 	})
 	if err != nil {
-		return nil, errors.BadRequest.Wrap(err, "error parsing model element")
+		return nil, report, errors.BadRequest.Wrap(err, "error parsing model element")
+	}
+
+	report, err = s.sanitize(model, elem)
+	if err != nil {
+		return nil, report, err
 	}
 
 	err = s.validator.Validate(ctx, elem)
 	if err != nil {
-		return nil, err
+		return nil, report, err
 	}
 
 	err = s.validate(ctx, model, elem, nil)
 	if err != nil {
-		return nil, err
+		return nil, report, err
 	}
 
 	repository := s.repositoryResolver.Resolve(model.Code)
 	if repository == nil {
-		return nil, errors.NoType.New("cannot resolve repository")
+		return nil, report, errors.NoType.New("cannot resolve repository")
 	}
 
 	pkey, err = repository.Create(ctx, elem)
 	if err != nil {
-		return nil, errors.NoType.Wrap(err, "error creating model element")
+		return nil, report, errors.NoType.Wrap(err, "error creating model element")
 	}
 
 	s.afterCreate(model.Code, pkey)
 
-	return pkey, nil
+	return pkey, report, nil
 }
 
 // Update обновление элемента модели.
-func (s ModelElements) Update(ctx context.Context, action UpdateModelElement) error {
+// Возвращает отчет об очистке html в полях с политикой очистки.
+func (s ModelElements) Update(ctx context.Context, action UpdateModelElement) (sanitizer.Report, error) {
+	report := sanitizer.Report{}
 	model := s.modelsRegistry.GetModel(action.ModelCode)
 	if model == nil {
-		return errors.NotFound.Newf("model with code \"%s\" not found", action.ModelCode)
+		return report, errors.NotFound.Newf("model with code \"%s\" not found", action.ModelCode)
 	}
 
 	repository := s.repositoryResolver.Resolve(model.Code)
 	if repository == nil {
-		return errors.NoType.New("cannot resolve repository")
+		return report, errors.NoType.New("cannot resolve repository")
 	}
 
 	pKey, err := model.PrimaryKey.NewValue(action.PKey)
 	if err != nil {
-		return errors.BadRequest.Wrap(err, "error converting pKey").T("model-element.field.wrong", model.PrimaryKey.Title)
+		return report, errors.BadRequest.Wrap(err, "error converting pKey").T("model-element.field.wrong", model.PrimaryKey.Title)
 	}
 
 	oldElem, err := repository.Get(ctx, pKey)
 	if err != nil {
-		return errors.NoType.Wrap(err, "error getting model element")
+		return report, errors.NoType.Wrap(err, "error getting model element")
 	}
 
 	elem, err := model.NewElement(action.ModelElement, func(field *focus.Field) bool { return !slices.Contains(field.Disabled, focus.UpdateView) })
 	if err != nil {
-		return errors.BadRequest.Wrap(err, "error parsing model element")
+		return report, errors.BadRequest.Wrap(err, "error parsing model element")
+	}
+
+	report, err = s.sanitize(model, elem)
+	if err != nil {
+		return report, err
 	}
 
 	fieldsFilter := func(field *focus.Field, fieldValue any) bool {
@@ -259,28 +276,28 @@ func (s ModelElements) Update(ctx context.Context, action UpdateModelElement) er
 	}
 	err = s.validate(ctx, model, elem, fieldsFilter)
 	if err != nil {
-		return errors.BadRequest.Wrap(err, "validation error")
+		return report, errors.BadRequest.Wrap(err, "validation error")
 	}
 
 	// наполняем полученную модель данными из запроса (заполняем только теми полями, которые доступны для обновления)
 	err = model.UpdateElement(elem, oldElem, func(field *focus.Field) bool { return slices.Contains(field.Disabled, focus.UpdateView) })
 	if err != nil {
-		return errors.BadRequest.Wrap(err, "error filling struct")
+		return report, errors.BadRequest.Wrap(err, "error filling struct")
 	}
 
 	err = s.validator.Validate(ctx, elem)
 	if err != nil {
-		return err
+		return report, err
 	}
 
 	err = repository.Update(ctx, elem)
 	if err != nil {
-		return errors.NoType.Wrap(err, "an error occurred while updating model")
+		return report, errors.NoType.Wrap(err, "an error occurred while updating model")
 	}
 
 	s.afterUpdate(model.Code, pKey)
 
-	return nil
+	return report, nil
 }
 
 // DeleteList удаление нескольких элементов модели.
@@ -408,8 +425,8 @@ func (s ModelElements) checkAssociations(ctx context.Context, assoc *focus.Assoc
 }
 
 // checkMedias проверяет поле типа медиа.
-// Если медиа не найдено, возвращается ошибка.
-func (s ModelElements) checkMedias(ctx context.Context, fv any) error {
+// Если медиа не найдено или не соответствует требованиям поля, возвращается ошибка.
+func (s ModelElements) checkMedias(ctx context.Context, field *focus.Field, fv any) error {
 	// если плагин focus.media не подключен - ошибка
 	if s.mediaService == nil {
 		return errMediaPluginIsNotImported
@@ -431,9 +448,52 @@ func (s ModelElements) checkMedias(ctx context.Context, fv any) error {
 	if err != nil {
 		return errors.BadRequest.Wrap(err, "validation error")
 	}
+	if field.MediaProperties == nil {
+		return nil
+	}
+
+	if field.RequireAlt {
+		err = s.mediaService.CheckAlt(ctx, ids...)
+		if err != nil {
+			return errors.BadRequest.Wrap(err, "validation error").T("model-element.field.media-alt-required", field.Title)
+		}
+	}
+	if field.AspectRatio != 0 {
+		err = s.mediaService.CheckAspectRatio(ctx, field.AspectRatio, ids...)
+		if err != nil {
+			return errors.BadRequest.Wrap(err, "validation error").T("model-element.field.media-aspect-ratio", field.Title)
+		}
+	}
 	return nil
 }
 
+// sanitize очищает html в полях элемента модели, для которых задана политика очистки.
+func (s ModelElements) sanitize(model *focus.Model, elem any) (sanitizer.Report, error) {
+	report := sanitizer.Report{}
+	value := reflect.ValueOf(elem).Elem()
+	for _, field := range model.Fields {
+		if field.Sanitize == "" {
+			continue
+		}
+		fv := value.FieldByName(field.Name())
+		for fv.Kind() == reflect.Pointer && !fv.IsNil() {
+			fv = fv.Elem()
+		}
+		if fv.Kind() != reflect.String || fv.String() == "" {
+			continue
+		}
+
+		sanitized, fieldReport, err := s.sanitizer.Sanitize(field.Sanitize, fv.String())
+		if err != nil {
+			return report, errors.NoType.Wrapf(err, "error sanitizing field %s", field.Code)
+		}
+		fv.SetString(sanitized)
+		report.Add(field.Code, fieldReport)
+	}
+
+	return report, nil
+}
+
 // validate проверяет поля элемента модели.
 func (s ModelElements) validate(ctx context.Context, model *focus.Model, elem any, filter func(field *focus.Field, fieldValue any) bool) error {
 	value := reflect.ValueOf(elem).Elem()
@@ -459,7 +519,7 @@ func (s ModelElements) validate(ctx context.Context, model *focus.Model, elem an
 			if s.mediaService == nil {
 				return errMediaPluginIsNotImported
 			}
-			err := s.checkMedias(ctx, fv)
+			err := s.checkMedias(ctx, field, fv)
 			if err != nil {
 				return err
 			}
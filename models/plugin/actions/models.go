@@ -143,6 +143,10 @@ func (s Models) createView(model *focus.Model) EditView {
 			formField.Step = field.Step
 			formField.Precision = field.Precision
 		}
+		if field.MediaProperties != nil {
+			formField.MediaRequireAlt = field.RequireAlt
+			formField.MediaAspectRatio = field.AspectRatio
+		}
 
 		formFields = append(formFields, formField)
 	}
@@ -176,6 +180,10 @@ func (s Models) updateView(model *focus.Model) EditView {
 			formField.Step = field.Step
 			formField.Precision = field.Precision
 		}
+		if field.MediaProperties != nil {
+			formField.MediaRequireAlt = field.RequireAlt
+			formField.MediaAspectRatio = field.AspectRatio
+		}
 
 		formFields = append(formFields, formField)
 	}
@@ -0,0 +1,82 @@
+package actions
+
+import (
+	"context"
+	"github.com/aeroideaservices/focus/models/plugin/focus"
+	"github.com/aeroideaservices/focus/services/errors"
+	sitemapEntity "github.com/aeroideaservices/focus/sitemap/plugin/entity"
+	"sort"
+)
+
+// sitemapBatchSize количество элементов модели, получаемых за один запрос
+const sitemapBatchSize = 1000
+
+// SitemapSource элементы моделей, реализующих focus.SitemapElement, для карты сайта
+type SitemapSource struct {
+	modelsRegistry     ModelsRegistry
+	repositoryResolver RepositoryResolver
+}
+
+func NewSitemapSource(modelsRegistry ModelsRegistry, repositoryResolver RepositoryResolver) *SitemapSource {
+	return &SitemapSource{
+		modelsRegistry:     modelsRegistry,
+		repositoryResolver: repositoryResolver,
+	}
+}
+
+// Urls адреса элементов моделей в порядке кодов моделей и первичных ключей, домен не учитывается
+func (s SitemapSource) Urls(ctx context.Context, _ string) ([]sitemapEntity.Url, error) {
+	models := s.modelsRegistry.ListModels()
+	sort.Slice(models, func(i, j int) bool {
+		return models[i].Code < models[j].Code
+	})
+
+	var urls []sitemapEntity.Url
+	for _, model := range models {
+		if !model.InSitemap() {
+			continue
+		}
+
+		modelUrls, err := s.modelUrls(ctx, model)
+		if err != nil {
+			return nil, err
+		}
+		urls = append(urls, modelUrls...)
+	}
+
+	return urls, nil
+}
+
+func (s SitemapSource) modelUrls(ctx context.Context, model *focus.Model) ([]sitemapEntity.Url, error) {
+	repository := s.repositoryResolver.Resolve(model.Code)
+	if repository == nil {
+		return nil, errors.NoType.Newf("cannot resolve repository of model %s", model.Code)
+	}
+
+	var urls []sitemapEntity.Url
+	for offset := 0; ; offset += sitemapBatchSize {
+		elems, err := repository.List(ctx, ListModelElementsQuery{
+			ModelCode:  model.Code,
+			Pagination: Pagination{Offset: offset, Limit: sitemapBatchSize},
+			OrderBy:    OrderBy{Sort: model.PrimaryKey.Column, Order: "asc"},
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, elem := range elems {
+			sitemapElement, ok := elem.(focus.SitemapElement)
+			if !ok || sitemapElement.SitemapLoc() == "" {
+				continue
+			}
+			urls = append(urls, sitemapEntity.Url{
+				Loc:     sitemapElement.SitemapLoc(),
+				LastMod: sitemapElement.SitemapLastMod(),
+			})
+		}
+
+		if len(elems) < sitemapBatchSize {
+			return urls, nil
+		}
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"github.com/aeroideaservices/focus/models/plugin/focus"
 	"github.com/aeroideaservices/focus/models/plugin/form"
 	focsCallbacks "github.com/aeroideaservices/focus/services/callbacks"
+	"github.com/aeroideaservices/focus/services/sanitizer"
 	"github.com/sarulabs/di/v2"
 	"go.uber.org/zap"
 )
@@ -39,7 +40,9 @@ var Definitions = []di.Def{
 				callbacks = callbacksI.(map[string]focsCallbacks.Callbacks)
 			}
 
-			modelElementsAction := actions.NewModelElements(modelsRegistry, repositoryResolver, mediaService, validator, callbacks)
+			modelElementsAction := actions.NewModelElements(
+				modelsRegistry, repositoryResolver, mediaService, validator, htmlSanitizer(ctn), callbacks,
+			)
 
 			return modelElementsAction, nil
 		},
@@ -56,6 +59,14 @@ var Definitions = []di.Def{
 			return actions.NewExport(repo, modelsRegistry, exporter, fileStorage, logger, fileStorageBaseEndpoint), nil
 		},
 	},
+	{
+		Name: "focus.models.sitemap.source",
+		Build: func(ctn di.Container) (interface{}, error) {
+			repositoryResolver := ctn.Get("focus.models.repositories.resolver").(actions.RepositoryResolver)
+			modelsRegistry := ctn.Get("focus.models.registry").(*focus.ModelsRegistry)
+			return actions.NewSitemapSource(modelsRegistry, repositoryResolver), nil
+		},
+	},
 	{
 		Name: "focus.models.registry",
 		Build: func(ctn di.Container) (interface{}, error) {
@@ -68,8 +79,20 @@ var Definitions = []di.Def{
 
 			modelsRegistry := focus.NewModelsRegistry(supportMedia)
 			modelsRegistry.Register(models...)
+			if err = modelsRegistry.CheckSanitizePolicies(htmlSanitizer(ctn)); err != nil {
+				return nil, err
+			}
 
 			return modelsRegistry, nil
 		},
 	},
 }
+
+// htmlSanitizer политики очистки html настраиваются через focus.sanitizer, без него используются политики по умолчанию
+func htmlSanitizer(ctn di.Container) actions.Sanitizer {
+	if sanitizerI, _ := ctn.SafeGet("focus.sanitizer"); sanitizerI != nil {
+		return sanitizerI.(actions.Sanitizer)
+	}
+
+	return sanitizer.NewSanitizer()
+}
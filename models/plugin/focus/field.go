@@ -49,6 +49,8 @@ type Field struct {
 	Multiple         bool           // Multiple множественное
 	Model            *Model         // Model Описание модели поля
 	*FloatProperties                // FloatProperties настройки для типа float
+	*MediaProperties                // MediaProperties требования к медиа
+	Sanitize         string         // Sanitize политика очистки html в значении поля
 
 	Association *Association // Association Описание ассоциации
 
@@ -62,6 +64,11 @@ type FloatProperties struct {
 	Precision int
 }
 
+type MediaProperties struct {
+	RequireAlt  bool    // RequireAlt медиа должно иметь альтернативный текст
+	AspectRatio float64 // AspectRatio требуемое соотношение сторон изображения или одной из его областей кадрирования
+}
+
 // Name получение наименования поля в объекте модели
 func (f Field) Name() string {
 	return f.name
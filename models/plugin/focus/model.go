@@ -4,6 +4,7 @@ import (
 	"github.com/aeroideaservices/focus/services/formatting/strings"
 	"github.com/pkg/errors"
 	"reflect"
+	"time"
 )
 
 type Model struct {
@@ -24,6 +25,15 @@ type Focusable interface {
 
 var focusableType = reflect.TypeOf((*Focusable)(nil)).Elem()
 
+// SitemapElement расширение Focusable для моделей, элементы которых выводятся в карту сайта
+type SitemapElement interface {
+	Focusable
+	SitemapLoc() string         // SitemapLoc путь к элементу на сайте, элемент с пустым путем в карту не попадает
+	SitemapLastMod() *time.Time // SitemapLastMod время последнего изменения элемента, nil - не выводится
+}
+
+var sitemapElementType = reflect.TypeOf((*SitemapElement)(nil)).Elem()
+
 // modelCode получает код модели для использования в запросах
 func modelCode(t reflect.Type) string {
 	if ok := t.Implements(focusableType); !ok {
@@ -66,6 +76,11 @@ func (m Model) Type() reflect.Type {
 	return m.t
 }
 
+// InSitemap выводятся ли элементы модели в карту сайта
+func (m Model) InSitemap() bool {
+	return m.t.Implements(sitemapElementType)
+}
+
 // Model.NewElement возвращает указатель на элемент модели
 func (m Model) NewElement(fieldsMap map[string]any, filter func(field *Field) bool) (modelElement any, err error) {
 	elem := reflect.New(m.t)
@@ -831,3 +831,33 @@ func Test_modelCode(t *testing.T) {
 		})
 	}
 }
+
+type SitemapExample struct {
+	Id   uuid.UUID `focus:"title:ID;primaryKey"`
+	Code string    `focus:"title:Код"`
+}
+
+func (e SitemapExample) TableName() string {
+	return "sitemap_examples"
+}
+
+func (e SitemapExample) ModelTitle() string {
+	return "Примеры в карте сайта"
+}
+
+func (e SitemapExample) SitemapLoc() string {
+	return "/examples/" + e.Code
+}
+
+func (e SitemapExample) SitemapLastMod() *time.Time {
+	return nil
+}
+
+func TestModel_InSitemap(t *testing.T) {
+	if NewModel(reflect.TypeOf(Example{})).InSitemap() {
+		t.Error("InSitemap() = true for model without SitemapElement")
+	}
+	if !NewModel(reflect.TypeOf(SitemapExample{})).InSitemap() {
+		t.Error("InSitemap() = false for model with SitemapElement")
+	}
+}
@@ -1,11 +1,17 @@
 package focus
 
 import (
+	"fmt"
 	"golang.org/x/exp/maps"
 	"reflect"
 	"sort"
 )
 
+// SanitizePolicies зарегистрированные политики очистки html
+type SanitizePolicies interface {
+	Has(policyName string) bool
+}
+
 type ModelsRegistry struct {
 	registered   map[string]*Model
 	supportMedia bool
@@ -64,6 +70,21 @@ func (r *ModelsRegistry) Register(items ...any) {
 	}
 }
 
+// CheckSanitizePolicies проверка, что политики очистки html в тегах sanitize полей моделей зарегистрированы
+func (r ModelsRegistry) CheckSanitizePolicies(policies SanitizePolicies) error {
+	for _, model := range r.registered {
+		for _, field := range model.Fields {
+			if field.Sanitize != "" && !policies.Has(field.Sanitize) {
+				return fmt.Errorf(
+					"unknown sanitize policy %q in field %s of model %s", field.Sanitize, field.Code, model.Code,
+				)
+			}
+		}
+	}
+
+	return nil
+}
+
 // ModelsRegistry.NewModel сканирование модели
 func (r *ModelsRegistry) NewModel(t reflect.Type) {
 	if t.Kind() != reflect.Struct {
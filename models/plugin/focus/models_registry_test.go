@@ -475,3 +475,39 @@ func TestModelsRegistry_NewModel(t *testing.T) {
 		})
 	}
 }
+
+type policiesStub []string
+
+func (p policiesStub) Has(policyName string) bool {
+	return slices.Contains(p, policyName)
+}
+
+func TestModelsRegistry_CheckSanitizePolicies(t *testing.T) {
+	registered := map[string]*Model{
+		"articles": {Code: "articles", Fields: Fields{{Code: "title"}, {Code: "text", Sanitize: "wysiwyg"}}},
+	}
+	tests := []struct {
+		name     string
+		policies policiesStub
+		wantErr  bool
+	}{
+		{
+			name:     "registered policy",
+			policies: policiesStub{"wysiwyg", "embed"},
+			wantErr:  false,
+		},
+		{
+			name:     "unknown policy",
+			policies: policiesStub{"embed"},
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := ModelsRegistry{registered: registered}
+			if err := r.CheckSanitizePolicies(tt.policies); (err != nil) != tt.wantErr {
+				t.Errorf("CheckSanitizePolicies() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -14,6 +14,7 @@ import (
 
 	"github.com/aeroideaservices/focus/models/plugin/form"
 	focusStrings "github.com/aeroideaservices/focus/services/formatting/strings"
+	"github.com/aeroideaservices/focus/services/sanitizer"
 )
 
 type FieldFiller struct {
@@ -31,6 +32,9 @@ var (
 		{Code: "code", Fill: codeFill, Default: codeDefault},
 		{Code: "time", Fill: timeFill, Default: timeDefault},
 		{Code: "media", Fill: mediaFill},
+		{Code: "mediaAlt", Fill: mediaAltFill},
+		{Code: "mediaAspectRatio", Fill: mediaAspectRatioFill},
+		{Code: "sanitize", Fill: sanitizeFill},
 		{Code: "view", Fill: viewFill, Default: viewDefault},
 		{Code: "viewExtra", Fill: viewExtraFill},
 		{Code: "multiple", Fill: multipleFill, Default: multipleDefault},
@@ -79,6 +83,65 @@ func mediaFill(field *Field, value string) {
 	field.IsMedia, _ = strconv.ParseBool(value)
 }
 
+func mediaAltFill(field *Field, value string) {
+	if !field.IsMedia {
+		panic("mediaAlt tag can only be applied to a media field")
+	}
+	if field.MediaProperties == nil {
+		field.MediaProperties = &MediaProperties{}
+	}
+	if value == "" {
+		field.RequireAlt = true
+		return
+	}
+	field.RequireAlt, _ = strconv.ParseBool(value)
+}
+
+// mediaAspectRatioFill соотношение сторон задается в виде "16:9", "16/9" или "1.78"
+func mediaAspectRatioFill(field *Field, value string) {
+	if !field.IsMedia {
+		panic("mediaAspectRatio tag can only be applied to a media field")
+	}
+
+	var ratio float64
+	var err error
+	if width, height, found := strings.Cut(strings.ReplaceAll(value, "/", ":"), ":"); found {
+		var w, h float64
+		w, err = strconv.ParseFloat(width, 64)
+		if err == nil {
+			h, err = strconv.ParseFloat(height, 64)
+		}
+		if err == nil && h != 0 {
+			ratio = w / h
+		}
+	} else {
+		ratio, err = strconv.ParseFloat(value, 64)
+	}
+	if err != nil || ratio <= 0 {
+		panic("the tag mediaAspectRatio value should be a positive ratio like 16:9 or 1.78")
+	}
+
+	if field.MediaProperties == nil {
+		field.MediaProperties = &MediaProperties{}
+	}
+	field.AspectRatio = ratio
+}
+
+// sanitizeFill политика очистки html, по умолчанию - политика визуального редактора
+func sanitizeFill(field *Field, value string) {
+	t := field.t
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.String {
+		panic("sanitize tag can only be applied to a string field")
+	}
+	if value == "" {
+		value = sanitizer.PolicyWysiwyg
+	}
+	field.Sanitize = value
+}
+
 func viewFill(field *Field, value string) {
 	if !slices.Contains(form.FieldTypes, form.FieldType(value)) {
 		log.Panicf("'view' tag must be one of %s, got %s", form.FieldTypes, value)
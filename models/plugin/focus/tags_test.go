@@ -554,6 +554,197 @@ func Test_mediaFill(t *testing.T) {
 	}
 }
 
+func Test_mediaAltFill(t *testing.T) {
+	type args struct {
+		field *Field
+		value string
+	}
+	tests := []struct {
+		name      string
+		args      args
+		wantField *Field
+		wantPanic bool
+	}{
+		{
+			name: "empty",
+			args: args{
+				field: &Field{IsMedia: true},
+				value: "",
+			},
+			wantField: &Field{IsMedia: true, MediaProperties: &MediaProperties{RequireAlt: true}},
+		},
+		{
+			name: "false",
+			args: args{
+				field: &Field{IsMedia: true},
+				value: "false",
+			},
+			wantField: &Field{IsMedia: true, MediaProperties: &MediaProperties{RequireAlt: false}},
+		},
+		{
+			name: "keeps aspect ratio",
+			args: args{
+				field: &Field{IsMedia: true, MediaProperties: &MediaProperties{AspectRatio: 1}},
+				value: "true",
+			},
+			wantField: &Field{IsMedia: true, MediaProperties: &MediaProperties{RequireAlt: true, AspectRatio: 1}},
+		},
+		{
+			name: "not a media",
+			args: args{
+				field: &Field{},
+				value: "",
+			},
+			wantField: &Field{},
+			wantPanic: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if r := recover(); (r != nil) != tt.wantPanic {
+					t.Errorf("mediaAltFill() panic = %v, wantPanic %v", r, tt.wantPanic)
+				}
+			}()
+			mediaAltFill(tt.args.field, tt.args.value)
+			if !reflect.DeepEqual(tt.args.field, tt.wantField) {
+				t.Errorf("mediaAltFill() gotField = %v, wantField %v", tt.args.field, tt.wantField)
+			}
+		})
+	}
+}
+
+func Test_mediaAspectRatioFill(t *testing.T) {
+	type args struct {
+		field *Field
+		value string
+	}
+	tests := []struct {
+		name      string
+		args      args
+		wantField *Field
+		wantPanic bool
+	}{
+		{
+			name: "colon",
+			args: args{
+				field: &Field{IsMedia: true},
+				value: "4:2",
+			},
+			wantField: &Field{IsMedia: true, MediaProperties: &MediaProperties{AspectRatio: 2}},
+		},
+		{
+			name: "slash",
+			args: args{
+				field: &Field{IsMedia: true},
+				value: "3/4",
+			},
+			wantField: &Field{IsMedia: true, MediaProperties: &MediaProperties{AspectRatio: 0.75}},
+		},
+		{
+			name: "float",
+			args: args{
+				field: &Field{IsMedia: true},
+				value: "1.5",
+			},
+			wantField: &Field{IsMedia: true, MediaProperties: &MediaProperties{AspectRatio: 1.5}},
+		},
+		{
+			name: "zero height",
+			args: args{
+				field: &Field{IsMedia: true},
+				value: "16:0",
+			},
+			wantField: &Field{IsMedia: true},
+			wantPanic: true,
+		},
+		{
+			name: "invalid",
+			args: args{
+				field: &Field{IsMedia: true},
+				value: "wide",
+			},
+			wantField: &Field{IsMedia: true},
+			wantPanic: true,
+		},
+		{
+			name: "not a media",
+			args: args{
+				field: &Field{},
+				value: "16:9",
+			},
+			wantField: &Field{},
+			wantPanic: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if r := recover(); (r != nil) != tt.wantPanic {
+					t.Errorf("mediaAspectRatioFill() panic = %v, wantPanic %v", r, tt.wantPanic)
+				}
+			}()
+			mediaAspectRatioFill(tt.args.field, tt.args.value)
+			if !reflect.DeepEqual(tt.args.field, tt.wantField) {
+				t.Errorf("mediaAspectRatioFill() gotField = %v, wantField %v", tt.args.field, tt.wantField)
+			}
+		})
+	}
+}
+
+func Test_sanitizeFill(t *testing.T) {
+	stringType, stringPtrType := reflect.TypeOf(""), reflect.TypeOf(new(string))
+	type args struct {
+		field *Field
+		value string
+	}
+	tests := []struct {
+		name      string
+		args      args
+		wantField *Field
+		wantPanic bool
+	}{
+		{
+			name: "empty",
+			args: args{
+				field: &Field{t: stringType},
+				value: "",
+			},
+			wantField: &Field{t: stringType, Sanitize: "wysiwyg"},
+		},
+		{
+			name: "policy",
+			args: args{
+				field: &Field{t: stringPtrType},
+				value: "embed",
+			},
+			wantField: &Field{t: stringPtrType, Sanitize: "embed"},
+		},
+		{
+			name: "not a string",
+			args: args{
+				field: &Field{t: reflect.TypeOf(0)},
+				value: "",
+			},
+			wantField: &Field{t: reflect.TypeOf(0)},
+			wantPanic: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if r := recover(); (r != nil) != tt.wantPanic {
+					t.Errorf("sanitizeFill() panic = %v, wantPanic %v", r, tt.wantPanic)
+				}
+			}()
+			sanitizeFill(tt.args.field, tt.args.value)
+			if !reflect.DeepEqual(tt.args.field, tt.wantField) {
+				t.Errorf("sanitizeFill() gotField = %v, wantField %v", tt.args.field, tt.wantField)
+			}
+		})
+	}
+}
+
 func Test_multipleDefault(t *testing.T) {
 	type args struct {
 		field *Field
@@ -6,7 +6,9 @@ require (
 	github.com/aeroideaservices/focus/services/callbacks v1.0.0
 	github.com/aeroideaservices/focus/services/errors v1.0.0
 	github.com/aeroideaservices/focus/services/formatting/strings v1.0.0
+	github.com/aeroideaservices/focus/services/sanitizer v1.0.0
 	github.com/aeroideaservices/focus/services/validation v1.0.0
+	github.com/aeroideaservices/focus/sitemap/plugin v1.0.0
 	github.com/google/uuid v1.3.0
 	github.com/json-iterator/go v1.1.12
 	github.com/pkg/errors v0.9.1
@@ -24,7 +26,8 @@ require (
 	github.com/modern-go/reflect2 v1.0.2 // indirect
 	go.uber.org/atomic v1.9.0 // indirect
 	go.uber.org/multierr v1.8.0 // indirect
-	golang.org/x/crypto v0.7.0 // indirect
-	golang.org/x/sys v0.6.0 // indirect
-	golang.org/x/text v0.8.0 // indirect
+	golang.org/x/crypto v0.23.0 // indirect
+	golang.org/x/net v0.25.0 // indirect
+	golang.org/x/sys v0.20.0 // indirect
+	golang.org/x/text v0.15.0 // indirect
 )
@@ -100,13 +100,17 @@ func (h ElementsHandler) Create(c *gin.Context) {
 		return
 	}
 
-	id, err := h.elements.Create(c, action)
+	id, report, err := h.elements.Create(c, action)
 	if err != nil {
 		_ = c.Error(err)
 		return
 	}
 
-	c.JSON(http.StatusCreated, map[string]any{"id": id})
+	response := map[string]any{"id": id}
+	if !report.Empty() {
+		response["sanitized"] = report
+	}
+	c.JSON(http.StatusCreated, response)
 }
 
 // Update обновление элемента модели
@@ -133,12 +137,17 @@ func (h ElementsHandler) Update(c *gin.Context) {
 		return
 	}
 
-	err = h.elements.Update(c, action)
+	report, err := h.elements.Update(c, action)
 	if err != nil {
 		_ = c.Error(err)
 		return
 	}
 
+	// если из значений полей был удален html, редактору возвращается отчет об очистке
+	if !report.Empty() {
+		c.JSON(http.StatusOK, map[string]any{"sanitized": report})
+		return
+	}
 	c.JSON(http.StatusNoContent, nil)
 }
 
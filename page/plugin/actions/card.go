@@ -2,9 +2,12 @@ package actions
 
 import (
 	"context"
+	"encoding/json"
 	"github.com/aeroideaservices/focus/media/plugin/actions"
 	"github.com/aeroideaservices/focus/page/plugin/entity"
+	"github.com/aeroideaservices/focus/page/plugin/services/cardtypes"
 	"github.com/aeroideaservices/focus/services/errors"
+	"github.com/aeroideaservices/focus/services/sanitizer"
 	"github.com/google/uuid"
 	actions2 "gitlab.aeroidea.ru/internal-projects/focus/forms/plugin/actions"
 	"go.uber.org/zap"
@@ -16,6 +19,9 @@ type CardUseCase struct {
 	tagRepository     TagRepository
 	mediaProvider     actions.MediaProvider
 	formActions       actions2.Forms
+	cardTypes         *cardtypes.Registry
+	sanitizer         Sanitizer
+	htmlPolicy        string // htmlPolicy политика очистки html карточек
 	copierService     CopierInterface
 	logger            *zap.SugaredLogger
 }
@@ -23,7 +29,9 @@ type CardUseCase struct {
 func NewCardUseCase(
 	cardRepository CardRepository, galleryRepository GalleryRepository, tagRepository TagRepository,
 	mediaProvider actions.MediaProvider,
-	formActions actions2.Forms, copierService CopierInterface, logger *zap.SugaredLogger,
+	formActions actions2.Forms, cardTypes *cardtypes.Registry, sanitizer Sanitizer, htmlPolicy string,
+	copierService CopierInterface,
+	logger *zap.SugaredLogger,
 ) *CardUseCase {
 	return &CardUseCase{
 		cardRepository:    cardRepository,
@@ -31,6 +39,9 @@ func NewCardUseCase(
 		tagRepository:     tagRepository,
 		mediaProvider:     mediaProvider,
 		formActions:       formActions,
+		cardTypes:         cardTypes,
+		sanitizer:         sanitizer,
+		htmlPolicy:        htmlPolicy,
 		copierService:     copierService,
 		logger:            logger,
 	}
@@ -56,7 +67,12 @@ func (uc CardUseCase) GetListWithSearch(ctx context.Context, searchValue string,
 func (uc CardUseCase) Create(ctx context.Context, dto *CreateCardRequest) (
 	*CreateCardResponse, error,
 ) {
-	cardEntity, err := uc.GetCardFromDto(*dto)
+	report, err := uc.sanitizeHtml(dto.HtmlCard)
+	if err != nil {
+		return nil, err
+	}
+
+	cardEntity, err := uc.GetCardFromDto(ctx, *dto)
 	if err != nil {
 		return nil, err
 	}
@@ -67,7 +83,8 @@ func (uc CardUseCase) Create(ctx context.Context, dto *CreateCardRequest) (
 		return nil, err
 	}
 	result := &CreateCardResponse{
-		ID: *cardId,
+		ID:        *cardId,
+		Sanitized: report,
 	}
 	uc.logger.Debug("Created card")
 	return result, nil
@@ -87,24 +104,29 @@ func (uc CardUseCase) GetById(ctx context.Context, dto GetCardRequest) (*CardDto
 	return cardDto, nil
 }
 
-func (uc CardUseCase) Update(ctx context.Context, dto *UpdateCardRequest) error {
+func (uc CardUseCase) Update(ctx context.Context, dto *UpdateCardRequest) (*UpdateCardResponse, error) {
 	uc.logger.Debug("Updating card")
 
 	_, err := uc.cardRepository.GetByIdWithoutAssociate(ctx, dto.ID)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	for _, galleryId := range dto.GalleryIds {
 		_, err = uc.galleryRepository.GetByIdWithoutAssociate(ctx, galleryId)
 		if err != nil {
-			return err
+			return nil, err
 		}
 	}
 
-	card, err := uc.GetCardFromUpdateDto(dto)
+	report, err := uc.sanitizeHtml(dto.HtmlCard)
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	card, err := uc.GetCardFromUpdateDto(ctx, dto)
+	if err != nil {
+		return nil, err
 	}
 
 	galleriesCard := uc.GetGalleriesCardsFromIds(ctx, dto.ID, dto.GalleryIds)
@@ -112,14 +134,14 @@ func (uc CardUseCase) Update(ctx context.Context, dto *UpdateCardRequest) error
 	err = uc.cardRepository.Update(ctx, card, galleriesCard)
 
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	if dto.IsPublished != nil {
 		uc.logger.Debug("Changing publish")
 		err = uc.cardRepository.UpdatePublish(ctx, dto.ID, dto.IsPublished)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		uc.logger.Debug("Changed publish")
 	}
@@ -128,16 +150,16 @@ func (uc CardUseCase) Update(ctx context.Context, dto *UpdateCardRequest) error
 		uc.logger.Debug("Changing inverted")
 		err = uc.cardRepository.UpdateInverted(ctx, dto.ID, dto.RegularCard.Inverted)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		uc.logger.Debug("Changed inverted")
 	}
 
 	if err != nil {
-		return err
+		return nil, err
 	}
 	uc.logger.Debug("Updated card")
-	return nil
+	return &UpdateCardResponse{Sanitized: report}, nil
 }
 
 func (uc CardUseCase) Delete(ctx context.Context, cardId uuid.UUID) error {
@@ -325,6 +347,11 @@ func (uc CardUseCase) GetDtosFromCard(cards []entity.Card) (cardDtos []CardDtoWi
 		cardDto := &CardDtoWithoutPosition{}
 
 		switch card.Type {
+		default:
+			err = uc.copierService.Copy(cardDto, card)
+			if err != nil {
+				return nil, err
+			}
 		case "html":
 			if card.HtmlCardId == nil {
 				err = uc.copierService.Copy(cardDto, card)
@@ -532,6 +559,11 @@ func (uc CardUseCase) GetDtosFromCard(cards []entity.Card) (cardDtos []CardDtoWi
 func (uc CardUseCase) GetDtoFromCard(card *entity.Card) (cardDto *CardDtoWithoutPosition, err error) {
 	cardDto = &CardDtoWithoutPosition{}
 	switch card.Type {
+	default:
+		err = uc.copierService.Copy(cardDto, card)
+		if err != nil {
+			return nil, err
+		}
 	case "html":
 		if card.HtmlCardId == nil {
 			err = uc.copierService.Copy(cardDto, card)
@@ -733,6 +765,12 @@ func (uc CardUseCase) GetDtosFromGalleriesCard(gallery []entity.GalleriesCards)
 		cardDto := &CardDto{}
 
 		switch card.Card.Type {
+		default:
+			err = uc.copierService.Copy(cardDto, card.Card)
+			if err != nil {
+				return nil, err
+			}
+			cardDto.Position = card.Position
 		case "html":
 			if card.Card.HtmlCardId == nil {
 				err = uc.copierService.Copy(cardDto, card.Card)
@@ -931,12 +969,22 @@ func (uc CardUseCase) GetDtosFromGalleriesCard(gallery []entity.GalleriesCards)
 	return cardDtos, nil
 }
 
-func (uc CardUseCase) GetCardFromUpdateDto(cardRequest *UpdateCardRequest) (
+func (uc CardUseCase) GetCardFromUpdateDto(ctx context.Context, cardRequest *UpdateCardRequest) (
 	card *entity.Card, err error,
 ) {
 	card = &entity.Card{}
 
 	switch cardRequest.Type {
+	default:
+		err = uc.copierService.Copy(card, cardRequest)
+		if err != nil {
+			return nil, err
+		}
+		// идентификатор данных карточки устанавливает репозиторий
+		card.CustomCard, err = uc.decodeCustomCard(ctx, cardRequest.Type, cardRequest.CustomCardData, uuid.Nil)
+		if err != nil {
+			return nil, err
+		}
 	case "html":
 		err = uc.copierService.Copy(card, cardRequest)
 		if err != nil {
@@ -1041,12 +1089,24 @@ func (uc CardUseCase) GetGalleriesCardsFromIds(
 	return galleriesCards
 }
 
-func (uc CardUseCase) GetCardFromDto(cardRequest CreateCardRequest) (
+func (uc CardUseCase) GetCardFromDto(ctx context.Context, cardRequest CreateCardRequest) (
 	card *entity.Card, err error,
 ) {
 	card = &entity.Card{}
 	newCardId := uuid.New()
 	switch cardRequest.Type {
+	default:
+		customCardId := uuid.New()
+		err = uc.copierService.Copy(card, cardRequest)
+		if err != nil {
+			return nil, err
+		}
+		card.CustomCard, err = uc.decodeCustomCard(ctx, cardRequest.Type, cardRequest.CustomCardData, customCardId)
+		if err != nil {
+			return nil, err
+		}
+		card.ID = newCardId
+		card.CustomCardId = &customCardId
 	case "html":
 		htmlCardId := uuid.New()
 		err = uc.copierService.Copy(card, cardRequest)
@@ -1153,7 +1213,9 @@ func (uc CardUseCase) GetCardFromDto(cardRequest CreateCardRequest) (
 	return card, nil
 }
 
-func (uc CardUseCase) GetCardFromDtos(cardsRequest []CreateCardRequest, galleryId uuid.UUID) (
+func (uc CardUseCase) GetCardFromDtos(
+	ctx context.Context, cardsRequest []CreateCardRequest, galleryId uuid.UUID,
+) (
 	cards []entity.GalleriesCards, err error,
 ) {
 	for _, card := range cardsRequest {
@@ -1161,9 +1223,30 @@ func (uc CardUseCase) GetCardFromDtos(cardsRequest []CreateCardRequest, galleryI
 		galleriesCard := &entity.GalleriesCards{}
 
 		switch card.Type {
+		default:
+			customCardId := uuid.New()
+			cardEntity := &entity.Card{}
+			err = uc.copierService.Copy(cardEntity, card)
+			if err != nil {
+				return nil, err
+			}
+			cardEntity.CustomCard, err = uc.decodeCustomCard(ctx, card.Type, card.CustomCardData, customCardId)
+			if err != nil {
+				return nil, err
+			}
+			galleriesCard.GalleryID = &galleryId
+			galleriesCard.CardID = &newCardId
+			galleriesCard.Card = cardEntity
+			galleriesCard.Card.ID = newCardId
+			galleriesCard.Card.CustomCardId = &customCardId
 		case "html":
 			htmlCardId := uuid.New()
 
+			// при создании карточек вместе с галереей отчет об очистке не возвращается
+			_, err = uc.sanitizeHtml(card.HtmlCard)
+			if err != nil {
+				return nil, err
+			}
 			err = uc.copierService.Copy(galleriesCard, card)
 			if err != nil {
 				return nil, err
@@ -1309,6 +1392,68 @@ func (uc CardUseCase) GetCardFromDtos(cardsRequest []CreateCardRequest, galleryI
 	return cards, nil
 }
 
+// GetTypes типы карточек с описанием полей для формы
+func (uc CardUseCase) GetTypes() []CardTypeDto {
+	types := uc.cardTypes.List()
+	list := make([]CardTypeDto, len(types))
+	for i, cardType := range types {
+		list[i] = CardTypeDto{
+			Code:    cardType.Code,
+			Title:   cardType.Title,
+			Builtin: cardType.Builtin(),
+			Fields:  cardType.Fields(),
+		}
+	}
+
+	return list
+}
+
+// sanitizeHtml очистка html карточки по политике плагина.
+// Отчет возвращается, только если из html что-то было удалено.
+func (uc CardUseCase) sanitizeHtml(htmlCard *CreateHtmlCardRequest) (*sanitizer.Report, error) {
+	if htmlCard == nil {
+		return nil, nil
+	}
+
+	return uc.sanitize(&htmlCard.Html)
+}
+
+// sanitize очистка html по политике html карточек, отчет возвращается, только если из html что-то было удалено
+func (uc CardUseCase) sanitize(html *string) (*sanitizer.Report, error) {
+	if *html == "" {
+		return nil, nil
+	}
+
+	sanitized, report, err := uc.sanitizer.Sanitize(uc.htmlPolicy, *html)
+	if err != nil {
+		return nil, errors.NoType.Wrap(err, "error sanitizing html card")
+	}
+	*html = sanitized
+	if report.Empty() {
+		return nil, nil
+	}
+
+	return &report, nil
+}
+
+// decodeCustomCard разбор данных карточки зарегистрированного приложением типа
+func (uc CardUseCase) decodeCustomCard(
+	ctx context.Context, cardType string, data json.RawMessage, id uuid.UUID,
+) (any, error) {
+	customType := uc.cardTypes.Custom(cardType)
+	if customType == nil {
+		return nil, errors.BadRequest.Newf("unknown card type %s", cardType)
+	}
+
+	customCard, err := customType.Decode(ctx, data)
+	if err != nil {
+		return nil, errors.BadRequest.Wrapf(err, "error decoding %s card", cardType)
+	}
+	cardtypes.SetID(customCard, id)
+
+	return customCard, nil
+}
+
 func (uc CardUseCase) getTagsFromDtos(dtos []TagDtoRequest) ([]entity.Tag, error) {
 	var tags []entity.Tag
 	for _, dto := range dtos {
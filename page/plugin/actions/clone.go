@@ -0,0 +1,294 @@
+package actions
+
+import (
+	"context"
+	"time"
+
+	"github.com/aeroideaservices/focus/page/plugin/entity"
+	"github.com/aeroideaservices/focus/page/plugin/services/cardtypes"
+	"github.com/aeroideaservices/focus/services/errors"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type CloneRequest struct {
+	ID   uuid.UUID `json:"-"`
+	Deep bool      `json:"deep"`                                   // Deep копирование вложенных галерей и карточек, иначе копия ссылается на существующие
+	Name string    `json:"name" validate:"omitempty,min=1,max=50"` // Name название копии, по умолчанию как у исходной сущности
+	Code string    `json:"code" validate:"omitempty,sluggable"`    // Code код копии, по умолчанию генерируется из исходного
+}
+
+type CloneResponse struct {
+	ID uuid.UUID `json:"id"`
+}
+
+// CloneUseCase копирование страниц, галерей и карточек.
+// Копия создается неопубликованной, вложенные копии сохраняют статус публикации исходных сущностей
+// без запланированных публикации и снятия с публикации.
+// Переводы копируются вместе с сущностями в той же транзакции.
+type CloneUseCase struct {
+	pageRepository        PageRepository
+	galleryRepository     GalleryRepository
+	cardRepository        CardRepository
+	translationRepository TranslationRepository
+	transactor            Transactor
+	logger                *zap.SugaredLogger
+}
+
+func NewCloneUseCase(
+	pageRepository PageRepository, galleryRepository GalleryRepository, cardRepository CardRepository,
+	translationRepository TranslationRepository, transactor Transactor, logger *zap.SugaredLogger,
+) *CloneUseCase {
+	return &CloneUseCase{
+		pageRepository:        pageRepository,
+		galleryRepository:     galleryRepository,
+		cardRepository:        cardRepository,
+		translationRepository: translationRepository,
+		transactor:            transactor,
+		logger:                logger,
+	}
+}
+
+func (uc CloneUseCase) ClonePage(ctx context.Context, dto CloneRequest) (*CloneResponse, error) {
+	uc.logger.Debug("Cloning page")
+	page, err := uc.pageRepository.GetById(ctx, dto.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if dto.Code != "" {
+		_, err = uc.pageRepository.GetByCode(ctx, dto.Code)
+		if err == nil {
+			return nil, errors.Conflict.Newf("page with code %s already exists", dto.Code)
+		}
+		if errors.GetType(err) != errors.NotFound {
+			return nil, err
+		}
+	}
+
+	clone := clonePage(*page, dto.Deep)
+	clone.Code = cloneCode(page.Code, clone.ID, dto.Code)
+	clone.Path = entity.PagePath(page.ParentPath(), clone.Code)
+	if dto.Name != "" {
+		clone.Name = dto.Name
+	}
+
+	ids := make(map[uuid.UUID]uuid.UUID)
+	pageCloneIds(ids, *page, clone, dto.Deep)
+	err = uc.transactor.Transaction(ctx, func(ctx context.Context) error {
+		if err := uc.pageRepository.Clone(ctx, &clone, dto.Deep); err != nil {
+			return err
+		}
+		return uc.translationRepository.Copy(ctx, ids)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	uc.logger.Debug("Cloned page")
+	return &CloneResponse{ID: clone.ID}, nil
+}
+
+func (uc CloneUseCase) CloneGallery(ctx context.Context, dto CloneRequest) (*CloneResponse, error) {
+	uc.logger.Debug("Cloning gallery")
+	gallery, err := uc.galleryRepository.GetById(ctx, dto.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if dto.Code != "" {
+		_, err = uc.galleryRepository.GetByCode(ctx, dto.Code)
+		if err == nil {
+			return nil, errors.Conflict.Newf("gallery with code %s already exists", dto.Code)
+		}
+		if errors.GetType(err) != errors.NotFound {
+			return nil, err
+		}
+	}
+
+	clone := cloneGallery(*gallery, dto.Deep)
+	clone.Code = cloneCode(gallery.Code, clone.ID, dto.Code)
+	clone.IsPublished, clone.PublishAt, clone.UnpublishAt = false, nil, nil
+	if dto.Name != "" {
+		clone.Name = dto.Name
+	}
+
+	ids := make(map[uuid.UUID]uuid.UUID)
+	galleryCloneIds(ids, *gallery, clone, dto.Deep)
+	err = uc.transactor.Transaction(ctx, func(ctx context.Context) error {
+		if err := uc.galleryRepository.Clone(ctx, &clone, dto.Deep); err != nil {
+			return err
+		}
+		return uc.translationRepository.Copy(ctx, ids)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	uc.logger.Debug("Cloned gallery")
+	return &CloneResponse{ID: clone.ID}, nil
+}
+
+// CloneCard копирование карточки вместе с ее типизированной частью и тегами.
+// Медиа, спикер и форма у копии общие с исходной карточкой.
+func (uc CloneUseCase) CloneCard(ctx context.Context, dto CloneRequest) (*CloneResponse, error) {
+	uc.logger.Debug("Cloning card")
+	card, err := uc.cardRepository.GetById(ctx, dto.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	clone := cloneCard(*card)
+	clone.Code = cloneCode(card.Code, clone.ID, dto.Code)
+	clone.IsPublished, clone.PublishAt, clone.UnpublishAt = false, nil, nil
+	if dto.Name != "" {
+		clone.Name = dto.Name
+	}
+
+	err = uc.transactor.Transaction(ctx, func(ctx context.Context) error {
+		if err := uc.cardRepository.Clone(ctx, &clone); err != nil {
+			return err
+		}
+		return uc.translationRepository.Copy(ctx, map[uuid.UUID]uuid.UUID{card.ID: clone.ID})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	uc.logger.Debug("Cloned card")
+	return &CloneResponse{ID: clone.ID}, nil
+}
+
+// cloneCode код копии: заданный явно или исходный с суффиксом из идентификатора копии
+func cloneCode(code string, id uuid.UUID, override string) string {
+	if override != "" {
+		return override
+	}
+	if code == "" {
+		return ""
+	}
+
+	return code + "-" + id.String()[:8]
+}
+
+func clonePage(page entity.Page, deep bool) entity.Page {
+	clone := page
+	clone.ID = uuid.New()
+	clone.IsPublished, clone.PublishAt, clone.UnpublishAt = false, nil, nil
+	clone.UpdatedAt = time.Time{}
+	clone.PagesGalleries = make([]entity.PagesGalleries, len(page.PagesGalleries))
+
+	for i, pagesGallery := range page.PagesGalleries {
+		gallery := pagesGallery.Gallery
+		if deep {
+			gallery = cloneGallery(pagesGallery.Gallery, true)
+			gallery.Code = cloneCode(pagesGallery.Gallery.Code, gallery.ID, "")
+			gallery.PublishAt, gallery.UnpublishAt = nil, nil
+		}
+		clone.PagesGalleries[i] = entity.PagesGalleries{
+			PagesID:   &clone.ID,
+			GalleryID: &gallery.ID,
+			Gallery:   gallery,
+			Position:  pagesGallery.Position,
+		}
+	}
+
+	return clone
+}
+
+func cloneGallery(gallery entity.Gallery, deep bool) entity.Gallery {
+	clone := gallery
+	clone.ID = uuid.New()
+	clone.GalleriesCards = make([]entity.GalleriesCards, 0, len(gallery.GalleriesCards))
+
+	for _, galleriesCard := range gallery.GalleriesCards {
+		if galleriesCard.Card == nil {
+			continue
+		}
+		card := galleriesCard.Card
+		if deep {
+			cardClone := cloneCard(*galleriesCard.Card)
+			cardClone.Code = cloneCode(card.Code, cardClone.ID, "")
+			cardClone.PublishAt, cardClone.UnpublishAt = nil, nil
+			card = &cardClone
+		}
+		clone.GalleriesCards = append(clone.GalleriesCards, entity.GalleriesCards{
+			GalleryID: &clone.ID,
+			CardID:    &card.ID,
+			Card:      card,
+			Position:  galleriesCard.Position,
+		})
+	}
+
+	return clone
+}
+
+// pageCloneIds идентификаторы копий страницы и, при глубоком копировании, вложенных галерей и карточек
+func pageCloneIds(ids map[uuid.UUID]uuid.UUID, page entity.Page, clone entity.Page, deep bool) {
+	ids[page.ID] = clone.ID
+	if !deep {
+		return
+	}
+	for i, pagesGallery := range page.PagesGalleries {
+		galleryCloneIds(ids, pagesGallery.Gallery, clone.PagesGalleries[i].Gallery, true)
+	}
+}
+
+// galleryCloneIds идентификаторы копий галереи и, при глубоком копировании, ее карточек.
+// Карточки без данных не копируются, поэтому копии сопоставляются по порядку среди карточек с данными
+func galleryCloneIds(ids map[uuid.UUID]uuid.UUID, gallery entity.Gallery, clone entity.Gallery, deep bool) {
+	ids[gallery.ID] = clone.ID
+	if !deep {
+		return
+	}
+	i := 0
+	for _, galleriesCard := range gallery.GalleriesCards {
+		if galleriesCard.Card == nil {
+			continue
+		}
+		ids[galleriesCard.Card.ID] = clone.GalleriesCards[i].Card.ID
+		i++
+	}
+}
+
+// cloneCard копия карточки с новыми идентификаторами карточки, ее типизированной части и связей с тегами
+func cloneCard(card entity.Card) entity.Card {
+	clone := card
+	clone.ID = uuid.New()
+
+	switch {
+	case card.RegularCard != nil:
+		regular := *card.RegularCard
+		regular.ID = uuid.New()
+		regular.RegularCardsTags = make([]entity.RegularCardsTags, len(card.RegularCard.RegularCardsTags))
+		for i, tag := range card.RegularCard.RegularCardsTags {
+			regular.RegularCardsTags[i] = entity.RegularCardsTags{RegularCardID: regular.ID, TagID: tag.TagID}
+		}
+		clone.RegularCard, clone.RegularCardId = &regular, &regular.ID
+	case card.VideoCard != nil:
+		video := *card.VideoCard
+		video.ID = uuid.New()
+		clone.VideoCard, clone.VideoCardId = &video, &video.ID
+	case card.HtmlCard != nil:
+		html := *card.HtmlCard
+		html.ID = uuid.New()
+		clone.HtmlCard, clone.HtmlCardId = &html, &html.ID
+	case card.PhotoCard != nil:
+		photo := *card.PhotoCard
+		photo.ID = uuid.New()
+		clone.PhotoCard, clone.PhotoCardId = &photo, &photo.ID
+	case card.FormCard != nil:
+		form := *card.FormCard
+		form.ID = uuid.New()
+		form.FormCardsTags = make([]entity.FormCardsTags, len(card.FormCard.FormCardsTags))
+		for i, tag := range card.FormCard.FormCardsTags {
+			form.FormCardsTags[i] = entity.FormCardsTags{FormCardID: form.ID, TagID: tag.TagID}
+		}
+		clone.FormCard, clone.FormCardId = &form, &form.ID
+	case card.CustomCard != nil:
+		customCardId := uuid.New()
+		clone.CustomCard, clone.CustomCardId = cardtypes.Copy(card.CustomCard, customCardId), &customCardId
+	}
+
+	return clone
+}
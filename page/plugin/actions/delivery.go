@@ -0,0 +1,725 @@
+package actions
+
+import (
+	"context"
+	"encoding/json"
+
+	mediaActions "github.com/aeroideaservices/focus/media/plugin/actions"
+	mediaEntity "github.com/aeroideaservices/focus/media/plugin/entity"
+	"github.com/aeroideaservices/focus/page/plugin/entity"
+	"github.com/aeroideaservices/focus/services/errors"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// GetDeliveryPageRequest получение страницы по коду или по полному пути
+type GetDeliveryPageRequest struct {
+	Code         string `json:"code" validate:"required_without=Path,omitempty,sluggable"`
+	Path         string `json:"path" validate:"required_without=Code"` // Path полный путь страницы, например about/team/leadership
+	Locale       string `json:"locale"`                                // Locale язык содержимого, по умолчанию основной язык
+	PreviewToken string `json:"-"`                                     // PreviewToken токен предпросмотра неопубликованного содержимого
+}
+
+// DeliveryPageDto опубликованная страница для публичного API
+type DeliveryPageDto struct {
+	ID             uuid.UUID               `json:"id"`
+	Code           string                  `json:"code"`
+	Path           string                  `json:"path"`
+	Locale         string                  `json:"locale"`
+	Name           string                  `json:"name"`
+	Title          string                  `json:"title"`
+	Description    string                  `json:"description"`
+	TitleSeo       string                  `json:"titleSeo"`
+	DescriptionSeo string                  `json:"descriptionSeo"`
+	Keywords       string                  `json:"keywords"`
+	OgType         string                  `json:"ogType"`
+	Breadcrumbs    []DeliveryBreadcrumbDto `json:"breadcrumbs"` // Breadcrumbs предки страницы от корневой до родительской
+	Galleries      []DeliveryGalleryDto    `json:"galleries"`
+}
+
+// DeliveryBreadcrumbDto страница-предок в навигационной цепочке
+type DeliveryBreadcrumbDto struct {
+	Code  string `json:"code"`
+	Path  string `json:"path"`
+	Name  string `json:"name"`
+	Title string `json:"title"`
+}
+
+type DeliveryGalleryDto struct {
+	ID     uuid.UUID         `json:"id"`
+	Code   string            `json:"code"`
+	Name   string            `json:"name"`
+	Hidden bool              `json:"hiddenMenu"`
+	Cards  []DeliveryCardDto `json:"cards"`
+}
+
+type DeliveryCardDto struct {
+	ID          uuid.UUID               `json:"id"`
+	Code        string                  `json:"code"`
+	Name        string                  `json:"name"`
+	Type        string                  `json:"type"`
+	Title       string                  `json:"title"`
+	Description string                  `json:"description"`
+	OgType      string                  `json:"ogType"`
+	RegularCard *DeliveryRegularCardDto `json:"regularCard,omitempty"`
+	VideoCard   *DeliveryVideoCardDto   `json:"videoCard,omitempty"`
+	HtmlCard    *HtmlCardDto            `json:"htmlCard,omitempty"`
+	PhotoCard   *DeliveryPhotoCardDto   `json:"photoCard,omitempty"`
+	FormCard    *DeliveryFormCardDto    `json:"formCard,omitempty"`
+	CustomCard  any                     `json:"customCard,omitempty"`
+}
+
+type DeliveryRegularCardDto struct {
+	PreviewText  string           `json:"previewText"`
+	DetailText   string           `json:"detailText"`
+	Inverted     bool             `json:"inverted"`
+	LearnMoreUrl *string          `json:"learnMoreUrl"`
+	User         *DeliveryUserDto `json:"user"`
+	Tags         []TagDto         `json:"tags"`
+	DeliveryVideoCardDto
+}
+
+type DeliveryVideoCardDto struct {
+	Video            *DeliveryMediaDto `json:"video"`
+	VideoLite        *DeliveryMediaDto `json:"videoLite"`
+	VideoPreview     *DeliveryMediaDto `json:"videoPreview"`
+	VideoPreviewBlur *DeliveryMediaDto `json:"videoPreviewBlur"`
+}
+
+type DeliveryPhotoCardDto struct {
+	Picture *DeliveryMediaDto `json:"picture"`
+}
+
+type DeliveryFormCardDto struct {
+	Form         *FormDto         `json:"form"`
+	User         *DeliveryUserDto `json:"user"`
+	Tags         []TagDto         `json:"tags"`
+	LearnMoreUrl *string          `json:"learnMoreUrl"`
+}
+
+type DeliveryUserDto struct {
+	FirstName string            `json:"firstName"`
+	LastName  string            `json:"lastName"`
+	Position  string            `json:"position"`
+	Picture   *DeliveryMediaDto `json:"picture"`
+}
+
+// DeliveryMediaDto медиа с публичной ссылкой
+type DeliveryMediaDto struct {
+	ID     uuid.UUID `json:"id"`
+	Url    string    `json:"url"`
+	Alt    string    `json:"alt"`
+	Title  string    `json:"title"`
+	Width  int       `json:"width,omitempty"`
+	Height int       `json:"height,omitempty"`
+}
+
+// DeliveryUseCase публичное получение опубликованных страниц.
+// Неопубликованные галереи и карточки отфильтровываются репозиторием и в ответ не попадают.
+// По токену предпросмотра возвращается неопубликованное содержимое, на которое выдан токен.
+// Содержимое переводится на язык запроса, отсутствующие переводы заменяются по цепочке языков замены.
+type DeliveryUseCase struct {
+	pageRepository        PageRepository
+	galleryRepository     GalleryRepository
+	cardRepository        CardRepository
+	publicationRepository PublicationRepository
+	cardUseCase           *CardUseCase
+	previewUseCase        *PreviewUseCase
+	translationUseCase    *TranslationUseCase
+	mediaProvider         mediaActions.MediaProvider
+	copierService         CopierInterface
+	logger                *zap.SugaredLogger
+}
+
+func NewDeliveryUseCase(
+	pageRepository PageRepository, galleryRepository GalleryRepository, cardRepository CardRepository,
+	publicationRepository PublicationRepository, cardUseCase *CardUseCase, previewUseCase *PreviewUseCase,
+	translationUseCase *TranslationUseCase, mediaProvider mediaActions.MediaProvider, copierService CopierInterface,
+	logger *zap.SugaredLogger,
+) *DeliveryUseCase {
+	return &DeliveryUseCase{
+		pageRepository:        pageRepository,
+		galleryRepository:     galleryRepository,
+		cardRepository:        cardRepository,
+		publicationRepository: publicationRepository,
+		cardUseCase:           cardUseCase,
+		previewUseCase:        previewUseCase,
+		translationUseCase:    translationUseCase,
+		mediaProvider:         mediaProvider,
+		copierService:         copierService,
+		logger:                logger,
+	}
+}
+
+func (uc DeliveryUseCase) GetPage(ctx context.Context, dto GetDeliveryPageRequest) (*DeliveryPageDto, error) {
+	if dto.Locale == "" {
+		dto.Locale = uc.translationUseCase.DefaultLocale()
+	}
+	if dto.PreviewToken != "" {
+		return uc.getPreviewPage(ctx, dto)
+	}
+
+	uc.logger.Debug("Getting published page")
+	page, err := uc.findPage(ctx, dto, true)
+	if err != nil {
+		return nil, err
+	}
+
+	err = uc.translationUseCase.Translate(ctx, dto.Locale, page.TranslatableFields())
+	if err != nil {
+		return nil, err
+	}
+
+	pageDto, err := uc.getPageDto(*page, deliveryScope{})
+	if err != nil {
+		return nil, err
+	}
+	pageDto.Locale = dto.Locale
+
+	pageDto.Breadcrumbs, err = uc.getBreadcrumbs(ctx, *page, dto.Locale, false)
+	if err != nil {
+		return nil, err
+	}
+
+	uc.logger.Debug("Got published page")
+	return pageDto, nil
+}
+
+// getPreviewPage получение страницы по токену предпросмотра.
+// Токен страницы открывает всю страницу с ее черновиком, токен галереи или карточки -
+// только эту галерею или карточку на опубликованной странице.
+// Состав галерей и карточек берется из черновиков открытых токеном страницы и галерей,
+// поля открытых токеном галерей и карточек на основном языке - из их черновиков.
+func (uc DeliveryUseCase) getPreviewPage(ctx context.Context, dto GetDeliveryPageRequest) (*DeliveryPageDto, error) {
+	uc.logger.Debug("Getting page preview")
+	token, err := uc.previewUseCase.Resolve(ctx, dto.PreviewToken)
+	if err != nil {
+		return nil, err
+	}
+
+	page, err := uc.findPage(ctx, dto, false)
+	if err != nil {
+		return nil, err
+	}
+
+	scope := deliveryScope{preview: token}
+	if token.EntityType == entity.DraftTypePage {
+		if token.EntityID != page.ID {
+			return nil, errors.Forbidden.New("preview token is not valid for this page")
+		}
+	} else if !page.IsPublished {
+		return nil, errors.NotFound.Newf("page %s not found", page.FullPath())
+	}
+
+	err = uc.applyDrafts(ctx, page, scope, dto.Locale == uc.translationUseCase.DefaultLocale())
+	if err != nil {
+		return nil, err
+	}
+
+	err = uc.translationUseCase.Translate(ctx, dto.Locale, page.TranslatableFields())
+	if err != nil {
+		return nil, err
+	}
+
+	pageDto, err := uc.getPageDto(*page, scope)
+	if err != nil {
+		return nil, err
+	}
+	pageDto.Locale = dto.Locale
+
+	pageDto.Breadcrumbs, err = uc.getBreadcrumbs(ctx, *page, dto.Locale, true)
+	if err != nil {
+		return nil, err
+	}
+
+	// черновик содержит изменения полей на основном языке и к переводам не применяется
+	if token.EntityType == entity.DraftTypePage && dto.Locale == uc.translationUseCase.DefaultLocale() {
+		err = uc.applyPageDraft(ctx, pageDto)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	uc.logger.Debug("Got page preview")
+	return pageDto, nil
+}
+
+// findPage поиск страницы по полному пути, если он передан, иначе по коду
+func (uc DeliveryUseCase) findPage(ctx context.Context, dto GetDeliveryPageRequest, published bool) (*entity.Page, error) {
+	switch {
+	case dto.Path != "" && published:
+		return uc.pageRepository.GetPublishedByPath(ctx, dto.Path)
+	case dto.Path != "":
+		return uc.pageRepository.GetByPath(ctx, dto.Path)
+	case published:
+		return uc.pageRepository.GetPublishedByCode(ctx, dto.Code)
+	default:
+		return uc.pageRepository.GetByCode(ctx, dto.Code)
+	}
+}
+
+// getBreadcrumbs навигационная цепочка из предков страницы.
+// Без предпросмотра неопубликованные предки в цепочку не попадают.
+func (uc DeliveryUseCase) getBreadcrumbs(ctx context.Context, page entity.Page, locale string, allowInactive bool) (
+	[]DeliveryBreadcrumbDto, error,
+) {
+	ancestors, err := uc.pageRepository.GetListByPaths(ctx, page.AncestorPaths(), allowInactive)
+	if err != nil {
+		return nil, err
+	}
+
+	var fields []entity.TranslatableField
+	for i := range ancestors {
+		fields = append(fields, ancestors[i].TranslatableFields()...)
+	}
+	err = uc.translationUseCase.Translate(ctx, locale, fields)
+	if err != nil {
+		return nil, err
+	}
+
+	breadcrumbs := make([]DeliveryBreadcrumbDto, 0, len(ancestors))
+	for _, ancestor := range ancestors {
+		breadcrumbs = append(breadcrumbs, DeliveryBreadcrumbDto{
+			Code:  ancestor.Code,
+			Path:  ancestor.FullPath(),
+			Name:  ancestor.Name,
+			Title: ancestor.Title,
+		})
+	}
+
+	return breadcrumbs, nil
+}
+
+// deliveryScope видимость галерей и карточек страницы.
+// Без токена предпросмотра видны только опубликованные, токен страницы открывает все содержимое,
+// токен галереи - галерею со всеми ее карточками, токен карточки - только карточку.
+type deliveryScope struct {
+	preview *entity.PreviewToken
+}
+
+func (s deliveryScope) opens(entityType entity.DraftType, id uuid.UUID) bool {
+	return s.preview != nil && s.preview.EntityType == entityType && s.preview.EntityID == id
+}
+
+// galleryVisible неопубликованная галерея видна и тогда, когда токен выдан на одну из ее карточек
+func (s deliveryScope) galleryVisible(gallery entity.Gallery) bool {
+	if gallery.IsPublished || s.galleryOpened(gallery) {
+		return true
+	}
+	for _, galleriesCard := range gallery.GalleriesCards {
+		if galleriesCard.Card != nil && s.opens(entity.DraftTypeCard, galleriesCard.Card.ID) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (s deliveryScope) galleryOpened(gallery entity.Gallery) bool {
+	return s.preview != nil && s.preview.EntityType == entity.DraftTypePage ||
+		s.opens(entity.DraftTypeGallery, gallery.ID)
+}
+
+func (s deliveryScope) cardVisible(gallery entity.Gallery, card entity.Card) bool {
+	return card.IsPublished || s.cardOpened(gallery, card)
+}
+
+// cardOpened карточка открыта токеном страницы, ее галереи или ее самой
+func (s deliveryScope) cardOpened(gallery entity.Gallery, card entity.Card) bool {
+	return s.galleryOpened(gallery) || s.opens(entity.DraftTypeCard, card.ID)
+}
+
+func (uc DeliveryUseCase) getPageDto(page entity.Page, scope deliveryScope) (*DeliveryPageDto, error) {
+	pageDto := &DeliveryPageDto{
+		ID:             page.ID,
+		Code:           page.Code,
+		Path:           page.FullPath(),
+		Name:           page.Name,
+		Title:          page.Title,
+		Description:    page.Description,
+		TitleSeo:       page.TitleSeo,
+		DescriptionSeo: page.DescriptionSeo,
+		Keywords:       page.Keywords,
+		OgType:         page.OgType,
+		Galleries:      make([]DeliveryGalleryDto, 0, len(page.PagesGalleries)),
+	}
+
+	for _, pagesGallery := range page.PagesGalleries {
+		gallery := pagesGallery.Gallery
+		if !scope.galleryVisible(gallery) {
+			continue
+		}
+
+		galleryDto := DeliveryGalleryDto{
+			ID:     gallery.ID,
+			Code:   gallery.Code,
+			Name:   gallery.Name,
+			Hidden: gallery.Hidden,
+			Cards:  make([]DeliveryCardDto, 0, len(gallery.GalleriesCards)),
+		}
+		for _, galleriesCard := range gallery.GalleriesCards {
+			card := galleriesCard.Card
+			if card == nil || !scope.cardVisible(gallery, *card) {
+				continue
+			}
+			cardDto, err := uc.getCardDto(*card)
+			if err != nil {
+				return nil, err
+			}
+			galleryDto.Cards = append(galleryDto.Cards, *cardDto)
+		}
+		pageDto.Galleries = append(pageDto.Galleries, galleryDto)
+	}
+
+	return pageDto, nil
+}
+
+// applyDrafts наложение черновиков сущностей, открытых токеном.
+// Галереи черновика страницы применяются при токене страницы, карточки черновика галереи -
+// для галерей, открытых токеном. Добавленные в черновик галереи и карточки загружаются, удаленные пропускаются.
+// С fields на галереи и карточки накладываются и поля их черновиков.
+func (uc DeliveryUseCase) applyDrafts(ctx context.Context, page *entity.Page, scope deliveryScope, fields bool) error {
+	if scope.opens(entity.DraftTypePage, page.ID) {
+		request := PageDraft{}
+		found, err := uc.getDraft(ctx, entity.DraftTypePage, page.ID, &request)
+		if err != nil {
+			return err
+		}
+		if found && request.GalleryIDs != nil {
+			page.PagesGalleries, err = uc.draftPageGalleries(ctx, *page, *request.GalleryIDs)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	for i := range page.PagesGalleries {
+		gallery := &page.PagesGalleries[i].Gallery
+		if scope.galleryOpened(*gallery) {
+			err := uc.applyGalleryDraft(ctx, gallery, fields)
+			if err != nil {
+				return err
+			}
+		}
+		if !fields {
+			continue
+		}
+
+		for j := range gallery.GalleriesCards {
+			galleriesCard := &gallery.GalleriesCards[j]
+			if galleriesCard.Card == nil || !scope.cardOpened(*gallery, *galleriesCard.Card) {
+				continue
+			}
+			card, err := uc.draftCard(ctx, *galleriesCard.Card)
+			if err != nil {
+				return err
+			}
+			if card != nil {
+				galleriesCard.Card = card
+			}
+		}
+	}
+
+	return nil
+}
+
+// applyGalleryDraft состав карточек галереи из черновика, с fields - и поля, пустые поля черновика не применяются
+func (uc DeliveryUseCase) applyGalleryDraft(ctx context.Context, gallery *entity.Gallery, fields bool) error {
+	request := GalleryDraft{}
+	found, err := uc.getDraft(ctx, entity.DraftTypeGallery, gallery.ID, &request)
+	if err != nil || !found {
+		return err
+	}
+
+	if request.CardIDs != nil {
+		gallery.GalleriesCards, err = uc.draftGalleryCards(ctx, *gallery, *request.CardIDs)
+		if err != nil {
+			return err
+		}
+	}
+	if !fields {
+		return nil
+	}
+	if request.Name != "" {
+		gallery.Name = request.Name
+	}
+	if request.Code != "" {
+		gallery.Code = request.Code
+	}
+	if request.Hidden != nil {
+		gallery.Hidden = *request.Hidden
+	}
+
+	return nil
+}
+
+// draftCard карточка, собранная из черновика, со связанными медиа, спикером, тегами и формой.
+// Без черновика возвращается nil. Черновик, из которого карточку не собрать, не применяется.
+func (uc DeliveryUseCase) draftCard(ctx context.Context, card entity.Card) (*entity.Card, error) {
+	request := UpdateCardRequest{}
+	found, err := uc.getDraft(ctx, entity.DraftTypeCard, card.ID, &request)
+	if err != nil || !found {
+		return nil, err
+	}
+	if request.Type == "" {
+		request.Type = card.Type
+	}
+	if !hasCardTypeData(request) {
+		uc.logger.Warnf("card %s draft has no %s card data, published card is shown", card.ID, request.Type)
+		return nil, nil
+	}
+
+	// html черновика не очищался при сохранении
+	_, err = uc.cardUseCase.sanitizeHtml(request.HtmlCard)
+	if err != nil {
+		return nil, err
+	}
+
+	draft, err := uc.cardUseCase.GetCardFromUpdateDto(ctx, &request)
+	if errors.GetType(err) == errors.BadRequest {
+		uc.logger.Warnf("card %s draft is invalid, published card is shown: %v", card.ID, err)
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	draft.ID, draft.IsPublished = card.ID, card.IsPublished
+	if draft.Name == "" {
+		draft.Name = card.Name
+	}
+	if draft.Code == "" {
+		draft.Code = card.Code
+	}
+
+	err = uc.cardRepository.LoadRelations(ctx, draft)
+	if err != nil {
+		return nil, err
+	}
+
+	return draft, nil
+}
+
+// draftPageGalleries галереи страницы в составе и порядке черновика
+func (uc DeliveryUseCase) draftPageGalleries(ctx context.Context, page entity.Page, galleryIDs []uuid.UUID) (
+	[]entity.PagesGalleries, error,
+) {
+	current := make(map[uuid.UUID]entity.Gallery, len(page.PagesGalleries))
+	for _, pagesGallery := range page.PagesGalleries {
+		current[pagesGallery.Gallery.ID] = pagesGallery.Gallery
+	}
+
+	pagesGalleries := make([]entity.PagesGalleries, 0, len(galleryIDs))
+	for _, id := range galleryIDs {
+		gallery, ok := current[id]
+		if !ok {
+			loaded, err := uc.galleryRepository.GetById(ctx, id)
+			if errors.GetType(err) == errors.NotFound {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			gallery = *loaded
+		}
+		galleryID := id
+		pagesGalleries = append(pagesGalleries, entity.PagesGalleries{
+			PagesID: &page.ID, GalleryID: &galleryID, Gallery: gallery, Position: len(pagesGalleries),
+		})
+	}
+
+	return pagesGalleries, nil
+}
+
+// draftGalleryCards карточки галереи в составе и порядке черновика
+func (uc DeliveryUseCase) draftGalleryCards(ctx context.Context, gallery entity.Gallery, cardIDs []uuid.UUID) (
+	[]entity.GalleriesCards, error,
+) {
+	current := make(map[uuid.UUID]*entity.Card, len(gallery.GalleriesCards))
+	for _, galleriesCard := range gallery.GalleriesCards {
+		if galleriesCard.Card != nil {
+			current[galleriesCard.Card.ID] = galleriesCard.Card
+		}
+	}
+
+	galleriesCards := make([]entity.GalleriesCards, 0, len(cardIDs))
+	for _, id := range cardIDs {
+		card, ok := current[id]
+		if !ok {
+			var err error
+			card, err = uc.cardRepository.GetById(ctx, id)
+			if errors.GetType(err) == errors.NotFound {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+		cardID := id
+		galleriesCards = append(galleriesCards, entity.GalleriesCards{
+			GalleryID: &gallery.ID, CardID: &cardID, Card: card, Position: len(galleriesCards),
+		})
+	}
+
+	return galleriesCards, nil
+}
+
+// hasCardTypeData есть ли в запросе данные встроенного типа карточки, без них карточку не собрать
+func hasCardTypeData(request UpdateCardRequest) bool {
+	switch request.Type {
+	case "regular":
+		return request.RegularCard != nil
+	case "video":
+		return request.VideoCard != nil
+	case "html":
+		return request.HtmlCard != nil
+	case "photo":
+		return request.PhotoCard != nil
+	case "form":
+		return request.FormCard != nil
+	}
+
+	return true
+}
+
+// getDraft разбор черновика сущности в request, false - черновика нет
+func (uc DeliveryUseCase) getDraft(ctx context.Context, entityType entity.DraftType, id uuid.UUID, request any) (
+	bool, error,
+) {
+	draft, err := uc.publicationRepository.GetDraft(ctx, entityType, id)
+	if errors.GetType(err) == errors.NotFound {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	err = json.Unmarshal(draft.Data, request)
+	if err != nil {
+		return false, errors.NoType.Wrapf(err, "error decoding %s draft", entityType)
+	}
+
+	return true, nil
+}
+
+// applyPageDraft наложение черновика страницы на ее поля, пустые поля черновика не применяются
+func (uc DeliveryUseCase) applyPageDraft(ctx context.Context, pageDto *DeliveryPageDto) error {
+	request := PatchPageRequest{}
+	found, err := uc.getDraft(ctx, entity.DraftTypePage, pageDto.ID, &request)
+	if err != nil || !found {
+		return err
+	}
+
+	for field, value := range map[*string]string{
+		&pageDto.Name:           request.Name,
+		&pageDto.Title:          request.Title,
+		&pageDto.Description:    request.Description,
+		&pageDto.TitleSeo:       request.TitleSeo,
+		&pageDto.DescriptionSeo: request.DescriptionSeo,
+		&pageDto.Keywords:       request.Keywords,
+		&pageDto.OgType:         request.OgType,
+	} {
+		if value != "" {
+			*field = value
+		}
+	}
+
+	return nil
+}
+
+func (uc DeliveryUseCase) getCardDto(card entity.Card) (*DeliveryCardDto, error) {
+	cardDto := &DeliveryCardDto{
+		ID:          card.ID,
+		Code:        card.Code,
+		Name:        card.Name,
+		Type:        card.Type,
+		Title:       card.Title,
+		Description: card.Description,
+		OgType:      card.OgType,
+	}
+
+	switch {
+	case card.RegularCard != nil:
+		regular := card.RegularCard
+		cardDto.RegularCard = &DeliveryRegularCardDto{
+			PreviewText:  regular.PreviewText,
+			DetailText:   regular.DetailText,
+			Inverted:     regular.Inverted,
+			LearnMoreUrl: regular.LearnMoreUrl,
+			User:         uc.getUserDto(regular.User),
+			Tags:         make([]TagDto, 0, len(regular.RegularCardsTags)),
+			DeliveryVideoCardDto: DeliveryVideoCardDto{
+				Video:            uc.getMediaDto(regular.Video),
+				VideoLite:        uc.getMediaDto(regular.VideoLite),
+				VideoPreview:     uc.getMediaDto(regular.VideoPreview),
+				VideoPreviewBlur: uc.getMediaDto(regular.VideoPreviewBlur),
+			},
+		}
+		for _, tag := range regular.RegularCardsTags {
+			cardDto.RegularCard.Tags = append(cardDto.RegularCard.Tags, getDeliveryTagDto(tag.Tag))
+		}
+	case card.VideoCard != nil:
+		cardDto.VideoCard = &DeliveryVideoCardDto{
+			Video:            uc.getMediaDto(card.VideoCard.Video),
+			VideoLite:        uc.getMediaDto(card.VideoCard.VideoLite),
+			VideoPreview:     uc.getMediaDto(card.VideoCard.VideoPreview),
+			VideoPreviewBlur: uc.getMediaDto(card.VideoCard.VideoPreviewBlur),
+		}
+	case card.HtmlCard != nil:
+		cardDto.HtmlCard = &HtmlCardDto{Html: card.HtmlCard.Html}
+	case card.PhotoCard != nil:
+		cardDto.PhotoCard = &DeliveryPhotoCardDto{Picture: uc.getMediaDto(card.PhotoCard.Picture)}
+	case card.FormCard != nil:
+		form := card.FormCard
+		cardDto.FormCard = &DeliveryFormCardDto{
+			User:         uc.getUserDto(form.User),
+			Tags:         make([]TagDto, 0, len(form.FormCardsTags)),
+			LearnMoreUrl: form.LearnMoreUrl,
+		}
+		if form.Form != nil {
+			cardDto.FormCard.Form = &FormDto{}
+			err := uc.copierService.Copy(cardDto.FormCard.Form, form.Form)
+			if err != nil {
+				return nil, err
+			}
+		}
+		for _, tag := range form.FormCardsTags {
+			cardDto.FormCard.Tags = append(cardDto.FormCard.Tags, getDeliveryTagDto(tag.Tag))
+		}
+	case card.CustomCard != nil:
+		cardDto.CustomCard = card.CustomCard
+	}
+
+	return cardDto, nil
+}
+
+func (uc DeliveryUseCase) getUserDto(user *entity.User) *DeliveryUserDto {
+	if user == nil {
+		return nil
+	}
+
+	return &DeliveryUserDto{
+		FirstName: user.FirstName,
+		LastName:  user.LastName,
+		Position:  user.Position,
+		Picture:   uc.getMediaDto(user.Picture),
+	}
+}
+
+func getDeliveryTagDto(tag entity.Tag) TagDto {
+	return TagDto{ID: tag.ID, Text: tag.Text, Link: tag.Link}
+}
+
+func (uc DeliveryUseCase) getMediaDto(media *mediaEntity.Media) *DeliveryMediaDto {
+	if media == nil {
+		return nil
+	}
+
+	return &DeliveryMediaDto{
+		ID:     media.Id,
+		Url:    uc.mediaProvider.GetUrlByFilepath(media.Filepath),
+		Alt:    media.Alt,
+		Title:  media.Title,
+		Width:  media.Width,
+		Height: media.Height,
+	}
+}
@@ -1,7 +1,10 @@
 package actions
 
 import (
+	"encoding/json"
 	"github.com/aeroideaservices/focus/media/plugin/entity"
+	"github.com/aeroideaservices/focus/page/plugin/services/cardtypes"
+	"github.com/aeroideaservices/focus/services/sanitizer"
 	"github.com/google/uuid"
 	"io"
 	"time"
@@ -9,6 +12,8 @@ import (
 
 type PageDto struct {
 	ID             uuid.UUID          `json:"id"`
+	ParentID       *uuid.UUID         `json:"parentId"`
+	Path           string             `json:"path"`
 	Name           string             `json:"name"`
 	Code           string             `json:"code"`
 	Title          string             `json:"title"`
@@ -17,6 +22,8 @@ type PageDto struct {
 	Description    string             `json:"description"`
 	DescriptionSeo string             `json:"descriptionSeo"`
 	IsPublished    bool               `json:"isPublished"`
+	PublishAt      *time.Time         `json:"publishAt"`
+	UnpublishAt    *time.Time         `json:"unpublishAt"`
 	Sort           int                `json:"sort"`
 	Galleries      []GalleryInPageDto `json:"galleries"`
 	//Header      *Header           `focus:"title:Header;view:select;hidden:list" validate:"omitempty,structonly"`
@@ -36,15 +43,35 @@ type GetPageDto struct {
 }
 
 type PageShort struct {
-	ID   uuid.UUID `json:"id"`
-	Name string    `json:"name"`
-	Code string    `json:"code"`
-	Sort int       `json:"sort"`
+	ID        uuid.UUID  `json:"id"`
+	ParentID  *uuid.UUID `json:"parentId"`
+	Path      string     `json:"path"`
+	Name      string     `json:"name"`
+	Code      string     `json:"code"`
+	Sort      int        `json:"sort"`
+	UpdatedAt *time.Time `json:"updatedAt,omitempty"` // UpdatedAt время последнего изменения, заполняется, если выбрано
+}
+
+// PageTreeDto страница в дереве страниц
+type PageTreeDto struct {
+	PageShort
+	Children []*PageTreeDto `json:"children"`
+}
+
+type GetPageTreeResponse struct {
+	Tree []*PageTreeDto `json:"tree"`
+}
+
+// MovePageRequest перемещение страницы вместе с вложенными страницами под другого родителя
+type MovePageRequest struct {
+	ID       uuid.UUID  `json:"-"`
+	ParentID *uuid.UUID `json:"parentId"` // ParentID новая родительская страница, nil - перемещение в корень
 }
 
 type CreatePageRequest struct {
+	ParentID       *uuid.UUID                   `json:"parentId"`
 	Name           string                       `json:"name" validate:"required"`
-	Code           string                       `json:"code" validate:"required"`
+	Code           string                       `json:"code" validate:"required,excludes=/"`
 	Title          string                       `json:"title" validate:"required"`
 	Description    string                       `json:"description"`
 	TitleSeo       string                       `json:"titleSeo"`
@@ -86,19 +113,23 @@ type GalleryDto struct {
 	Code string    `json:"code"`
 	Name string    `json:"name"`
 	//Position    int       `json:"position"`
-	IsPublished bool      `json:"isPublished"`
-	Hidden      bool      `json:"hiddenMenu"`
-	Cards       []CardDto `json:"cards"`
+	IsPublished bool       `json:"isPublished"`
+	PublishAt   *time.Time `json:"publishAt"`
+	UnpublishAt *time.Time `json:"unpublishAt"`
+	Hidden      bool       `json:"hiddenMenu"`
+	Cards       []CardDto  `json:"cards"`
 }
 
 type GalleryInPageDto struct {
-	ID          uuid.UUID `json:"id"`
-	Code        string    `json:"code"`
-	Name        string    `json:"name"`
-	Position    int       `json:"position"`
-	IsPublished bool      `json:"isPublished"`
-	Hidden      bool      `json:"hiddenMenu"`
-	Cards       []CardDto `json:"cards"`
+	ID          uuid.UUID  `json:"id"`
+	Code        string     `json:"code"`
+	Name        string     `json:"name"`
+	Position    int        `json:"position"`
+	IsPublished bool       `json:"isPublished"`
+	PublishAt   *time.Time `json:"publishAt"`
+	UnpublishAt *time.Time `json:"unpublishAt"`
+	Hidden      bool       `json:"hiddenMenu"`
+	Cards       []CardDto  `json:"cards"`
 }
 
 type GalleryDtoWithCardsTotal struct {
@@ -129,6 +160,12 @@ type PatchGalleryPosition struct {
 	NewPosition *int      `json:"newPosition" validate:"required"`
 }
 
+// ReorderGalleriesRequest полный порядок галерей страницы
+type ReorderGalleriesRequest struct {
+	PageID     uuid.UUID   `json:"-"`
+	GalleryIDs []uuid.UUID `json:"galleryIds" validate:"unique"` // GalleryIDs все галереи страницы в новом порядке
+}
+
 type UpdateGalleryRequest struct {
 	ID          uuid.UUID           `json:"id"`
 	Name        string              `json:"name"`
@@ -152,6 +189,18 @@ type PatchCardPosition struct {
 	NewPosition *int      `json:"newPosition" validate:"required"`
 }
 
+// ReorderCardsRequest полный порядок карточек галереи
+type ReorderCardsRequest struct {
+	GalleryID uuid.UUID   `json:"-"`
+	CardIDs   []uuid.UUID `json:"cardIds" validate:"unique"` // CardIDs все карточки галереи в новом порядке
+}
+
+// RepairPositionsResponse количество связей, позиции которых были исправлены
+type RepairPositionsResponse struct {
+	Galleries int64 `json:"galleries"`
+	Cards     int64 `json:"cards"`
+}
+
 type CreateGalleryInPageRequest struct {
 	Name        string              `json:"name"`
 	Code        string              `json:"code"`
@@ -183,11 +232,14 @@ type CardDto struct {
 	Type        string          `json:"type"`
 	Position    int             `json:"position"`
 	IsPublished bool            `json:"isPublished"`
+	PublishAt   *time.Time      `json:"publishAt"`
+	UnpublishAt *time.Time      `json:"unpublishAt"`
 	RegularCard *RegularCardDto `json:"regularCard"`
 	VideoCard   *VideoCardDto   `json:"videoCard"`
 	HtmlCard    *HtmlCardDto    `json:"htmlCard"`
 	PhotoCard   *PhotoCardDto   `json:"photoCard"`
 	FormCard    *FormCardDto    `json:"formCard"`
+	CustomCard  any             `json:"customCard,omitempty"` // CustomCard данные карточки зарегистрированного приложением типа
 
 	Title       string `json:"title"`
 	Description string `json:"description"`
@@ -200,11 +252,14 @@ type CardDtoWithoutPosition struct {
 	Code        string          `json:"code"`
 	Type        string          `json:"type"`
 	IsPublished bool            `json:"isPublished"`
+	PublishAt   *time.Time      `json:"publishAt"`
+	UnpublishAt *time.Time      `json:"unpublishAt"`
 	RegularCard *RegularCardDto `json:"regularCard"`
 	VideoCard   *VideoCardDto   `json:"videoCard"`
 	HtmlCard    *HtmlCardDto    `json:"htmlCard"`
 	PhotoCard   *PhotoCardDto   `json:"photoCard"`
 	FormCard    *FormCardDto    `json:"formCard"`
+	CustomCard  any             `json:"customCard,omitempty"` // CustomCard данные карточки зарегистрированного приложением типа
 
 	Title       string `json:"title"`
 	Description string `json:"description"`
@@ -251,6 +306,14 @@ type FormDto struct {
 	Name string    `json:"name"`
 }
 
+// CardTypeDto тип карточки с описанием полей данных
+type CardTypeDto struct {
+	Code    string            `json:"code"`
+	Title   string            `json:"title"`
+	Builtin bool              `json:"builtin"`
+	Fields  []cardtypes.Field `json:"fields"`
+}
+
 type CreateCardRequest struct {
 	GalleryIds  []uuid.UUID               `json:"galleryIds"`
 	Type        string                    `json:"type"  validate:"required"`
@@ -263,6 +326,8 @@ type CreateCardRequest struct {
 	HtmlCard    *CreateHtmlCardRequest    `json:"htmlCard"`
 	PhotoCard   *CreatePhotoCardRequest   `json:"photoCard"`
 	FormCard    *CreateFormCardRequest    `json:"formCard"`
+	// CustomCardData данные карточки зарегистрированного приложением типа
+	CustomCardData json.RawMessage `json:"customCard"`
 
 	Title       string `json:"title"`
 	Description string `json:"description"`
@@ -282,6 +347,8 @@ type UpdateCardRequest struct {
 	HtmlCard    *CreateHtmlCardRequest    `json:"htmlCard"`
 	PhotoCard   *CreatePhotoCardRequest   `json:"photoCard"`
 	FormCard    *CreateFormCardRequest    `json:"formCard"`
+	// CustomCardData данные карточки зарегистрированного приложением типа
+	CustomCardData json.RawMessage `json:"customCard"`
 
 	Title       string `json:"title"`
 	Description string `json:"description"`
@@ -289,7 +356,12 @@ type UpdateCardRequest struct {
 }
 
 type CreateCardResponse struct {
-	ID uuid.UUID `json:"id"`
+	ID        uuid.UUID         `json:"id"`
+	Sanitized *sanitizer.Report `json:"sanitized,omitempty"` // Sanitized отчет об удаленном из html карточки
+}
+
+type UpdateCardResponse struct {
+	Sanitized *sanitizer.Report `json:"sanitized,omitempty"` // Sanitized отчет об удаленном из html карточки
 }
 
 type CreateTagResponse struct {
@@ -389,63 +461,18 @@ type CreateVideoRequest struct {
 	File     io.ReadSeeker `validate:"required"`
 }
 
+// CreateVideoResponse загруженное видео.
+// Превью создаются задачей samples, поэтому PreviewId и PreviewBlurredId остаются null,
+// пока задача не выполнена, идентификаторы превью возвращаются в результате задачи.
+// Плейлист доступен после формирования потока
 type CreateVideoResponse struct {
-	VideoId          uuid.UUID `json:"videoId"`
-	VideoLiteId      uuid.UUID `json:"videoLiteId"`
-	PreviewId        uuid.UUID `json:"videoPreviewId"`
-	PreviewBlurredId uuid.UUID `json:"videoPreviewBlurId"`
-}
-
-type YandexSpeechOperationResult struct {
-	Done       bool                 `json:"done"`
-	Response   YandexSpeechResponse `json:"response"`
-	Id         string               `json:"id"`
-	CreatedAt  time.Time            `json:"createdAt"`
-	CreatedBy  string               `json:"createdBy"`
-	ModifiedAt time.Time            `json:"modifiedAt"`
-}
-
-type YandexSpeechResponse struct {
-	Type   string  `json:"@type"`
-	Chunks []Chunk `json:"chunks"`
-}
-
-type Chunk struct {
-	Alternatives []Alternative `json:"alternatives"`
-	ChannelTag   string        `json:"channelTag"`
-}
-
-type Alternative struct {
-	Words      []Word `json:"words"`
-	Text       string `json:"text"`
-	Confidence int    `json:"confidence"`
-}
-
-type Word struct {
-	StartTime  string `json:"startTime"`
-	EndTime    string `json:"endTime"`
-	Word       string `json:"word"`
-	Confidence int    `json:"confidence"`
-}
-
-type RecognitionRequest struct {
-	Config RecognitionConfig `json:"config"`
-	Audio  RecognitionAudio  `json:"audio"`
-}
-
-type RecognitionConfig struct {
-	Specification Specification `json:"specification"`
-}
-
-type Specification struct {
-	ProfanityFilter bool   `json:"profanityFilter"`
-	LiteratureText  bool   `json:"literature_text"`
-	AudioEncoding   string `json:"audioEncoding"`
-	RawResults      bool   `json:"rawResults"`
-}
-
-type RecognitionAudio struct {
-	Uri string `json:"uri"`
+	VideoId          uuid.UUID           `json:"videoId"`
+	VideoLiteId      uuid.UUID           `json:"videoLiteId"`
+	PreviewId        *uuid.UUID          `json:"videoPreviewId"`
+	PreviewBlurredId *uuid.UUID          `json:"videoPreviewBlurId"`
+	PlaylistUrl      string              `json:"playlistUrl"` // PlaylistUrl мастер-плейлист HLS
+	StreamStatus     entity.StreamStatus `json:"streamStatus"`
+	Jobs             VideoJobs           `json:"jobs"` // Jobs задачи обработки видео
 }
 
 type SubtitlesToSave struct {
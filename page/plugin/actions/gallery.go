@@ -47,7 +47,7 @@ func (uc GalleryUseCase) GetByCode(ctx context.Context, code string) (*GalleryDt
 
 func (uc GalleryUseCase) Create(ctx context.Context, dto *CreateGalleryRequest) (*CreateGalleryResponse, error) {
 	uc.logger.Debug("Creating gallery")
-	galleryEntity, err := uc.GetGalleryFromDto(dto)
+	galleryEntity, err := uc.GetGalleryFromDto(ctx, dto)
 	if err != nil {
 		return nil, err
 	}
@@ -103,7 +103,7 @@ func (uc GalleryUseCase) Update(ctx context.Context, dto *UpdateGalleryRequest)
 		return err
 	}
 
-	gallery, err := uc.GetGalleryFromUpdateDto(dto)
+	gallery, err := uc.GetGalleryFromUpdateDto(ctx, dto)
 	if err != nil {
 		return err
 	}
@@ -171,6 +171,24 @@ func (uc GalleryUseCase) PatchCardPosition(ctx context.Context, dto *PatchCardPo
 	return nil
 }
 
+// ReorderCards установка полного порядка карточек галереи.
+// Список должен содержать все привязанные карточки ровно по одному разу, иначе возвращается конфликт.
+func (uc GalleryUseCase) ReorderCards(ctx context.Context, dto *ReorderCardsRequest) error {
+	uc.logger.Debug("Reordering gallery cards")
+
+	_, err := uc.galleryRepository.GetByIdWithoutAssociate(ctx, dto.GalleryID)
+	if err != nil {
+		return err
+	}
+
+	err = uc.galleryRepository.ReorderCards(ctx, dto.GalleryID, dto.CardIDs)
+	if err != nil {
+		return err
+	}
+	uc.logger.Debug("Reordered gallery cards")
+	return nil
+}
+
 func (uc GalleryUseCase) LinkCards(ctx context.Context, galleryId uuid.UUID, cardIds []uuid.UUID) (error, bool) {
 	uc.logger.Debug("Linking cards to gallery")
 
@@ -303,7 +321,9 @@ func (uc GalleryUseCase) GetDtoFromGallery(gallery *entity.Gallery) (
 	return galleryDto, err
 }
 
-func (uc GalleryUseCase) GetGalleryFromDtos(galleryDtos []CreateGalleryInPageRequest, pageId uuid.UUID) (
+func (uc GalleryUseCase) GetGalleryFromDtos(
+	ctx context.Context, galleryDtos []CreateGalleryInPageRequest, pageId uuid.UUID,
+) (
 	galleries []entity.PagesGalleries, err error,
 ) {
 	for i, gallery := range galleryDtos {
@@ -325,13 +345,13 @@ func (uc GalleryUseCase) GetGalleryFromDtos(galleryDtos []CreateGalleryInPageReq
 		pagesGalleries.Gallery.ID = newGalleryId
 		galleries = append(galleries, *pagesGalleries)
 
-		galleries[i].Gallery.GalleriesCards, err = uc.cardUseCase.GetCardFromDtos(gallery.Cards, newGalleryId)
+		galleries[i].Gallery.GalleriesCards, err = uc.cardUseCase.GetCardFromDtos(ctx, gallery.Cards, newGalleryId)
 	}
 
 	return galleries, err
 }
 
-func (uc GalleryUseCase) GetGalleryFromDto(galleryDto *CreateGalleryRequest) (
+func (uc GalleryUseCase) GetGalleryFromDto(ctx context.Context, galleryDto *CreateGalleryRequest) (
 	gallery *entity.Gallery, err error,
 ) {
 	gallery = &entity.Gallery{}
@@ -344,12 +364,12 @@ func (uc GalleryUseCase) GetGalleryFromDto(galleryDto *CreateGalleryRequest) (
 	}
 	gallery.ID = newGalleryId
 
-	gallery.GalleriesCards, err = uc.cardUseCase.GetCardFromDtos(galleryDto.Cards, newGalleryId)
+	gallery.GalleriesCards, err = uc.cardUseCase.GetCardFromDtos(ctx, galleryDto.Cards, newGalleryId)
 
 	return gallery, err
 }
 
-func (uc GalleryUseCase) GetGalleryFromUpdateDto(galleryDto *UpdateGalleryRequest) (
+func (uc GalleryUseCase) GetGalleryFromUpdateDto(ctx context.Context, galleryDto *UpdateGalleryRequest) (
 	gallery *entity.Gallery, err error,
 ) {
 	gallery = &entity.Gallery{}
@@ -358,7 +378,7 @@ func (uc GalleryUseCase) GetGalleryFromUpdateDto(galleryDto *UpdateGalleryReques
 		return nil, err
 	}
 
-	gallery.GalleriesCards, err = uc.cardUseCase.GetCardFromDtos(galleryDto.Cards, galleryDto.ID)
+	gallery.GalleriesCards, err = uc.cardUseCase.GetCardFromDtos(ctx, galleryDto.Cards, galleryDto.ID)
 	if err != nil {
 		return nil, err
 	}
@@ -2,22 +2,37 @@ package actions
 
 import (
 	"context"
+	mediaActions "github.com/aeroideaservices/focus/media/plugin/actions"
 	"github.com/aeroideaservices/focus/page/plugin/entity"
+	"github.com/aeroideaservices/focus/page/plugin/services/jobs"
+	"github.com/aeroideaservices/focus/services/sanitizer"
 	"github.com/google/uuid"
+	"time"
 )
 
 type PageRepository interface {
 	GetById(ctx context.Context, id uuid.UUID) (*entity.Page, error)
 	GetByIdWithoutAssociate(ctx context.Context, id uuid.UUID) (*entity.Page, error)
+	GetPublishedByCode(ctx context.Context, code string) (*entity.Page, error)
+	GetByCode(ctx context.Context, code string) (*entity.Page, error)
+	GetPublishedByPath(ctx context.Context, path string) (*entity.Page, error)
+	GetByPath(ctx context.Context, path string) (*entity.Page, error)
+	GetListByPaths(ctx context.Context, paths []string, allowInactive bool) ([]entity.Page, error)
+	GetDescendantPaths(ctx context.Context, path string) ([]string, error)
+	HasChildren(ctx context.Context, pageID uuid.UUID) (bool, error)
 	GetList(ctx context.Context, fields []string, allowInactive bool) ([]PageShort, error)
 	Create(ctx context.Context, page *entity.Page) (*uuid.UUID, error)
 	Delete(ctx context.Context, pageID uuid.UUID) error
 	PatchProperties(ctx context.Context, page *entity.Page) error
+	Move(ctx context.Context, pageID uuid.UUID, parentID *uuid.UUID, path string) error
 	PatchGalleryPosition(ctx context.Context, dto *PatchGalleryPosition) error
+	ReorderGalleries(ctx context.Context, pageID uuid.UUID, galleryIDs []uuid.UUID) error
+	RepairGalleriesPositions(ctx context.Context) (int64, error)
 	GetLastPosition(ctx context.Context, pageID uuid.UUID) (int, error)
 	CreatePagesGalleries(ctx context.Context, pagesGalleries []entity.PagesGalleries) (error, bool)
 	DeletePagesGalleries(ctx context.Context, pageID uuid.UUID, galleryIDs []uuid.UUID) error
 	UpdatePublish(ctx context.Context, pageID uuid.UUID, publish *bool) error
+	Clone(ctx context.Context, page *entity.Page, deep bool) error
 }
 
 type GalleryRepository interface {
@@ -30,12 +45,15 @@ type GalleryRepository interface {
 	Update(ctx context.Context, gallery *entity.Gallery) error
 	PatchName(ctx context.Context, gallery *entity.Gallery) error
 	PatchCardPosition(ctx context.Context, dto *PatchCardPosition) error
+	ReorderCards(ctx context.Context, galleryID uuid.UUID, cardIDs []uuid.UUID) error
+	RepairCardsPositions(ctx context.Context) (int64, error)
 	DeleteList(ctx context.Context, galleryIds []uuid.UUID) error
 	GetLastPosition(ctx context.Context, galleryID uuid.UUID) (int, error)
 	CreateGalleriesCards(ctx context.Context, galleriesCards []entity.GalleriesCards) (error, bool)
 	DeleteGalleriesCards(ctx context.Context, galleryID uuid.UUID, cardIDs []uuid.UUID) error
 	UpdateHidden(ctx context.Context, galleryID uuid.UUID, hidden *bool) error
 	UpdatePublish(ctx context.Context, galleryID uuid.UUID, publish *bool) error
+	Clone(ctx context.Context, gallery *entity.Gallery, deep bool) error
 }
 
 type CardRepository interface {
@@ -56,6 +74,8 @@ type CardRepository interface {
 	UpdatePublish(ctx context.Context, cardID uuid.UUID, publish *bool) error
 	GetLastPositionInGalley(ctx context.Context, galleryID uuid.UUID) (int, error)
 	UpdateInverted(ctx context.Context, cardID uuid.UUID, inverted *bool) error
+	Clone(ctx context.Context, card *entity.Card) error
+	LoadRelations(ctx context.Context, card *entity.Card) error
 }
 
 type TagRepository interface {
@@ -66,10 +86,84 @@ type TagRepository interface {
 	Create(ctx context.Context, tag *entity.Tag) (*uuid.UUID, error)
 	Update(ctx context.Context, tag *entity.Tag) error
 	Delete(ctx context.Context, tagId uuid.UUID) error
+	GetListWithUsage(ctx context.Context, filter TagFilter) ([]TagUsage, int64, error)
+	GetUsage(ctx context.Context, tagId uuid.UUID) (*TagUsage, error)
+	Merge(ctx context.Context, dto MergeTagsRequest) (int64, error)
 }
 
 type UserRepository interface {
 	GetById(ctx context.Context, userId uuid.UUID) (*entity.User, error)
+	GetList(ctx context.Context, filter UserFilter) ([]entity.User, int64, error)
+	Create(ctx context.Context, user *entity.User) error
+	Update(ctx context.Context, user *entity.User) error
+	Delete(ctx context.Context, userId uuid.UUID) error
+	GetCards(ctx context.Context, userId uuid.UUID) ([]entity.Card, error)
+}
+
+type JobRepository interface {
+	jobs.Queue
+	Enqueue(ctx context.Context, jobs ...entity.Job) error
+	GetById(ctx context.Context, id uuid.UUID) (*entity.Job, error)
+	GetList(ctx context.Context, filter JobFilter) ([]entity.Job, int64, error)
+	Retry(ctx context.Context, id uuid.UUID) error
+}
+
+// Transactor выполнение действий в одной транзакции: репозитории, вызванные с контекстом fn, работают в ней
+type Transactor interface {
+	Transaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+type PublicationRepository interface {
+	GetDraft(ctx context.Context, entityType entity.DraftType, id uuid.UUID) (*entity.Draft, error)
+	SaveDraft(ctx context.Context, draft *entity.Draft) error
+	DeleteDraft(ctx context.Context, entityType entity.DraftType, id uuid.UUID) error
+	UpdatePublish(ctx context.Context, entityType entity.DraftType, id uuid.UUID, publish bool) error
+	Schedule(ctx context.Context, entityType entity.DraftType, id uuid.UUID, publishAt, unpublishAt *time.Time) error
+	GetScheduled(ctx context.Context, now time.Time) ([]ScheduledPublication, error)
+}
+
+type TranslationRepository interface {
+	GetList(ctx context.Context, entityIDs []uuid.UUID, locales []string) ([]entity.Translation, error)
+	Save(ctx context.Context, translation *entity.Translation) error
+	Delete(ctx context.Context, entityType entity.TranslationType, entityID uuid.UUID, locale string) error
+	Copy(ctx context.Context, ids map[uuid.UUID]uuid.UUID) error
+}
+
+type TransferRepository interface {
+	GetCardsByCodes(ctx context.Context, codes []string) ([]entity.Card, error)
+	GetTagsByTexts(ctx context.Context, texts []string) ([]entity.Tag, error)
+	GetUsersByLastNames(ctx context.Context, lastNames []string) ([]entity.User, error)
+	Import(ctx context.Context, data ImportData) error
+}
+
+// TransferMedias медиа окружения, в которые импорт страницы загружает файлы архива
+type TransferMedias interface {
+	Find(ctx context.Context, dto mediaActions.FindMedia) (*mediaActions.MediaPreview, error)
+	Download(ctx context.Context, dto mediaActions.GetMedia) (string, error)
+	Create(ctx context.Context, action mediaActions.CreateMedia) (*uuid.UUID, error)
+	Delete(ctx context.Context, dto mediaActions.GetMedia) error
+}
+
+type SnapshotRepository interface {
+	Create(ctx context.Context, snapshot *entity.Snapshot) error
+	GetList(ctx context.Context, pageID uuid.UUID, limit int, offset int) ([]entity.Snapshot, int64, error)
+	GetById(ctx context.Context, pageID uuid.UUID, id uuid.UUID) (*entity.Snapshot, error)
+	GetPageIds(ctx context.Context, entityType entity.DraftType, id uuid.UUID) ([]uuid.UUID, error)
+}
+
+type SearchRepository interface {
+	Search(ctx context.Context, dto SearchRequest) ([]SearchResult, int64, error)
+}
+
+type PreviewTokenRepository interface {
+	Create(ctx context.Context, token *entity.PreviewToken) error
+	GetById(ctx context.Context, id uuid.UUID) (*entity.PreviewToken, error)
+	GetList(ctx context.Context, entityType entity.DraftType, entityId uuid.UUID) ([]entity.PreviewToken, error)
+	Revoke(ctx context.Context, id uuid.UUID, revokedBy string) error
+}
+
+type Sanitizer interface {
+	Sanitize(policy string, input string) (string, sanitizer.Report, error)
 }
 
 type CopierInterface interface {
@@ -0,0 +1,251 @@
+package actions
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	mediaActions "github.com/aeroideaservices/focus/media/plugin/actions"
+	mediaEntity "github.com/aeroideaservices/focus/media/plugin/entity"
+	"github.com/aeroideaservices/focus/page/plugin/entity"
+	"github.com/aeroideaservices/focus/page/plugin/services/jobs"
+	"github.com/google/uuid"
+)
+
+const (
+	VideoJobSamples   = "video.samples"   // VideoJobSamples создание превью видео
+	VideoJobSubtitles = "video.subtitles" // VideoJobSubtitles распознавание речи и сохранение субтитров
+	VideoJobStream    = "video.stream"    // VideoJobStream формирование потока HLS
+
+	videoJobMaxAttempts = 5
+	defaultJobsLimit    = 20
+)
+
+type JobFilter struct {
+	Status entity.JobStatus `form:"status" validate:"omitempty,oneof=queued running succeeded failed"`
+	Type   string           `form:"type"`
+	Limit  int              `form:"limit" validate:"omitempty,min=1,max=100"`
+	Offset int              `form:"offset" validate:"omitempty,min=0"`
+}
+
+type JobDto struct {
+	ID          uuid.UUID        `json:"id"`
+	Type        string           `json:"type"`
+	Status      entity.JobStatus `json:"status"`
+	Payload     json.RawMessage  `json:"payload,omitempty"`
+	Result      json.RawMessage  `json:"result,omitempty"`
+	Attempts    int              `json:"attempts"`
+	MaxAttempts int              `json:"maxAttempts"`
+	RunAt       time.Time        `json:"runAt"`
+	LastError   string           `json:"lastError,omitempty"`
+	CreatedAt   time.Time        `json:"createdAt"`
+	UpdatedAt   time.Time        `json:"updatedAt"`
+	FinishedAt  *time.Time       `json:"finishedAt,omitempty"`
+}
+
+type JobList struct {
+	Total int64    `json:"total"`
+	Items []JobDto `json:"items"`
+}
+
+// VideoJobs идентификаторы задач обработки загруженного видео
+type VideoJobs struct {
+	Samples   uuid.UUID `json:"samples"`
+	Subtitles uuid.UUID `json:"subtitles"`
+	Stream    uuid.UUID `json:"stream"`
+}
+
+// VideoJobPayload параметры задачи обработки видео
+type VideoJobPayload struct {
+	MediaId  uuid.UUID  `json:"mediaId"`
+	FolderId *uuid.UUID `json:"folderId,omitempty"`
+	Filename string     `json:"filename,omitempty"`
+}
+
+// VideoSamplesResult результат задачи создания превью видео
+type VideoSamplesResult struct {
+	PreviewId        uuid.UUID `json:"videoPreviewId"`
+	PreviewBlurredId uuid.UUID `json:"videoPreviewBlurId"`
+}
+
+func newVideoJob(jobType string, payload VideoJobPayload) (entity.Job, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return entity.Job{}, err
+	}
+
+	return entity.Job{
+		ID:          uuid.New(),
+		Type:        jobType,
+		Payload:     data,
+		Status:      entity.JobStatusQueued,
+		MaxAttempts: videoJobMaxAttempts,
+		RunAt:       time.Now(),
+	}, nil
+}
+
+// RegisterJobHandlers регистрация обработчиков задач обработки видео
+func (uc VideoUseCase) RegisterJobHandlers(runner *jobs.Runner) {
+	runner.Register(VideoJobSamples, uc.handleSamplesJob)
+	runner.Register(VideoJobSubtitles, uc.handleSubtitlesJob)
+	runner.Register(VideoJobStream, uc.handleStreamJob)
+}
+
+// GetJob получение задачи обработки видео
+func (uc VideoUseCase) GetJob(ctx context.Context, id uuid.UUID) (*JobDto, error) {
+	job, err := uc.jobs.GetById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	dto := jobDto(*job)
+	return &dto, nil
+}
+
+// GetJobs получение списка задач, по умолчанию задач в статусе failed
+func (uc VideoUseCase) GetJobs(ctx context.Context, filter JobFilter) (*JobList, error) {
+	if filter.Status == "" {
+		filter.Status = entity.JobStatusFailed
+	}
+	if filter.Limit == 0 {
+		filter.Limit = defaultJobsLimit
+	}
+
+	list, total, err := uc.jobs.GetList(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]JobDto, len(list))
+	for i, job := range list {
+		items[i] = jobDto(job)
+	}
+
+	return &JobList{Total: total, Items: items}, nil
+}
+
+// RetryJob повторная постановка в очередь задачи в статусе failed
+func (uc VideoUseCase) RetryJob(ctx context.Context, id uuid.UUID) error {
+	return uc.jobs.Retry(ctx, id)
+}
+
+func (uc VideoUseCase) handleSamplesJob(ctx context.Context, job entity.Job) ([]byte, error) {
+	payload, err := videoJobPayload(job)
+	if err != nil {
+		return nil, err
+	}
+
+	fileName, err := uc.medias.Download(ctx, mediaActions.GetMedia{Id: payload.MediaId})
+	if fileName != "" {
+		defer os.Remove(fileName)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	uc.logger.Debug("Creating video samples", "mediaId", payload.MediaId)
+	videoSamples, err := uc.createVideoSamples(fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	fileTitle := strings.TrimSuffix(payload.Filename, filepath.Ext(payload.Filename))
+	ids, err := uc.medias.UploadList(
+		ctx, mediaActions.CreateMediasList{
+			FolderId: payload.FolderId,
+			Files: []mediaActions.MediaFile{
+				{
+					Filename: fileTitle + "_preview" + ".jpg",
+					Size:     videoSamples.Preview.Size(),
+					File:     videoSamples.Preview,
+				},
+				{
+					Filename: fileTitle + "_preview_blurred" + ".jpg",
+					Size:     videoSamples.PreviewBlurred.Size(),
+					File:     videoSamples.PreviewBlurred,
+				},
+			},
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) != 2 {
+		return nil, fmt.Errorf("error uploading medias")
+	}
+
+	return json.Marshal(VideoSamplesResult{
+		PreviewId:        ids[0],
+		PreviewBlurredId: ids[1],
+	})
+}
+
+func (uc VideoUseCase) handleSubtitlesJob(ctx context.Context, job entity.Job) ([]byte, error) {
+	payload, err := videoJobPayload(job)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, subtitlesTimeout)
+	defer cancel()
+
+	return nil, uc.generateSubtitles(ctx, payload.MediaId)
+}
+
+func (uc VideoUseCase) handleStreamJob(ctx context.Context, job entity.Job) ([]byte, error) {
+	payload, err := videoJobPayload(job)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, streamTimeout)
+	defer cancel()
+
+	err = uc.generateStream(ctx, payload.MediaId)
+	if err == nil {
+		return nil, nil
+	}
+
+	// статус потока отражает последнюю попытку, при повторе поток снова переходит в processing
+	statusErr := uc.medias.UpdateStreamStatus(context.WithoutCancel(ctx), mediaActions.UpdateMediaStreamStatus{
+		Id:     payload.MediaId,
+		Status: mediaEntity.StreamStatusFailed,
+		Error:  err.Error(),
+	})
+	if statusErr != nil {
+		uc.logger.Errorw("Error updating video stream status", "mediaId", payload.MediaId, "error", statusErr)
+	}
+
+	return nil, err
+}
+
+func videoJobPayload(job entity.Job) (VideoJobPayload, error) {
+	payload := VideoJobPayload{}
+	err := json.Unmarshal(job.Payload, &payload)
+	if err != nil {
+		return payload, jobs.Permanent(fmt.Errorf("invalid job payload: %w", err))
+	}
+
+	return payload, nil
+}
+
+func jobDto(job entity.Job) JobDto {
+	return JobDto{
+		ID:          job.ID,
+		Type:        job.Type,
+		Status:      job.Status,
+		Payload:     job.Payload,
+		Result:      job.Result,
+		Attempts:    job.Attempts,
+		MaxAttempts: job.MaxAttempts,
+		RunAt:       job.RunAt,
+		LastError:   job.LastError,
+		CreatedAt:   job.CreatedAt,
+		UpdatedAt:   job.UpdatedAt,
+		FinishedAt:  job.FinishedAt,
+	}
+}
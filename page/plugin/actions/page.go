@@ -3,26 +3,37 @@ package actions
 import (
 	"context"
 	"github.com/aeroideaservices/focus/page/plugin/entity"
+	"github.com/aeroideaservices/focus/services/errors"
 	"github.com/google/uuid"
 	"go.uber.org/zap"
+	"strings"
+)
+
+var (
+	ErrPageMaxDepthExceeded = errors.BadRequest.New("the maximum depth level for pages has been reached").T("page.max-depth-exceeded")
+	ErrPageCycle            = errors.BadRequest.New("page cannot be moved into itself or its descendant").T("page.cycle")
+	ErrPageHasChildren      = errors.Conflict.New("page has child pages").T("page.has-children")
+	ErrPageCodeHasSeparator = errors.BadRequest.New("page code cannot contain path separator").T("page.code-separator")
 )
 
 type PageUseCase struct {
 	pageRepository    PageRepository
 	galleryRepository GalleryRepository
 	galleryUseCase    GalleryUseCase
+	maxPageDepth      int // maxPageDepth максимальная вложенность страниц, 0 - без ограничения
 	copierService     CopierInterface
 	logger            *zap.SugaredLogger
 }
 
 func NewPageUseCase(
 	pageRepository PageRepository, galleryRepository GalleryRepository, galleryUseCase GalleryUseCase,
-	copierService CopierInterface, logger *zap.SugaredLogger,
+	maxPageDepth int, copierService CopierInterface, logger *zap.SugaredLogger,
 ) *PageUseCase {
 	return &PageUseCase{
 		pageRepository:    pageRepository,
 		galleryRepository: galleryRepository,
 		galleryUseCase:    galleryUseCase,
+		maxPageDepth:      maxPageDepth,
 		copierService:     copierService,
 		logger:            logger,
 	}
@@ -47,10 +58,23 @@ func (uc PageUseCase) GetById(ctx context.Context, dto GetPageDto) (*PageDto, er
 
 func (uc PageUseCase) Create(ctx context.Context, dto *CreatePageRequest) (*CreatePageResponse, error) {
 	uc.logger.Debug("Creating page")
-	pageEntity, err := uc.GetPageFromDto(*dto)
+	pageEntity, err := uc.GetPageFromDto(ctx, *dto)
 	if err != nil {
 		return nil, err
 	}
+
+	pageEntity.Path = pageEntity.Code
+	if dto.ParentID != nil {
+		parent, err := uc.pageRepository.GetByIdWithoutAssociate(ctx, *dto.ParentID)
+		if err != nil {
+			return nil, err
+		}
+		if uc.maxPageDepth != 0 && parent.Depth() >= uc.maxPageDepth {
+			return nil, ErrPageMaxDepthExceeded
+		}
+		pageEntity.Path = entity.PagePath(parent.FullPath(), pageEntity.Code)
+	}
+
 	//pageEntity.ID = uuid.New()
 	pageId, err := uc.pageRepository.Create(ctx, pageEntity)
 	if err != nil {
@@ -69,6 +93,15 @@ func (uc PageUseCase) Delete(ctx context.Context, pageId uuid.UUID) error {
 	if err != nil {
 		return err
 	}
+
+	hasChildren, err := uc.pageRepository.HasChildren(ctx, pageId)
+	if err != nil {
+		return err
+	}
+	if hasChildren {
+		return ErrPageHasChildren
+	}
+
 	err = uc.pageRepository.Delete(ctx, pageId)
 	if err != nil {
 		return err
@@ -96,9 +129,42 @@ func (uc PageUseCase) PatchGalleryPosition(ctx context.Context, dto *PatchGaller
 	return nil
 }
 
+// ReorderGalleries установка полного порядка галерей страницы.
+// Список должен содержать все привязанные галереи ровно по одному разу, иначе возвращается конфликт.
+func (uc PageUseCase) ReorderGalleries(ctx context.Context, dto *ReorderGalleriesRequest) error {
+	uc.logger.Debug("Reordering page galleries")
+	_, err := uc.pageRepository.GetByIdWithoutAssociate(ctx, dto.PageID)
+	if err != nil {
+		return err
+	}
+
+	err = uc.pageRepository.ReorderGalleries(ctx, dto.PageID, dto.GalleryIDs)
+	if err != nil {
+		return err
+	}
+	uc.logger.Debug("Reordered page galleries")
+	return nil
+}
+
+// RepairPositions перенумерация позиций галерей страниц и карточек галерей в 1..n без пропусков и повторов
+func (uc PageUseCase) RepairPositions(ctx context.Context) (*RepairPositionsResponse, error) {
+	uc.logger.Debug("Repairing positions")
+	galleries, err := uc.pageRepository.RepairGalleriesPositions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	cards, err := uc.galleryRepository.RepairCardsPositions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	uc.logger.Debugf("Repaired positions: %d galleries, %d cards", galleries, cards)
+	return &RepairPositionsResponse{Galleries: galleries, Cards: cards}, nil
+}
+
 func (uc PageUseCase) GetList(ctx context.Context) (*GetPageListResponse, error) {
 	uc.logger.Debug("Getting pages list")
-	pages, err := uc.pageRepository.GetList(ctx, []string{"id", "name", "code", "sort", "is_published"}, true)
+	pages, err := uc.pageRepository.GetList(ctx, pageShortFields, true)
 	if err != nil {
 		uc.logger.Error(err.Error())
 		return nil, err
@@ -110,6 +176,97 @@ func (uc PageUseCase) GetList(ctx context.Context) (*GetPageListResponse, error)
 	return res, nil
 }
 
+var pageShortFields = []string{"id", "parent_id", "path", "name", "code", "sort", "is_published"}
+
+// GetTree получение дерева страниц, дочерние страницы в порядке сортировки
+func (uc PageUseCase) GetTree(ctx context.Context) (*GetPageTreeResponse, error) {
+	uc.logger.Debug("Getting pages tree")
+	pages, err := uc.pageRepository.GetList(ctx, pageShortFields, true)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &GetPageTreeResponse{Tree: buildPageTree(pages)}
+
+	uc.logger.Debug("Got pages tree")
+	return res, nil
+}
+
+// buildPageTree построение дерева из списка страниц.
+// Страница, родитель которой отсутствует в списке, попадает в корень дерева.
+func buildPageTree(pages []PageShort) []*PageTreeDto {
+	nodes := make(map[uuid.UUID]*PageTreeDto, len(pages))
+	for _, page := range pages {
+		nodes[page.ID] = &PageTreeDto{PageShort: page, Children: []*PageTreeDto{}}
+	}
+
+	tree := make([]*PageTreeDto, 0)
+	for _, page := range pages {
+		node := nodes[page.ID]
+		if page.ParentID != nil {
+			if parent, ok := nodes[*page.ParentID]; ok {
+				parent.Children = append(parent.Children, node)
+				continue
+			}
+		}
+		tree = append(tree, node)
+	}
+
+	return tree
+}
+
+// Move перемещение страницы вместе с вложенными страницами под другого родителя или в корень.
+// Пути перемещаемой и вложенных страниц пересчитываются.
+func (uc PageUseCase) Move(ctx context.Context, dto MovePageRequest) error {
+	uc.logger.Debug("Moving page")
+	page, err := uc.pageRepository.GetByIdWithoutAssociate(ctx, dto.ID)
+	if err != nil {
+		return err
+	}
+
+	var parentPath string
+	if dto.ParentID != nil {
+		parent, err := uc.pageRepository.GetByIdWithoutAssociate(ctx, *dto.ParentID)
+		if err != nil {
+			return err
+		}
+		parentPath = parent.FullPath()
+		if parent.ID == page.ID || strings.HasPrefix(parentPath, page.FullPath()+entity.PagePathSeparator) {
+			return ErrPageCycle
+		}
+
+		if uc.maxPageDepth != 0 {
+			descendantPaths, err := uc.pageRepository.GetDescendantPaths(ctx, page.FullPath())
+			if err != nil {
+				return err
+			}
+			if parent.Depth()+subtreeHeight(*page, descendantPaths) > uc.maxPageDepth {
+				return ErrPageMaxDepthExceeded
+			}
+		}
+	}
+
+	err = uc.pageRepository.Move(ctx, page.ID, dto.ParentID, entity.PagePath(parentPath, page.Code))
+	if err != nil {
+		return err
+	}
+
+	uc.logger.Debug("Moved page")
+	return nil
+}
+
+// subtreeHeight количество уровней в поддереве страницы, включая саму страницу
+func subtreeHeight(page entity.Page, descendantPaths []string) int {
+	height := 1
+	for _, path := range descendantPaths {
+		if h := entity.PathDepth(path) - page.Depth() + 1; h > height {
+			height = h
+		}
+	}
+
+	return height
+}
+
 func (uc PageUseCase) LinkGalleries(ctx context.Context, pageId uuid.UUID, galleryIds []uuid.UUID) (error, bool) {
 	_, err := uc.pageRepository.GetByIdWithoutAssociate(ctx, pageId)
 	if err != nil {
@@ -169,7 +326,11 @@ func (uc PageUseCase) UnlinkGalleries(ctx context.Context, pageId uuid.UUID, gal
 func (uc PageUseCase) PatchProperties(ctx context.Context, dto *PatchPageRequest) error {
 	uc.logger.Debug("Patching page")
 
-	_, err := uc.pageRepository.GetByIdWithoutAssociate(ctx, dto.ID)
+	if strings.Contains(dto.Code, entity.PagePathSeparator) {
+		return ErrPageCodeHasSeparator
+	}
+
+	current, err := uc.pageRepository.GetByIdWithoutAssociate(ctx, dto.ID)
 	if err != nil {
 		return err
 	}
@@ -178,6 +339,9 @@ func (uc PageUseCase) PatchProperties(ctx context.Context, dto *PatchPageRequest
 	if err != nil {
 		return err
 	}
+	if dto.Code != "" && dto.Code != current.Code {
+		pageEntity.Path = entity.PagePath(current.ParentPath(), dto.Code)
+	}
 	//pageEntity.ID = uuid.New()
 	err = uc.pageRepository.PatchProperties(ctx, pageEntity)
 	if err != nil {
@@ -196,7 +360,7 @@ func (uc PageUseCase) PatchProperties(ctx context.Context, dto *PatchPageRequest
 	return nil
 }
 
-func (uc PageUseCase) GetPageFromDto(pageDto CreatePageRequest) (page *entity.Page, err error) {
+func (uc PageUseCase) GetPageFromDto(ctx context.Context, pageDto CreatePageRequest) (page *entity.Page, err error) {
 	page = &entity.Page{}
 	if err != nil {
 		return nil, err
@@ -208,7 +372,7 @@ func (uc PageUseCase) GetPageFromDto(pageDto CreatePageRequest) (page *entity.Pa
 		return nil, err
 	}
 
-	galleries, err := uc.galleryUseCase.GetGalleryFromDtos(pageDto.Galleries, newPageId)
+	galleries, err := uc.galleryUseCase.GetGalleryFromDtos(ctx, pageDto.Galleries, newPageId)
 
 	page.PagesGalleries = galleries
 	page.ID = newPageId
@@ -0,0 +1,161 @@
+package actions
+
+import (
+	"context"
+	"time"
+
+	"github.com/aeroideaservices/focus/page/plugin/entity"
+	"github.com/aeroideaservices/focus/page/plugin/services/preview"
+	"github.com/aeroideaservices/focus/services/errors"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	DefaultPreviewTokenTTL = 24 * time.Hour      // DefaultPreviewTokenTTL срок действия токена предпросмотра по умолчанию
+	MaxPreviewTokenTTL     = 30 * 24 * time.Hour // MaxPreviewTokenTTL максимальный срок действия токена предпросмотра
+)
+
+type CreatePreviewTokenRequest struct {
+	EntityType    entity.DraftType `json:"-"`
+	ID            uuid.UUID        `json:"-"`
+	ExpiresIn     int              `json:"expiresIn" validate:"omitempty,min=60,max=2592000"` // ExpiresIn срок действия в секундах
+	CreatedBy     string           `json:"-"`
+	CreatedByName string           `json:"-"`
+}
+
+type RevokePreviewTokenRequest struct {
+	ID        uuid.UUID `json:"-"`
+	RevokedBy string    `json:"-"`
+}
+
+type PreviewTokenDto struct {
+	ID            uuid.UUID        `json:"id"`
+	EntityType    entity.DraftType `json:"entityType"`
+	EntityID      uuid.UUID        `json:"entityId"`
+	Token         string           `json:"token,omitempty"` // Token возвращается только при создании
+	CreatedBy     string           `json:"createdBy"`
+	CreatedByName string           `json:"createdByName"`
+	CreatedAt     time.Time        `json:"createdAt"`
+	ExpiresAt     time.Time        `json:"expiresAt"`
+	RevokedAt     *time.Time       `json:"revokedAt"`
+	RevokedBy     string           `json:"revokedBy,omitempty"`
+}
+
+// PreviewUseCase токены предпросмотра неопубликованных страниц, галерей и карточек
+type PreviewUseCase struct {
+	previewTokenRepository PreviewTokenRepository
+	publicationUseCase     PublicationUseCase
+	signer                 *preview.Signer
+	logger                 *zap.SugaredLogger
+}
+
+func NewPreviewUseCase(
+	previewTokenRepository PreviewTokenRepository, publicationUseCase PublicationUseCase, signer *preview.Signer,
+	logger *zap.SugaredLogger,
+) *PreviewUseCase {
+	return &PreviewUseCase{
+		previewTokenRepository: previewTokenRepository,
+		publicationUseCase:     publicationUseCase,
+		signer:                 signer,
+		logger:                 logger,
+	}
+}
+
+func (uc PreviewUseCase) Create(ctx context.Context, dto CreatePreviewTokenRequest) (*PreviewTokenDto, error) {
+	uc.logger.Debug("Creating preview token")
+	err := uc.publicationUseCase.checkExists(ctx, dto.EntityType, dto.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	ttl := DefaultPreviewTokenTTL
+	if dto.ExpiresIn > 0 {
+		ttl = time.Duration(dto.ExpiresIn) * time.Second
+	}
+	if ttl > MaxPreviewTokenTTL {
+		ttl = MaxPreviewTokenTTL
+	}
+
+	token := &entity.PreviewToken{
+		ID:            uuid.New(),
+		EntityType:    dto.EntityType,
+		EntityID:      dto.ID,
+		CreatedBy:     dto.CreatedBy,
+		CreatedByName: dto.CreatedByName,
+		ExpiresAt:     time.Now().Add(ttl).Truncate(time.Second),
+	}
+	err = uc.previewTokenRepository.Create(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	tokenDto := previewTokenDto(*token)
+	tokenDto.Token = uc.signer.Sign(token.ID, token.ExpiresAt)
+
+	uc.logger.Debug("Created preview token")
+	return &tokenDto, nil
+}
+
+// GetList выданные токены сущности, включая отозванные и просроченные
+func (uc PreviewUseCase) GetList(ctx context.Context, dto PublicationRequest) ([]PreviewTokenDto, error) {
+	uc.logger.Debug("Getting preview tokens")
+	tokens, err := uc.previewTokenRepository.GetList(ctx, dto.EntityType, dto.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]PreviewTokenDto, len(tokens))
+	for i, token := range tokens {
+		list[i] = previewTokenDto(token)
+	}
+
+	uc.logger.Debug("Got preview tokens")
+	return list, nil
+}
+
+func (uc PreviewUseCase) Revoke(ctx context.Context, dto RevokePreviewTokenRequest) error {
+	uc.logger.Debug("Revoking preview token")
+	err := uc.previewTokenRepository.Revoke(ctx, dto.ID, dto.RevokedBy)
+	if err != nil {
+		return err
+	}
+
+	uc.logger.Debug("Revoked preview token")
+	return nil
+}
+
+// Resolve проверка токена предпросмотра: подписи, срока действия и отзыва
+func (uc PreviewUseCase) Resolve(ctx context.Context, token string) (*entity.PreviewToken, error) {
+	id, err := uc.signer.Verify(token, time.Now())
+	if err != nil {
+		return nil, errors.Unauthorized.Wrap(err, "invalid preview token")
+	}
+
+	previewToken, err := uc.previewTokenRepository.GetById(ctx, id)
+	if errors.GetType(err) == errors.NotFound {
+		return nil, errors.Unauthorized.Wrap(err, "invalid preview token")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if previewToken.RevokedAt != nil {
+		return nil, errors.Unauthorized.New("preview token revoked")
+	}
+
+	return previewToken, nil
+}
+
+func previewTokenDto(token entity.PreviewToken) PreviewTokenDto {
+	return PreviewTokenDto{
+		ID:            token.ID,
+		EntityType:    token.EntityType,
+		EntityID:      token.EntityID,
+		CreatedBy:     token.CreatedBy,
+		CreatedByName: token.CreatedByName,
+		CreatedAt:     token.CreatedAt,
+		ExpiresAt:     token.ExpiresAt,
+		RevokedAt:     token.RevokedAt,
+		RevokedBy:     token.RevokedBy,
+	}
+}
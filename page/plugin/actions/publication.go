@@ -0,0 +1,575 @@
+package actions
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/aeroideaservices/focus/page/plugin/entity"
+	"github.com/aeroideaservices/focus/services/errors"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// DefaultPublicationInterval интервал проверки запланированных публикаций по умолчанию
+const DefaultPublicationInterval = time.Minute
+
+// ErrDraftEdits изменение опубликованной сущности в обход черновика в режиме редактирования через черновики
+var ErrDraftEdits = errors.Conflict.New("entity is edited through drafts, save changes to draft").
+	T("publication.draft-edits")
+
+// ErrPublishedDelete удаление опубликованной сущности в режиме редактирования через черновики
+var ErrPublishedDelete = errors.Conflict.New("entity is published, unpublish it before deleting").
+	T("publication.published-delete")
+
+// draftEditKeep поля черновика, которые сохраняются при изменении сущности через SaveEdit, nil - все поля.
+// Свойства страницы изменяются частично, галерея и карточка - целиком, кроме состава карточек галереи.
+var draftEditKeep = map[entity.DraftType][]string{
+	entity.DraftTypeGallery: {"cardIds"},
+	entity.DraftTypeCard:    {},
+}
+
+type PublicationRequest struct {
+	EntityType entity.DraftType `json:"-"`
+	ID         uuid.UUID        `json:"-"`
+}
+
+type SaveDraftRequest struct {
+	EntityType entity.DraftType `json:"-"`
+	ID         uuid.UUID        `json:"-"`
+	Data       json.RawMessage  `json:"data" validate:"required"` // Data тело запроса на изменение сущности
+}
+
+// PageDraft черновик страницы: изменения свойств и, если задан, полный состав галерей в порядке вывода
+type PageDraft struct {
+	PatchPageRequest
+	GalleryIDs *[]uuid.UUID `json:"galleryIds,omitempty"`
+}
+
+// GalleryDraft черновик галереи: изменения свойств и, если задан, полный состав карточек в порядке вывода
+type GalleryDraft struct {
+	UpdateGalleryRequest
+	CardIDs *[]uuid.UUID `json:"cardIds,omitempty"`
+}
+
+type ScheduleRequest struct {
+	EntityType  entity.DraftType `json:"-"`
+	ID          uuid.UUID        `json:"-"`
+	PublishAt   *time.Time       `json:"publishAt"`
+	UnpublishAt *time.Time       `json:"unpublishAt"`
+}
+
+type DraftDto struct {
+	EntityType entity.DraftType `json:"entityType"`
+	EntityID   uuid.UUID        `json:"entityId"`
+	Data       json.RawMessage  `json:"data"`
+	CreatedAt  time.Time        `json:"createdAt"`
+	UpdatedAt  time.Time        `json:"updatedAt"`
+}
+
+// ScheduledPublication запланированное изменение статуса публикации, время которого наступило
+type ScheduledPublication struct {
+	EntityType entity.DraftType
+	ID         uuid.UUID
+	Publish    bool // Publish true - публикация, false - снятие с публикации
+}
+
+// PublicationUseCase черновики и публикация страниц, галерей и карточек.
+// Изменения, сохраненные в черновик, применяются к сущности при публикации в одной транзакции.
+// После публикации снимаются снимки страниц, содержимое которых она изменила.
+//
+// По умолчанию включен режим редактирования через черновики (focus.page.draftEdits): изменения свойств
+// сохраняются в черновик методом SaveEdit, остальные изменения опубликованного содержимого запрещены,
+// а опубликованные страницы и карточки удаляются только после снятия с публикации.
+// Копии создаются неопубликованными и опубликованные данные не меняют.
+// Если focus.page.draftEdits равен false, обычные методы изменения меняют опубликованные данные,
+// а черновики используются по желанию клиента.
+type PublicationUseCase struct {
+	publicationRepository PublicationRepository
+	pageRepository        PageRepository
+	galleryRepository     GalleryRepository
+	cardRepository        CardRepository
+	pageUseCase           PageUseCase
+	galleryUseCase        GalleryUseCase
+	cardUseCase           CardUseCase
+	snapshotUseCase       *SnapshotUseCase
+	transactor            Transactor
+	logger                *zap.SugaredLogger
+}
+
+func NewPublicationUseCase(
+	publicationRepository PublicationRepository, pageRepository PageRepository, galleryRepository GalleryRepository,
+	cardRepository CardRepository, pageUseCase PageUseCase, galleryUseCase GalleryUseCase, cardUseCase CardUseCase,
+	snapshotUseCase *SnapshotUseCase, transactor Transactor, logger *zap.SugaredLogger,
+) *PublicationUseCase {
+	return &PublicationUseCase{
+		publicationRepository: publicationRepository,
+		pageRepository:        pageRepository,
+		galleryRepository:     galleryRepository,
+		cardRepository:        cardRepository,
+		pageUseCase:           pageUseCase,
+		galleryUseCase:        galleryUseCase,
+		cardUseCase:           cardUseCase,
+		snapshotUseCase:       snapshotUseCase,
+		transactor:            transactor,
+		logger:                logger,
+	}
+}
+
+func (uc PublicationUseCase) GetDraft(ctx context.Context, dto PublicationRequest) (*DraftDto, error) {
+	uc.logger.Debug("Getting draft")
+	draft, err := uc.publicationRepository.GetDraft(ctx, dto.EntityType, dto.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	uc.logger.Debug("Got draft")
+	return &DraftDto{
+		EntityType: draft.EntityType,
+		EntityID:   draft.EntityID,
+		Data:       draft.Data,
+		CreatedAt:  draft.CreatedAt,
+		UpdatedAt:  draft.UpdatedAt,
+	}, nil
+}
+
+// SaveDraft сохранение изменений в черновик без изменения опубликованной сущности.
+// Статус публикации из черновика не применяется, он меняется только публикацией и снятием с публикации.
+func (uc PublicationUseCase) SaveDraft(ctx context.Context, dto SaveDraftRequest) error {
+	uc.logger.Debug("Saving draft")
+	err := uc.checkExists(ctx, dto.EntityType, dto.ID)
+	if err != nil {
+		return err
+	}
+
+	request, err := uc.decodeDraft(dto.EntityType, dto.ID, dto.Data)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+
+	err = uc.publicationRepository.SaveDraft(ctx, &entity.Draft{
+		EntityType: dto.EntityType,
+		EntityID:   dto.ID,
+		Data:       data,
+	})
+	if err != nil {
+		return err
+	}
+
+	uc.logger.Debug("Saved draft")
+	return nil
+}
+
+// SaveEdit сохранение изменения сущности в черновик в режиме редактирования через черновики.
+// Изменение дополняет уже сохраненный черновик: поля draftEditKeep черновика сохраняются, остальные заменяются.
+func (uc PublicationUseCase) SaveEdit(ctx context.Context, dto SaveDraftRequest) error {
+	draft, err := uc.publicationRepository.GetDraft(ctx, dto.EntityType, dto.ID)
+	if err != nil && errors.GetType(err) != errors.NotFound {
+		return err
+	}
+	if draft != nil {
+		dto.Data, err = mergeDraftData(draft.Data, dto.Data, draftEditKeep[dto.EntityType])
+		if err != nil {
+			return err
+		}
+	}
+
+	return uc.SaveDraft(ctx, dto)
+}
+
+func (uc PublicationUseCase) DeleteDraft(ctx context.Context, dto PublicationRequest) error {
+	uc.logger.Debug("Deleting draft")
+	err := uc.publicationRepository.DeleteDraft(ctx, dto.EntityType, dto.ID)
+	if err != nil {
+		return err
+	}
+
+	uc.logger.Debug("Deleted draft")
+	return nil
+}
+
+// Publish применение черновика (если есть) и публикация сущности
+func (uc PublicationUseCase) Publish(ctx context.Context, dto PublicationRequest) error {
+	uc.logger.Debug("Publishing")
+	err := uc.checkExists(ctx, dto.EntityType, dto.ID)
+	if err != nil {
+		return err
+	}
+
+	// черновик применяется целиком или не применяется совсем и удаляется вместе с публикацией
+	err = uc.transactor.Transaction(ctx, func(ctx context.Context) error {
+		draft, err := uc.publicationRepository.GetDraft(ctx, dto.EntityType, dto.ID)
+		if err != nil && errors.GetType(err) != errors.NotFound {
+			return err
+		}
+		if draft != nil {
+			err = uc.applyDraft(ctx, *draft)
+			if err != nil {
+				return err
+			}
+		}
+
+		err = uc.publicationRepository.UpdatePublish(ctx, dto.EntityType, dto.ID, true)
+		if err != nil || draft == nil {
+			return err
+		}
+
+		return uc.publicationRepository.DeleteDraft(ctx, dto.EntityType, dto.ID)
+	})
+	if err != nil {
+		return err
+	}
+
+	// публикация уже выполнена, поэтому ошибка снимка ее не отменяет
+	err = uc.snapshotUseCase.TakeAfterPublish(ctx, dto.EntityType, dto.ID)
+	if err != nil {
+		uc.logger.Errorw("Error taking page snapshot", "entityType", dto.EntityType, "id", dto.ID, "error", err)
+	}
+
+	uc.logger.Debug("Published")
+	return nil
+}
+
+func (uc PublicationUseCase) Unpublish(ctx context.Context, dto PublicationRequest) error {
+	uc.logger.Debug("Unpublishing")
+	err := uc.checkExists(ctx, dto.EntityType, dto.ID)
+	if err != nil {
+		return err
+	}
+
+	err = uc.publicationRepository.UpdatePublish(ctx, dto.EntityType, dto.ID, false)
+	if err != nil {
+		return err
+	}
+
+	uc.logger.Debug("Unpublished")
+	return nil
+}
+
+// Schedule планирование публикации и снятия с публикации, nil отменяет запланированное действие
+func (uc PublicationUseCase) Schedule(ctx context.Context, dto ScheduleRequest) error {
+	uc.logger.Debug("Scheduling publication")
+	if dto.PublishAt != nil && dto.UnpublishAt != nil && !dto.UnpublishAt.After(*dto.PublishAt) {
+		return errors.BadRequest.New("unpublishAt must be after publishAt")
+	}
+
+	err := uc.checkExists(ctx, dto.EntityType, dto.ID)
+	if err != nil {
+		return err
+	}
+
+	err = uc.publicationRepository.Schedule(ctx, dto.EntityType, dto.ID, dto.PublishAt, dto.UnpublishAt)
+	if err != nil {
+		return err
+	}
+
+	uc.logger.Debug("Scheduled publication")
+	return nil
+}
+
+// PublishScheduled выполнение запланированных публикаций, время которых наступило.
+// Ошибка одной сущности не прерывает обработку остальных.
+func (uc PublicationUseCase) PublishScheduled(ctx context.Context, now time.Time) error {
+	scheduled, err := uc.publicationRepository.GetScheduled(ctx, now)
+	if err != nil {
+		return err
+	}
+
+	for _, publication := range scheduled {
+		dto := PublicationRequest{EntityType: publication.EntityType, ID: publication.ID}
+		if publication.Publish {
+			err = uc.Publish(ctx, dto)
+		} else {
+			err = uc.Unpublish(ctx, dto)
+		}
+		if err != nil {
+			uc.logger.Errorw(
+				"Error executing scheduled publication",
+				"entityType", publication.EntityType, "id", publication.ID, "publish", publication.Publish, "error", err,
+			)
+		}
+	}
+
+	return nil
+}
+
+// CheckUnpublished проверка, что сущность не опубликована и ее удаление не изменит опубликованные данные
+func (uc PublicationUseCase) CheckUnpublished(ctx context.Context, dto PublicationRequest) error {
+	var published bool
+	switch dto.EntityType {
+	case entity.DraftTypePage:
+		page, err := uc.pageRepository.GetByIdWithoutAssociate(ctx, dto.ID)
+		if err != nil {
+			return err
+		}
+		published = page.IsPublished
+	case entity.DraftTypeGallery:
+		gallery, err := uc.galleryRepository.GetByIdWithoutAssociate(ctx, dto.ID)
+		if err != nil {
+			return err
+		}
+		published = gallery.IsPublished
+	case entity.DraftTypeCard:
+		card, err := uc.cardRepository.GetByIdWithoutAssociate(ctx, dto.ID)
+		if err != nil {
+			return err
+		}
+		published = card.IsPublished
+	default:
+		return errors.BadRequest.Newf("unknown entity type %s", dto.EntityType)
+	}
+	if published {
+		return ErrPublishedDelete
+	}
+
+	return nil
+}
+
+func (uc PublicationUseCase) checkExists(ctx context.Context, entityType entity.DraftType, id uuid.UUID) error {
+	var err error
+	switch entityType {
+	case entity.DraftTypePage:
+		_, err = uc.pageRepository.GetByIdWithoutAssociate(ctx, id)
+	case entity.DraftTypeGallery:
+		_, err = uc.galleryRepository.GetByIdWithoutAssociate(ctx, id)
+	case entity.DraftTypeCard:
+		_, err = uc.cardRepository.GetByIdWithoutAssociate(ctx, id)
+	default:
+		err = errors.BadRequest.Newf("unknown entity type %s", entityType)
+	}
+
+	return err
+}
+
+// decodeDraft разбор черновика в запрос на изменение сущности соответствующего типа
+func (uc PublicationUseCase) decodeDraft(entityType entity.DraftType, id uuid.UUID, data json.RawMessage) (
+	interface{}, error,
+) {
+	var request interface{}
+	switch entityType {
+	case entity.DraftTypePage:
+		request = &PageDraft{}
+	case entity.DraftTypeGallery:
+		request = &GalleryDraft{}
+	case entity.DraftTypeCard:
+		request = &UpdateCardRequest{}
+	default:
+		return nil, errors.BadRequest.Newf("unknown entity type %s", entityType)
+	}
+
+	err := json.Unmarshal(data, request)
+	if err != nil {
+		return nil, errors.BadRequest.Wrap(err, "error decoding draft")
+	}
+
+	switch request := request.(type) {
+	case *PageDraft:
+		request.ID, request.IsPublished = id, nil
+	case *GalleryDraft:
+		request.ID, request.IsPublished = id, nil
+	case *UpdateCardRequest:
+		request.ID, request.IsPublished = id, nil
+	}
+
+	return request, nil
+}
+
+func (uc PublicationUseCase) applyDraft(ctx context.Context, draft entity.Draft) error {
+	request, err := uc.decodeDraft(draft.EntityType, draft.EntityID, draft.Data)
+	if err != nil {
+		return err
+	}
+
+	switch request := request.(type) {
+	case *PageDraft:
+		err = uc.pageUseCase.PatchProperties(ctx, &request.PatchPageRequest)
+		if err != nil || request.GalleryIDs == nil {
+			return err
+		}
+		return uc.applyPageGalleries(ctx, request.ID, *request.GalleryIDs)
+	case *GalleryDraft:
+		err = uc.galleryUseCase.Update(ctx, &request.UpdateGalleryRequest)
+		if err != nil || request.CardIDs == nil {
+			return err
+		}
+		return uc.applyGalleryCards(ctx, request.ID, *request.CardIDs)
+	case *UpdateCardRequest:
+		_, err = uc.cardUseCase.Update(ctx, request)
+		return err
+	}
+
+	return nil
+}
+
+// applyPageGalleries приведение состава и порядка галерей страницы к черновику
+func (uc PublicationUseCase) applyPageGalleries(ctx context.Context, pageID uuid.UUID, galleryIDs []uuid.UUID) error {
+	page, err := uc.pageRepository.GetById(ctx, pageID)
+	if err != nil {
+		return err
+	}
+
+	current := make([]uuid.UUID, 0, len(page.PagesGalleries))
+	for _, pageGallery := range page.PagesGalleries {
+		if pageGallery.GalleryID != nil {
+			current = append(current, *pageGallery.GalleryID)
+		}
+	}
+
+	unlink, link := diffIds(current, galleryIDs)
+	if len(unlink) != 0 {
+		if err = uc.pageUseCase.UnlinkGalleries(ctx, pageID, unlink); err != nil {
+			return err
+		}
+	}
+	if len(link) != 0 {
+		if err, _ = uc.pageUseCase.LinkGalleries(ctx, pageID, link); err != nil {
+			return err
+		}
+	}
+
+	return uc.pageUseCase.ReorderGalleries(ctx, &ReorderGalleriesRequest{PageID: pageID, GalleryIDs: galleryIDs})
+}
+
+// applyGalleryCards приведение состава и порядка карточек галереи к черновику
+func (uc PublicationUseCase) applyGalleryCards(ctx context.Context, galleryID uuid.UUID, cardIDs []uuid.UUID) error {
+	gallery, err := uc.galleryRepository.GetById(ctx, galleryID)
+	if err != nil {
+		return err
+	}
+
+	current := make([]uuid.UUID, 0, len(gallery.GalleriesCards))
+	for _, galleryCard := range gallery.GalleriesCards {
+		if galleryCard.CardID != nil {
+			current = append(current, *galleryCard.CardID)
+		}
+	}
+
+	unlink, link := diffIds(current, cardIDs)
+	if len(unlink) != 0 {
+		if err = uc.galleryUseCase.UnlinkCards(ctx, galleryID, unlink); err != nil {
+			return err
+		}
+	}
+	if len(link) != 0 {
+		if err, _ = uc.galleryUseCase.LinkCards(ctx, galleryID, link); err != nil {
+			return err
+		}
+	}
+
+	return uc.galleryUseCase.ReorderCards(ctx, &ReorderCardsRequest{GalleryID: galleryID, CardIDs: cardIDs})
+}
+
+// mergeDraftData поля data поверх полей черновика draft, из полей черновика сохраняются только keep, nil - все
+func mergeDraftData(draft, data json.RawMessage, keep []string) (json.RawMessage, error) {
+	var changes map[string]json.RawMessage
+	if err := json.Unmarshal(data, &changes); err != nil {
+		return nil, errors.BadRequest.Wrap(err, "error decoding draft")
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(draft, &fields); err != nil {
+		return nil, errors.NoType.Wrap(err, "error decoding draft")
+	}
+	if fields == nil || keep != nil {
+		kept := make(map[string]json.RawMessage, len(keep))
+		for _, field := range keep {
+			if value, ok := fields[field]; ok {
+				kept[field] = value
+			}
+		}
+		fields = kept
+	}
+
+	for field, value := range changes {
+		fields[field] = value
+	}
+
+	return json.Marshal(fields)
+}
+
+// diffIds идентификаторы, которых нет в wanted, и идентификаторы wanted, которых нет в current
+func diffIds(current, wanted []uuid.UUID) (removed, added []uuid.UUID) {
+	inCurrent := make(map[uuid.UUID]bool, len(current))
+	for _, id := range current {
+		inCurrent[id] = true
+	}
+	inWanted := make(map[uuid.UUID]bool, len(wanted))
+	for _, id := range wanted {
+		inWanted[id] = true
+		if !inCurrent[id] {
+			added = append(added, id)
+		}
+	}
+	for _, id := range current {
+		if !inWanted[id] {
+			removed = append(removed, id)
+		}
+	}
+
+	return removed, added
+}
+
+// PublicationScheduler периодическое выполнение запланированных публикаций.
+// Публикация идемпотентна, поэтому планировщик может работать в нескольких экземплярах приложения.
+type PublicationScheduler struct {
+	publicationUseCase *PublicationUseCase
+	interval           time.Duration
+	logger             *zap.SugaredLogger
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewPublicationScheduler(
+	publicationUseCase *PublicationUseCase, interval time.Duration, logger *zap.SugaredLogger,
+) *PublicationScheduler {
+	if interval <= 0 {
+		interval = DefaultPublicationInterval
+	}
+
+	return &PublicationScheduler{
+		publicationUseCase: publicationUseCase,
+		interval:           interval,
+		logger:             logger,
+	}
+}
+
+// Start запуск планировщика в фоне
+func (s *PublicationScheduler) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+		for {
+			err := s.publicationUseCase.PublishScheduled(ctx, time.Now())
+			if err != nil && ctx.Err() == nil {
+				s.logger.Errorw("Error publishing scheduled", "error", err)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop остановка планировщика с ожиданием завершения текущей проверки
+func (s *PublicationScheduler) Stop() {
+	if s.cancel != nil {
+		s.cancel()
+	}
+	s.wg.Wait()
+}
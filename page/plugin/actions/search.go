@@ -0,0 +1,78 @@
+package actions
+
+import (
+	"context"
+	"strings"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const defaultSearchLimit = 20
+
+// Типы сущностей в результатах поиска
+const (
+	SearchTypePage    = "page"
+	SearchTypeGallery = "gallery"
+	SearchTypeCard    = "card"
+	SearchTypeTag     = "tag"
+)
+
+// SearchRequest полнотекстовый поиск по страницам, галереям, карточкам и тегам.
+// Query разбирается как поисковая строка: слова, фразы в кавычках, or и исключение через минус.
+type SearchRequest struct {
+	Query  string   `form:"query" validate:"required,min=2,max=200"`
+	Types  []string `form:"types" validate:"omitempty,unique,dive,oneof=page gallery card tag"`
+	Limit  int      `form:"limit" validate:"omitempty,min=1,max=100"`
+	Offset int      `form:"offset" validate:"omitempty,min=0"`
+}
+
+// SearchResult найденная сущность.
+// Highlight - фрагменты найденного текста, совпадения выделены тегом mark, остальной текст экранирован.
+type SearchResult struct {
+	EntityType  string    `json:"entityType"`
+	ID          uuid.UUID `json:"id"`
+	Code        string    `json:"code"` // Code код сущности, для страницы - полный путь, для тега - ссылка
+	Title       string    `json:"title"`
+	Highlight   string    `json:"highlight"`
+	Rank        float64   `json:"rank"`
+	IsPublished bool      `json:"isPublished"`
+}
+
+type SearchResponse struct {
+	Total int64          `json:"total"`
+	Items []SearchResult `json:"items"`
+}
+
+// SearchUseCase поиск содержимого для конструктора страниц
+type SearchUseCase struct {
+	searchRepository SearchRepository
+	logger           *zap.SugaredLogger
+}
+
+func NewSearchUseCase(searchRepository SearchRepository, logger *zap.SugaredLogger) *SearchUseCase {
+	return &SearchUseCase{
+		searchRepository: searchRepository,
+		logger:           logger,
+	}
+}
+
+// Search поиск с сортировкой по релевантности и постраничной выборкой
+func (uc SearchUseCase) Search(ctx context.Context, dto SearchRequest) (*SearchResponse, error) {
+	uc.logger.Debug("Searching content")
+	dto.Query = strings.TrimSpace(dto.Query)
+	if dto.Limit == 0 {
+		dto.Limit = defaultSearchLimit
+	}
+
+	items, total, err := uc.searchRepository.Search(ctx, dto)
+	if err != nil {
+		return nil, err
+	}
+	if items == nil {
+		items = []SearchResult{}
+	}
+
+	uc.logger.Debug("Searched content")
+	return &SearchResponse{Total: total, Items: items}, nil
+}
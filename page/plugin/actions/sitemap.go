@@ -0,0 +1,43 @@
+package actions
+
+import (
+	"context"
+	"path"
+
+	sitemapEntity "github.com/aeroideaservices/focus/sitemap/plugin/entity"
+)
+
+// SitemapSource опубликованные страницы для карты сайта.
+// Адрес страницы строится из ее полного пути, страницы выводятся на всех доменах.
+type SitemapSource struct {
+	pageRepository PageRepository
+	basePath       string // basePath путь на сайте, от которого строятся адреса страниц
+}
+
+func NewSitemapSource(pageRepository PageRepository, basePath string) *SitemapSource {
+	return &SitemapSource{
+		pageRepository: pageRepository,
+		basePath:       basePath,
+	}
+}
+
+// sitemapPageFields поля страниц для карты сайта, время изменения выводится в lastmod
+var sitemapPageFields = []string{"id", "path", "code", "updated_at"}
+
+func (s SitemapSource) Urls(ctx context.Context, _ string) ([]sitemapEntity.Url, error) {
+	pages, err := s.pageRepository.GetList(ctx, sitemapPageFields, false)
+	if err != nil {
+		return nil, err
+	}
+
+	urls := make([]sitemapEntity.Url, 0, len(pages))
+	for _, page := range pages {
+		pagePath := page.Path
+		if pagePath == "" {
+			pagePath = page.Code
+		}
+		urls = append(urls, sitemapEntity.Url{Loc: path.Join("/", s.basePath, pagePath), LastMod: page.UpdatedAt})
+	}
+
+	return urls, nil
+}
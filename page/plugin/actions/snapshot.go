@@ -0,0 +1,504 @@
+package actions
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/aeroideaservices/focus/page/plugin/entity"
+	"github.com/aeroideaservices/focus/services/errors"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const defaultSnapshotLimit = 20
+
+type CreateSnapshotRequest struct {
+	PageID  uuid.UUID `json:"-"`
+	Comment string    `json:"comment" validate:"max=255"`
+}
+
+type GetSnapshotRequest struct {
+	PageID uuid.UUID
+	ID     uuid.UUID
+}
+
+type SnapshotFilter struct {
+	PageID uuid.UUID `form:"-"`
+	Limit  int       `form:"limit" validate:"omitempty,min=1,max=100"`
+	Offset int       `form:"offset" validate:"omitempty,min=0"`
+}
+
+// DiffSnapshotsRequest сравнение снимка From со снимком To, без To - с текущим состоянием страницы
+type DiffSnapshotsRequest struct {
+	PageID uuid.UUID `form:"-"`
+	From   string    `form:"from" validate:"required,uuid"`
+	To     string    `form:"to" validate:"omitempty,uuid"`
+}
+
+type SnapshotDto struct {
+	ID        uuid.UUID             `json:"id"`
+	PageID    uuid.UUID             `json:"pageId"`
+	Reason    entity.SnapshotReason `json:"reason"`
+	Comment   string                `json:"comment"`
+	CreatedAt time.Time             `json:"createdAt"`
+}
+
+type SnapshotWithDataDto struct {
+	SnapshotDto
+	Data entity.SnapshotData `json:"data"`
+}
+
+type SnapshotList struct {
+	Total int64         `json:"total"`
+	Items []SnapshotDto `json:"items"`
+}
+
+// SnapshotEntityDto сущность снимка при восстановлении
+type SnapshotEntityDto struct {
+	EntityType entity.DraftType `json:"entityType"`
+	ID         uuid.UUID        `json:"id"`
+	Name       string           `json:"name"`
+}
+
+// RestoreSnapshotResponse результат восстановления снимка в черновики
+type RestoreSnapshotResponse struct {
+	Drafts  []SnapshotEntityDto `json:"drafts"`  // Drafts сущности, черновики которых заменены данными снимка
+	Missing []SnapshotEntityDto `json:"missing"` // Missing сущности, удаленные после снимка, они не восстанавливаются
+}
+
+// SnapshotUseCase снимки страниц с галереями и карточками.
+// Снимок создается после публикации страницы или ее галереи или карточки и по запросу и не изменяется.
+// Восстановление записывает данные снимка в черновики страницы, галерей и карточек,
+// в том числе состав и порядок галерей и карточек, и применяется публикацией этих черновиков.
+type SnapshotUseCase struct {
+	snapshotRepository    SnapshotRepository
+	pageRepository        PageRepository
+	galleryRepository     GalleryRepository
+	cardRepository        CardRepository
+	publicationRepository PublicationRepository
+	transactor            Transactor
+	logger                *zap.SugaredLogger
+}
+
+func NewSnapshotUseCase(
+	snapshotRepository SnapshotRepository, pageRepository PageRepository, galleryRepository GalleryRepository,
+	cardRepository CardRepository, publicationRepository PublicationRepository, transactor Transactor,
+	logger *zap.SugaredLogger,
+) *SnapshotUseCase {
+	return &SnapshotUseCase{
+		snapshotRepository:    snapshotRepository,
+		pageRepository:        pageRepository,
+		galleryRepository:     galleryRepository,
+		cardRepository:        cardRepository,
+		publicationRepository: publicationRepository,
+		transactor:            transactor,
+		logger:                logger,
+	}
+}
+
+func (uc SnapshotUseCase) Create(ctx context.Context, dto CreateSnapshotRequest) (*SnapshotDto, error) {
+	uc.logger.Debug("Creating page snapshot")
+	snapshot, err := uc.take(ctx, dto.PageID, entity.SnapshotReasonManual, dto.Comment)
+	if err != nil {
+		return nil, err
+	}
+
+	uc.logger.Debug("Created page snapshot")
+	return getSnapshotDto(*snapshot), nil
+}
+
+// TakeAfterPublish снимки страниц, содержимое которых изменила публикация сущности
+func (uc SnapshotUseCase) TakeAfterPublish(ctx context.Context, entityType entity.DraftType, id uuid.UUID) error {
+	pageIds := []uuid.UUID{id}
+	if entityType != entity.DraftTypePage {
+		var err error
+		pageIds, err = uc.snapshotRepository.GetPageIds(ctx, entityType, id)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, pageId := range pageIds {
+		_, err := uc.take(ctx, pageId, entity.SnapshotReasonPublish, "")
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// GetList снимки страницы, начиная с последнего, без данных
+func (uc SnapshotUseCase) GetList(ctx context.Context, dto SnapshotFilter) (*SnapshotList, error) {
+	uc.logger.Debug("Getting page snapshots")
+	if dto.Limit == 0 {
+		dto.Limit = defaultSnapshotLimit
+	}
+
+	_, err := uc.pageRepository.GetByIdWithoutAssociate(ctx, dto.PageID)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshots, total, err := uc.snapshotRepository.GetList(ctx, dto.PageID, dto.Limit, dto.Offset)
+	if err != nil {
+		return nil, err
+	}
+
+	list := &SnapshotList{Total: total, Items: make([]SnapshotDto, len(snapshots))}
+	for i, snapshot := range snapshots {
+		list.Items[i] = *getSnapshotDto(snapshot)
+	}
+
+	uc.logger.Debug("Got page snapshots")
+	return list, nil
+}
+
+func (uc SnapshotUseCase) GetById(ctx context.Context, dto GetSnapshotRequest) (*SnapshotWithDataDto, error) {
+	uc.logger.Debug("Getting page snapshot")
+	snapshot, data, err := uc.get(ctx, dto.PageID, dto.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	uc.logger.Debug("Got page snapshot")
+	return &SnapshotWithDataDto{SnapshotDto: *getSnapshotDto(*snapshot), Data: *data}, nil
+}
+
+func (uc SnapshotUseCase) Diff(ctx context.Context, dto DiffSnapshotsRequest) (*entity.SnapshotDiff, error) {
+	uc.logger.Debug("Comparing page snapshots")
+	fromID, err := uuid.Parse(dto.From)
+	if err != nil {
+		return nil, errors.BadRequest.Wrap(err, "error parsing uuid")
+	}
+	_, from, err := uc.get(ctx, dto.PageID, fromID)
+	if err != nil {
+		return nil, err
+	}
+
+	var to *entity.SnapshotData
+	if dto.To != "" {
+		var toID uuid.UUID
+		if toID, err = uuid.Parse(dto.To); err != nil {
+			return nil, errors.BadRequest.Wrap(err, "error parsing uuid")
+		}
+		_, to, err = uc.get(ctx, dto.PageID, toID)
+	} else {
+		to, err = uc.current(ctx, dto.PageID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	diff, err := entity.DiffSnapshots(*from, *to)
+	if err != nil {
+		return nil, errors.NoType.Wrap(err, "error comparing page snapshots")
+	}
+
+	uc.logger.Debug("Compared page snapshots")
+	return diff, nil
+}
+
+// Restore запись снимка в черновики страницы, ее галерей и карточек.
+// Существующие черновики этих сущностей заменяются, статус публикации из снимка не восстанавливается.
+func (uc SnapshotUseCase) Restore(ctx context.Context, dto GetSnapshotRequest) (*RestoreSnapshotResponse, error) {
+	uc.logger.Debug("Restoring page snapshot")
+	_, err := uc.pageRepository.GetByIdWithoutAssociate(ctx, dto.PageID)
+	if err != nil {
+		return nil, err
+	}
+
+	_, data, err := uc.get(ctx, dto.PageID, dto.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &RestoreSnapshotResponse{Drafts: []SnapshotEntityDto{}, Missing: []SnapshotEntityDto{}}
+	drafts := make([]entity.Draft, 0, len(data.Galleries)+1)
+	checked := make(map[uuid.UUID]bool)
+	exists := func(entityType entity.DraftType, item entity.SnapshotItem) (bool, error) {
+		if ok, found := checked[item.ID]; found {
+			return ok, nil
+		}
+		var err error
+		if entityType == entity.DraftTypeGallery {
+			_, err = uc.galleryRepository.GetByIdWithoutAssociate(ctx, item.ID)
+		} else {
+			_, err = uc.cardRepository.GetByIdWithoutAssociate(ctx, item.ID)
+		}
+		if err != nil && errors.GetType(err) != errors.NotFound {
+			return false, err
+		}
+		checked[item.ID] = err == nil
+		if err != nil {
+			resp.Missing = append(
+				resp.Missing, SnapshotEntityDto{EntityType: entityType, ID: item.ID, Name: item.Name},
+			)
+		}
+		return err == nil, nil
+	}
+	addDraft := func(entityType entity.DraftType, item entity.SnapshotItem, request any) error {
+		data, err := json.Marshal(request)
+		if err != nil {
+			return errors.NoType.Wrap(err, "error encoding draft")
+		}
+		drafts = append(drafts, entity.Draft{EntityType: entityType, EntityID: item.ID, Data: data})
+		resp.Drafts = append(resp.Drafts, SnapshotEntityDto{EntityType: entityType, ID: item.ID, Name: item.Name})
+		return nil
+	}
+
+	galleryIds := make([]uuid.UUID, 0, len(data.Galleries))
+	restoredCards := make(map[uuid.UUID]bool)
+	for _, gallery := range data.Galleries {
+		ok, err := exists(entity.DraftTypeGallery, gallery.SnapshotItem)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		galleryIds = append(galleryIds, gallery.ID)
+
+		cardIds := make([]uuid.UUID, 0, len(gallery.Cards))
+		for _, card := range gallery.Cards {
+			ok, err = exists(entity.DraftTypeCard, card)
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				continue
+			}
+			cardIds = append(cardIds, card.ID)
+			if restoredCards[card.ID] {
+				continue
+			}
+			restoredCards[card.ID] = true
+
+			request := &UpdateCardRequest{}
+			if err = json.Unmarshal(card.Data, request); err != nil {
+				return nil, errors.NoType.Wrap(err, "error decoding card snapshot")
+			}
+			// привязка к галереям восстанавливается черновиками галерей
+			request.ID, request.IsPublished, request.GalleryIds = card.ID, nil, nil
+			if err = addDraft(entity.DraftTypeCard, card, request); err != nil {
+				return nil, err
+			}
+		}
+
+		request := &GalleryDraft{}
+		if err = json.Unmarshal(gallery.Data, request); err != nil {
+			return nil, errors.NoType.Wrap(err, "error decoding gallery snapshot")
+		}
+		request.ID, request.IsPublished, request.CardIDs = gallery.ID, nil, &cardIds
+		if err = addDraft(entity.DraftTypeGallery, gallery.SnapshotItem, request); err != nil {
+			return nil, err
+		}
+	}
+
+	request := &PageDraft{}
+	if err = json.Unmarshal(data.Page.Data, request); err != nil {
+		return nil, errors.NoType.Wrap(err, "error decoding page snapshot")
+	}
+	request.ID, request.IsPublished, request.GalleryIDs = dto.PageID, nil, &galleryIds
+	data.Page.ID = dto.PageID
+	if err = addDraft(entity.DraftTypePage, data.Page, request); err != nil {
+		return nil, err
+	}
+
+	// черновики страницы, галерей и карточек сохраняются вместе, чтобы не восстановить снимок частично
+	err = uc.transactor.Transaction(ctx, func(ctx context.Context) error {
+		for i := range drafts {
+			if err := uc.publicationRepository.SaveDraft(ctx, &drafts[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	uc.logger.Debug("Restored page snapshot")
+	return resp, nil
+}
+
+func (uc SnapshotUseCase) take(
+	ctx context.Context, pageID uuid.UUID, reason entity.SnapshotReason, comment string,
+) (*entity.Snapshot, error) {
+	data, err := uc.current(ctx, pageID)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := &entity.Snapshot{ID: uuid.New(), PageID: pageID, Reason: reason, Comment: comment}
+	snapshot.Data, err = json.Marshal(data)
+	if err != nil {
+		return nil, errors.NoType.Wrap(err, "error encoding page snapshot")
+	}
+
+	err = uc.snapshotRepository.Create(ctx, snapshot)
+	if err != nil {
+		return nil, err
+	}
+
+	return snapshot, nil
+}
+
+func (uc SnapshotUseCase) get(ctx context.Context, pageID uuid.UUID, id uuid.UUID) (
+	*entity.Snapshot, *entity.SnapshotData, error,
+) {
+	snapshot, err := uc.snapshotRepository.GetById(ctx, pageID, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	data := &entity.SnapshotData{}
+	if err = json.Unmarshal(snapshot.Data, data); err != nil {
+		return nil, nil, errors.NoType.Wrap(err, "error decoding page snapshot")
+	}
+
+	return snapshot, data, nil
+}
+
+// current текущее состояние страницы в формате снимка
+func (uc SnapshotUseCase) current(ctx context.Context, pageID uuid.UUID) (*entity.SnapshotData, error) {
+	page, err := uc.pageRepository.GetById(ctx, pageID)
+	if err != nil {
+		return nil, err
+	}
+
+	data := &entity.SnapshotData{Galleries: make([]entity.SnapshotGallery, 0, len(page.PagesGalleries))}
+	data.Page, err = snapshotItem(page.ID, page.Name, PatchPageRequest{
+		ID:             page.ID,
+		Name:           page.Name,
+		Code:           page.Code,
+		Title:          page.Title,
+		TitleSeo:       page.TitleSeo,
+		Keywords:       page.Keywords,
+		Description:    page.Description,
+		DescriptionSeo: page.DescriptionSeo,
+		IsPublished:    &page.IsPublished,
+		Sort:           page.Sort,
+		OgType:         page.OgType,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, pageGallery := range page.PagesGalleries {
+		gallery := pageGallery.Gallery
+		snapshotGallery := entity.SnapshotGallery{Cards: make([]entity.SnapshotItem, 0, len(gallery.GalleriesCards))}
+		snapshotGallery.SnapshotItem, err = snapshotItem(gallery.ID, gallery.Name, UpdateGalleryRequest{
+			ID:          gallery.ID,
+			Name:        gallery.Name,
+			Code:        gallery.Code,
+			Hidden:      &gallery.Hidden,
+			IsPublished: &gallery.IsPublished,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, galleryCard := range gallery.GalleriesCards {
+			if galleryCard.Card == nil {
+				continue
+			}
+			request, err := cardUpdateRequest(*galleryCard.Card)
+			if err != nil {
+				return nil, err
+			}
+			card, err := snapshotItem(galleryCard.Card.ID, galleryCard.Card.Name, request)
+			if err != nil {
+				return nil, err
+			}
+			snapshotGallery.Cards = append(snapshotGallery.Cards, card)
+		}
+		data.Galleries = append(data.Galleries, snapshotGallery)
+	}
+
+	return data, nil
+}
+
+func snapshotItem(id uuid.UUID, name string, request any) (entity.SnapshotItem, error) {
+	data, err := json.Marshal(request)
+	if err != nil {
+		return entity.SnapshotItem{}, errors.NoType.Wrap(err, "error encoding page snapshot")
+	}
+
+	return entity.SnapshotItem{ID: id, Name: name, Data: data}, nil
+}
+
+// cardUpdateRequest запрос на изменение карточки, приводящий ее к текущему состоянию
+func cardUpdateRequest(card entity.Card) (*UpdateCardRequest, error) {
+	request := &UpdateCardRequest{
+		ID:          card.ID,
+		Name:        card.Name,
+		Code:        card.Code,
+		Type:        card.Type,
+		IsPublished: &card.IsPublished,
+		Title:       card.Title,
+		Description: card.Description,
+		OgType:      card.OgType,
+	}
+
+	switch {
+	case card.RegularCard != nil:
+		regular := card.RegularCard
+		request.RegularCard = &CreateRegularCardRequest{
+			PreviewText:        regular.PreviewText,
+			DetailText:         regular.DetailText,
+			Inverted:           &regular.Inverted,
+			VideoId:            regular.VideoId,
+			VideoLiteId:        regular.VideoLiteId,
+			VideoPreviewId:     regular.VideoPreviewId,
+			VideoPreviewBlurId: regular.VideoPreviewBlurId,
+			Tags:               make([]uuid.UUID, len(regular.RegularCardsTags)),
+			UserId:             regular.UserId,
+			LearnMoreUrl:       regular.LearnMoreUrl,
+		}
+		for i, tag := range regular.RegularCardsTags {
+			request.RegularCard.Tags[i] = tag.TagID
+		}
+	case card.VideoCard != nil:
+		request.VideoCard = &CreateVideoCardRequest{
+			VideoId:            card.VideoCard.VideoId,
+			VideoLiteId:        card.VideoCard.VideoLiteId,
+			VideoPreviewId:     card.VideoCard.VideoPreviewId,
+			VideoPreviewBlurId: card.VideoCard.VideoPreviewBlurId,
+		}
+	case card.HtmlCard != nil:
+		request.HtmlCard = &CreateHtmlCardRequest{Html: card.HtmlCard.Html}
+	case card.PhotoCard != nil:
+		request.PhotoCard = &CreatePhotoCardRequest{PictureId: card.PhotoCard.PictureId}
+	case card.FormCard != nil:
+		form := card.FormCard
+		request.FormCard = &CreateFormCardRequest{
+			FormId:       form.FormId,
+			UserId:       form.UserId,
+			Tags:         make([]uuid.UUID, len(form.FormCardsTags)),
+			LearnMoreUrl: form.LearnMoreUrl,
+		}
+		for i, tag := range form.FormCardsTags {
+			request.FormCard.Tags[i] = tag.TagID
+		}
+	case card.CustomCard != nil:
+		data, err := json.Marshal(card.CustomCard)
+		if err != nil {
+			return nil, errors.NoType.Wrap(err, "error encoding custom card")
+		}
+		request.CustomCardData = data
+	}
+
+	return request, nil
+}
+
+func getSnapshotDto(snapshot entity.Snapshot) *SnapshotDto {
+	return &SnapshotDto{
+		ID:        snapshot.ID,
+		PageID:    snapshot.PageID,
+		Reason:    snapshot.Reason,
+		Comment:   snapshot.Comment,
+		CreatedAt: snapshot.CreatedAt,
+	}
+}
@@ -0,0 +1,363 @@
+package actions
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"html/template"
+	"io"
+	"os"
+	"path"
+	"sort"
+	"sync"
+
+	mediaActions "github.com/aeroideaservices/focus/media/plugin/actions"
+	"github.com/aeroideaservices/focus/page/plugin/services/staticsite"
+	"github.com/aeroideaservices/focus/services/errors"
+	"go.uber.org/zap"
+)
+
+const staticMediaDir = "media"
+
+// DefaultStaticTemplates шаблоны статической выгрузки по умолчанию.
+// Данные шаблона страницы - DeliveryPageDto, шаблона карточки - DeliveryCardDto.
+// Кроме функций staticsite в шаблонах доступна sanitizeHTML: очистка html по политике html карточек.
+var DefaultStaticTemplates = staticsite.Templates{
+	Layout: `<!DOCTYPE html>
+<html lang="{{.Locale}}">
+<head>
+<meta charset="utf-8">
+<title>{{if .TitleSeo}}{{.TitleSeo}}{{else}}{{.Title}}{{end}}</title>
+{{- with .DescriptionSeo}}
+<meta name="description" content="{{.}}">{{end}}
+{{- with .Keywords}}
+<meta name="keywords" content="{{.}}">{{end}}
+{{- with .OgType}}
+<meta property="og:type" content="{{.}}">{{end}}
+</head>
+<body>
+{{- with .Breadcrumbs}}
+<nav>{{range .}}<a href="{{pageUrl .Path}}">{{.Name}}</a> / {{end}}</nav>{{end}}
+<h1>{{.Title}}</h1>
+{{- with .Description}}
+<p>{{.}}</p>{{end}}
+{{- range .Galleries}}
+<section id="{{.Code}}">
+{{- range .Cards}}
+{{card .Type .}}{{end}}
+</section>{{end}}
+</body>
+</html>
+`,
+	Cards: map[string]string{
+		"regular": `<article>{{with .Title}}<h2>{{.}}</h2>{{end}}{{with .RegularCard}}
+{{- with .VideoPreview}}<img src="{{.Url}}" alt="{{.Alt}}">{{end}}
+{{- with .PreviewText}}<p>{{.}}</p>{{end}}{{with .DetailText}}<div>{{.}}</div>{{end}}
+{{- with .User}}<p>{{.FirstName}} {{.LastName}}{{with .Position}}, {{.}}{{end}}</p>{{end}}
+{{- with .LearnMoreUrl}}<a href="{{.}}">{{$.Title}}</a>{{end}}{{end}}</article>`,
+		"video": `<figure>{{with .VideoCard}}{{with .Video}}<video controls src="{{.Url}}"
+{{- with $.VideoCard.VideoPreview}} poster="{{.Url}}"{{end}}></video>{{end}}{{end}}
+{{- with .Title}}<figcaption>{{.}}</figcaption>{{end}}</figure>`,
+		// сохраненное содержимое могло быть записано в обход очистки или до смены политики, поэтому очищается заново
+		"html": `{{with .HtmlCard}}{{sanitizeHTML .Html}}{{end}}`,
+		"photo": `<figure>{{with .PhotoCard}}{{with .Picture}}<img src="{{.Url}}" alt="{{.Alt}}"
+{{- with .Width}} width="{{.}}"{{end}}{{with .Height}} height="{{.}}"{{end}}>{{end}}{{end}}
+{{- with .Title}}<figcaption>{{.}}</figcaption>{{end}}</figure>`,
+		"form": `<section>{{with .Title}}<h2>{{.}}</h2>{{end}}{{with .Description}}<p>{{.}}</p>{{end}}
+{{- with .FormCard}}{{with .Form}}<div data-form-id="{{.Id}}"></div>{{end}}{{end}}</section>`,
+	},
+}
+
+// StaticExportConfig настройки статической выгрузки
+type StaticExportConfig struct {
+	Dir       string // Dir каталог выгрузки на сервере, без него доступна только выгрузка в архив
+	BasePath  string // BasePath путь на сайте, от которого строятся адреса страниц и медиа
+	Locale    string // Locale язык содержимого, по умолчанию основной язык
+	CopyMedia bool   // CopyMedia копировать медиа в выгрузку, иначе остаются ссылки MediaProvider
+	// Templates шаблоны страницы и карточек, незаданные берутся из DefaultStaticTemplates.
+	// Для карточек типов приложения без шаблона в выгрузку ничего не выводится.
+	Templates staticsite.Templates
+}
+
+// StaticExportRequest выгрузка в каталог из настроек.
+// При Incremental отрисовываются только страницы, данные которых изменились после предыдущей выгрузки.
+type StaticExportRequest struct {
+	Incremental bool `json:"incremental"`
+}
+
+// StaticExportReport результат выгрузки
+type StaticExportReport struct {
+	Rendered []string `json:"rendered"` // Rendered полные пути отрисованных страниц
+	Skipped  int      `json:"skipped"`  // Skipped количество неизменившихся страниц
+	Removed  []string `json:"removed"`  // Removed полные пути страниц, удаленных из выгрузки
+	Media    int      `json:"media"`    // Media количество скопированных медиа
+}
+
+// StaticExportUseCase выгрузка опубликованных страниц в статические html-файлы.
+// Страница отрисовывается из тех же данных, что отдает публичное API, в файл <полный путь>/index.html.
+type StaticExportUseCase struct {
+	pageRepository  PageRepository
+	deliveryUseCase *DeliveryUseCase
+	medias          *mediaActions.Medias
+	renderer        *staticsite.Renderer
+	config          StaticExportConfig
+	dirMutex        *sync.Mutex // dirMutex выгрузки в каталог выполняются по очереди
+	logger          *zap.SugaredLogger
+}
+
+func NewStaticExportUseCase(
+	pageRepository PageRepository, deliveryUseCase *DeliveryUseCase, medias *mediaActions.Medias,
+	sanitizer Sanitizer, htmlPolicy string, config StaticExportConfig, logger *zap.SugaredLogger,
+) (*StaticExportUseCase, error) {
+	templates := config.Templates.Merge(DefaultStaticTemplates)
+	templates.Funcs["sanitizeHTML"] = func(html string) (template.HTML, error) {
+		sanitized, _, err := sanitizer.Sanitize(htmlPolicy, html)
+		if err != nil {
+			return "", errors.NoType.Wrap(err, "error sanitizing html card")
+		}
+		return template.HTML(sanitized), nil
+	}
+
+	renderer, err := staticsite.NewRenderer(templates, config.BasePath)
+	if err != nil {
+		return nil, errors.NoType.Wrap(err, "error parsing static export templates")
+	}
+
+	return &StaticExportUseCase{
+		pageRepository:  pageRepository,
+		deliveryUseCase: deliveryUseCase,
+		medias:          medias,
+		renderer:        renderer,
+		config:          config,
+		dirMutex:        &sync.Mutex{},
+		logger:          logger,
+	}, nil
+}
+
+// ExportZip полная выгрузка в zip-архив
+func (uc StaticExportUseCase) ExportZip(ctx context.Context, w io.Writer) (*StaticExportReport, error) {
+	uc.logger.Debug("Exporting static site to archive")
+	output := staticsite.NewZipOutput(w)
+	report, err := uc.export(ctx, output, false)
+	if err != nil {
+		return nil, err
+	}
+	if err = output.Close(); err != nil {
+		return nil, errors.NoType.Wrap(err, "error writing static site archive")
+	}
+
+	uc.logger.Debug("Exported static site to archive")
+	return report, nil
+}
+
+// ExportDir выгрузка в каталог из настроек, страницы, снятые с публикации, удаляются из каталога
+func (uc StaticExportUseCase) ExportDir(ctx context.Context, dto StaticExportRequest) (*StaticExportReport, error) {
+	if uc.config.Dir == "" {
+		return nil, errors.BadRequest.New("static export directory is not configured").T("static.dir-not-configured")
+	}
+
+	uc.dirMutex.Lock()
+	defer uc.dirMutex.Unlock()
+
+	uc.logger.Debug("Exporting static site to directory")
+	output, err := staticsite.NewDirOutput(uc.config.Dir)
+	if err != nil {
+		return nil, errors.NoType.Wrap(err, "error creating static export directory")
+	}
+	report, err := uc.export(ctx, output, dto.Incremental)
+	if err != nil {
+		return nil, err
+	}
+
+	uc.logger.Debug("Exported static site to directory")
+	return report, nil
+}
+
+func (uc StaticExportUseCase) export(
+	ctx context.Context, output staticsite.Output, incremental bool,
+) (*StaticExportReport, error) {
+	previous, err := output.Manifest()
+	if err != nil {
+		return nil, errors.NoType.Wrap(err, "error reading static export manifest")
+	}
+	// страницы, отрисованные другими шаблонами, отрисовываются заново
+	incremental = incremental && previous.Templates == uc.renderer.Fingerprint()
+
+	pages, err := uc.pageRepository.GetList(ctx, pageShortFields, false)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &StaticExportReport{Rendered: []string{}, Removed: []string{}}
+	manifest := staticsite.NewManifest()
+	manifest.Templates = uc.renderer.Fingerprint()
+	for _, page := range pages {
+		pagePath := page.Path
+		if pagePath == "" {
+			pagePath = page.Code
+		}
+
+		pageDto, err := uc.deliveryUseCase.GetPage(
+			ctx, GetDeliveryPageRequest{Path: pagePath, Locale: uc.config.Locale},
+		)
+		if errors.GetType(err) == errors.NotFound {
+			// опубликованная страница под неопубликованной в публичном API недоступна
+			uc.logger.Debugf("Skipping unavailable page %s", pagePath)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		// хеш считается до замены ссылок, чтобы замена файла медиа тоже меняла хеш страницы
+		data, err := json.Marshal(pageDto)
+		if err != nil {
+			return nil, errors.NoType.Wrap(err, "error hashing page")
+		}
+		hash := sha256.Sum256(data)
+		manifest.Pages[pagePath] = hex.EncodeToString(hash[:])
+
+		if uc.config.CopyMedia {
+			if err = uc.copyMedia(ctx, output, pageDto, previous, manifest, incremental, report); err != nil {
+				return nil, err
+			}
+		}
+
+		if incremental && previous.Pages[pagePath] == manifest.Pages[pagePath] {
+			report.Skipped++
+			continue
+		}
+
+		var b bytes.Buffer
+		if err = uc.renderer.Render(&b, pageDto); err != nil {
+			return nil, errors.NoType.Wrapf(err, "error rendering page %s", pagePath)
+		}
+		if err = output.Write(staticsite.PageFile(pagePath), b.Bytes()); err != nil {
+			return nil, errors.NoType.Wrapf(err, "error writing page %s", pagePath)
+		}
+		report.Rendered = append(report.Rendered, pagePath)
+	}
+
+	for pagePath := range previous.Pages {
+		if _, ok := manifest.Pages[pagePath]; ok {
+			continue
+		}
+		if err = output.Remove(staticsite.PageFile(pagePath)); err != nil {
+			return nil, errors.NoType.Wrapf(err, "error removing page %s", pagePath)
+		}
+		report.Removed = append(report.Removed, pagePath)
+	}
+	sort.Strings(report.Removed)
+	for id, source := range previous.Media {
+		if _, ok := manifest.Media[id]; ok {
+			continue
+		}
+		if err = output.Remove(staticMediaFile(id, source)); err != nil {
+			return nil, errors.NoType.Wrapf(err, "error removing media %s", id)
+		}
+	}
+
+	if err = output.SaveManifest(manifest); err != nil {
+		return nil, errors.NoType.Wrap(err, "error writing static export manifest")
+	}
+
+	return report, nil
+}
+
+// copyMedia копирование медиа страницы в выгрузку и замена ссылок на скопированные файлы.
+// Медиа, скопированное этой или предыдущей выгрузкой из того же источника, повторно не копируется.
+func (uc StaticExportUseCase) copyMedia(
+	ctx context.Context, output staticsite.Output, pageDto *DeliveryPageDto, previous, manifest *staticsite.Manifest,
+	incremental bool, report *StaticExportReport,
+) error {
+	for _, media := range deliveryPageMedia(pageDto) {
+		id, source := media.ID.String(), media.Url
+		file := staticMediaFile(id, source)
+		media.Url = path.Join("/", uc.config.BasePath, file)
+
+		if manifest.Media[id] == source || incremental && previous.Media[id] == source {
+			manifest.Media[id] = source
+			continue
+		}
+
+		if err := uc.writeMedia(ctx, output, media, file); err != nil {
+			return err
+		}
+		if previous.Media[id] != "" && staticMediaFile(id, previous.Media[id]) != file {
+			if err := output.Remove(staticMediaFile(id, previous.Media[id])); err != nil {
+				return errors.NoType.Wrapf(err, "error removing media %s", id)
+			}
+		}
+		manifest.Media[id] = source
+		report.Media++
+	}
+
+	return nil
+}
+
+func (uc StaticExportUseCase) writeMedia(
+	ctx context.Context, output staticsite.Output, media *DeliveryMediaDto, file string,
+) error {
+	fileName, err := uc.medias.Download(ctx, mediaActions.GetMedia{Id: media.ID})
+	if fileName != "" {
+		defer os.Remove(fileName)
+	}
+	if err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		return errors.NoType.Wrap(err, "error reading media file")
+	}
+	if err = output.Write(file, data); err != nil {
+		return errors.NoType.Wrapf(err, "error writing media %s", media.ID)
+	}
+
+	return nil
+}
+
+// staticMediaFile файл медиа в выгрузке, имя файла берется из ссылки на источник
+func staticMediaFile(id, source string) string {
+	return path.Join(staticMediaDir, id, path.Base(source))
+}
+
+// deliveryPageMedia все медиа страницы
+func deliveryPageMedia(pageDto *DeliveryPageDto) []*DeliveryMediaDto {
+	var medias []*DeliveryMediaDto
+	appendMedia := func(list ...*DeliveryMediaDto) {
+		for _, media := range list {
+			if media != nil {
+				medias = append(medias, media)
+			}
+		}
+	}
+	appendVideo := func(video DeliveryVideoCardDto) {
+		appendMedia(video.Video, video.VideoLite, video.VideoPreview, video.VideoPreviewBlur)
+	}
+	appendUser := func(user *DeliveryUserDto) {
+		if user != nil {
+			appendMedia(user.Picture)
+		}
+	}
+
+	for _, gallery := range pageDto.Galleries {
+		for _, card := range gallery.Cards {
+			switch {
+			case card.RegularCard != nil:
+				appendVideo(card.RegularCard.DeliveryVideoCardDto)
+				appendUser(card.RegularCard.User)
+			case card.VideoCard != nil:
+				appendVideo(*card.VideoCard)
+			case card.PhotoCard != nil:
+				appendMedia(card.PhotoCard.Picture)
+			case card.FormCard != nil:
+				appendUser(card.FormCard.User)
+			}
+		}
+	}
+
+	return medias
+}
@@ -0,0 +1,33 @@
+package actions
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/aeroideaservices/focus/services/sanitizer"
+	"go.uber.org/zap"
+)
+
+func TestStaticExportUseCase_RenderSanitizesHtmlCard(t *testing.T) {
+	uc, err := NewStaticExportUseCase(
+		nil, nil, nil, sanitizer.NewSanitizer(), sanitizer.PolicyEmbed, StaticExportConfig{}, zap.NewNop().Sugar(),
+	)
+	if err != nil {
+		t.Fatalf("NewStaticExportUseCase() error = %v", err)
+	}
+
+	// содержимое, записанное в обход очистки при сохранении
+	page := DeliveryPageDto{Title: "About", Galleries: []DeliveryGalleryDto{{Code: "main", Cards: []DeliveryCardDto{{
+		Type:     "html",
+		HtmlCard: &HtmlCardDto{Html: `<p>Text</p><script>alert(1)</script>`},
+	}}}}}
+
+	var b bytes.Buffer
+	if err = uc.renderer.Render(&b, page); err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	if html := b.String(); strings.Contains(html, "<script>") || !strings.Contains(html, "<p>Text</p>") {
+		t.Errorf("rendered html card = %s, want sanitized", html)
+	}
+}
@@ -3,10 +3,15 @@ package actions
 import (
 	"context"
 	"github.com/aeroideaservices/focus/page/plugin/entity"
+	"github.com/aeroideaservices/focus/services/errors"
 	"github.com/google/uuid"
 	"go.uber.org/zap"
 )
 
+const defaultTagsLimit = 20
+
+var ErrMergeTagIntoItself = errors.BadRequest.New("tag cannot be merged into itself").T("tag.merge-itself")
+
 type TagUseCase struct {
 	tagRepository TagRepository
 	copierService CopierInterface
@@ -124,3 +129,115 @@ func (uc TagUseCase) GetTagFromRequestDto(tag TagDtoRequest) (*entity.Tag, error
 	}
 	return tagEntity, nil
 }
+
+// TagFilter выборка тегов с количеством использований.
+// Sort - поле сортировки text или usage, с минусом - по убыванию, по умолчанию text.
+type TagFilter struct {
+	Query  string `form:"query"`
+	Sort   string `form:"sort" validate:"omitempty,oneof=text -text usage -usage"`
+	Limit  int    `form:"limit" validate:"omitempty,min=1,max=100"`
+	Offset int    `form:"offset" validate:"omitempty,min=0"`
+}
+
+// TagUsage тег с количеством обычных карточек и карточек с формой, в которых он указан
+type TagUsage struct {
+	entity.Tag
+	RegularCards int64
+	FormCards    int64
+}
+
+type TagUsageDto struct {
+	ID           uuid.UUID `json:"id"`
+	Text         string    `json:"text"`
+	Link         string    `json:"link"`
+	RegularCards int64     `json:"regularCards"`
+	FormCards    int64     `json:"formCards"`
+	Usage        int64     `json:"usage"`
+}
+
+type TagList struct {
+	Total int64         `json:"total"`
+	Items []TagUsageDto `json:"items"`
+}
+
+// MergeTagsRequest объединение тегов SourceIDs с тегом TargetID.
+// Text и Link переименовывают тег TargetID в той же транзакции.
+type MergeTagsRequest struct {
+	TargetID  uuid.UUID   `json:"-"`
+	SourceIDs []uuid.UUID `json:"sourceIds" validate:"required,min=1,unique"`
+	Text      *string     `json:"text" validate:"omitempty,min=1,max=50"`
+	Link      *string     `json:"link" validate:"omitempty,min=1"`
+}
+
+type MergeTagsResponse struct {
+	Tag    TagUsageDto `json:"tag"`
+	Linked int64       `json:"linked"` // Linked количество карточек, привязанных к тегу при объединении
+}
+
+// GetListWithUsage получение списка тегов с количеством использований, с поиском, сортировкой и постраничной выборкой
+func (uc TagUseCase) GetListWithUsage(ctx context.Context, filter TagFilter) (*TagList, error) {
+	uc.logger.Debug("Getting list tags with usage")
+	if filter.Limit == 0 {
+		filter.Limit = defaultTagsLimit
+	}
+
+	tags, total, err := uc.tagRepository.GetListWithUsage(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]TagUsageDto, len(tags))
+	for i, tag := range tags {
+		items[i] = tagUsageDto(tag)
+	}
+
+	uc.logger.Debug("Got list tags with usage")
+	return &TagList{Total: total, Items: items}, nil
+}
+
+// GetUsage получение тега с количеством использований
+func (uc TagUseCase) GetUsage(ctx context.Context, tagId uuid.UUID) (*TagUsageDto, error) {
+	tag, err := uc.tagRepository.GetUsage(ctx, tagId)
+	if err != nil {
+		return nil, err
+	}
+
+	dto := tagUsageDto(*tag)
+	return &dto, nil
+}
+
+// Merge объединение тегов: карточки и черновики карточек с тегами SourceIDs переводятся на тег TargetID,
+// теги SourceIDs удаляются. Переводы тегов SourceIDs переносятся на тег TargetID для языков,
+// на которые он не переведен.
+func (uc TagUseCase) Merge(ctx context.Context, dto MergeTagsRequest) (*MergeTagsResponse, error) {
+	uc.logger.Debug("Merging tags")
+	for _, sourceId := range dto.SourceIDs {
+		if sourceId == dto.TargetID {
+			return nil, ErrMergeTagIntoItself
+		}
+	}
+
+	linked, err := uc.tagRepository.Merge(ctx, dto)
+	if err != nil {
+		return nil, err
+	}
+
+	tag, err := uc.tagRepository.GetUsage(ctx, dto.TargetID)
+	if err != nil {
+		return nil, err
+	}
+
+	uc.logger.Debug("Merged tags")
+	return &MergeTagsResponse{Tag: tagUsageDto(*tag), Linked: linked}, nil
+}
+
+func tagUsageDto(tag TagUsage) TagUsageDto {
+	return TagUsageDto{
+		ID:           tag.ID,
+		Text:         tag.Text,
+		Link:         tag.Link,
+		RegularCards: tag.RegularCards,
+		FormCards:    tag.FormCards,
+		Usage:        tag.RegularCards + tag.FormCards,
+	}
+}
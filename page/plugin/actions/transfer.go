@@ -0,0 +1,913 @@
+package actions
+
+import (
+	"archive/zip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	mediaActions "github.com/aeroideaservices/focus/media/plugin/actions"
+	mediaEntity "github.com/aeroideaservices/focus/media/plugin/entity"
+	"github.com/aeroideaservices/focus/page/plugin/entity"
+	"github.com/aeroideaservices/focus/page/plugin/services/cardtypes"
+	"github.com/aeroideaservices/focus/services/errors"
+	"github.com/aeroideaservices/focus/services/sanitizer"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	BundleVersion  = 1               // BundleVersion версия формата архива страницы
+	bundleManifest = "manifest.json" // bundleManifest описание страницы в архиве
+	bundleMediaDir = "media"         // bundleMediaDir каталог файлов медиа в архиве
+)
+
+// Действия импорта над сущностью
+const (
+	ImportActionCreate = "create" // ImportActionCreate сущность создается
+	ImportActionUpdate = "update" // ImportActionUpdate существующая сущность с тем же кодом обновляется
+	ImportActionReuse  = "reuse"  // ImportActionReuse используется существующая сущность без изменений
+)
+
+var ErrBundleVersion = errors.BadRequest.New("unsupported page bundle version").T("transfer.bundle-version")
+
+// Bundle описание страницы в архиве для переноса между окружениями
+type Bundle struct {
+	Version    int           `json:"version"`
+	ExportedAt time.Time     `json:"exportedAt"`
+	Page       entity.Page   `json:"page"`  // Page страница с галереями, карточками, тегами и спикерами, медиа указаны идентификаторами
+	Media      []BundleMedia `json:"media"` // Media медиа, на которые ссылается страница, файлы лежат в архиве
+}
+
+// BundleMedia медиа в архиве страницы
+type BundleMedia struct {
+	ID       uuid.UUID `json:"id"`
+	Filename string    `json:"filename"`
+	Alt      string    `json:"alt"`
+	Title    string    `json:"title"`
+	Size     int64     `json:"size"`
+	Sha256   string    `json:"sha256"`
+	File     string    `json:"file"` // File путь к файлу в архиве
+}
+
+type ExportPageRequest struct {
+	ID uuid.UUID `json:"-"`
+}
+
+type ImportPageRequest struct {
+	Archive  io.ReaderAt `json:"-" validate:"required"`
+	Size     int64       `json:"-" validate:"required"`
+	FolderID *uuid.UUID  `json:"folderId"` // FolderID папка для загружаемых медиа, nil - корневая папка
+	DryRun   bool        `json:"dryRun"`   // DryRun только проверка и отчет, без изменений
+}
+
+// ImportReport отчет об импорте страницы.
+// При наличии конфликтов импорт не применяется.
+type ImportReport struct {
+	Applied   bool              `json:"applied"`
+	PageID    *uuid.UUID        `json:"pageId"`
+	Items     []ImportItem      `json:"items"`
+	Conflicts []ImportConflict  `json:"conflicts"`
+	Sanitized []ImportSanitized `json:"sanitized"` // Sanitized html карточек, из которого при импорте удалено лишнее
+}
+
+type ImportItem struct {
+	EntityType string `json:"entityType"`
+	Code       string `json:"code"`
+	Action     string `json:"action"`
+}
+
+type ImportConflict struct {
+	EntityType string `json:"entityType"`
+	Code       string `json:"code"`
+	Message    string `json:"message"`
+}
+
+// ImportSanitized удаленное из html карточки при импорте, html очищается так же, как при сохранении карточки
+type ImportSanitized struct {
+	Code    string              `json:"code"`
+	Name    string              `json:"name"`
+	Removed []sanitizer.Removal `json:"removed"`
+}
+
+// ImportData сущности страницы для записи, идентификаторы уже сопоставлены с существующими
+type ImportData struct {
+	Page   entity.Page
+	Update map[uuid.UUID]bool // Update существующие страница, галереи и карточки, которые обновляются
+	Tags   []entity.Tag       // Tags новые теги
+	Users  []entity.User      // Users новые спикеры
+}
+
+// TransferUseCase перенос страниц между окружениями.
+// Экспорт собирает страницу с галереями, карточками, тегами, спикерами и файлами медиа в zip-архив,
+// импорт сопоставляет страницу по пути, галереи и карточки по коду, теги по тексту и ссылке,
+// спикеров по имени и должности, а медиа загружает заново, переиспользуя совпадающие файлы в папке.
+// Формы карточек с формами не переносятся, они должны существовать в окружении с теми же идентификаторами.
+// Переводы не переносятся: после импорта их нужно заполнить в окружении заново.
+type TransferUseCase struct {
+	pageRepository     PageRepository
+	galleryRepository  GalleryRepository
+	transferRepository TransferRepository
+	cardUseCase        CardUseCase
+	medias             TransferMedias
+	cardTypes          *cardtypes.Registry
+	logger             *zap.SugaredLogger
+}
+
+func NewTransferUseCase(
+	pageRepository PageRepository, galleryRepository GalleryRepository, transferRepository TransferRepository,
+	cardUseCase CardUseCase, medias TransferMedias, cardTypes *cardtypes.Registry, logger *zap.SugaredLogger,
+) *TransferUseCase {
+	return &TransferUseCase{
+		pageRepository:     pageRepository,
+		galleryRepository:  galleryRepository,
+		transferRepository: transferRepository,
+		cardUseCase:        cardUseCase,
+		medias:             medias,
+		cardTypes:          cardTypes,
+		logger:             logger,
+	}
+}
+
+// Export запись архива страницы в w, возвращает имя файла архива
+func (uc TransferUseCase) Export(ctx context.Context, dto ExportPageRequest, w io.Writer) (string, error) {
+	uc.logger.Debug("Exporting page")
+	page, err := uc.pageRepository.GetById(ctx, dto.ID)
+	if err != nil {
+		return "", err
+	}
+
+	bundle := Bundle{Version: BundleVersion, ExportedAt: time.Now(), Page: *page}
+	archive := zip.NewWriter(w)
+
+	exported := make(map[uuid.UUID]bool)
+	var exportErr error
+	walkMedia(&bundle.Page, func(id **uuid.UUID, media **mediaEntity.Media) {
+		if exportErr != nil || *id == nil || exported[**id] {
+			*media = nil
+			return
+		}
+		exported[**id] = true
+
+		var bundleMedia *BundleMedia
+		bundleMedia, exportErr = uc.exportMedia(ctx, archive, **id, *media)
+		if exportErr == nil {
+			bundle.Media = append(bundle.Media, *bundleMedia)
+		}
+		*media = nil
+	})
+	if exportErr != nil {
+		return "", exportErr
+	}
+	walkCards(&bundle.Page, func(card *entity.Card) {
+		if card.FormCard != nil {
+			card.FormCard.Form = nil
+		}
+	})
+
+	manifest, err := archive.Create(bundleManifest)
+	if err != nil {
+		return "", errors.NoType.Wrap(err, "error writing page bundle")
+	}
+	encoder := json.NewEncoder(manifest)
+	encoder.SetIndent("", "  ")
+	if err = encoder.Encode(bundle); err != nil {
+		return "", errors.NoType.Wrap(err, "error encoding page bundle")
+	}
+	if err = archive.Close(); err != nil {
+		return "", errors.NoType.Wrap(err, "error writing page bundle")
+	}
+
+	uc.logger.Debug("Exported page")
+	return "page-" + strings.ReplaceAll(page.FullPath(), entity.PagePathSeparator, "-") + ".zip", nil
+}
+
+func (uc TransferUseCase) exportMedia(
+	ctx context.Context, archive *zip.Writer, id uuid.UUID, media *mediaEntity.Media,
+) (*BundleMedia, error) {
+	if media == nil {
+		return nil, errors.NotFound.Newf("media with id %s not found", id)
+	}
+
+	fileName, err := uc.medias.Download(ctx, mediaActions.GetMedia{Id: id})
+	if fileName != "" {
+		defer os.Remove(fileName)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(fileName)
+	if err != nil {
+		return nil, errors.NoType.Wrap(err, "error reading media file")
+	}
+	defer file.Close()
+
+	bundleMedia := &BundleMedia{
+		ID:       id,
+		Filename: media.Filename,
+		Alt:      media.Alt,
+		Title:    media.Title,
+		File:     path.Join(bundleMediaDir, id.String(), media.Filename),
+	}
+	writer, err := archive.Create(bundleMedia.File)
+	if err != nil {
+		return nil, errors.NoType.Wrap(err, "error writing page bundle")
+	}
+
+	hash := sha256.New()
+	bundleMedia.Size, err = io.Copy(io.MultiWriter(writer, hash), file)
+	if err != nil {
+		return nil, errors.NoType.Wrap(err, "error writing page bundle")
+	}
+	bundleMedia.Sha256 = hex.EncodeToString(hash.Sum(nil))
+
+	return bundleMedia, nil
+}
+
+// importPlan сопоставление сущностей архива с существующими
+type importPlan struct {
+	report ImportReport
+	data   ImportData
+	files  map[string]*zip.File
+	media  map[string]*plannedMedia // media медиа к загрузке по хешу содержимого
+	ids    map[uuid.UUID]uuid.UUID  // ids идентификаторы медиа архива и медиа окружения
+	upload []uuid.UUID              // upload загруженные импортом медиа, удаляются, если страницу записать не удалось
+}
+
+// plannedMedia медиа архива, которое загружается или переиспользуется
+type plannedMedia struct {
+	BundleMedia
+	existing *uuid.UUID // existing найденное в папке медиа с тем же содержимым
+	sources  []uuid.UUID
+}
+
+// Import импорт страницы из архива.
+// Сначала сущности архива сопоставляются с существующими, при конфликтах или DryRun возвращается только отчет,
+// иначе загружаются медиа и страница записывается в одной транзакции.
+// Загрузка медиа не входит в транзакцию, поэтому при ошибке записи загруженные медиа удаляются.
+func (uc TransferUseCase) Import(ctx context.Context, dto ImportPageRequest) (*ImportReport, error) {
+	uc.logger.Debug("Importing page")
+	archive, err := zip.NewReader(dto.Archive, dto.Size)
+	if err != nil {
+		return nil, errors.BadRequest.Wrap(err, "error reading page bundle")
+	}
+
+	plan := &importPlan{
+		report: ImportReport{Items: []ImportItem{}, Conflicts: []ImportConflict{}, Sanitized: []ImportSanitized{}},
+		data:   ImportData{Update: make(map[uuid.UUID]bool)},
+		files:  make(map[string]*zip.File, len(archive.File)),
+		media:  make(map[string]*plannedMedia),
+		ids:    make(map[uuid.UUID]uuid.UUID),
+	}
+	for _, file := range archive.File {
+		plan.files[file.Name] = file
+	}
+
+	bundle, err := readBundle(plan.files[bundleManifest])
+	if err != nil {
+		return nil, err
+	}
+
+	err = uc.plan(ctx, bundle, dto.FolderID, plan)
+	if err != nil {
+		return nil, err
+	}
+	if dto.DryRun || len(plan.report.Conflicts) > 0 {
+		uc.logger.Debug("Checked page bundle")
+		return &plan.report, nil
+	}
+
+	err = uc.uploadMedia(ctx, dto.FolderID, plan)
+	if err == nil {
+		remapMedia(plan)
+		err = uc.transferRepository.Import(ctx, plan.data)
+	}
+	if err != nil {
+		uc.deleteUploaded(ctx, plan)
+		return nil, err
+	}
+
+	plan.report.Applied = true
+	plan.report.PageID = &plan.data.Page.ID
+	uc.logger.Debug("Imported page")
+	return &plan.report, nil
+}
+
+func readBundle(file *zip.File) (*Bundle, error) {
+	if file == nil {
+		return nil, errors.BadRequest.Newf("%s not found in page bundle", bundleManifest)
+	}
+
+	reader, err := file.Open()
+	if err != nil {
+		return nil, errors.BadRequest.Wrap(err, "error reading page bundle")
+	}
+	defer reader.Close()
+
+	bundle := &Bundle{}
+	err = json.NewDecoder(reader).Decode(bundle)
+	if err != nil {
+		return nil, errors.BadRequest.Wrap(err, "error decoding page bundle")
+	}
+	if bundle.Version != BundleVersion {
+		return nil, ErrBundleVersion
+	}
+
+	return bundle, nil
+}
+
+func (uc TransferUseCase) plan(ctx context.Context, bundle *Bundle, folderID *uuid.UUID, plan *importPlan) error {
+	err := uc.planMedia(ctx, bundle.Media, folderID, plan)
+	if err != nil {
+		return err
+	}
+
+	tagIDs, err := uc.planTags(ctx, bundle, plan)
+	if err != nil {
+		return err
+	}
+
+	userIDs, err := uc.planUsers(ctx, bundle, plan)
+	if err != nil {
+		return err
+	}
+
+	cards, err := uc.planCards(ctx, bundle, tagIDs, userIDs, plan)
+	if err != nil {
+		return err
+	}
+
+	page := bundle.Page
+	err = uc.planPage(ctx, &page, plan)
+	if err != nil {
+		return err
+	}
+
+	page.PagesGalleries = make([]entity.PagesGalleries, 0, len(bundle.Page.PagesGalleries))
+	galleryCodes := make(map[string]bool, len(bundle.Page.PagesGalleries))
+	for _, pagesGallery := range bundle.Page.PagesGalleries {
+		code := pagesGallery.Gallery.Code
+		if galleryCodes[code] {
+			plan.conflict("gallery", code, "several galleries in the bundle have this code")
+			continue
+		}
+		galleryCodes[code] = true
+
+		gallery, err := uc.planGallery(ctx, pagesGallery.Gallery, cards, plan)
+		if err != nil {
+			return err
+		}
+		page.PagesGalleries = append(page.PagesGalleries, entity.PagesGalleries{
+			PagesID:   &page.ID,
+			GalleryID: &gallery.ID,
+			Gallery:   gallery,
+			Position:  pagesGallery.Position,
+		})
+	}
+	plan.data.Page = page
+
+	walkMedia(&plan.data.Page, func(id **uuid.UUID, _ **mediaEntity.Media) {
+		if *id != nil && !plan.hasMedia(**id) {
+			plan.conflict("media", (*id).String(), "media file is missing from the bundle")
+		}
+	})
+
+	return nil
+}
+
+// planPage страница сопоставляется по полному пути, родительская страница должна существовать
+func (uc TransferUseCase) planPage(ctx context.Context, page *entity.Page, plan *importPlan) error {
+	page.Path = page.FullPath()
+	existing, err := uc.pageRepository.GetByPath(ctx, page.Path)
+	if err == nil {
+		page.ID, page.ParentID = existing.ID, existing.ParentID
+		plan.data.Update[page.ID] = true
+		plan.item("page", page.Path, ImportActionUpdate)
+		return nil
+	}
+	if errors.GetType(err) != errors.NotFound {
+		return err
+	}
+
+	page.ID, page.ParentID = uuid.New(), nil
+	if parentPath := page.ParentPath(); parentPath != "" {
+		parent, err := uc.pageRepository.GetByPath(ctx, parentPath)
+		if errors.GetType(err) == errors.NotFound {
+			plan.conflict("page", page.Path, "parent page "+parentPath+" not found")
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		page.ParentID = &parent.ID
+	}
+	plan.item("page", page.Path, ImportActionCreate)
+
+	return nil
+}
+
+// planGallery галерея сопоставляется по коду, ее карточки заменяются карточками из архива
+func (uc TransferUseCase) planGallery(
+	ctx context.Context, gallery entity.Gallery, cards map[uuid.UUID]*entity.Card, plan *importPlan,
+) (entity.Gallery, error) {
+	existing, err := uc.galleryRepository.GetByCode(ctx, gallery.Code)
+	switch {
+	case err == nil:
+		gallery.ID = existing.ID
+		plan.data.Update[gallery.ID] = true
+		plan.item("gallery", gallery.Code, ImportActionUpdate)
+	case errors.GetType(err) == errors.NotFound:
+		gallery.ID = uuid.New()
+		plan.item("gallery", gallery.Code, ImportActionCreate)
+	default:
+		return gallery, err
+	}
+
+	galleriesCards := make([]entity.GalleriesCards, 0, len(gallery.GalleriesCards))
+	for _, galleriesCard := range gallery.GalleriesCards {
+		if galleriesCard.CardID == nil || cards[*galleriesCard.CardID] == nil {
+			continue
+		}
+		card := cards[*galleriesCard.CardID]
+		galleriesCards = append(galleriesCards, entity.GalleriesCards{
+			GalleryID: &gallery.ID,
+			CardID:    &card.ID,
+			Card:      card,
+			Position:  galleriesCard.Position,
+		})
+	}
+	gallery.GalleriesCards = galleriesCards
+
+	return gallery, nil
+}
+
+// planCards карточки сопоставляются по коду, карточки без кода создаются заново.
+// Типизированная часть карточки создается заново и у существующих карточек.
+func (uc TransferUseCase) planCards(
+	ctx context.Context, bundle *Bundle, tagIDs map[uuid.UUID]uuid.UUID, userIDs map[uuid.UUID]uuid.UUID,
+	plan *importPlan,
+) (map[uuid.UUID]*entity.Card, error) {
+	var source []*entity.Card
+	var codes []string
+	walkCards(&bundle.Page, func(card *entity.Card) {
+		source = append(source, card)
+		if card.Code != "" {
+			codes = append(codes, card.Code)
+		}
+	})
+
+	existing, err := uc.transferRepository.GetCardsByCodes(ctx, codes)
+	if err != nil {
+		return nil, err
+	}
+	byCode := make(map[string][]entity.Card, len(existing))
+	for _, card := range existing {
+		byCode[card.Code] = append(byCode[card.Code], card)
+	}
+
+	cards := make(map[uuid.UUID]*entity.Card, len(source))
+	for _, card := range source {
+		if cards[card.ID] != nil {
+			continue
+		}
+
+		if card.CustomCard != nil {
+			ok := uc.decodeCustomCard(ctx, card, plan)
+			if !ok {
+				continue
+			}
+		}
+
+		clone := cloneCard(*card)
+		remapCardLinks(&clone, tagIDs, userIDs)
+		if err = uc.sanitizeHtmlCard(&clone, plan); err != nil {
+			return nil, err
+		}
+
+		action := ImportActionCreate
+		switch matches := byCode[card.Code]; {
+		case card.Code == "" || len(matches) == 0:
+		case len(matches) > 1:
+			plan.conflict("card", card.Code, "several cards have this code")
+			continue
+		case matches[0].Type != card.Type:
+			plan.conflict("card", card.Code, "existing card has type "+matches[0].Type)
+			continue
+		default:
+			clone.ID = matches[0].ID
+			plan.data.Update[clone.ID] = true
+			action = ImportActionUpdate
+		}
+		plan.item("card", card.Code, action)
+		cards[card.ID] = &clone
+	}
+
+	return cards, nil
+}
+
+// sanitizeHtmlCard очистка html карточки из архива по политике html карточек, удаленное попадает в отчет
+func (uc TransferUseCase) sanitizeHtmlCard(card *entity.Card, plan *importPlan) error {
+	if card.HtmlCard == nil {
+		return nil
+	}
+
+	report, err := uc.cardUseCase.sanitize(&card.HtmlCard.Html)
+	if err != nil || report == nil {
+		return err
+	}
+	plan.report.Sanitized = append(plan.report.Sanitized, ImportSanitized{
+		Code: card.Code, Name: card.Name, Removed: report.Removed,
+	})
+
+	return nil
+}
+
+// decodeCustomCard разбор данных карточки зарегистрированного приложением типа
+func (uc TransferUseCase) decodeCustomCard(ctx context.Context, card *entity.Card, plan *importPlan) bool {
+	customType := uc.cardTypes.Custom(card.Type)
+	if customType == nil {
+		plan.conflict("card", card.Code, "card type "+card.Type+" is not registered")
+		return false
+	}
+
+	data, err := json.Marshal(card.CustomCard)
+	if err == nil {
+		card.CustomCard, err = customType.Decode(ctx, data)
+	}
+	if err != nil {
+		plan.conflict("card", card.Code, "invalid "+card.Type+" card data: "+err.Error())
+		return false
+	}
+
+	return true
+}
+
+// planTags теги сопоставляются по тексту и ссылке
+func (uc TransferUseCase) planTags(ctx context.Context, bundle *Bundle, plan *importPlan) (
+	map[uuid.UUID]uuid.UUID, error,
+) {
+	tags := make(map[uuid.UUID]entity.Tag)
+	var texts []string
+	walkCards(&bundle.Page, func(card *entity.Card) {
+		var cardTags []entity.Tag
+		if card.RegularCard != nil {
+			for _, tag := range card.RegularCard.RegularCardsTags {
+				cardTags = append(cardTags, tag.Tag)
+			}
+		}
+		if card.FormCard != nil {
+			for _, tag := range card.FormCard.FormCardsTags {
+				cardTags = append(cardTags, tag.Tag)
+			}
+		}
+		for _, tag := range cardTags {
+			if _, ok := tags[tag.ID]; !ok {
+				tags[tag.ID] = tag
+				texts = append(texts, tag.Text)
+			}
+		}
+	})
+
+	existing, err := uc.transferRepository.GetTagsByTexts(ctx, texts)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make(map[uuid.UUID]uuid.UUID, len(tags))
+	for id, tag := range tags {
+		ids[id] = uuid.New()
+		action := ImportActionCreate
+		for _, candidate := range existing {
+			if candidate.Text == tag.Text && candidate.Link == tag.Link {
+				ids[id], action = candidate.ID, ImportActionReuse
+				break
+			}
+		}
+		if action == ImportActionCreate {
+			tag.ID = ids[id]
+			plan.data.Tags = append(plan.data.Tags, tag)
+		}
+		plan.item("tag", tag.Text, action)
+	}
+
+	return ids, nil
+}
+
+// planUsers спикеры сопоставляются по имени, фамилии и должности
+func (uc TransferUseCase) planUsers(ctx context.Context, bundle *Bundle, plan *importPlan) (
+	map[uuid.UUID]uuid.UUID, error,
+) {
+	users := make(map[uuid.UUID]entity.User)
+	var lastNames []string
+	walkCards(&bundle.Page, func(card *entity.Card) {
+		user := cardUser(card)
+		if user == nil {
+			return
+		}
+		if _, ok := users[user.ID]; !ok {
+			users[user.ID] = *user
+			lastNames = append(lastNames, user.LastName)
+		}
+	})
+
+	existing, err := uc.transferRepository.GetUsersByLastNames(ctx, lastNames)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make(map[uuid.UUID]uuid.UUID, len(users))
+	for id, user := range users {
+		ids[id] = uuid.New()
+		action := ImportActionCreate
+		for _, candidate := range existing {
+			if candidate.FirstName == user.FirstName && candidate.LastName == user.LastName &&
+				candidate.Position == user.Position {
+				ids[id], action = candidate.ID, ImportActionReuse
+				break
+			}
+		}
+		if action == ImportActionCreate {
+			user.ID, user.Picture = ids[id], nil
+			if user.PictureId != nil && !plan.hasMedia(*user.PictureId) {
+				plan.conflict("media", user.PictureId.String(), "media file is missing from the bundle")
+			}
+			plan.data.Users = append(plan.data.Users, user)
+		}
+		plan.item("user", user.FirstName+" "+user.LastName, action)
+	}
+
+	return ids, nil
+}
+
+// planMedia медиа с одинаковым содержимым загружаются один раз.
+// Если в папке уже есть файл с тем же именем и хешем содержимого, он используется вместо загрузки,
+// файл с тем же именем и другим содержимым не заменяется, а загружается под именем с хешем.
+func (uc TransferUseCase) planMedia(
+	ctx context.Context, media []BundleMedia, folderID *uuid.UUID, plan *importPlan,
+) error {
+	for _, bundleMedia := range media {
+		if plan.files[bundleMedia.File] == nil {
+			plan.conflict("media", bundleMedia.Filename, "media file is missing from the bundle")
+			continue
+		}
+		if planned := plan.media[bundleMedia.Sha256]; planned != nil {
+			planned.sources = append(planned.sources, bundleMedia.ID)
+			continue
+		}
+
+		planned := &plannedMedia{BundleMedia: bundleMedia, sources: []uuid.UUID{bundleMedia.ID}}
+		planned.Filename = ""
+		filenames := []string{bundleMedia.Filename, hashedFilename(bundleMedia.Filename, bundleMedia.Sha256)}
+		for _, filename := range filenames {
+			existing, err := uc.medias.Find(ctx, mediaActions.FindMedia{FolderId: folderID, Filename: filename})
+			if errors.GetType(err) == errors.NotFound {
+				planned.Filename = filename
+				break
+			}
+			if err != nil {
+				return err
+			}
+			if int64(existing.Size) != bundleMedia.Size {
+				continue
+			}
+			// размер совпадает и у разных файлов, поэтому сравнивается содержимое
+			existingSha256, err := uc.mediaSha256(ctx, existing.Id)
+			if err != nil {
+				return err
+			}
+			if existingSha256 == bundleMedia.Sha256 {
+				planned.Filename, planned.existing = filename, &existing.Id
+				break
+			}
+		}
+		plan.media[bundleMedia.Sha256] = planned
+		if planned.Filename == "" {
+			plan.conflict("media", bundleMedia.Filename, "files with this name and other content already exist in the folder")
+			continue
+		}
+
+		action := ImportActionCreate
+		if planned.existing != nil {
+			action = ImportActionReuse
+		}
+		plan.item("media", planned.Filename, action)
+	}
+
+	return nil
+}
+
+// mediaSha256 хеш содержимого медиа окружения
+func (uc TransferUseCase) mediaSha256(ctx context.Context, id uuid.UUID) (string, error) {
+	fileName, err := uc.medias.Download(ctx, mediaActions.GetMedia{Id: id})
+	if fileName != "" {
+		defer os.Remove(fileName)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	file, err := os.Open(fileName)
+	if err != nil {
+		return "", errors.NoType.Wrap(err, "error reading media file")
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err = io.Copy(hash, file); err != nil {
+		return "", errors.NoType.Wrap(err, "error reading media file")
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// uploadMedia загрузка медиа архива, которых нет в папке
+func (uc TransferUseCase) uploadMedia(ctx context.Context, folderID *uuid.UUID, plan *importPlan) error {
+	for _, planned := range plan.media {
+		id := planned.existing
+		if id == nil {
+			var err error
+			id, err = uc.uploadFile(ctx, folderID, planned, plan.files[planned.File])
+			if err != nil {
+				return err
+			}
+			plan.upload = append(plan.upload, *id)
+		}
+		for _, source := range planned.sources {
+			plan.ids[source] = *id
+		}
+	}
+
+	return nil
+}
+
+// deleteUploaded удаление медиа, загруженных неудавшимся импортом
+func (uc TransferUseCase) deleteUploaded(ctx context.Context, plan *importPlan) {
+	ctx = context.WithoutCancel(ctx)
+	for _, id := range plan.upload {
+		if err := uc.medias.Delete(ctx, mediaActions.GetMedia{Id: id}); err != nil {
+			uc.logger.Errorw("Error deleting imported media", "mediaId", id, "error", err)
+		}
+	}
+}
+
+func (uc TransferUseCase) uploadFile(
+	ctx context.Context, folderID *uuid.UUID, planned *plannedMedia, file *zip.File,
+) (*uuid.UUID, error) {
+	reader, err := file.Open()
+	if err != nil {
+		return nil, errors.BadRequest.Wrap(err, "error reading page bundle")
+	}
+	defer reader.Close()
+
+	// загрузка в хранилище требует перемотки файла, поэтому он распаковывается во временный файл
+	tmp, err := os.CreateTemp("", "page-import-*"+filepath.Ext(planned.Filename))
+	if err != nil {
+		return nil, errors.NoType.Wrap(err, "error creating temporary file")
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	size, err := io.Copy(tmp, reader)
+	if err == nil {
+		_, err = tmp.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		return nil, errors.NoType.Wrap(err, "error unpacking media file")
+	}
+
+	return uc.medias.Create(ctx, mediaActions.CreateMedia{
+		Filename: planned.Filename,
+		Size:     size,
+		Alt:      planned.Alt,
+		Title:    planned.Title,
+		FolderId: folderID,
+		File:     tmp,
+	})
+}
+
+func (p *importPlan) item(entityType string, code string, action string) {
+	p.report.Items = append(p.report.Items, ImportItem{EntityType: entityType, Code: code, Action: action})
+}
+
+func (p *importPlan) conflict(entityType string, code string, message string) {
+	p.report.Conflicts = append(p.report.Conflicts, ImportConflict{EntityType: entityType, Code: code, Message: message})
+}
+
+func (p *importPlan) hasMedia(id uuid.UUID) bool {
+	for _, planned := range p.media {
+		for _, source := range planned.sources {
+			if source == id {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// remapMedia замена идентификаторов медиа архива на идентификаторы загруженных медиа
+func remapMedia(plan *importPlan) {
+	walkMedia(&plan.data.Page, func(id **uuid.UUID, media **mediaEntity.Media) {
+		if *id != nil {
+			mediaID := plan.ids[**id]
+			*id = &mediaID
+		}
+		*media = nil
+	})
+	for i := range plan.data.Users {
+		user := &plan.data.Users[i]
+		if user.PictureId != nil {
+			mediaID := plan.ids[*user.PictureId]
+			user.PictureId = &mediaID
+		}
+	}
+}
+
+// remapCardLinks замена идентификаторов тегов и спикеров архива на сопоставленные
+func remapCardLinks(card *entity.Card, tagIDs map[uuid.UUID]uuid.UUID, userIDs map[uuid.UUID]uuid.UUID) {
+	remapUser := func(userID **uuid.UUID, user **entity.User) {
+		if *userID != nil {
+			id := userIDs[**userID]
+			*userID = &id
+		}
+		*user = nil
+	}
+
+	if card.RegularCard != nil {
+		for i := range card.RegularCard.RegularCardsTags {
+			card.RegularCard.RegularCardsTags[i].TagID = tagIDs[card.RegularCard.RegularCardsTags[i].TagID]
+		}
+		remapUser(&card.RegularCard.UserId, &card.RegularCard.User)
+	}
+	if card.FormCard != nil {
+		for i := range card.FormCard.FormCardsTags {
+			card.FormCard.FormCardsTags[i].TagID = tagIDs[card.FormCard.FormCardsTags[i].TagID]
+		}
+		card.FormCard.Form = nil
+		remapUser(&card.FormCard.UserId, &card.FormCard.User)
+	}
+}
+
+func hashedFilename(filename string, sha string) string {
+	ext := filepath.Ext(filename)
+	if len(sha) > 8 {
+		sha = sha[:8]
+	}
+
+	return strings.TrimSuffix(filename, ext) + "-" + sha + ext
+}
+
+func cardUser(card *entity.Card) *entity.User {
+	switch {
+	case card.RegularCard != nil:
+		return card.RegularCard.User
+	case card.FormCard != nil:
+		return card.FormCard.User
+	}
+
+	return nil
+}
+
+// walkCards обход карточек всех галерей страницы
+func walkCards(page *entity.Page, visit func(card *entity.Card)) {
+	for i := range page.PagesGalleries {
+		for _, galleriesCard := range page.PagesGalleries[i].Gallery.GalleriesCards {
+			if galleriesCard.Card != nil {
+				visit(galleriesCard.Card)
+			}
+		}
+	}
+}
+
+// walkMedia обход ссылок карточек страницы и их спикеров на медиа: идентификатора и загруженного медиа
+func walkMedia(page *entity.Page, visit func(id **uuid.UUID, media **mediaEntity.Media)) {
+	walkCards(page, func(card *entity.Card) {
+		switch {
+		case card.RegularCard != nil:
+			regular := card.RegularCard
+			visit(&regular.VideoId, &regular.Video)
+			visit(&regular.VideoLiteId, &regular.VideoLite)
+			visit(&regular.VideoPreviewId, &regular.VideoPreview)
+			visit(&regular.VideoPreviewBlurId, &regular.VideoPreviewBlur)
+		case card.VideoCard != nil:
+			video := card.VideoCard
+			visit(&video.VideoId, &video.Video)
+			visit(&video.VideoLiteId, &video.VideoLite)
+			visit(&video.VideoPreviewId, &video.VideoPreview)
+			visit(&video.VideoPreviewBlurId, &video.VideoPreviewBlur)
+		case card.PhotoCard != nil:
+			visit(&card.PhotoCard.PictureId, &card.PhotoCard.Picture)
+		}
+		if user := cardUser(card); user != nil {
+			visit(&user.PictureId, &user.Picture)
+		}
+	})
+}
@@ -0,0 +1,360 @@
+package actions
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"testing"
+
+	mediaActions "github.com/aeroideaservices/focus/media/plugin/actions"
+	"github.com/aeroideaservices/focus/media/plugin/service/utils"
+	"github.com/aeroideaservices/focus/page/plugin/entity"
+	"github.com/aeroideaservices/focus/services/errors"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// memoryPages страницы окружения по пути, остальные методы репозитория импортом не используются
+type memoryPages struct {
+	PageRepository
+	pages map[string]entity.Page
+}
+
+func (r memoryPages) GetByPath(_ context.Context, path string) (*entity.Page, error) {
+	page, ok := r.pages[path]
+	if !ok {
+		return nil, errors.NotFound.New("page not found")
+	}
+	return &page, nil
+}
+
+// memoryGalleries галереи окружения по коду
+type memoryGalleries struct {
+	GalleryRepository
+	galleries map[string]entity.Gallery
+}
+
+func (r memoryGalleries) GetByCode(_ context.Context, code string) (*entity.Gallery, error) {
+	gallery, ok := r.galleries[code]
+	if !ok {
+		return nil, errors.NotFound.New("gallery not found")
+	}
+	return &gallery, nil
+}
+
+// memoryTransfer карточки и теги окружения, записанные импортом данные сохраняются в imported
+type memoryTransfer struct {
+	cards     []entity.Card
+	tags      []entity.Tag
+	importErr error
+	imported  *ImportData
+}
+
+func (r *memoryTransfer) GetCardsByCodes(_ context.Context, codes []string) ([]entity.Card, error) {
+	var cards []entity.Card
+	for _, card := range r.cards {
+		for _, code := range codes {
+			if card.Code == code {
+				cards = append(cards, card)
+				break
+			}
+		}
+	}
+	return cards, nil
+}
+
+func (r *memoryTransfer) GetTagsByTexts(_ context.Context, _ []string) ([]entity.Tag, error) {
+	return r.tags, nil
+}
+
+func (r *memoryTransfer) GetUsersByLastNames(_ context.Context, _ []string) ([]entity.User, error) {
+	return nil, nil
+}
+
+func (r *memoryTransfer) Import(_ context.Context, data ImportData) error {
+	if r.importErr != nil {
+		return r.importErr
+	}
+	r.imported = &data
+	return nil
+}
+
+// memoryMedias медиа папки импорта по имени файла
+type memoryMedias struct {
+	ids      map[string]uuid.UUID
+	contents map[uuid.UUID][]byte
+	created  []string
+	deleted  []uuid.UUID
+}
+
+func newMemoryMedias() *memoryMedias {
+	return &memoryMedias{ids: make(map[string]uuid.UUID), contents: make(map[uuid.UUID][]byte)}
+}
+
+func (m *memoryMedias) add(filename string, content []byte) uuid.UUID {
+	id := uuid.New()
+	m.ids[filename], m.contents[id] = id, content
+	return id
+}
+
+func (m *memoryMedias) Find(_ context.Context, dto mediaActions.FindMedia) (*mediaActions.MediaPreview, error) {
+	id, ok := m.ids[dto.Filename]
+	if !ok {
+		return nil, mediaActions.ErrMediaNotFound
+	}
+	return &mediaActions.MediaPreview{Id: id, Size: utils.Filesize(len(m.contents[id]))}, nil
+}
+
+func (m *memoryMedias) Download(_ context.Context, dto mediaActions.GetMedia) (string, error) {
+	file, err := os.CreateTemp("", "media-*")
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	_, err = file.Write(m.contents[dto.Id])
+	return file.Name(), err
+}
+
+func (m *memoryMedias) Create(_ context.Context, action mediaActions.CreateMedia) (*uuid.UUID, error) {
+	var content bytes.Buffer
+	if _, err := content.ReadFrom(action.File); err != nil {
+		return nil, err
+	}
+	id := m.add(action.Filename, content.Bytes())
+	m.created = append(m.created, action.Filename)
+	return &id, nil
+}
+
+func (m *memoryMedias) Delete(_ context.Context, dto mediaActions.GetMedia) error {
+	m.deleted = append(m.deleted, dto.Id)
+	return nil
+}
+
+// transferFixture окружение импорта и архив страницы about с галереей main
+type transferFixture struct {
+	pages     memoryPages
+	galleries memoryGalleries
+	transfer  *memoryTransfer
+	medias    *memoryMedias
+	bundle    Bundle
+	files     map[string][]byte
+}
+
+func newTransferFixture() *transferFixture {
+	return &transferFixture{
+		pages:     memoryPages{pages: make(map[string]entity.Page)},
+		galleries: memoryGalleries{galleries: make(map[string]entity.Gallery)},
+		transfer:  &memoryTransfer{},
+		medias:    newMemoryMedias(),
+		bundle: Bundle{
+			Version: BundleVersion,
+			Page:    entity.Page{ID: uuid.New(), Code: "about", Path: "about", Name: "About"},
+		},
+		files: make(map[string][]byte),
+	}
+}
+
+func (f *transferFixture) addGallery(code string, cards ...*entity.Card) {
+	gallery := entity.Gallery{ID: uuid.New(), Code: code, Name: code}
+	for i, card := range cards {
+		gallery.GalleriesCards = append(gallery.GalleriesCards, entity.GalleriesCards{
+			GalleryID: &gallery.ID, CardID: &card.ID, Card: card, Position: i,
+		})
+	}
+	f.bundle.Page.PagesGalleries = append(f.bundle.Page.PagesGalleries, entity.PagesGalleries{
+		PagesID: &f.bundle.Page.ID, GalleryID: &gallery.ID, Gallery: gallery,
+	})
+}
+
+func (f *transferFixture) addMedia(filename string, content []byte) uuid.UUID {
+	sum := sha256.Sum256(content)
+	media := BundleMedia{
+		ID:       uuid.New(),
+		Filename: filename,
+		Size:     int64(len(content)),
+		Sha256:   hex.EncodeToString(sum[:]),
+	}
+	media.File = bundleMediaDir + "/" + media.ID.String()
+	f.bundle.Media = append(f.bundle.Media, media)
+	f.files[media.File] = content
+	return media.ID
+}
+
+func (f *transferFixture) run(t *testing.T) (*ImportReport, error) {
+	t.Helper()
+
+	var archive bytes.Buffer
+	writer := zip.NewWriter(&archive)
+	f.files[bundleManifest], _ = json.Marshal(f.bundle)
+	for name, content := range f.files {
+		file, err := writer.Create(name)
+		if err == nil {
+			_, err = file.Write(content)
+		}
+		if err != nil {
+			t.Fatalf("error writing bundle: %v", err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("error writing bundle: %v", err)
+	}
+
+	uc := NewTransferUseCase(
+		f.pages, f.galleries, f.transfer, CardUseCase{}, f.medias, nil, zap.NewNop().Sugar(),
+	)
+	return uc.Import(context.Background(), ImportPageRequest{
+		Archive: bytes.NewReader(archive.Bytes()), Size: int64(archive.Len()),
+	})
+}
+
+func photoCard(code string, pictureID uuid.UUID) *entity.Card {
+	photo := &entity.PhotoCard{ID: uuid.New(), PictureId: &pictureID}
+	return &entity.Card{ID: uuid.New(), Code: code, Type: "photo", PhotoCard: photo, PhotoCardId: &photo.ID}
+}
+
+func TestTransferUseCase_ImportConflicts(t *testing.T) {
+	f := newTransferFixture()
+	pictureID := f.addMedia("photo.jpg", []byte("photo"))
+	f.addGallery("main", photoCard("promo", pictureID))
+	f.addGallery("main", photoCard("", pictureID))
+	f.transfer.cards = []entity.Card{{ID: uuid.New(), Code: "promo", Type: "video"}}
+
+	report, err := f.run(t)
+	if err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+
+	want := map[string]string{"gallery": "main", "card": "promo"}
+	if len(report.Conflicts) != len(want) {
+		t.Fatalf("conflicts = %+v, want %v", report.Conflicts, want)
+	}
+	for _, conflict := range report.Conflicts {
+		if want[conflict.EntityType] != conflict.Code {
+			t.Errorf("unexpected conflict %+v", conflict)
+		}
+	}
+	if report.Applied || f.transfer.imported != nil || len(f.medias.created) != 0 {
+		t.Errorf("bundle with conflicts is imported")
+	}
+}
+
+func TestTransferUseCase_ImportRemapsIds(t *testing.T) {
+	f := newTransferFixture()
+	tag := entity.Tag{ID: uuid.New(), Text: "news", Link: "/news"}
+	regular := &entity.RegularCard{ID: uuid.New()}
+	regular.RegularCardsTags = []entity.RegularCardsTags{{RegularCardID: regular.ID, TagID: tag.ID, Tag: tag}}
+	card := &entity.Card{
+		ID: uuid.New(), Code: "promo", Type: "regular", RegularCard: regular, RegularCardId: &regular.ID,
+	}
+	f.addGallery("main", card)
+
+	existingPage := entity.Page{ID: uuid.New(), Code: "about", Path: "about"}
+	existingGallery := entity.Gallery{ID: uuid.New(), Code: "main"}
+	existingCard := entity.Card{ID: uuid.New(), Code: "promo", Type: "regular"}
+	existingTag := entity.Tag{ID: uuid.New(), Text: "news", Link: "/news"}
+	f.pages.pages["about"] = existingPage
+	f.galleries.galleries["main"] = existingGallery
+	f.transfer.cards = []entity.Card{existingCard}
+	f.transfer.tags = []entity.Tag{existingTag}
+
+	report, err := f.run(t)
+	if err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+	if !report.Applied || f.transfer.imported == nil {
+		t.Fatalf("report = %+v, want applied", report)
+	}
+
+	data := f.transfer.imported
+	if data.Page.ID != existingPage.ID || !data.Update[existingPage.ID] {
+		t.Errorf("page id = %s, want existing %s", data.Page.ID, existingPage.ID)
+	}
+	gallery := data.Page.PagesGalleries[0].Gallery
+	if gallery.ID != existingGallery.ID || *data.Page.PagesGalleries[0].GalleryID != existingGallery.ID {
+		t.Errorf("gallery id = %s, want existing %s", gallery.ID, existingGallery.ID)
+	}
+	imported := gallery.GalleriesCards[0].Card
+	if imported.ID != existingCard.ID || *gallery.GalleriesCards[0].CardID != existingCard.ID {
+		t.Errorf("card id = %s, want existing %s", imported.ID, existingCard.ID)
+	}
+	// типизированная часть создается заново, а не перезаписывает часть карточки из архива
+	if imported.RegularCard.ID == regular.ID {
+		t.Errorf("regular card id is not replaced")
+	}
+	if tagID := imported.RegularCard.RegularCardsTags[0].TagID; tagID != existingTag.ID {
+		t.Errorf("tag id = %s, want existing %s", tagID, existingTag.ID)
+	}
+	if len(data.Tags) != 0 {
+		t.Errorf("tags = %+v, want existing tag reused", data.Tags)
+	}
+}
+
+func TestTransferUseCase_ImportMediaDedup(t *testing.T) {
+	f := newTransferFixture()
+	first := f.addMedia("photo.jpg", []byte("photo"))
+	second := f.addMedia("photo-copy.jpg", []byte("photo"))
+	reused := f.addMedia("logo.png", []byte("logo"))
+	other := f.addMedia("banner.png", []byte("new banner"))
+	f.addGallery("main", photoCard("", first), photoCard("", second), photoCard("", reused), photoCard("", other))
+
+	existingLogo := f.medias.add("logo.png", []byte("logo"))
+	f.medias.add("banner.png", []byte("old banner"))
+
+	report, err := f.run(t)
+	if err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+	if !report.Applied {
+		t.Fatalf("report = %+v, want applied", report)
+	}
+
+	// одинаковое содержимое загружается один раз, файл с тем же именем и другим содержимым не заменяется
+	wantCreated := map[string]bool{"photo.jpg": true, hashedFilename("banner.png", f.bundle.Media[3].Sha256): true}
+	if len(f.medias.created) != len(wantCreated) {
+		t.Fatalf("uploaded = %v, want %v", f.medias.created, wantCreated)
+	}
+	for _, filename := range f.medias.created {
+		if !wantCreated[filename] {
+			t.Errorf("unexpected upload %s", filename)
+		}
+	}
+
+	var pictures []uuid.UUID
+	walkCards(&f.transfer.imported.Page, func(card *entity.Card) {
+		pictures = append(pictures, *card.PhotoCard.PictureId)
+	})
+	if pictures[0] != f.medias.ids["photo.jpg"] || pictures[1] != pictures[0] {
+		t.Errorf("pictures of equal media = %v, want %s", pictures[:2], f.medias.ids["photo.jpg"])
+	}
+	if pictures[2] != existingLogo {
+		t.Errorf("picture = %s, want existing media %s", pictures[2], existingLogo)
+	}
+}
+
+func TestTransferUseCase_ImportDeletesUploadedMedia(t *testing.T) {
+	f := newTransferFixture()
+	uploaded := f.addMedia("photo.jpg", []byte("photo"))
+	reused := f.addMedia("logo.png", []byte("logo"))
+	f.addGallery("main", photoCard("", uploaded), photoCard("", reused))
+	existingLogo := f.medias.add("logo.png", []byte("logo"))
+	f.transfer.importErr = errors.Conflict.New("imported page conflicts with existing data")
+
+	_, err := f.run(t)
+	if errors.GetType(err) != errors.Conflict {
+		t.Fatalf("Import() error = %v, want conflict", err)
+	}
+
+	if len(f.medias.deleted) != 1 || f.medias.deleted[0] != f.medias.ids["photo.jpg"] {
+		t.Errorf("deleted = %v, want uploaded %s", f.medias.deleted, f.medias.ids["photo.jpg"])
+	}
+	for _, id := range f.medias.deleted {
+		if id == existingLogo {
+			t.Errorf("existing media %s is deleted", existingLogo)
+		}
+	}
+}
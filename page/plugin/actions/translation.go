@@ -0,0 +1,340 @@
+package actions
+
+import (
+	"context"
+	"time"
+
+	"github.com/aeroideaservices/focus/page/plugin/entity"
+	"github.com/aeroideaservices/focus/page/plugin/services/locales"
+	"github.com/aeroideaservices/focus/services/errors"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+var ErrDefaultLocaleTranslation = errors.BadRequest.New("default locale content is edited in the entity itself").
+	T("translation.default-locale")
+
+type TranslationRequest struct {
+	EntityType entity.TranslationType `json:"-"`
+	ID         uuid.UUID              `json:"-"`
+	Locale     string                 `json:"-"`
+}
+
+type SaveTranslationRequest struct {
+	TranslationRequest
+	Fields map[string]string `json:"fields" validate:"required"` // Fields значения переводимых полей, пустое значение удаляет перевод поля
+}
+
+type TranslationDto struct {
+	Locale    string            `json:"locale"`
+	Fields    map[string]string `json:"fields"`
+	UpdatedAt time.Time         `json:"updatedAt"`
+}
+
+type GetTranslationsResponse struct {
+	EntityType entity.TranslationType `json:"entityType"`
+	EntityID   uuid.UUID              `json:"entityId"`
+	Fields     []string               `json:"fields"` // Fields коды переводимых полей сущности
+	Items      []TranslationDto       `json:"items"`
+}
+
+// LocalesDto настроенные языки содержимого
+type LocalesDto struct {
+	Default   string              `json:"default"`
+	Locales   []string            `json:"locales"`
+	Fallbacks map[string][]string `json:"fallbacks"`
+}
+
+// TranslationStatusDto полнота перевода страницы на язык
+type TranslationStatusDto struct {
+	Locale     string               `json:"locale"`
+	Total      int                  `json:"total"`      // Total количество заполненных полей на языке по умолчанию
+	Translated int                  `json:"translated"` // Translated количество переведенных из них, без учета языков замены
+	Complete   bool                 `json:"complete"`
+	Missing    []MissingTranslation `json:"missing"`
+}
+
+// MissingTranslation непереведенное поле
+type MissingTranslation struct {
+	EntityType entity.TranslationType `json:"entityType"`
+	EntityID   uuid.UUID              `json:"entityId"`
+	Field      string                 `json:"field"`
+}
+
+type GetTranslationStatusResponse struct {
+	PageID   uuid.UUID              `json:"pageId"`
+	Statuses []TranslationStatusDto `json:"statuses"`
+}
+
+// TranslationUseCase переводы страниц, галерей, карточек и тегов.
+// Содержимое на языке по умолчанию редактируется в самих сущностях, здесь хранятся только переводы на другие языки.
+// Переводы удаляются вместе с сущностями и копируются при их копировании, но не переносятся экспортом и импортом.
+type TranslationUseCase struct {
+	translationRepository TranslationRepository
+	pageRepository        PageRepository
+	galleryRepository     GalleryRepository
+	cardRepository        CardRepository
+	tagRepository         TagRepository
+	locales               *locales.Locales
+	logger                *zap.SugaredLogger
+}
+
+func NewTranslationUseCase(
+	translationRepository TranslationRepository, pageRepository PageRepository, galleryRepository GalleryRepository,
+	cardRepository CardRepository, tagRepository TagRepository, locales *locales.Locales, logger *zap.SugaredLogger,
+) *TranslationUseCase {
+	return &TranslationUseCase{
+		translationRepository: translationRepository,
+		pageRepository:        pageRepository,
+		galleryRepository:     galleryRepository,
+		cardRepository:        cardRepository,
+		tagRepository:         tagRepository,
+		locales:               locales,
+		logger:                logger,
+	}
+}
+
+func (uc TranslationUseCase) GetLocales() LocalesDto {
+	return LocalesDto{
+		Default:   uc.locales.Default(),
+		Locales:   uc.locales.List(),
+		Fallbacks: uc.locales.Fallbacks(),
+	}
+}
+
+func (uc TranslationUseCase) GetList(ctx context.Context, dto TranslationRequest) (*GetTranslationsResponse, error) {
+	uc.logger.Debug("Getting translations")
+	err := uc.checkExists(ctx, dto.EntityType, dto.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	translations, err := uc.translationRepository.GetList(ctx, []uuid.UUID{dto.ID}, uc.locales.Translated())
+	if err != nil {
+		return nil, err
+	}
+
+	response := &GetTranslationsResponse{
+		EntityType: dto.EntityType,
+		EntityID:   dto.ID,
+		Fields:     entity.TranslatableFields[dto.EntityType],
+		Items:      make([]TranslationDto, 0, len(translations)),
+	}
+	for _, translation := range translations {
+		if translation.EntityType != dto.EntityType {
+			continue
+		}
+		response.Items = append(response.Items, TranslationDto{
+			Locale:    translation.Locale,
+			Fields:    translation.Fields,
+			UpdatedAt: translation.UpdatedAt,
+		})
+	}
+
+	uc.logger.Debug("Got translations")
+	return response, nil
+}
+
+// Save сохранение перевода сущности на язык.
+// Переданные поля заменяют сохраненный перевод целиком, поля с пустым значением не сохраняются.
+func (uc TranslationUseCase) Save(ctx context.Context, dto SaveTranslationRequest) error {
+	uc.logger.Debug("Saving translation")
+	err := uc.checkLocale(dto.Locale)
+	if err != nil {
+		return err
+	}
+
+	fields := make(entity.TranslationFields, len(dto.Fields))
+	for code, value := range dto.Fields {
+		if !isTranslatableField(dto.EntityType, code) {
+			return errors.BadRequest.Newf("field %s of %s is not translatable", code, dto.EntityType)
+		}
+		if value != "" {
+			fields[code] = value
+		}
+	}
+
+	err = uc.checkExists(ctx, dto.EntityType, dto.ID)
+	if err != nil {
+		return err
+	}
+
+	if len(fields) == 0 {
+		err = uc.translationRepository.Delete(ctx, dto.EntityType, dto.ID, dto.Locale)
+	} else {
+		err = uc.translationRepository.Save(ctx, &entity.Translation{
+			EntityType: dto.EntityType,
+			EntityID:   dto.ID,
+			Locale:     dto.Locale,
+			Fields:     fields,
+		})
+	}
+	if err != nil {
+		return err
+	}
+
+	uc.logger.Debug("Saved translation")
+	return nil
+}
+
+func (uc TranslationUseCase) Delete(ctx context.Context, dto TranslationRequest) error {
+	uc.logger.Debug("Deleting translation")
+	err := uc.checkLocale(dto.Locale)
+	if err != nil {
+		return err
+	}
+
+	err = uc.translationRepository.Delete(ctx, dto.EntityType, dto.ID, dto.Locale)
+	if err != nil {
+		return err
+	}
+
+	uc.logger.Debug("Deleted translation")
+	return nil
+}
+
+// GetStatus полнота перевода страницы вместе с ее галереями, карточками и тегами на каждый язык.
+// Учитываются только поля, заполненные на языке по умолчанию.
+func (uc TranslationUseCase) GetStatus(ctx context.Context, pageID uuid.UUID) (*GetTranslationStatusResponse, error) {
+	uc.logger.Debug("Getting page translation status")
+	page, err := uc.pageRepository.GetById(ctx, pageID)
+	if err != nil {
+		return nil, err
+	}
+
+	var sourceFields []entity.TranslatableField
+	for _, field := range page.TranslatableFields() {
+		if *field.Value != "" {
+			sourceFields = append(sourceFields, field)
+		}
+	}
+
+	translated := uc.locales.Translated()
+	translations, err := uc.translationRepository.GetList(ctx, translationEntityIDs(sourceFields), translated)
+	if err != nil {
+		return nil, err
+	}
+	byLocale := groupTranslations(translations)
+
+	response := &GetTranslationStatusResponse{PageID: pageID, Statuses: make([]TranslationStatusDto, 0, len(translated))}
+	for _, locale := range translated {
+		status := TranslationStatusDto{Locale: locale, Total: len(sourceFields), Missing: []MissingTranslation{}}
+		for _, field := range sourceFields {
+			if byLocale[locale][field.TranslationKey][field.Code] != "" {
+				status.Translated++
+				continue
+			}
+			status.Missing = append(status.Missing, MissingTranslation{
+				EntityType: field.EntityType,
+				EntityID:   field.EntityID,
+				Field:      field.Code,
+			})
+		}
+		status.Complete = status.Translated == status.Total
+		response.Statuses = append(response.Statuses, status)
+	}
+
+	uc.logger.Debug("Got page translation status")
+	return response, nil
+}
+
+// Translate замена полей на перевод на язык locale с учетом языков замены.
+// Для языка по умолчанию поля не изменяются.
+func (uc TranslationUseCase) Translate(ctx context.Context, locale string, fields []entity.TranslatableField) error {
+	chain, err := uc.locales.Chain(locale)
+	if err != nil {
+		return unknownLocaleError(locale)
+	}
+	if len(chain) == 0 || len(fields) == 0 {
+		return nil
+	}
+
+	translations, err := uc.translationRepository.GetList(ctx, translationEntityIDs(fields), chain)
+	if err != nil {
+		return err
+	}
+
+	byLocale := groupTranslations(translations)
+	ordered := make([]map[entity.TranslationKey]entity.TranslationFields, 0, len(chain))
+	for _, chainLocale := range chain {
+		ordered = append(ordered, byLocale[chainLocale])
+	}
+	entity.Translate(fields, ordered...)
+
+	return nil
+}
+
+// DefaultLocale язык, на котором заполнены поля сущностей
+func (uc TranslationUseCase) DefaultLocale() string {
+	return uc.locales.Default()
+}
+
+func (uc TranslationUseCase) checkLocale(locale string) error {
+	if locale == uc.locales.Default() {
+		return ErrDefaultLocaleTranslation
+	}
+	if !uc.locales.Has(locale) {
+		return unknownLocaleError(locale)
+	}
+
+	return nil
+}
+
+func (uc TranslationUseCase) checkExists(ctx context.Context, entityType entity.TranslationType, id uuid.UUID) error {
+	var err error
+	switch entityType {
+	case entity.TranslationTypePage:
+		_, err = uc.pageRepository.GetByIdWithoutAssociate(ctx, id)
+	case entity.TranslationTypeGallery:
+		_, err = uc.galleryRepository.GetByIdWithoutAssociate(ctx, id)
+	case entity.TranslationTypeCard:
+		_, err = uc.cardRepository.GetByIdWithoutAssociate(ctx, id)
+	case entity.TranslationTypeTag:
+		_, err = uc.tagRepository.GetById(ctx, id)
+	default:
+		err = errors.BadRequest.Newf("unknown entity type %s", entityType)
+	}
+
+	return err
+}
+
+func unknownLocaleError(locale string) error {
+	return errors.BadRequest.Newf("unknown locale %s", locale).T("translation.unknown-locale", locale)
+}
+
+func isTranslatableField(entityType entity.TranslationType, code string) bool {
+	for _, field := range entity.TranslatableFields[entityType] {
+		if field == code {
+			return true
+		}
+	}
+
+	return false
+}
+
+func translationEntityIDs(fields []entity.TranslatableField) []uuid.UUID {
+	ids := make([]uuid.UUID, 0, len(fields))
+	seen := make(map[uuid.UUID]bool)
+	for _, field := range fields {
+		if !seen[field.EntityID] {
+			seen[field.EntityID] = true
+			ids = append(ids, field.EntityID)
+		}
+	}
+
+	return ids
+}
+
+// groupTranslations переводы по языкам и переводимым сущностям
+func groupTranslations(translations []entity.Translation) map[string]map[entity.TranslationKey]entity.TranslationFields {
+	byLocale := make(map[string]map[entity.TranslationKey]entity.TranslationFields)
+	for _, translation := range translations {
+		if byLocale[translation.Locale] == nil {
+			byLocale[translation.Locale] = make(map[entity.TranslationKey]entity.TranslationFields)
+		}
+		key := entity.TranslationKey{EntityType: translation.EntityType, EntityID: translation.EntityID}
+		byLocale[translation.Locale][key] = translation.Fields
+	}
+
+	return byLocale
+}
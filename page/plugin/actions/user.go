@@ -0,0 +1,247 @@
+package actions
+
+import (
+	"context"
+	"io"
+
+	mediaActions "github.com/aeroideaservices/focus/media/plugin/actions"
+	"github.com/aeroideaservices/focus/page/plugin/entity"
+	"github.com/aeroideaservices/focus/services/errors"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const defaultUsersLimit = 20
+
+var ErrUserInUse = errors.Conflict.New("user is linked to cards").T("user.in-use")
+
+type UserFilter struct {
+	Query  string `form:"query"` // Query поиск по имени, фамилии и должности
+	Limit  int    `form:"limit" validate:"omitempty,min=1,max=100"`
+	Offset int    `form:"offset" validate:"omitempty,min=0"`
+}
+
+type UserList struct {
+	Total int64     `json:"total"`
+	Items []UserDto `json:"items"`
+}
+
+type SaveUserRequest struct {
+	ID        uuid.UUID  `json:"-"`
+	FirstName string     `json:"firstName" validate:"required,min=1,max=50"`
+	LastName  string     `json:"lastName" validate:"required,min=1,max=50"`
+	Position  string     `json:"position" validate:"required,min=1,max=50"`
+	PictureId *uuid.UUID `json:"pictureId"`
+}
+
+type UploadUserPictureRequest struct {
+	ID       uuid.UUID     `validate:"required"`
+	Filename string        `validate:"required,min=3"`
+	Size     int64         `validate:""`
+	FolderId *uuid.UUID    `validate:"omitempty,notBlank"`
+	File     io.ReadSeeker `validate:"required"`
+}
+
+type CreateUserResponse struct {
+	ID uuid.UUID `json:"id"`
+}
+
+// UserCardDto карточка, в которой указан спикер
+type UserCardDto struct {
+	ID          uuid.UUID `json:"id"`
+	Name        string    `json:"name"`
+	Code        string    `json:"code"`
+	Type        string    `json:"type"`
+	Title       string    `json:"title"`
+	IsPublished bool      `json:"isPublished"`
+}
+
+// UserUseCase управление спикерами карточек
+type UserUseCase struct {
+	userRepository UserRepository
+	medias         *mediaActions.Medias
+	logger         *zap.SugaredLogger
+}
+
+func NewUserUseCase(
+	userRepository UserRepository, medias *mediaActions.Medias, logger *zap.SugaredLogger,
+) *UserUseCase {
+	return &UserUseCase{
+		userRepository: userRepository,
+		medias:         medias,
+		logger:         logger,
+	}
+}
+
+// GetList получение списка спикеров с поиском и постраничной выборкой
+func (uc UserUseCase) GetList(ctx context.Context, filter UserFilter) (*UserList, error) {
+	uc.logger.Debug("Getting list users")
+	if filter.Limit == 0 {
+		filter.Limit = defaultUsersLimit
+	}
+
+	users, total, err := uc.userRepository.GetList(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]UserDto, len(users))
+	for i, user := range users {
+		items[i] = userDto(user)
+	}
+
+	uc.logger.Debug("Got list users")
+	return &UserList{Total: total, Items: items}, nil
+}
+
+func (uc UserUseCase) GetById(ctx context.Context, userId uuid.UUID) (*UserDto, error) {
+	user, err := uc.userRepository.GetById(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	dto := userDto(*user)
+	return &dto, nil
+}
+
+func (uc UserUseCase) Create(ctx context.Context, dto SaveUserRequest) (*CreateUserResponse, error) {
+	uc.logger.Debug("Creating user")
+	err := uc.checkPicture(ctx, dto.PictureId)
+	if err != nil {
+		return nil, err
+	}
+
+	user := userFromRequest(dto)
+	user.ID = uuid.New()
+	err = uc.userRepository.Create(ctx, &user)
+	if err != nil {
+		return nil, err
+	}
+
+	uc.logger.Debug("Created user")
+	return &CreateUserResponse{ID: user.ID}, nil
+}
+
+// Update обновление спикера, без pictureId картинка отвязывается
+func (uc UserUseCase) Update(ctx context.Context, dto SaveUserRequest) error {
+	uc.logger.Debug("Updating user")
+	_, err := uc.userRepository.GetById(ctx, dto.ID)
+	if err != nil {
+		return err
+	}
+
+	err = uc.checkPicture(ctx, dto.PictureId)
+	if err != nil {
+		return err
+	}
+
+	user := userFromRequest(dto)
+	err = uc.userRepository.Update(ctx, &user)
+	if err != nil {
+		return err
+	}
+
+	uc.logger.Debug("Updated user")
+	return nil
+}
+
+// Delete удаление спикера, спикер, указанный в карточках, не удаляется
+func (uc UserUseCase) Delete(ctx context.Context, userId uuid.UUID) error {
+	uc.logger.Debug("Deleting user")
+	_, err := uc.userRepository.GetById(ctx, userId)
+	if err != nil {
+		return err
+	}
+
+	err = uc.userRepository.Delete(ctx, userId)
+	if err != nil {
+		return err
+	}
+
+	uc.logger.Debug("Deleted user")
+	return nil
+}
+
+// UploadPicture загрузка картинки спикера в медиа и привязка ее к спикеру.
+// Предыдущая картинка остается в медиа, она может использоваться в других местах.
+func (uc UserUseCase) UploadPicture(ctx context.Context, dto UploadUserPictureRequest) (*UserDto, error) {
+	uc.logger.Debug("Uploading user picture", "fileName", dto.Filename)
+	user, err := uc.userRepository.GetById(ctx, dto.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	pictureId, err := uc.medias.Create(ctx, mediaActions.CreateMedia{
+		Filename: dto.Filename,
+		Size:     dto.Size,
+		FolderId: dto.FolderId,
+		File:     dto.File,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	user.PictureId, user.Picture = pictureId, nil
+	err = uc.userRepository.Update(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+
+	uc.logger.Debug("Uploaded user picture")
+	return uc.GetById(ctx, dto.ID)
+}
+
+// GetCards получение карточек, в которых указан спикер
+func (uc UserUseCase) GetCards(ctx context.Context, userId uuid.UUID) ([]UserCardDto, error) {
+	_, err := uc.userRepository.GetById(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	cards, err := uc.userRepository.GetCards(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	dtos := make([]UserCardDto, len(cards))
+	for i, card := range cards {
+		dtos[i] = UserCardDto{
+			ID:          card.ID,
+			Name:        card.Name,
+			Code:        card.Code,
+			Type:        card.Type,
+			Title:       card.Title,
+			IsPublished: card.IsPublished,
+		}
+	}
+
+	return dtos, nil
+}
+
+func (uc UserUseCase) checkPicture(ctx context.Context, pictureId *uuid.UUID) error {
+	if pictureId == nil {
+		return nil
+	}
+
+	return uc.medias.CheckIds(ctx, *pictureId)
+}
+
+func userFromRequest(dto SaveUserRequest) entity.User {
+	return entity.User{
+		ID:        dto.ID,
+		FirstName: dto.FirstName,
+		LastName:  dto.LastName,
+		Position:  dto.Position,
+		PictureId: dto.PictureId,
+	}
+}
+
+func userDto(user entity.User) UserDto {
+	return UserDto{
+		ID:        user.ID,
+		FirstName: user.FirstName,
+		LastName:  user.LastName,
+		Position:  user.Position,
+		Picture:   user.Picture,
+	}
+}
@@ -7,12 +7,14 @@ import (
 	"errors"
 	"fmt"
 	mediaActions "github.com/aeroideaservices/focus/media/plugin/actions"
+	mediaEntity "github.com/aeroideaservices/focus/media/plugin/entity"
+	"github.com/aeroideaservices/focus/page/plugin/entity"
 	"github.com/aeroideaservices/focus/page/plugin/services"
+	"github.com/aeroideaservices/focus/page/plugin/services/hls"
+	"github.com/aeroideaservices/focus/page/plugin/services/jobs"
+	"github.com/aeroideaservices/focus/page/plugin/services/transcriber"
 	"github.com/google/uuid"
 	"go.uber.org/zap"
-	"io"
-	"io/ioutil"
-	"net/http"
 	"os"
 	"path/filepath"
 	"strings"
@@ -20,43 +22,50 @@ import (
 )
 
 const (
-	yandexSpeechUrl          = "https://transcribe.api.cloud.yandex.net/speech/stt/v2/longRunningRecognize"
-	yandexSpeechOperationUrl = "https://operation.api.cloud.yandex.net/operations/"
-	chunks                   = 20
-	audioEncoding            = "MP3"
-	videoFormats             = ".mp4"
+	chunks           = 20
+	audioEncoding    = "MP3"
+	videoFormats     = ".mp4"
+	subtitlesTimeout = 2 * time.Hour          // subtitlesTimeout максимальное время генерации субтитров после загрузки видео
+	maxCueWords      = 10                     // maxCueWords максимальное количество слов во фрагменте дорожки субтитров
+	maxCueDuration   = 4 * time.Second        // maxCueDuration максимальная длительность фрагмента дорожки субтитров
+	minCueDuration   = 500 * time.Millisecond // minCueDuration минимальная длительность фрагмента дорожки субтитров
+	streamTimeout    = 6 * time.Hour          // streamTimeout максимальное время формирования потока HLS
 )
 
 type VideoUseCase struct {
-	medias       *mediaActions.Medias
-	logger       *zap.SugaredLogger
-	yandexApiKey string
+	medias      *mediaActions.Medias
+	logger      *zap.SugaredLogger
+	transcriber transcriber.Transcriber
+	options     transcriber.Options
+	hls         hls.Transcoder
+	hlsOptions  hls.Options
+	jobs        JobRepository
 }
 
 func NewVideoUseCase(
 	medias *mediaActions.Medias,
 	logger *zap.SugaredLogger,
-	yandexApiKey string,
+	transcriber transcriber.Transcriber,
+	options transcriber.Options,
+	hlsTranscoder hls.Transcoder,
+	hlsOptions hls.Options,
+	jobRepository JobRepository,
 ) *VideoUseCase {
 	return &VideoUseCase{
-		medias:       medias,
-		logger:       logger,
-		yandexApiKey: yandexApiKey,
+		medias:      medias,
+		logger:      logger,
+		transcriber: transcriber,
+		options:     options,
+		hls:         hlsTranscoder,
+		hlsOptions:  hlsOptions,
+		jobs:        jobRepository,
 	}
 }
 
 func (uc VideoUseCase) Create(request CreateVideoRequest) (*CreateVideoResponse, error) {
 	ctx := context.Background()
 
-	uc.logger.Debug("Creating video samples", "fileName", request.Filename)
-	videoSamples, err := uc.createVideoSamples(request.File)
-	if err != nil {
-		return nil, err
-	}
-
-	uc.logger.Debug("Uploading medias", "fileName", request.Filename)
-	fileExt := filepath.Ext(request.Filename)
-	fileTitle := strings.TrimSuffix(request.Filename, fileExt)
+	uc.logger.Debug("Uploading video", "fileName", request.Filename)
 	ids, err := uc.medias.UploadList(
 		ctx, mediaActions.CreateMediasList{
 			FolderId: request.FolderId,
@@ -66,21 +75,6 @@ func (uc VideoUseCase) Create(request CreateVideoRequest) (*CreateVideoResponse,
 					Size:     request.Size,
 					File:     request.File,
 				},
-				//{
-				//	Filename: fileTitle + "_compressed" + fileExt,
-				//	Size:     videoSamples.CompressedVideo.Size(),
-				//	File:     videoSamples.CompressedVideo,
-				//},
-				{
-					Filename: fileTitle + "_preview" + ".jpg",
-					Size:     videoSamples.Preview.Size(),
-					File:     videoSamples.Preview,
-				},
-				{
-					Filename: fileTitle + "_preview_blurred" + ".jpg",
-					Size:     videoSamples.PreviewBlurred.Size(),
-					File:     videoSamples.PreviewBlurred,
-				},
 			},
 		},
 	)
@@ -88,96 +82,206 @@ func (uc VideoUseCase) Create(request CreateVideoRequest) (*CreateVideoResponse,
 		return nil, err
 	}
 
-	if len(ids) != 3 {
+	if len(ids) != 1 {
 		return nil, fmt.Errorf("error uploading medias")
 	}
 
-	go uc.GenerateSubtitles(ctx, []uuid.UUID{ids[0]})
+	stream, err := uc.medias.CreateStream(ctx, mediaActions.GetMedia{Id: ids[0]})
+	if err != nil {
+		return nil, err
+	}
+
+	payload := VideoJobPayload{
+		MediaId:  ids[0],
+		FolderId: request.FolderId,
+		Filename: request.Filename,
+	}
+	samplesJob, err := newVideoJob(VideoJobSamples, payload)
+	if err != nil {
+		return nil, err
+	}
+	subtitlesJob, err := newVideoJob(VideoJobSubtitles, payload)
+	if err != nil {
+		return nil, err
+	}
+	streamJob, err := newVideoJob(VideoJobStream, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	err = uc.jobs.Enqueue(ctx, samplesJob, subtitlesJob, streamJob)
+	if err != nil {
+		return nil, err
+	}
 
 	return &CreateVideoResponse{
-		VideoId: ids[0],
-		//VideoLiteId:      ids[1],
-		PreviewId:        ids[1],
-		PreviewBlurredId: ids[2],
+		VideoId:      ids[0],
+		PlaylistUrl:  stream.PlaylistUrl,
+		StreamStatus: stream.Status,
+		Jobs: VideoJobs{
+			Samples:   samplesJob.ID,
+			Subtitles: subtitlesJob.ID,
+			Stream:    streamJob.ID,
+		},
 	}, nil
 }
 
-func (uc VideoUseCase) GenerateSubtitles(ctx context.Context, mediaIds []uuid.UUID) error {
-
+// GenerateStreams постановка видео в очередь на формирование потоков HLS.
+// Потоки формируются в фоне, статус возвращается в списке потоков медиа.
+func (uc VideoUseCase) GenerateStreams(ctx context.Context, mediaIds []uuid.UUID) error {
+	list := make([]entity.Job, 0, len(mediaIds))
 	for _, id := range mediaIds {
-		// get file from s3
-		fileName, err := uc.medias.Download(ctx, mediaActions.GetMedia{Id: id})
+		_, err := uc.medias.CreateStream(ctx, mediaActions.GetMedia{Id: id})
 		if err != nil {
-			os.Remove(fileName)
-			return err
-		}
-		if !strings.Contains(videoFormats, filepath.Ext(fileName)) {
-			os.Remove(fileName)
-			return errors.New("file is not video")
+			return fmt.Errorf("error creating stream for media %s: %w", id, err)
 		}
 
-		// get audio from video
-		audio, audioFN, err := uc.getAudioFromVideo(fileName)
-
-		os.Remove(fileName)
-
+		job, err := newVideoJob(VideoJobStream, VideoJobPayload{MediaId: id})
 		if err != nil {
 			return err
 		}
-		if audio == nil {
-			return errors.New("error getting audio from video")
-		}
+		list = append(list, job)
+	}
 
-		// save audio to s3
-		uri, audioId, err := uc.medias.UploadReturnsId(
-			ctx, mediaActions.CreateMedia{
-				Filename: audioFN,
-				Size:     audio.Size(),
-				File:     audio,
-			},
-		)
+	return uc.jobs.Enqueue(ctx, list...)
+}
 
-		os.Remove(audioFN)
+func (uc VideoUseCase) generateStream(ctx context.Context, id uuid.UUID) error {
+	err := uc.medias.UpdateStreamStatus(ctx, mediaActions.UpdateMediaStreamStatus{
+		Id:     id,
+		Status: mediaEntity.StreamStatusProcessing,
+	})
+	if err != nil {
+		return err
+	}
 
-		//  url of audio to yandex speech
-		operation, err := uc.requestYandexSpeech(uri)
+	fileName, err := uc.medias.Download(ctx, mediaActions.GetMedia{Id: id})
+	if fileName != "" {
+		defer os.Remove(fileName)
+	}
+	if err != nil {
+		return err
+	}
 
-		if err != nil {
-			return err
+	uc.logger.Debug("Transcoding video to HLS", "mediaId", id)
+	result, err := uc.hls.Transcode(ctx, fileName, uc.hlsOptions)
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(result.Dir)
+
+	variants := make([]mediaEntity.StreamVariant, len(result.Variants))
+	for i, variant := range result.Variants {
+		variants[i] = mediaEntity.StreamVariant{
+			Name:      variant.Name,
+			Width:     variant.Width,
+			Height:    variant.Height,
+			Bandwidth: variant.Bandwidth,
+			Playlist:  variant.Playlist,
 		}
+	}
 
-		operation, err = uc.getResultOfYandexSpeech(operation.Id)
-		if err != nil {
-			return err
-		}
+	return uc.medias.UploadStream(ctx, mediaActions.UploadMediaStream{
+		Id:       id,
+		Dir:      result.Dir,
+		Variants: variants,
+	})
+}
 
-		saveOperations, err := uc.splitSubtitles(*operation, chunks)
+// GenerateSubtitles распознавание речи в видео и сохранение субтитров.
+// Обработка прерывается на первой ошибке или при отмене контекста.
+func (uc VideoUseCase) GenerateSubtitles(ctx context.Context, mediaIds []uuid.UUID) error {
+	for _, id := range mediaIds {
+		err := uc.generateSubtitles(ctx, id)
 		if err != nil {
-			return err
+			return fmt.Errorf("error generating subtitles for media %s: %w", id, err)
 		}
+	}
+	return nil
+}
 
-		subJson, err := json.Marshal(saveOperations)
-		if err != nil {
-			return err
-		}
+func (uc VideoUseCase) generateSubtitles(ctx context.Context, id uuid.UUID) error {
+	// get file from s3
+	fileName, err := uc.medias.Download(ctx, mediaActions.GetMedia{Id: id})
+	if fileName != "" {
+		defer os.Remove(fileName)
+	}
+	if err != nil {
+		return err
+	}
+	if !strings.Contains(videoFormats, filepath.Ext(fileName)) {
+		return jobs.Permanent(errors.New("file is not video"))
+	}
 
-		updSubtitles := &mediaActions.UpdateMediaSubtitles{
-			Id: id,
-		}
+	// get audio from video
+	audio, audioFN, err := uc.getAudioFromVideo(fileName)
+	if audioFN != "" {
+		defer os.Remove(audioFN)
+	}
+	if err != nil {
+		return err
+	}
 
-		err = updSubtitles.Subtitles.Scan(subJson)
+	// save audio to s3, some providers recognize audio by url
+	uri, audioId, err := uc.medias.UploadReturnsId(
+		ctx, mediaActions.CreateMedia{
+			Filename: audioFN,
+			Size:     audio.Size(),
+			File:     audio,
+		},
+	)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err := uc.medias.Delete(context.Background(), mediaActions.GetMedia{Id: *audioId})
 		if err != nil {
-			return err
+			uc.logger.Warnw("Error deleting temporary audio", "mediaId", *audioId, "error", err)
 		}
+	}()
 
-		err = uc.medias.UpdateSubtitles(ctx, *updSubtitles)
-		if err != nil {
-			return err
-		}
+	uc.logger.Debug("Transcribing audio", "mediaId", id)
+	transcript, err := uc.transcriber.Transcribe(
+		ctx, transcriber.Audio{
+			Url:      uri,
+			Filename: audioFN,
+			Encoding: audioEncoding,
+			File:     audio,
+		}, uc.options,
+	)
+	if err != nil {
+		return err
+	}
 
-		_ = uc.medias.Delete(ctx, mediaActions.GetMedia{Id: *audioId})
+	saveOperations, err := uc.splitSubtitles(*transcript, chunks)
+	if err != nil {
+		return err
 	}
-	return nil
+
+	subJson, err := json.Marshal(saveOperations)
+	if err != nil {
+		return err
+	}
+
+	updSubtitles := &mediaActions.UpdateMediaSubtitles{
+		Id: id,
+	}
+
+	err = updSubtitles.Subtitles.Scan(subJson)
+	if err != nil {
+		return err
+	}
+
+	err = uc.medias.UpdateSubtitles(ctx, *updSubtitles)
+	if err != nil {
+		return err
+	}
+
+	return uc.medias.SaveSubtitles(ctx, mediaActions.SaveMediaSubtitles{
+		Id:       id,
+		Language: uc.options.Language,
+		Cues:     subtitleCues(transcript.Words),
+	})
 }
 
 func (uc VideoUseCase) UpdateSubtitles(ctx context.Context, subtitles SubtitlesToSave, mediaId uuid.UUID) error {
@@ -201,45 +305,24 @@ func (uc VideoUseCase) UpdateSubtitles(ctx context.Context, subtitles SubtitlesT
 	return uc.medias.UpdateSubtitles(ctx, *updSubtitles)
 }
 
-func (uc VideoUseCase) createVideoSamples(video io.ReadSeeker) (*VideoSamples, error) {
-	f, fname, err := createTempVideoFile(video)
-	if err != nil {
-		return nil, err
-	}
-	defer func() {
-		f.Close()
-		os.Remove(f.Name())
-	}()
-
+func (uc VideoUseCase) createVideoSamples(fname string) (*VideoSamples, error) {
 	uc.logger.Debug("Creating video preview")
 	preview, err := services.GetNFrame(fname, 1)
 	if err != nil {
 		return nil, err
 	}
-	_, err = video.Seek(0, io.SeekStart)
-	if err != nil {
-		return nil, err
-	}
 
 	uc.logger.Debug("Creating video blurred preview")
 	blurred, err := services.GetNFrameBlurred(fname, 1, 35)
 	if err != nil {
 		return nil, err
 	}
-	_, err = video.Seek(0, io.SeekStart)
-	if err != nil {
-		return nil, err
-	}
 
 	//uc.logger.Debug("Creating compressed video")
 	//compressed, err := services.CompressVideo(video)
 	//if err != nil {
 	//	return nil, err
 	//}
-	//_, err = video.Seek(0, io.SeekStart)
-	//if err != nil {
-	//	return nil, err
-	//}
 
 	return &VideoSamples{
 		//CompressedVideo: compressed,
@@ -248,19 +331,6 @@ func (uc VideoUseCase) createVideoSamples(video io.ReadSeeker) (*VideoSamples, e
 	}, nil
 }
 
-func createTempVideoFile(in io.Reader) (*os.File, string, error) {
-	fname := fmt.Sprintf("video_" + uuid.New().String() + ".mp4")
-	f, err := os.Create(fname)
-	if err != nil {
-		return nil, "", err
-	}
-	_, err = io.Copy(f, in)
-	if err != nil {
-		return nil, "", err
-	}
-	return f, fname, nil
-}
-
 type VideoSamples struct {
 	CompressedVideo *bytes.Reader
 	PreviewBlurred  *bytes.Reader
@@ -272,135 +342,29 @@ func (uc VideoUseCase) getAudioFromVideo(fname string) (*bytes.Reader, string, e
 	return audio, audioFN, err
 }
 
-func (uc VideoUseCase) requestYandexSpeech(uri string) (*YandexSpeechOperationResult, error) {
-	requestBody := RecognitionRequest{
-		Config: RecognitionConfig{
-			Specification: Specification{
-				ProfanityFilter: false,
-				LiteratureText:  true,
-				AudioEncoding:   audioEncoding,
-				RawResults:      false,
-			},
-		},
-		Audio: RecognitionAudio{
-			Uri: uri,
-		},
-	}
-
-	jsonData, err := json.Marshal(requestBody)
-	if err != nil {
-		fmt.Println("Error marshalling request body:", err)
-		return nil, err
-	}
-
-	req, err := http.NewRequest("POST", yandexSpeechUrl, bytes.NewBuffer(jsonData))
-	if err != nil {
-		fmt.Println("Error creating request:", err)
-		return nil, err
-	}
-
-	req.Header.Add("Content-Type", "application/json")
-	req.Header.Add("Authorization", "Api-Key "+uc.yandexApiKey)
-
-	client := &http.Client{}
-	res, err := client.Do(req)
-	if err != nil {
-		fmt.Println(err)
-		return nil, err
-	}
-	defer res.Body.Close()
-
-	body, err := ioutil.ReadAll(res.Body)
-	if err != nil {
-		fmt.Println(err)
-		return nil, err
-	}
-
-	operation := &YandexSpeechOperationResult{}
-	err = json.Unmarshal(body, operation)
-	if err != nil {
-		return nil, err
-	}
-
-	return operation, nil
-}
-
-func (uc VideoUseCase) getResultOfYandexSpeech(id string) (*YandexSpeechOperationResult, error) {
-	url := yandexSpeechOperationUrl + id
-	method := "GET"
-
-	client := &http.Client{}
-	req, err := http.NewRequest(method, url, nil)
-	if err != nil {
-		return nil, err
-	}
-	req.Header.Add("Authorization", "Api-Key "+uc.yandexApiKey)
-	operation := &YandexSpeechOperationResult{}
-
-	for true {
-		time.Sleep(time.Second * 30)
-		res, err := client.Do(req)
-		if err != nil {
-			return nil, err
-		}
-
-		body, err := ioutil.ReadAll(res.Body)
-		if err != nil {
-			return nil, err
-		}
-
-		err = json.Unmarshal(body, operation)
-		if err != nil {
-			return nil, err
-		}
-		if operation.Done {
-			res.Body.Close()
-			return operation, nil
-		}
-		res.Body.Close()
-	}
-
-	return operation, nil
-}
-
-func (uc VideoUseCase) splitSubtitles(operation YandexSpeechOperationResult, chunks int) (*SubtitlesToSave, error) {
-	if len(operation.Response.Chunks) < 0 {
-		return nil, errors.New("no chunks in response")
-	}
-
-	allChunks := &Chunk{
-		Alternatives: []Alternative{
-			{
-				Words: make([]Word, 0),
-				Text:  "",
-			},
-		},
+func (uc VideoUseCase) splitSubtitles(transcript transcriber.Transcript, chunks int) (*SubtitlesToSave, error) {
+	words := transcript.Words
+	if len(words) == 0 {
+		return nil, errors.New("no words in response")
 	}
-
-	for _, chunk := range operation.Response.Chunks {
-		if chunk.ChannelTag == "1" {
-			allChunks.Alternatives[0].Words = append(allChunks.Alternatives[0].Words, chunk.Alternatives[0].Words...)
-			allChunks.Alternatives[0].Text += chunk.Alternatives[0].Text + " "
-		}
+	if len(words) < chunks {
+		chunks = len(words)
 	}
 
-	if len(allChunks.Alternatives[0].Words) == 0 {
-		return nil, errors.New("no words in response")
-	}
 	result := &SubtitlesToSave{
-		FullText: allChunks.Alternatives[0].Text,
+		FullText: transcript.Text,
 		Chunks:   make([]ChunkToSave, chunks),
 	}
 
-	chunkSize := len(allChunks.Alternatives[0].Words) / chunks
-	extraWords := len(allChunks.Alternatives[0].Words) % chunks
+	chunkSize := len(words) / chunks
+	extraWords := len(words) % chunks
 
 	chunkIndex := 0
 
-	for i := 0; i < len(allChunks.Alternatives[0].Words); i += chunkSize {
+	for i := 0; i < len(words); i += chunkSize {
 		end := i + chunkSize
-		if end > len(allChunks.Alternatives[0].Words) {
-			end = len(allChunks.Alternatives[0].Words)
+		if end > len(words) {
+			end = len(words)
 		}
 		extraWordFlag := chunkIndex < extraWords
 
@@ -408,16 +372,14 @@ func (uc VideoUseCase) splitSubtitles(operation YandexSpeechOperationResult, chu
 			end++
 		}
 
-		startTime := allChunks.Alternatives[0].Words[i].StartTime
-		endTime := allChunks.Alternatives[0].Words[end-1].EndTime
 		chunkText := ""
 		for j := i; j < end; j++ {
-			chunkText += allChunks.Alternatives[0].Words[j].Word + " "
+			chunkText += words[j].Text + " "
 		}
 
 		result.Chunks[chunkIndex] = ChunkToSave{
-			StartTime: startTime,
-			EndTime:   endTime,
+			StartTime: transcriber.FormatTime(words[i].Start),
+			EndTime:   transcriber.FormatTime(words[end-1].End),
 			Text:      chunkText,
 		}
 
@@ -430,6 +392,42 @@ func (uc VideoUseCase) splitSubtitles(operation YandexSpeechOperationResult, chu
 	return result, nil
 }
 
+// subtitleCues группировка распознанных слов во фрагменты дорожки субтитров
+// не более maxCueWords слов и не дольше maxCueDuration
+func subtitleCues(words []transcriber.Word) mediaEntity.SubtitleCues {
+	var cues mediaEntity.SubtitleCues
+	var texts []string
+	var cue mediaEntity.SubtitleCue
+
+	flush := func() {
+		if len(texts) == 0 {
+			return
+		}
+		if cue.End < cue.Start+minCueDuration {
+			cue.End = cue.Start + minCueDuration
+		}
+		cue.Text = strings.Join(texts, " ")
+		cues = append(cues, cue)
+		texts = nil
+	}
+
+	for _, word := range words {
+		if len(texts) != 0 && (len(texts) == maxCueWords || word.End-cue.Start > maxCueDuration) {
+			flush()
+		}
+		if len(texts) == 0 {
+			cue = mediaEntity.SubtitleCue{Start: word.Start}
+		}
+		texts = append(texts, word.Text)
+		if word.End > cue.End {
+			cue.End = word.End
+		}
+	}
+	flush()
+
+	return cues
+}
+
 func (uc VideoUseCase) updateChunks(subtitles SubtitlesToSave, chunks int) *SubtitlesToSave {
 	newWords := strings.Split(subtitles.FullText, " ")
 	chunkSize := len(newWords) / chunks
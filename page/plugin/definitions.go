@@ -1,11 +1,20 @@
 package plugin
 
 import (
+	"crypto/rand"
 	media_usecase "github.com/aeroideaservices/focus/media/plugin/actions"
 	"github.com/aeroideaservices/focus/page/plugin/actions"
 	"github.com/aeroideaservices/focus/page/plugin/services"
+	"github.com/aeroideaservices/focus/page/plugin/services/cardtypes"
+	"github.com/aeroideaservices/focus/page/plugin/services/hls"
+	"github.com/aeroideaservices/focus/page/plugin/services/jobs"
+	"github.com/aeroideaservices/focus/page/plugin/services/locales"
+	"github.com/aeroideaservices/focus/page/plugin/services/preview"
+	"github.com/aeroideaservices/focus/page/plugin/services/transcriber"
+	"github.com/aeroideaservices/focus/services/sanitizer"
 	"github.com/sarulabs/di/v2"
 	actions3 "gitlab.aeroidea.ru/internal-projects/focus/forms/plugin/actions"
+	"time"
 	//	helpers "gitlab.aeroidea.ru/platform/platformlib/go/lib/golang-helpers-lib"
 
 	"go.uber.org/zap"
@@ -30,12 +39,28 @@ var Definitions = []di.Def{
 			//if callbacksI, _ := ctn.SafeGet("focus.configurations.actions.configurations.callbacks"); callbacksI != nil {
 			//	callbacks = callbacksI.(focsCallbacks.Callbacks)
 			//}
+			maxPageDepth := 0
+			if maxPageDepthI, err := ctn.SafeGet("focus.page.maxPageDepth"); err == nil {
+				maxPageDepth = maxPageDepthI.(int)
+			}
 
 			return actions.NewPageUseCase(
-				pageRepository, galleryRepository, *galleryUseCase, copierService, logger,
+				pageRepository, galleryRepository, *galleryUseCase, maxPageDepth, copierService, logger,
 			), nil
 		},
 	},
+	{
+		Name: "focus.page.sitemap.source",
+		Build: func(ctn di.Container) (interface{}, error) {
+			pageRepository := ctn.Get("focus.page.repositories.page").(actions.PageRepository)
+			basePath := "/"
+			if basePathI, err := ctn.SafeGet("focus.page.sitemap.basePath"); err == nil {
+				basePath = basePathI.(string)
+			}
+
+			return actions.NewSitemapSource(pageRepository, basePath), nil
+		},
+	},
 	{
 		Name: "focus.page.actions.gallery",
 		Build: func(ctn di.Container) (interface{}, error) {
@@ -54,6 +79,18 @@ var Definitions = []di.Def{
 			), nil
 		},
 	},
+	{
+		Name: "focus.page.cardTypes",
+		Build: func(ctn di.Container) (interface{}, error) {
+			validator := ctn.Get("focus.validator").(cardtypes.Validator)
+			registry := cardtypes.NewRegistry(validator)
+			// типы карточек приложения, остальные можно зарегистрировать через Register до первого запроса
+			if typesI, err := ctn.SafeGet("focus.page.cardTypes.custom"); err == nil {
+				registry.Register(typesI.([]cardtypes.Type)...)
+			}
+			return registry, nil
+		},
+	},
 	{
 		Name: "focus.page.actions.card",
 		Build: func(ctn di.Container) (interface{}, error) {
@@ -63,14 +100,18 @@ var Definitions = []di.Def{
 			tagRepository := ctn.Get("focus.page.repositories.tag").(actions.TagRepository)
 			copierService := ctn.Get("copier_service").(actions.CopierInterface)
 			formUseCase := ctn.Get("focus.forms.actions.forms").(*actions3.Forms)
+			cardTypes := ctn.Get("focus.page.cardTypes").(*cardtypes.Registry)
 			logger := ctn.Get("logger").(*zap.SugaredLogger)
 			//var callbacks focsCallbacks.Callbacks
 			//if callbacksI, _ := ctn.SafeGet("focus.configurations.actions.configurations.callbacks"); callbacksI != nil {
 			//	callbacks = callbacksI.(focsCallbacks.Callbacks)
 			//}
 
+			htmlSanitizer, htmlPolicy := htmlCardSanitizer(ctn)
+
 			return actions.NewCardUseCase(
-				cardRepository, galleryRepository, tagRepository, mediaProvider, *formUseCase, copierService, logger,
+				cardRepository, galleryRepository, tagRepository, mediaProvider, *formUseCase, cardTypes,
+				htmlSanitizer, htmlPolicy, copierService, logger,
 			), nil
 		},
 	},
@@ -88,12 +129,294 @@ var Definitions = []di.Def{
 		},
 	},
 	{
-		Name: "focus.page.actions.video",
+		Name: "focus.page.actions.user",
+		Build: func(ctn di.Container) (interface{}, error) {
+			userRepository := ctn.Get("focus.page.repositories.user").(actions.UserRepository)
+			media := ctn.Get("focus.media.actions.media").(*media_usecase.Medias)
+			logger := ctn.Get("logger").(*zap.SugaredLogger)
+			return actions.NewUserUseCase(userRepository, media, logger), nil
+		},
+	},
+	{
+		Name: "focus.page.actions.clone",
+		Build: func(ctn di.Container) (interface{}, error) {
+			pageRepository := ctn.Get("focus.page.repositories.page").(actions.PageRepository)
+			galleryRepository := ctn.Get("focus.page.repositories.gallery").(actions.GalleryRepository)
+			cardRepository := ctn.Get("focus.card.repositories.card").(actions.CardRepository)
+			translationRepository := ctn.Get("focus.page.repositories.translation").(actions.TranslationRepository)
+			transactor := ctn.Get("focus.page.repositories.transactor").(actions.Transactor)
+			logger := ctn.Get("logger").(*zap.SugaredLogger)
+			return actions.NewCloneUseCase(
+				pageRepository, galleryRepository, cardRepository, translationRepository, transactor, logger,
+			), nil
+		},
+	},
+	{
+		Name: "focus.page.actions.delivery",
+		Build: func(ctn di.Container) (interface{}, error) {
+			pageRepository := ctn.Get("focus.page.repositories.page").(actions.PageRepository)
+			galleryRepository := ctn.Get("focus.page.repositories.gallery").(actions.GalleryRepository)
+			cardRepository := ctn.Get("focus.card.repositories.card").(actions.CardRepository)
+			publicationRepository := ctn.Get("focus.page.repositories.publication").(actions.PublicationRepository)
+			cardUseCase := ctn.Get("focus.page.actions.card").(*actions.CardUseCase)
+			previewUseCase := ctn.Get("focus.page.actions.preview").(*actions.PreviewUseCase)
+			translationUseCase := ctn.Get("focus.page.actions.translation").(*actions.TranslationUseCase)
+			mediaProvider := ctn.Get("focus.media.provider").(media_usecase.MediaProvider)
+			copierService := ctn.Get("copier_service").(actions.CopierInterface)
+			logger := ctn.Get("logger").(*zap.SugaredLogger)
+			return actions.NewDeliveryUseCase(
+				pageRepository, galleryRepository, cardRepository, publicationRepository, cardUseCase, previewUseCase,
+				translationUseCase, mediaProvider, copierService, logger,
+			), nil
+		},
+	},
+	{
+		Name: "focus.page.locales",
+		Build: func(ctn di.Container) (interface{}, error) {
+			// без настроек содержимое страниц только на языке по умолчанию locales.DefaultLocale
+			var config locales.Config
+			if configI, err := ctn.SafeGet("focus.page.locales.config"); err == nil {
+				config = configI.(locales.Config)
+			}
+			return locales.New(config)
+		},
+	},
+	{
+		Name: "focus.page.actions.translation",
+		Build: func(ctn di.Container) (interface{}, error) {
+			translationRepository := ctn.Get("focus.page.repositories.translation").(actions.TranslationRepository)
+			pageRepository := ctn.Get("focus.page.repositories.page").(actions.PageRepository)
+			galleryRepository := ctn.Get("focus.page.repositories.gallery").(actions.GalleryRepository)
+			cardRepository := ctn.Get("focus.card.repositories.card").(actions.CardRepository)
+			tagRepository := ctn.Get("focus.page.repositories.tag").(actions.TagRepository)
+			pageLocales := ctn.Get("focus.page.locales").(*locales.Locales)
+			logger := ctn.Get("logger").(*zap.SugaredLogger)
+			return actions.NewTranslationUseCase(
+				translationRepository, pageRepository, galleryRepository, cardRepository, tagRepository, pageLocales,
+				logger,
+			), nil
+		},
+	},
+	{
+		Name: "focus.page.actions.transfer",
 		Build: func(ctn di.Container) (interface{}, error) {
+			pageRepository := ctn.Get("focus.page.repositories.page").(actions.PageRepository)
+			galleryRepository := ctn.Get("focus.page.repositories.gallery").(actions.GalleryRepository)
+			transferRepository := ctn.Get("focus.page.repositories.transfer").(actions.TransferRepository)
+			cardUseCase := ctn.Get("focus.page.actions.card").(*actions.CardUseCase)
 			media := ctn.Get("focus.media.actions.media").(*media_usecase.Medias)
+			cardTypes := ctn.Get("focus.page.cardTypes").(*cardtypes.Registry)
+			logger := ctn.Get("logger").(*zap.SugaredLogger)
+			return actions.NewTransferUseCase(
+				pageRepository, galleryRepository, transferRepository, *cardUseCase, media, cardTypes, logger,
+			), nil
+		},
+	},
+	{
+		Name: "focus.page.actions.search",
+		Build: func(ctn di.Container) (interface{}, error) {
+			searchRepository := ctn.Get("focus.page.repositories.search").(actions.SearchRepository)
 			logger := ctn.Get("logger").(*zap.SugaredLogger)
+			return actions.NewSearchUseCase(searchRepository, logger), nil
+		},
+	},
+	{
+		Name: "focus.page.actions.static",
+		Build: func(ctn di.Container) (interface{}, error) {
+			pageRepository := ctn.Get("focus.page.repositories.page").(actions.PageRepository)
+			deliveryUseCase := ctn.Get("focus.page.actions.delivery").(*actions.DeliveryUseCase)
+			media := ctn.Get("focus.media.actions.media").(*media_usecase.Medias)
+			logger := ctn.Get("logger").(*zap.SugaredLogger)
+			// без настроек выгрузка только в архив, с шаблонами по умолчанию и ссылками на медиа из MediaProvider
+			var config actions.StaticExportConfig
+			if configI, err := ctn.SafeGet("focus.page.static.config"); err == nil {
+				config = configI.(actions.StaticExportConfig)
+			}
+			// html карточек очищается при отрисовке по той же политике, что и при сохранении
+			htmlSanitizer, htmlPolicy := htmlCardSanitizer(ctn)
+			return actions.NewStaticExportUseCase(
+				pageRepository, deliveryUseCase, media, htmlSanitizer, htmlPolicy, config, logger,
+			)
+		},
+	},
+	{
+		Name: "focus.page.preview.signer",
+		Build: func(ctn di.Container) (interface{}, error) {
+			if secretI, err := ctn.SafeGet("focus.page.preview.secret"); err == nil {
+				return preview.NewSigner([]byte(secretI.(string))), nil
+			}
+
+			// без общего секрета ссылки предпросмотра действуют только до перезапуска экземпляра
+			ctn.Get("logger").(*zap.SugaredLogger).Warn("focus.page.preview.secret is not set, using random secret")
+			secret := make([]byte, 32)
+			if _, err := rand.Read(secret); err != nil {
+				return nil, err
+			}
+			return preview.NewSigner(secret), nil
+		},
+	},
+	{
+		Name: "focus.page.actions.preview",
+		Build: func(ctn di.Container) (interface{}, error) {
+			previewTokenRepository := ctn.Get("focus.page.repositories.previewToken").(actions.PreviewTokenRepository)
+			publicationUseCase := ctn.Get("focus.page.actions.publication").(*actions.PublicationUseCase)
+			signer := ctn.Get("focus.page.preview.signer").(*preview.Signer)
+			logger := ctn.Get("logger").(*zap.SugaredLogger)
+			return actions.NewPreviewUseCase(previewTokenRepository, *publicationUseCase, signer, logger), nil
+		},
+	},
+	{
+		Name: "focus.page.actions.publication",
+		Build: func(ctn di.Container) (interface{}, error) {
+			publicationRepository := ctn.Get("focus.page.repositories.publication").(actions.PublicationRepository)
+			pageRepository := ctn.Get("focus.page.repositories.page").(actions.PageRepository)
+			galleryRepository := ctn.Get("focus.page.repositories.gallery").(actions.GalleryRepository)
+			cardRepository := ctn.Get("focus.card.repositories.card").(actions.CardRepository)
+			pageUseCase := ctn.Get("focus.page.actions.page").(*actions.PageUseCase)
+			galleryUseCase := ctn.Get("focus.page.actions.gallery").(*actions.GalleryUseCase)
+			cardUseCase := ctn.Get("focus.page.actions.card").(*actions.CardUseCase)
+			snapshotUseCase := ctn.Get("focus.page.actions.snapshot").(*actions.SnapshotUseCase)
+			transactor := ctn.Get("focus.page.repositories.transactor").(actions.Transactor)
+			logger := ctn.Get("logger").(*zap.SugaredLogger)
+
+			return actions.NewPublicationUseCase(
+				publicationRepository, pageRepository, galleryRepository, cardRepository,
+				*pageUseCase, *galleryUseCase, *cardUseCase, snapshotUseCase, transactor, logger,
+			), nil
+		},
+	},
+	{
+		Name: "focus.page.actions.snapshot",
+		Build: func(ctn di.Container) (interface{}, error) {
+			snapshotRepository := ctn.Get("focus.page.repositories.snapshot").(actions.SnapshotRepository)
+			pageRepository := ctn.Get("focus.page.repositories.page").(actions.PageRepository)
+			galleryRepository := ctn.Get("focus.page.repositories.gallery").(actions.GalleryRepository)
+			cardRepository := ctn.Get("focus.card.repositories.card").(actions.CardRepository)
+			publicationRepository := ctn.Get("focus.page.repositories.publication").(actions.PublicationRepository)
+			transactor := ctn.Get("focus.page.repositories.transactor").(actions.Transactor)
+			logger := ctn.Get("logger").(*zap.SugaredLogger)
+			return actions.NewSnapshotUseCase(
+				snapshotRepository, pageRepository, galleryRepository, cardRepository, publicationRepository,
+				transactor, logger,
+			), nil
+		},
+	},
+	{
+		Name: "focus.page.publication.scheduler",
+		Build: func(ctn di.Container) (interface{}, error) {
+			publicationUseCase := ctn.Get("focus.page.actions.publication").(*actions.PublicationUseCase)
+			logger := ctn.Get("logger").(*zap.SugaredLogger)
+			interval := actions.DefaultPublicationInterval
+			if intervalI, err := ctn.SafeGet("focus.page.publication.interval"); err == nil {
+				interval = intervalI.(time.Duration)
+			}
+			scheduler := actions.NewPublicationScheduler(publicationUseCase, interval, logger)
+			scheduler.Start()
+			return scheduler, nil
+		},
+		Close: func(obj interface{}) error {
+			obj.(*actions.PublicationScheduler).Stop()
+			return nil
+		},
+	},
+	{
+		Name: "focus.page.transcriber",
+		Build: func(ctn di.Container) (interface{}, error) {
+			// при заданном адресе Whisper-совместимого сервера используется он, иначе - Yandex SpeechKit
+			if whisperUrl, err := ctn.SafeGet("whisper.url"); err == nil && whisperUrl.(string) != "" {
+				config := transcriber.WhisperConfig{Url: whisperUrl.(string)}
+				if model, err := ctn.SafeGet("whisper.model"); err == nil {
+					config.Model = model.(string)
+				}
+				if apiKey, err := ctn.SafeGet("whisper.api.key"); err == nil {
+					config.ApiKey = apiKey.(string)
+				}
+				return transcriber.NewWhisper(config), nil
+			}
+
 			yandexApiKey := ctn.Get("yandex.api.key").(string)
-			return actions.NewVideoUseCase(media, logger, yandexApiKey), nil
+			return transcriber.NewYandex(transcriber.YandexConfig{ApiKey: yandexApiKey}), nil
+		},
+	},
+	{
+		Name: "focus.page.transcriber.options",
+		Build: func(ctn di.Container) (interface{}, error) {
+			options := transcriber.DefaultOptions
+			if language, err := ctn.SafeGet("focus.page.transcriber.language"); err == nil {
+				options.Language = language.(string)
+			}
+			if profanityFilter, err := ctn.SafeGet("focus.page.transcriber.profanityFilter"); err == nil {
+				options.ProfanityFilter = profanityFilter.(bool)
+			}
+			return options, nil
+		},
+	},
+	{
+		Name: "focus.page.hls",
+		Build: func(ctn di.Container) (interface{}, error) {
+			return hls.NewFFmpeg(), nil
+		},
+	},
+	{
+		Name: "focus.page.hls.options",
+		Build: func(ctn di.Container) (interface{}, error) {
+			options := hls.DefaultOptions
+			if renditions, err := ctn.SafeGet("focus.page.hls.renditions"); err == nil {
+				options.Renditions = renditions.([]hls.Rendition)
+			}
+			if segmentDuration, err := ctn.SafeGet("focus.page.hls.segmentDuration"); err == nil {
+				options.SegmentDuration = segmentDuration.(time.Duration)
+			}
+			return options, nil
 		},
 	},
+	{
+		Name: "focus.page.jobs.runner",
+		Build: func(ctn di.Container) (interface{}, error) {
+			repository := ctn.Get("focus.page.repositories.job").(actions.JobRepository)
+			logger := ctn.Get("logger").(*zap.SugaredLogger)
+			config := jobs.DefaultConfig
+			if workers, err := ctn.SafeGet("focus.page.jobs.workers"); err == nil {
+				config.Workers = workers.(int)
+			}
+			runner := jobs.NewRunner(repository, logger, config)
+			runner.Start()
+			return runner, nil
+		},
+		Close: func(obj interface{}) error {
+			obj.(*jobs.Runner).Stop()
+			return nil
+		},
+	},
+	{
+		Name: "focus.page.actions.video",
+		Build: func(ctn di.Container) (interface{}, error) {
+			media := ctn.Get("focus.media.actions.media").(*media_usecase.Medias)
+			logger := ctn.Get("logger").(*zap.SugaredLogger)
+			speechTranscriber := ctn.Get("focus.page.transcriber").(transcriber.Transcriber)
+			options := ctn.Get("focus.page.transcriber.options").(transcriber.Options)
+			hlsTranscoder := ctn.Get("focus.page.hls").(hls.Transcoder)
+			hlsOptions := ctn.Get("focus.page.hls.options").(hls.Options)
+			repository := ctn.Get("focus.page.repositories.job").(actions.JobRepository)
+			runner := ctn.Get("focus.page.jobs.runner").(*jobs.Runner)
+			uc := actions.NewVideoUseCase(media, logger, speechTranscriber, options, hlsTranscoder, hlsOptions, repository)
+			uc.RegisterJobHandlers(runner)
+			return uc, nil
+		},
+	},
+}
+
+// htmlCardSanitizer очистка html карточек: политики настраиваются через focus.sanitizer,
+// без него используются политики по умолчанию, политика html карточек - focus.page.htmlCardPolicy
+func htmlCardSanitizer(ctn di.Container) (actions.Sanitizer, string) {
+	var htmlSanitizer actions.Sanitizer = sanitizer.NewSanitizer()
+	if sanitizerI, _ := ctn.SafeGet("focus.sanitizer"); sanitizerI != nil {
+		htmlSanitizer = sanitizerI.(actions.Sanitizer)
+	}
+	htmlPolicy := sanitizer.PolicyEmbed
+	if policyI, _ := ctn.SafeGet("focus.page.htmlCardPolicy"); policyI != nil {
+		htmlPolicy = policyI.(string)
+	}
+
+	return htmlSanitizer, htmlPolicy
 }
@@ -4,6 +4,7 @@ import (
 	"github.com/aeroideaservices/focus/media/plugin/entity"
 	"github.com/google/uuid"
 	entity2 "gitlab.aeroidea.ru/internal-projects/focus/forms/plugin/entity"
+	"time"
 )
 
 type Card struct {
@@ -12,6 +13,8 @@ type Card struct {
 	Code          string       `focus:"title:Код"`
 	Type          string       `focus:"title:Название;filterable" validate:"required,min=1,max=50"`
 	IsPublished   bool         `focus:"title:Опубликована ли карточка;" validate:"required"`
+	PublishAt     *time.Time   `focus:"title:Дата публикации;hidden:list" validate:"-"`
+	UnpublishAt   *time.Time   `focus:"title:Дата снятия с публикации;hidden:list" validate:"-"`
 	RegularCard   *RegularCard `focus:"title:Привязанная обычная карточка;view:select;viewExtra:regularCardSelect;hidden:list" validate:"structonly"`
 	RegularCardId *uuid.UUID   `focus:"-" validate:"-"`
 	VideoCard     *VideoCard   `focus:"title:Привязанная карточка с видео;view:select;viewExtra:videoCardSelect;hidden:list" validate:"structonly"`
@@ -22,6 +25,8 @@ type Card struct {
 	PhotoCardId   *uuid.UUID   `focus:"-" validate:"-"`
 	FormCard      *FormCard    `focus:"title:Привязанная карточка с фото;view:select;hidden:list" validate:"structonly"`
 	FormCardId    *uuid.UUID   `focus:"-" validate:"-"`
+	CustomCard    any          `focus:"-" gorm:"-" validate:"-"` // CustomCard данные карточки зарегистрированного приложением типа
+	CustomCardId  *uuid.UUID   `focus:"-" validate:"-"`
 
 	Title       string `focus:"title:Заголовок"`
 	Description string `focus:"title:Описание"`
@@ -0,0 +1,94 @@
+package entity
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DraftType тип сущности, для которой сохраняется черновик
+type DraftType string
+
+const (
+	DraftTypePage    DraftType = "page"    // DraftTypePage черновик страницы
+	DraftTypeGallery DraftType = "gallery" // DraftTypeGallery черновик галереи
+	DraftTypeCard    DraftType = "card"    // DraftTypeCard черновик карточки
+)
+
+// Draft черновик изменений сущности, применяется к сущности при публикации
+type Draft struct {
+	EntityType DraftType       `gorm:"primaryKey"`
+	EntityID   uuid.UUID       `gorm:"type:uuid;primaryKey"`
+	Data       json.RawMessage `gorm:"type:jsonb"` // Data запрос на изменение сущности в формате JSON
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+func (Draft) TableName() string {
+	return "drafts"
+}
+
+// draftTagsFields поля черновика карточки со списком идентификаторов тегов
+var draftTagsFields = []string{"regularCard", "formCard"}
+
+// ReplaceTags замена в черновике карточки тегов sources на тег target без повторов.
+// Возвращает false, если черновик не ссылается на теги sources и не изменен.
+func (d *Draft) ReplaceTags(sources map[uuid.UUID]bool, target uuid.UUID) (bool, error) {
+	if d.EntityType != DraftTypeCard {
+		return false, nil
+	}
+
+	var data map[string]json.RawMessage
+	if err := json.Unmarshal(d.Data, &data); err != nil {
+		return false, err
+	}
+
+	replaced := false
+	for _, field := range draftTagsFields {
+		var card map[string]json.RawMessage
+		if len(data[field]) == 0 || json.Unmarshal(data[field], &card) != nil || len(card["tags"]) == 0 {
+			continue
+		}
+		var tags []uuid.UUID
+		if err := json.Unmarshal(card["tags"], &tags); err != nil {
+			return false, err
+		}
+
+		changed := false
+		seen := make(map[uuid.UUID]bool, len(tags))
+		result := make([]uuid.UUID, 0, len(tags))
+		for _, tag := range tags {
+			if sources[tag] {
+				tag, changed = target, true
+			}
+			if !seen[tag] {
+				seen[tag] = true
+				result = append(result, tag)
+			}
+		}
+		if !changed {
+			continue
+		}
+
+		var err error
+		if card["tags"], err = json.Marshal(result); err != nil {
+			return false, err
+		}
+		if data[field], err = json.Marshal(card); err != nil {
+			return false, err
+		}
+		replaced = true
+	}
+	if !replaced {
+		return false, nil
+	}
+
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return false, err
+	}
+	d.Data = raw
+
+	return true, nil
+}
@@ -0,0 +1,51 @@
+package entity
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestDraft_ReplaceTags(t *testing.T) {
+	target, source, other := uuid.New(), uuid.New(), uuid.New()
+	sources := map[uuid.UUID]bool{source: true}
+
+	draft := Draft{
+		EntityType: DraftTypeCard,
+		Data: json.RawMessage(`{"name":"card","regularCard":{"previewText":"text","tags":["` +
+			source.String() + `","` + other.String() + `","` + target.String() + `"]},"formCard":null}`),
+	}
+	replaced, err := draft.ReplaceTags(sources, target)
+	if err != nil || !replaced {
+		t.Fatalf("ReplaceTags() = %v, %v, want true", replaced, err)
+	}
+
+	var data struct {
+		Name        string `json:"name"`
+		RegularCard struct {
+			PreviewText string      `json:"previewText"`
+			Tags        []uuid.UUID `json:"tags"`
+		} `json:"regularCard"`
+	}
+	if err = json.Unmarshal(draft.Data, &data); err != nil {
+		t.Fatalf("draft data = %s: %v", draft.Data, err)
+	}
+	if want := []uuid.UUID{target, other}; !reflect.DeepEqual(data.RegularCard.Tags, want) {
+		t.Errorf("tags = %v, want %v", data.RegularCard.Tags, want)
+	}
+	if data.Name != "card" || data.RegularCard.PreviewText != "text" {
+		t.Errorf("other fields changed: %s", draft.Data)
+	}
+
+	unchanged := Draft{EntityType: DraftTypeCard, Data: json.RawMessage(`{"formCard":{"tags":["` + other.String() + `"]}}`)}
+	if replaced, err = unchanged.ReplaceTags(sources, target); err != nil || replaced {
+		t.Errorf("ReplaceTags() without source tags = %v, %v, want false", replaced, err)
+	}
+
+	page := Draft{EntityType: DraftTypePage, Data: json.RawMessage(`{}`)}
+	if replaced, err = page.ReplaceTags(sources, target); err != nil || replaced {
+		t.Errorf("ReplaceTags() for page draft = %v, %v, want false", replaced, err)
+	}
+}
@@ -1,6 +1,8 @@
 package entity
 
 import (
+	"time"
+
 	"github.com/google/uuid"
 )
 
@@ -11,6 +13,8 @@ type Gallery struct {
 	GalleriesCards []GalleriesCards `focus:"title:Карточки;many2many:galleries_cards;viewExtra:selectCards" gorm:"foreignKey:GalleryID" validate:"omitempty,unique=ID,dive,notBlank,structonly"`
 	Hidden         bool             `focus:"title:Скрыт в меню;" validate:"required"`
 	IsPublished    bool             `focus:"title:Опубликована ли галерея;" validate:"required"`
+	PublishAt      *time.Time       `focus:"title:Дата публикации;hidden:list" validate:"-"`
+	UnpublishAt    *time.Time       `focus:"title:Дата снятия с публикации;hidden:list" validate:"-"`
 }
 
 func (Gallery) TableName() string {
@@ -0,0 +1,40 @@
+package entity
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// JobStatus статус фоновой задачи
+type JobStatus string
+
+const (
+	JobStatusQueued    JobStatus = "queued"    // JobStatusQueued задача ожидает выполнения, в том числе повторной попытки
+	JobStatusRunning   JobStatus = "running"   // JobStatusRunning задача выполняется одним из обработчиков
+	JobStatusSucceeded JobStatus = "succeeded" // JobStatusSucceeded задача выполнена
+	JobStatusFailed    JobStatus = "failed"    // JobStatusFailed попытки исчерпаны, задача ожидает ручного перезапуска
+)
+
+// Job фоновая задача очереди
+type Job struct {
+	ID          uuid.UUID       `gorm:"type:uuid;primaryKey"`
+	Type        string          `gorm:"index"`
+	Payload     json.RawMessage `gorm:"type:jsonb"`
+	Result      json.RawMessage `gorm:"type:jsonb"`
+	Status      JobStatus       `gorm:"index"`
+	Attempts    int             // Attempts количество начатых попыток
+	MaxAttempts int             // MaxAttempts максимальное количество попыток, после которого задача переходит в статус failed
+	RunAt       time.Time       `gorm:"index"` // RunAt время, не раньше которого задача может быть взята в работу
+	LockedBy    string          // LockedBy идентификатор обработчика, взявшего задачу
+	LockedUntil *time.Time      // LockedUntil время окончания аренды задачи обработчиком
+	LastError   string
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	FinishedAt  *time.Time
+}
+
+func (Job) TableName() string {
+	return "jobs"
+}
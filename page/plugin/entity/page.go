@@ -1,11 +1,16 @@
 package entity
 
 import (
+	"strings"
+	"time"
+
 	"github.com/google/uuid"
 )
 
 type Page struct {
 	ID             uuid.UUID        `gorm:"type:uuid;primaryKey;" json:"id"`
+	ParentID       *uuid.UUID       `gorm:"type:uuid" json:"parentId"` // ParentID родительская страница, nil у корневых страниц
+	Path           string           `json:"path"`                      // Path полный путь из кодов страницы и ее предков, например about/team/leadership
 	Name           string           `json:"name"`
 	Code           string           `json:"code"`
 	Title          string           `json:"title"`
@@ -14,7 +19,10 @@ type Page struct {
 	DescriptionSeo string           `json:"descriptionSeo"`
 	Keywords       string           `json:"keywords"`
 	IsPublished    bool             `json:"isPublished"`
+	PublishAt      *time.Time       `json:"publishAt"`   // PublishAt время запланированной публикации
+	UnpublishAt    *time.Time       `json:"unpublishAt"` // UnpublishAt время запланированного снятия с публикации
 	Sort           int              `json:"sort"`
+	UpdatedAt      time.Time        `json:"updatedAt"` // UpdatedAt время последнего изменения страницы
 	PagesGalleries []PagesGalleries `json:"pagesGalleries" gorm:"foreignKey:PagesID"`
 
 	OgType string `json:"ogType"`
@@ -24,6 +32,60 @@ func (Page) TableName() string {
 	return "pages"
 }
 
+// PagePathSeparator разделитель кодов в пути страницы
+const PagePathSeparator = "/"
+
+// PagePath путь страницы с кодом code, вложенной в страницу с путем parentPath
+func PagePath(parentPath string, code string) string {
+	if parentPath == "" {
+		return code
+	}
+
+	return parentPath + PagePathSeparator + code
+}
+
+// FullPath полный путь страницы.
+// У страниц, созданных до появления иерархии, путь не заполнен и совпадает с кодом.
+func (p Page) FullPath() string {
+	if p.Path == "" {
+		return p.Code
+	}
+
+	return p.Path
+}
+
+// ParentPath путь родительской страницы, пустая строка у корневых страниц
+func (p Page) ParentPath() string {
+	path := p.FullPath()
+	i := strings.LastIndex(path, PagePathSeparator)
+	if i < 0 {
+		return ""
+	}
+
+	return path[:i]
+}
+
+// Depth уровень вложенности страницы, у корневых страниц 1
+func (p Page) Depth() int {
+	return PathDepth(p.FullPath())
+}
+
+// PathDepth уровень вложенности страницы с путем path
+func PathDepth(path string) int {
+	return strings.Count(path, PagePathSeparator) + 1
+}
+
+// AncestorPaths пути предков страницы от корневой до родительской
+func (p Page) AncestorPaths() []string {
+	codes := strings.Split(p.FullPath(), PagePathSeparator)
+	paths := make([]string, 0, len(codes)-1)
+	for i := 1; i < len(codes); i++ {
+		paths = append(paths, strings.Join(codes[:i], PagePathSeparator))
+	}
+
+	return paths
+}
+
 type PagesGalleries struct {
 	PagesID   *uuid.UUID
 	GalleryID *uuid.UUID
@@ -0,0 +1,63 @@
+package entity
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPage_Paths(t *testing.T) {
+	tests := []struct {
+		name          string
+		page          Page
+		wantPath      string
+		wantParent    string
+		wantDepth     int
+		wantAncestors []string
+	}{
+		{
+			name:          "root page",
+			page:          Page{Code: "about", Path: "about"},
+			wantPath:      "about",
+			wantDepth:     1,
+			wantAncestors: []string{},
+		},
+		{
+			name:          "page without path",
+			page:          Page{Code: "about"},
+			wantPath:      "about",
+			wantDepth:     1,
+			wantAncestors: []string{},
+		},
+		{
+			name:          "nested page",
+			page:          Page{Code: "leadership", Path: "about/team/leadership"},
+			wantPath:      "about/team/leadership",
+			wantParent:    "about/team",
+			wantDepth:     3,
+			wantAncestors: []string{"about", "about/team"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.page.FullPath(); got != tt.wantPath {
+				t.Errorf("FullPath() = %q, want %q", got, tt.wantPath)
+			}
+			if got := tt.page.ParentPath(); got != tt.wantParent {
+				t.Errorf("ParentPath() = %q, want %q", got, tt.wantParent)
+			}
+			if got := tt.page.Depth(); got != tt.wantDepth {
+				t.Errorf("Depth() = %d, want %d", got, tt.wantDepth)
+			}
+			if got := tt.page.AncestorPaths(); !reflect.DeepEqual(got, tt.wantAncestors) {
+				t.Errorf("AncestorPaths() = %v, want %v", got, tt.wantAncestors)
+			}
+		})
+	}
+
+	if got := PagePath("about/team", "leadership"); got != "about/team/leadership" {
+		t.Errorf("PagePath() = %q", got)
+	}
+	if got := PagePath("", "about"); got != "about" {
+		t.Errorf("PagePath() for root = %q", got)
+	}
+}
@@ -0,0 +1,25 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PreviewToken выданная ссылка предпросмотра неопубликованной сущности.
+// Сам токен не хранится, запись нужна для отзыва токена и аудита.
+type PreviewToken struct {
+	ID            uuid.UUID `gorm:"type:uuid;primaryKey"`
+	EntityType    DraftType `gorm:"index:idx_preview_tokens_entity"`
+	EntityID      uuid.UUID `gorm:"type:uuid;index:idx_preview_tokens_entity"`
+	CreatedBy     string    // CreatedBy идентификатор пользователя, создавшего токен
+	CreatedByName string    // CreatedByName имя пользователя, создавшего токен
+	CreatedAt     time.Time
+	ExpiresAt     time.Time
+	RevokedAt     *time.Time
+	RevokedBy     string // RevokedBy идентификатор пользователя, отозвавшего токен
+}
+
+func (PreviewToken) TableName() string {
+	return "preview_tokens"
+}
@@ -0,0 +1,292 @@
+package entity
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SnapshotReason причина создания снимка страницы
+type SnapshotReason string
+
+const (
+	SnapshotReasonPublish SnapshotReason = "publish" // SnapshotReasonPublish снимок после публикации
+	SnapshotReasonManual  SnapshotReason = "manual"  // SnapshotReasonManual снимок по запросу
+)
+
+// Snapshot неизменяемый снимок страницы с ее галереями и карточками
+type Snapshot struct {
+	ID        uuid.UUID `gorm:"type:uuid;primaryKey"`
+	PageID    uuid.UUID `gorm:"type:uuid;index"`
+	Reason    SnapshotReason
+	Comment   string
+	Data      json.RawMessage `gorm:"type:jsonb"` // Data содержимое страницы SnapshotData в формате JSON
+	CreatedAt time.Time
+}
+
+func (Snapshot) TableName() string {
+	return "page_snapshots"
+}
+
+// SnapshotData содержимое страницы на момент снимка.
+// Данные сущностей хранятся в формате их черновиков, поэтому снимок восстанавливается записью черновиков.
+type SnapshotData struct {
+	Page      SnapshotItem      `json:"page"`
+	Galleries []SnapshotGallery `json:"galleries"` // Galleries галереи страницы в порядке вывода
+}
+
+// SnapshotItem сущность в снимке
+type SnapshotItem struct {
+	ID   uuid.UUID       `json:"id"`
+	Name string          `json:"name"`
+	Data json.RawMessage `json:"data"` // Data запрос на изменение сущности в формате JSON, как в черновике
+}
+
+type SnapshotGallery struct {
+	SnapshotItem
+	Cards []SnapshotItem `json:"cards"` // Cards карточки галереи в порядке вывода
+}
+
+// SnapshotFieldDiff изменение поля, вложенные поля записываются через точку, например regularCard.previewText.
+// Отсутствующее значение - null.
+type SnapshotFieldDiff struct {
+	Field string          `json:"field"`
+	From  json.RawMessage `json:"from"`
+	To    json.RawMessage `json:"to"`
+}
+
+// SnapshotItemDiff изменение галереи или карточки.
+// Перемещенными считаются только сущности, сменившие порядок относительно других, а не сдвинутые добавлением
+// или удалением соседей. Позиции считаются с 1.
+type SnapshotItemDiff struct {
+	EntityType   DraftType           `json:"entityType"`
+	ID           uuid.UUID           `json:"id"`
+	GalleryID    *uuid.UUID          `json:"galleryId,omitempty"` // GalleryID галерея карточки
+	Name         string              `json:"name"`
+	Added        bool                `json:"added"`
+	Removed      bool                `json:"removed"`
+	Moved        bool                `json:"moved"`
+	FromPosition int                 `json:"fromPosition,omitempty"`
+	ToPosition   int                 `json:"toPosition,omitempty"`
+	Fields       []SnapshotFieldDiff `json:"fields"`
+}
+
+// SnapshotDiff структурное сравнение двух снимков страницы
+type SnapshotDiff struct {
+	Page  []SnapshotFieldDiff `json:"page"`
+	Items []SnapshotItemDiff  `json:"items"`
+}
+
+// DiffSnapshots сравнение снимка from со снимком to.
+// Поля карточки, выведенной в нескольких галереях, сравниваются один раз, у первого ее вхождения.
+// Карточки добавленных и удаленных галерей по отдельности не перечисляются.
+func DiffSnapshots(from, to SnapshotData) (*SnapshotDiff, error) {
+	pageFields, err := diffSnapshotFields(from.Page.Data, to.Page.Data)
+	if err != nil {
+		return nil, err
+	}
+	diff := &SnapshotDiff{Page: pageFields, Items: []SnapshotItemDiff{}}
+
+	fromGalleries := make([]SnapshotItem, len(from.Galleries))
+	fromGalleryByID := make(map[uuid.UUID]SnapshotGallery, len(from.Galleries))
+	for i, gallery := range from.Galleries {
+		fromGalleries[i] = gallery.SnapshotItem
+		fromGalleryByID[gallery.ID] = gallery
+	}
+	toGalleries := make([]SnapshotItem, len(to.Galleries))
+	for i, gallery := range to.Galleries {
+		toGalleries[i] = gallery.SnapshotItem
+	}
+
+	galleryDiffs, err := diffSnapshotItems(DraftTypeGallery, nil, fromGalleries, toGalleries, nil)
+	if err != nil {
+		return nil, err
+	}
+	diff.Items = append(diff.Items, galleryDiffs...)
+
+	comparedCards := make(map[uuid.UUID]bool)
+	for _, gallery := range to.Galleries {
+		fromGallery, ok := fromGalleryByID[gallery.ID]
+		if !ok {
+			continue
+		}
+		galleryID := gallery.ID
+		cardDiffs, err := diffSnapshotItems(DraftTypeCard, &galleryID, fromGallery.Cards, gallery.Cards, comparedCards)
+		if err != nil {
+			return nil, err
+		}
+		diff.Items = append(diff.Items, cardDiffs...)
+	}
+
+	return diff, nil
+}
+
+// diffSnapshotItems сравнение упорядоченных списков сущностей, compared - сущности, поля которых уже сравнены
+func diffSnapshotItems(
+	entityType DraftType, galleryID *uuid.UUID, from, to []SnapshotItem, compared map[uuid.UUID]bool,
+) ([]SnapshotItemDiff, error) {
+	fromPositions := make(map[uuid.UUID]int, len(from))
+	fromIDs := make([]uuid.UUID, len(from))
+	for i, item := range from {
+		fromPositions[item.ID] = i
+		fromIDs[i] = item.ID
+	}
+	toPositions := make(map[uuid.UUID]int, len(to))
+	toIDs := make([]uuid.UUID, len(to))
+	for i, item := range to {
+		toPositions[item.ID] = i
+		toIDs[i] = item.ID
+	}
+	stable := longestCommonSubsequence(fromIDs, toIDs)
+
+	var diffs []SnapshotItemDiff
+	for i, item := range to {
+		itemDiff := SnapshotItemDiff{
+			EntityType: entityType, ID: item.ID, GalleryID: galleryID, Name: item.Name, ToPosition: i + 1,
+			Fields: []SnapshotFieldDiff{},
+		}
+		fromPosition, ok := fromPositions[item.ID]
+		if !ok {
+			itemDiff.Added = true
+			diffs = append(diffs, itemDiff)
+			continue
+		}
+
+		if !stable[item.ID] {
+			itemDiff.Moved, itemDiff.FromPosition = true, fromPosition+1
+		}
+		if compared == nil || !compared[item.ID] {
+			fields, err := diffSnapshotFields(from[fromPosition].Data, item.Data)
+			if err != nil {
+				return nil, err
+			}
+			itemDiff.Fields = fields
+			if compared != nil {
+				compared[item.ID] = true
+			}
+		}
+		if itemDiff.Moved || len(itemDiff.Fields) != 0 {
+			diffs = append(diffs, itemDiff)
+		}
+	}
+	for i, item := range from {
+		if _, ok := toPositions[item.ID]; !ok {
+			diffs = append(diffs, SnapshotItemDiff{
+				EntityType: entityType, ID: item.ID, GalleryID: galleryID, Name: item.Name, Removed: true,
+				FromPosition: i + 1, Fields: []SnapshotFieldDiff{},
+			})
+		}
+	}
+
+	return diffs, nil
+}
+
+// longestCommonSubsequence сущности, сохранившие взаимный порядок
+func longestCommonSubsequence(from, to []uuid.UUID) map[uuid.UUID]bool {
+	lengths := make([][]int, len(from)+1)
+	for i := range lengths {
+		lengths[i] = make([]int, len(to)+1)
+	}
+	for i := len(from) - 1; i >= 0; i-- {
+		for j := len(to) - 1; j >= 0; j-- {
+			switch {
+			case from[i] == to[j]:
+				lengths[i][j] = lengths[i+1][j+1] + 1
+			case lengths[i+1][j] >= lengths[i][j+1]:
+				lengths[i][j] = lengths[i+1][j]
+			default:
+				lengths[i][j] = lengths[i][j+1]
+			}
+		}
+	}
+
+	stable := make(map[uuid.UUID]bool, lengths[0][0])
+	for i, j := 0, 0; i < len(from) && j < len(to); {
+		switch {
+		case from[i] == to[j]:
+			stable[from[i]] = true
+			i, j = i+1, j+1
+		case lengths[i+1][j] >= lengths[i][j+1]:
+			i++
+		default:
+			j++
+		}
+	}
+
+	return stable
+}
+
+// diffSnapshotFields сравнение данных сущности по полям
+func diffSnapshotFields(from, to json.RawMessage) ([]SnapshotFieldDiff, error) {
+	fromFields, toFields := make(map[string]json.RawMessage), make(map[string]json.RawMessage)
+	if err := flattenSnapshotFields("", from, fromFields); err != nil {
+		return nil, err
+	}
+	if err := flattenSnapshotFields("", to, toFields); err != nil {
+		return nil, err
+	}
+
+	fields := make([]string, 0, len(toFields))
+	for field := range toFields {
+		fields = append(fields, field)
+	}
+	for field := range fromFields {
+		if _, ok := toFields[field]; !ok {
+			fields = append(fields, field)
+		}
+	}
+	sort.Strings(fields)
+
+	diffs := []SnapshotFieldDiff{}
+	null := json.RawMessage("null")
+	for _, field := range fields {
+		fromValue, ok := fromFields[field]
+		if !ok {
+			fromValue = null
+		}
+		toValue, ok := toFields[field]
+		if !ok {
+			toValue = null
+		}
+		if !bytes.Equal(fromValue, toValue) {
+			diffs = append(diffs, SnapshotFieldDiff{Field: field, From: fromValue, To: toValue})
+		}
+	}
+
+	return diffs, nil
+}
+
+// flattenSnapshotFields значения полей объекта data с раскрытием вложенных объектов, массивы сравниваются целиком
+func flattenSnapshotFields(prefix string, data json.RawMessage, fields map[string]json.RawMessage) error {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 {
+		return nil
+	}
+	if data[0] != '{' {
+		var compacted bytes.Buffer
+		if err := json.Compact(&compacted, data); err != nil {
+			return err
+		}
+		fields[prefix] = compacted.Bytes()
+		return nil
+	}
+
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(data, &object); err != nil {
+		return err
+	}
+	for key, value := range object {
+		field := key
+		if prefix != "" {
+			field = prefix + "." + key
+		}
+		if err := flattenSnapshotFields(field, value, fields); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,88 @@
+package entity
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestDiffSnapshots(t *testing.T) {
+	kept, moved, removed, added := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	card, shared, dropped := uuid.New(), uuid.New(), uuid.New()
+	item := func(id uuid.UUID, data string) SnapshotItem {
+		return SnapshotItem{ID: id, Name: id.String(), Data: json.RawMessage(data)}
+	}
+
+	from := SnapshotData{
+		Page: item(uuid.Nil, `{"name":"page","title":"old"}`),
+		Galleries: []SnapshotGallery{
+			{SnapshotItem: item(moved, `{"name":"moved"}`)},
+			{SnapshotItem: item(kept, `{"name":"kept"}`), Cards: []SnapshotItem{
+				item(card, `{"regularCard":{"previewText":"old","tags":["a"]}}`),
+				item(shared, `{"name":"shared"}`),
+				item(dropped, `{}`),
+			}},
+			{SnapshotItem: item(removed, `{}`)},
+		},
+	}
+	to := SnapshotData{
+		Page: item(uuid.Nil, `{"name": "page", "title": "new"}`),
+		Galleries: []SnapshotGallery{
+			{SnapshotItem: item(added, `{}`), Cards: []SnapshotItem{item(card, `{}`)}},
+			{SnapshotItem: item(kept, `{"name":"kept"}`), Cards: []SnapshotItem{
+				item(shared, `{"name":"shared"}`),
+				item(card, `{"regularCard":{"previewText":"new","tags":["a"]}}`),
+			}},
+			{SnapshotItem: item(moved, `{"name":"moved"}`), Cards: []SnapshotItem{item(shared, `{"name":"changed"}`)}},
+		},
+	}
+
+	diff, err := DiffSnapshots(from, to)
+	if err != nil {
+		t.Fatalf("DiffSnapshots() error = %v", err)
+	}
+
+	if len(diff.Page) != 1 || diff.Page[0].Field != "title" ||
+		string(diff.Page[0].From) != `"old"` || string(diff.Page[0].To) != `"new"` {
+		t.Errorf("page diff = %+v", diff.Page)
+	}
+
+	type key struct {
+		entityType DraftType
+		id         uuid.UUID
+	}
+	items := make(map[key]SnapshotItemDiff)
+	for _, itemDiff := range diff.Items {
+		items[key{itemDiff.EntityType, itemDiff.ID}] = itemDiff
+	}
+	if len(items) != len(diff.Items) || len(items) != 6 {
+		t.Fatalf("items = %+v", diff.Items)
+	}
+
+	if got := items[key{DraftTypeGallery, added}]; !got.Added || got.ToPosition != 1 {
+		t.Errorf("added gallery = %+v", got)
+	}
+	if got := items[key{DraftTypeGallery, removed}]; !got.Removed || got.FromPosition != 3 {
+		t.Errorf("removed gallery = %+v", got)
+	}
+	if got := items[key{DraftTypeGallery, moved}]; !got.Moved || got.FromPosition != 1 || got.ToPosition != 3 {
+		t.Errorf("moved gallery = %+v", got)
+	}
+	if _, ok := items[key{DraftTypeGallery, kept}]; ok {
+		t.Errorf("kept gallery must not be reported")
+	}
+
+	got := items[key{DraftTypeCard, card}]
+	if !got.Moved || got.GalleryID == nil || *got.GalleryID != kept ||
+		len(got.Fields) != 1 || got.Fields[0].Field != "regularCard.previewText" {
+		t.Errorf("changed card = %+v", got)
+	}
+	if got := items[key{DraftTypeCard, shared}]; got.Moved || got.GalleryID == nil || *got.GalleryID != moved ||
+		!got.Added {
+		t.Errorf("card added to moved gallery = %+v", got)
+	}
+	if got := items[key{DraftTypeCard, dropped}]; !got.Removed {
+		t.Errorf("removed card = %+v", got)
+	}
+}
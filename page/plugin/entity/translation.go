@@ -0,0 +1,160 @@
+package entity
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TranslationType тип сущности, для которой хранятся переводы
+type TranslationType string
+
+const (
+	TranslationTypePage    TranslationType = "page"    // TranslationTypePage перевод страницы
+	TranslationTypeGallery TranslationType = "gallery" // TranslationTypeGallery перевод галереи
+	TranslationTypeCard    TranslationType = "card"    // TranslationTypeCard перевод карточки
+	TranslationTypeTag     TranslationType = "tag"     // TranslationTypeTag перевод тега
+)
+
+// TranslatableFields коды переводимых полей сущностей каждого типа
+var TranslatableFields = map[TranslationType][]string{
+	TranslationTypePage:    {"title", "description", "titleSeo", "descriptionSeo", "keywords"},
+	TranslationTypeGallery: {"name"},
+	TranslationTypeCard:    {"title", "description", "previewText", "detailText"},
+	TranslationTypeTag:     {"text"},
+}
+
+// TranslationFields значения переводимых полей по их кодам
+type TranslationFields map[string]string
+
+func (f TranslationFields) Value() (driver.Value, error) {
+	if f == nil {
+		return "{}", nil
+	}
+
+	data, err := json.Marshal(f)
+	return string(data), err
+}
+
+func (f *TranslationFields) Scan(value interface{}) error {
+	var data []byte
+	switch v := value.(type) {
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	case nil:
+		*f = nil
+		return nil
+	default:
+		return errors.New("unsupported translation fields value")
+	}
+
+	return json.Unmarshal(data, f)
+}
+
+// Translation перевод полей сущности на язык, отличный от языка по умолчанию
+type Translation struct {
+	EntityType TranslationType   `gorm:"primaryKey"`
+	EntityID   uuid.UUID         `gorm:"type:uuid;primaryKey"`
+	Locale     string            `gorm:"primaryKey"`
+	Fields     TranslationFields `gorm:"type:jsonb"`
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+func (Translation) TableName() string {
+	return "translations"
+}
+
+// TranslationKey идентификатор переводимой сущности
+type TranslationKey struct {
+	EntityType TranslationType
+	EntityID   uuid.UUID
+}
+
+// TranslatableField переводимое поле сущности
+type TranslatableField struct {
+	TranslationKey
+	Code  string  // Code код поля из TranslatableFields
+	Value *string // Value указатель на значение поля в сущности
+}
+
+// TranslatableFields переводимые поля страницы вместе с полями ее галерей, карточек и тегов
+func (p *Page) TranslatableFields() []TranslatableField {
+	key := TranslationKey{EntityType: TranslationTypePage, EntityID: p.ID}
+	fields := []TranslatableField{
+		{TranslationKey: key, Code: "title", Value: &p.Title},
+		{TranslationKey: key, Code: "description", Value: &p.Description},
+		{TranslationKey: key, Code: "titleSeo", Value: &p.TitleSeo},
+		{TranslationKey: key, Code: "descriptionSeo", Value: &p.DescriptionSeo},
+		{TranslationKey: key, Code: "keywords", Value: &p.Keywords},
+	}
+	for i := range p.PagesGalleries {
+		fields = append(fields, p.PagesGalleries[i].Gallery.TranslatableFields()...)
+	}
+
+	return fields
+}
+
+// TranslatableFields переводимые поля галереи вместе с полями ее карточек и тегов
+func (g *Gallery) TranslatableFields() []TranslatableField {
+	fields := []TranslatableField{
+		{TranslationKey: TranslationKey{EntityType: TranslationTypeGallery, EntityID: g.ID}, Code: "name", Value: &g.Name},
+	}
+	for _, galleriesCard := range g.GalleriesCards {
+		if galleriesCard.Card != nil {
+			fields = append(fields, galleriesCard.Card.TranslatableFields()...)
+		}
+	}
+
+	return fields
+}
+
+// TranslatableFields переводимые поля карточки вместе с полями ее тегов
+func (c *Card) TranslatableFields() []TranslatableField {
+	key := TranslationKey{EntityType: TranslationTypeCard, EntityID: c.ID}
+	fields := []TranslatableField{
+		{TranslationKey: key, Code: "title", Value: &c.Title},
+		{TranslationKey: key, Code: "description", Value: &c.Description},
+	}
+	if c.RegularCard != nil {
+		fields = append(fields,
+			TranslatableField{TranslationKey: key, Code: "previewText", Value: &c.RegularCard.PreviewText},
+			TranslatableField{TranslationKey: key, Code: "detailText", Value: &c.RegularCard.DetailText},
+		)
+		for i := range c.RegularCard.RegularCardsTags {
+			fields = append(fields, c.RegularCard.RegularCardsTags[i].Tag.TranslatableFields()...)
+		}
+	}
+	if c.FormCard != nil {
+		for i := range c.FormCard.FormCardsTags {
+			fields = append(fields, c.FormCard.FormCardsTags[i].Tag.TranslatableFields()...)
+		}
+	}
+
+	return fields
+}
+
+// TranslatableFields переводимые поля тега
+func (t *Tag) TranslatableFields() []TranslatableField {
+	return []TranslatableField{
+		{TranslationKey: TranslationKey{EntityType: TranslationTypeTag, EntityID: t.ID}, Code: "text", Value: &t.Text},
+	}
+}
+
+// Translate замена значений полей переводами.
+// Для каждого поля берется первый непустой перевод в порядке translations, без перевода поле не изменяется.
+func Translate(fields []TranslatableField, translations ...map[TranslationKey]TranslationFields) {
+	for _, field := range fields {
+		for _, byKey := range translations {
+			if value := byKey[field.TranslationKey][field.Code]; value != "" {
+				*field.Value = value
+				break
+			}
+		}
+	}
+}
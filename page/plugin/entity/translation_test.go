@@ -0,0 +1,63 @@
+package entity
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestTranslate(t *testing.T) {
+	tag := Tag{ID: uuid.New(), Text: "Новости"}
+	card := Card{
+		ID:    uuid.New(),
+		Title: "Заголовок",
+		RegularCard: &RegularCard{
+			PreviewText:      "Превью",
+			DetailText:       "Текст",
+			RegularCardsTags: []RegularCardsTags{{Tag: tag}},
+		},
+	}
+	page := Page{
+		ID:             uuid.New(),
+		Title:          "Страница",
+		Keywords:       "ключевые слова",
+		PagesGalleries: []PagesGalleries{{Gallery: Gallery{ID: uuid.New(), Name: "Галерея", GalleriesCards: []GalleriesCards{{Card: &card}}}}},
+	}
+
+	fields := page.TranslatableFields()
+	if len(fields) != 5+1+4+1 {
+		t.Fatalf("TranslatableFields() returned %d fields", len(fields))
+	}
+
+	pageKey := TranslationKey{EntityType: TranslationTypePage, EntityID: page.ID}
+	cardKey := TranslationKey{EntityType: TranslationTypeCard, EntityID: card.ID}
+	tagKey := TranslationKey{EntityType: TranslationTypeTag, EntityID: tag.ID}
+	Translate(fields,
+		map[TranslationKey]TranslationFields{pageKey: {"title": "Page", "keywords": ""}, cardKey: {"previewText": "Preview"}},
+		map[TranslationKey]TranslationFields{pageKey: {"title": "Seite", "keywords": "Stichworte"}, tagKey: {"text": "News"}},
+	)
+
+	gallery := page.PagesGalleries[0].Gallery
+	translated := gallery.GalleriesCards[0].Card
+	if page.Title != "Page" || page.Keywords != "Stichworte" || gallery.Name != "Галерея" {
+		t.Errorf("Translate() page = %q, %q, gallery = %q", page.Title, page.Keywords, gallery.Name)
+	}
+	if translated.RegularCard.PreviewText != "Preview" || translated.RegularCard.DetailText != "Текст" {
+		t.Errorf("Translate() card = %+v", translated.RegularCard)
+	}
+	if got := translated.RegularCard.RegularCardsTags[0].Tag.Text; got != "News" {
+		t.Errorf("Translate() tag = %q", got)
+	}
+}
+
+func TestTranslationFields_Scan(t *testing.T) {
+	value, err := TranslationFields{"title": "Page"}.Value()
+	if err != nil {
+		t.Fatalf("Value() error = %v", err)
+	}
+
+	var fields TranslationFields
+	if err = fields.Scan([]byte(value.(string))); err != nil || fields["title"] != "Page" {
+		t.Errorf("Scan() = %v, %v", fields, err)
+	}
+}
@@ -5,6 +5,8 @@ go 1.21.0
 require (
 	github.com/aeroideaservices/focus/media/plugin v1.0.3
 	github.com/aeroideaservices/focus/services/errors v1.0.0
+	github.com/aeroideaservices/focus/services/sanitizer v1.0.0
+	github.com/aeroideaservices/focus/sitemap/plugin v1.0.0
 	github.com/google/uuid v1.6.0
 	github.com/jinzhu/copier v0.4.0
 	github.com/sarulabs/di/v2 v2.4.2
@@ -27,5 +29,6 @@ require (
 	gitlab.aeroidea.ru/internal-projects/focus/services/errors v0.2.1 // indirect
 	go.uber.org/multierr v1.10.0 // indirect
 	golang.org/x/exp v0.0.0-20230522175609-2e198f4a06a1 // indirect
+	golang.org/x/net v0.25.0 // indirect
 	gorm.io/gorm v1.23.8 // indirect
 )
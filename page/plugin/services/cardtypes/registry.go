@@ -0,0 +1,286 @@
+// Package cardtypes реестр типов карточек.
+// Встроенные типы (regular, video, html, photo, form) обрабатываются плагином напрямую,
+// новые типы регистрируются приложением: данные карточки такого типа хранятся в собственной таблице,
+// а плагин работает с ними через описание типа.
+package cardtypes
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// Builtin коды встроенных типов карточек
+var Builtin = []string{"regular", "video", "html", "photo", "form"}
+
+var ErrUnknownType = errors.New("unknown card type") // ErrUnknownType тип карточки не зарегистрирован
+
+// Validator проверка структуры по тегам validate, общая для focus
+type Validator interface {
+	Validate(ctx context.Context, value any) error
+}
+
+// Type описание типа карточки
+type Type struct {
+	Code  string // Code значение поля Type карточки
+	Title string // Title название типа в интерфейсе
+	Table string // Table таблица с данными карточек этого типа
+
+	// Model структура данных карточки с полем ID типа uuid.UUID.
+	// Поля описываются тегами json, focus (title) и validate, как в моделях focus.
+	Model any
+
+	// Validate дополнительная проверка данных карточки, вызывается после разбора
+	Validate func(data any) error
+
+	builtin   bool
+	t         reflect.Type
+	validator Validator
+}
+
+// Builtin встроенный ли тип
+func (t Type) Builtin() bool {
+	return t.builtin
+}
+
+// New пустые данные карточки, указатель на структуру Model
+func (t Type) New() any {
+	return reflect.New(t.t).Interface()
+}
+
+// NewSlice указатель на пустой срез структур Model, используется для выборки из таблицы
+func (t Type) NewSlice() any {
+	return reflect.New(reflect.SliceOf(t.t)).Interface()
+}
+
+// Decode разбор и проверка данных карточки: сначала по тегам validate модели, затем функцией Validate
+func (t Type) Decode(ctx context.Context, data json.RawMessage) (any, error) {
+	value := t.New()
+	if len(data) != 0 {
+		if err := json.Unmarshal(data, value); err != nil {
+			return nil, err
+		}
+	}
+	if t.validator != nil {
+		if err := t.validator.Validate(ctx, value); err != nil {
+			return nil, err
+		}
+	}
+	if t.Validate != nil {
+		if err := t.Validate(value); err != nil {
+			return nil, err
+		}
+	}
+
+	return value, nil
+}
+
+// Field описание поля данных карточки для формы focus
+type Field struct {
+	Code     string `json:"code"`
+	Title    string `json:"title"`
+	Type     string `json:"type"`
+	Required bool   `json:"required"`
+}
+
+// Fields поля данных карточки, кроме идентификатора
+func (t Type) Fields() []Field {
+	if t.t == nil {
+		return nil
+	}
+
+	fields := make([]Field, 0, t.t.NumField())
+	for i := 0; i < t.t.NumField(); i++ {
+		field := t.t.Field(i)
+		if !field.IsExported() || field.Name == "ID" {
+			continue
+		}
+		code, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if code == "-" {
+			continue
+		}
+		if code == "" {
+			code = field.Name
+		}
+		title := tagValue(field.Tag.Get("focus"), "title")
+		if title == "" {
+			title = field.Name
+		}
+
+		fields = append(fields, Field{
+			Code:     code,
+			Title:    title,
+			Type:     fieldType(field.Type),
+			Required: strings.Contains(","+field.Tag.Get("validate")+",", ",required,"),
+		})
+	}
+
+	return fields
+}
+
+// ID идентификатор данных карточки
+func ID(data any) uuid.UUID {
+	return idField(data).Interface().(uuid.UUID)
+}
+
+// SetID установка идентификатора данных карточки
+func SetID(data any, id uuid.UUID) {
+	idField(data).Set(reflect.ValueOf(id))
+}
+
+// Items указатели на элементы среза, полученного через NewSlice
+func Items(rows any) []any {
+	value := reflect.ValueOf(rows)
+	for value.Kind() == reflect.Pointer {
+		value = value.Elem()
+	}
+
+	items := make([]any, value.Len())
+	for i := range items {
+		items[i] = value.Index(i).Addr().Interface()
+	}
+
+	return items
+}
+
+// Copy копия данных карточки с новым идентификатором
+func Copy(data any, id uuid.UUID) any {
+	value := reflect.ValueOf(data).Elem()
+	clone := reflect.New(value.Type())
+	clone.Elem().Set(value)
+	SetID(clone.Interface(), id)
+
+	return clone.Interface()
+}
+
+// Registry зарегистрированные типы карточек
+type Registry struct {
+	registered map[string]*Type
+	validator  Validator
+}
+
+// NewRegistry конструктор, встроенные типы регистрируются сразу.
+// Валидатор проверяет данные карточек зарегистрированных типов при разборе, nil - проверка только функцией Validate
+func NewRegistry(validator Validator) *Registry {
+	r := &Registry{registered: make(map[string]*Type), validator: validator}
+	for _, code := range Builtin {
+		r.registered[code] = &Type{Code: code, Title: code, builtin: true}
+	}
+
+	return r
+}
+
+// Register регистрация типов карточек.
+// Паникует при повторной регистрации кода и при неверном описании типа, как и реестр моделей focus.
+func (r *Registry) Register(types ...Type) {
+	for _, cardType := range types {
+		if cardType.Code == "" || cardType.Table == "" {
+			panic("card type code and table are required")
+		}
+		if _, ok := r.registered[cardType.Code]; ok {
+			panic(fmt.Sprintf("card type %s already registered", cardType.Code))
+		}
+
+		t := reflect.TypeOf(cardType.Model)
+		for t != nil && t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+		if t == nil || t.Kind() != reflect.Struct {
+			panic(fmt.Sprintf("card type %s: wrong model specified, expected struct", cardType.Code))
+		}
+		if field, ok := t.FieldByName("ID"); !ok || field.Type != reflect.TypeOf(uuid.UUID{}) {
+			panic(fmt.Sprintf("card type %s: model must have ID field of type uuid.UUID", cardType.Code))
+		}
+		if cardType.Title == "" {
+			cardType.Title = cardType.Code
+		}
+
+		cardType.t = t
+		cardType.validator = r.validator
+		r.registered[cardType.Code] = &cardType
+	}
+}
+
+// Get получение типа по коду
+func (r Registry) Get(code string) (*Type, error) {
+	cardType, ok := r.registered[code]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownType, code)
+	}
+
+	return cardType, nil
+}
+
+// Custom получение зарегистрированного приложением типа, nil для встроенных и неизвестных типов
+func (r Registry) Custom(code string) *Type {
+	cardType, ok := r.registered[code]
+	if !ok || cardType.builtin {
+		return nil
+	}
+
+	return cardType
+}
+
+// List все типы, упорядоченные по коду
+func (r Registry) List() []*Type {
+	list := make([]*Type, 0, len(r.registered))
+	for _, cardType := range r.registered {
+		list = append(list, cardType)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Code < list[j].Code })
+
+	return list
+}
+
+func idField(data any) reflect.Value {
+	value := reflect.ValueOf(data)
+	for value.Kind() == reflect.Pointer {
+		value = value.Elem()
+	}
+
+	return value.FieldByName("ID")
+}
+
+func tagValue(tag string, key string) string {
+	for _, part := range strings.Split(tag, ";") {
+		name, value, _ := strings.Cut(part, ":")
+		if strings.TrimSpace(name) == key {
+			return strings.TrimSpace(value)
+		}
+	}
+
+	return ""
+}
+
+func fieldType(t reflect.Type) string {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == reflect.TypeOf(uuid.UUID{}) {
+		return "uuid"
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return "bool"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "int"
+	case reflect.Float32, reflect.Float64:
+		return "float"
+	case reflect.String:
+		return "string"
+	case reflect.Struct:
+		if t.PkgPath() == "time" && t.Name() == "Time" {
+			return "time"
+		}
+	}
+
+	return "json"
+}
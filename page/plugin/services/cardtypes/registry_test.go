@@ -0,0 +1,164 @@
+package cardtypes
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+type quoteCard struct {
+	ID     uuid.UUID `json:"-"`
+	Text   string    `json:"text" focus:"title:Цитата" validate:"required"`
+	Author *string   `json:"author" focus:"title:Автор"`
+	Rating int       `json:"rating"`
+}
+
+// requiredValidator проверка только тега validate:"required", как у валидатора focus
+type requiredValidator struct{}
+
+func (requiredValidator) Validate(_ context.Context, value any) error {
+	v := reflect.ValueOf(value).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		if strings.Contains(field.Tag.Get("validate"), "required") && v.Field(i).IsZero() {
+			return errors.New(field.Name + " is required")
+		}
+	}
+	return nil
+}
+
+func newTestRegistry() *Registry {
+	r := NewRegistry(requiredValidator{})
+	r.Register(Type{
+		Code:  "quote",
+		Title: "Цитата",
+		Table: "quote_cards",
+		Model: quoteCard{},
+		Validate: func(data any) error {
+			if data.(*quoteCard).Rating < 0 {
+				return errors.New("rating must not be negative")
+			}
+			return nil
+		},
+	})
+
+	return r
+}
+
+func TestRegistry_Get(t *testing.T) {
+	r := newTestRegistry()
+
+	for _, code := range append(Builtin, "quote") {
+		if _, err := r.Get(code); err != nil {
+			t.Errorf("Get(%q) error = %v", code, err)
+		}
+	}
+	if _, err := r.Get("countdown"); !errors.Is(err, ErrUnknownType) {
+		t.Errorf("Get(countdown) error = %v, want ErrUnknownType", err)
+	}
+
+	if r.Custom("regular") != nil {
+		t.Error("Custom(regular) must be nil for builtin type")
+	}
+	if r.Custom("quote") == nil {
+		t.Error("Custom(quote) = nil")
+	}
+	if got := len(r.List()); got != len(Builtin)+1 {
+		t.Errorf("len(List()) = %d, want %d", got, len(Builtin)+1)
+	}
+}
+
+func TestRegistry_RegisterPanics(t *testing.T) {
+	tests := map[string]Type{
+		"builtin code": {Code: "html", Table: "x", Model: quoteCard{}},
+		"no table":     {Code: "x", Model: quoteCard{}},
+		"not struct":   {Code: "x", Table: "x", Model: "text"},
+		"no id":        {Code: "x", Table: "x", Model: struct{ Text string }{}},
+	}
+	for name, cardType := range tests {
+		t.Run(name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Error("Register did not panic")
+				}
+			}()
+			NewRegistry(nil).Register(cardType)
+		})
+	}
+}
+
+func TestType_Decode(t *testing.T) {
+	quote, _ := newTestRegistry().Get("quote")
+
+	ctx := context.Background()
+
+	data, err := quote.Decode(ctx, json.RawMessage(`{"text":"hello","rating":5}`))
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if card := data.(*quoteCard); card.Text != "hello" || card.Rating != 5 {
+		t.Errorf("Decode() = %+v", card)
+	}
+
+	if _, err = quote.Decode(ctx, json.RawMessage(`{"rating":5}`)); err == nil {
+		t.Error("Decode() without text must fail validate tags")
+	}
+	if _, err = quote.Decode(ctx, json.RawMessage(`{"text":"hello","rating":-1}`)); err == nil {
+		t.Error("Decode() with negative rating must fail Validate")
+	}
+	if _, err = quote.Decode(ctx, json.RawMessage(`{"text":`)); err == nil {
+		t.Error("Decode() of broken json must fail")
+	}
+}
+
+func TestType_Fields(t *testing.T) {
+	quote, _ := newTestRegistry().Get("quote")
+
+	want := []Field{
+		{Code: "text", Title: "Цитата", Type: "string", Required: true},
+		{Code: "author", Title: "Автор", Type: "string"},
+		{Code: "rating", Title: "Rating", Type: "int"},
+	}
+	got := quote.Fields()
+	if len(got) != len(want) {
+		t.Fatalf("Fields() = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Fields()[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestCopy(t *testing.T) {
+	original := &quoteCard{ID: uuid.New(), Text: "hello"}
+	id := uuid.New()
+
+	clone := Copy(original, id).(*quoteCard)
+	if clone == original || clone.ID != id || clone.Text != "hello" {
+		t.Errorf("Copy() = %+v", clone)
+	}
+	if ID(original) == id {
+		t.Error("Copy() changed original id")
+	}
+}
+
+func TestItems(t *testing.T) {
+	quote, _ := newTestRegistry().Get("quote")
+	rows := quote.NewSlice()
+	*rows.(*[]quoteCard) = []quoteCard{{Text: "a"}, {Text: "b"}}
+
+	items := Items(rows)
+	if len(items) != 2 || items[1].(*quoteCard).Text != "b" {
+		t.Fatalf("Items() = %+v", items)
+	}
+	items[0].(*quoteCard).Text = "c"
+	if (*rows.(*[]quoteCard))[0].Text != "c" {
+		t.Error("Items() must point to slice elements")
+	}
+}
@@ -1,7 +1,6 @@
 package services
 
 import (
-	"bufio"
 	"bytes"
 	ffmpeg "github.com/u2takey/ffmpeg-go"
 	"io"
@@ -57,29 +56,21 @@ func CompressVideo(in io.Reader) (*bytes.Reader, error) {
 }
 
 func GetAudioFromVideo(fname string) (*bytes.Reader, string, error) {
-	audio := bytes.NewBuffer(nil)
-
 	outputFile := strings.ReplaceAll(fname, ".mp4", ".mp3")
 
 	outputFile = "audio_" + outputFile
 
 	err := ffmpeg.Input("file:"+fname).Output(
 		outputFile, ffmpeg.KwArgs{"q:a": 0, "map": "a"},
-	).WithOutput(audio).Run()
-
-	audioOs, err := os.Open(outputFile)
-
-	stat, err := audioOs.Stat()
+	).Run()
 	if err != nil {
-		return nil, "", err
+		return nil, outputFile, err
 	}
 
-	// Read the file into a byte slice
-	bs := make([]byte, stat.Size())
-	_, err = bufio.NewReader(audioOs).Read(bs)
-	if err != nil && err != io.EOF {
-		return nil, "", err
+	bs, err := os.ReadFile(outputFile)
+	if err != nil {
+		return nil, outputFile, err
 	}
 
-	return bytes.NewReader(bs), outputFile, err
+	return bytes.NewReader(bs), outputFile, nil
 }
@@ -0,0 +1,166 @@
+package hls
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	ffmpeg "github.com/u2takey/ffmpeg-go"
+)
+
+const maxErrorOutputLen = 1024
+
+// FFmpeg формирование потока HLS утилитами ffmpeg и ffprobe
+type FFmpeg struct{}
+
+// NewFFmpeg конструктор
+func NewFFmpeg() *FFmpeg {
+	return &FFmpeg{}
+}
+
+// Transcode формирование вариантов качества, каждый вариант кодируется отдельным запуском ffmpeg
+func (f FFmpeg) Transcode(ctx context.Context, input string, options Options) (*Result, error) {
+	err := validate(options)
+	if err != nil {
+		return nil, err
+	}
+
+	probe, err := ffmpeg.Probe(input)
+	if err != nil {
+		return nil, fmt.Errorf("error probing video: %w", err)
+	}
+	width, height, err := parseProbe([]byte(probe))
+	if err != nil {
+		return nil, err
+	}
+
+	dir, err := os.MkdirTemp("", "hls_")
+	if err != nil {
+		return nil, err
+	}
+	result := &Result{Dir: dir, Variants: Plan(width, height, options.Renditions)}
+
+	for _, variant := range result.Variants {
+		err = f.transcodeVariant(ctx, input, dir, variant, options)
+		if err != nil {
+			_ = os.RemoveAll(dir)
+			return nil, fmt.Errorf("error transcoding %s: %w", variant.Name, err)
+		}
+	}
+
+	master, err := os.Create(filepath.Join(dir, MasterPlaylist))
+	if err != nil {
+		_ = os.RemoveAll(dir)
+		return nil, err
+	}
+	defer master.Close()
+
+	err = WriteMasterPlaylist(master, result.Variants)
+	if err != nil {
+		_ = os.RemoveAll(dir)
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (f FFmpeg) transcodeVariant(ctx context.Context, input string, dir string, variant Variant, options Options) error {
+	variantDir := filepath.Join(dir, variant.Name)
+	err := os.Mkdir(variantDir, 0o755)
+	if err != nil {
+		return err
+	}
+
+	stderr := &bytes.Buffer{}
+	stream := ffmpeg.Input("file:"+input).
+		Output(filepath.Join(dir, variant.Playlist), variantArgs(variantDir, variant, options))
+	stream.Context = ctx
+
+	err = stream.OverWriteOutput().WithErrorOutput(stderr).Run()
+	if err != nil {
+		output := stderr.String()
+		if len(output) > maxErrorOutputLen {
+			output = output[len(output)-maxErrorOutputLen:]
+		}
+		return fmt.Errorf("%w: %s", err, output)
+	}
+
+	return nil
+}
+
+// variantArgs аргументы ffmpeg для варианта качества: H.264 + AAC, ключевые кадры на границах сегментов
+func variantArgs(variantDir string, variant Variant, options Options) ffmpeg.KwArgs {
+	segment := strconv.FormatFloat(options.SegmentDuration.Seconds(), 'f', -1, 64)
+	videoBitrate := variant.rendition.VideoBitrate
+
+	return ffmpeg.KwArgs{
+		"vf":                   fmt.Sprintf("scale=%d:%d", variant.Width, variant.Height),
+		"c:v":                  "libx264",
+		"preset":               "veryfast",
+		"profile:v":            "main",
+		"b:v":                  strconv.Itoa(videoBitrate) + "k",
+		"maxrate":              strconv.Itoa(videoBitrate*107/100) + "k",
+		"bufsize":              strconv.Itoa(videoBitrate*3/2) + "k",
+		"force_key_frames":     "expr:gte(t,n_forced*" + segment + ")",
+		"sc_threshold":         0,
+		"c:a":                  "aac",
+		"b:a":                  strconv.Itoa(variant.rendition.AudioBitrate) + "k",
+		"ac":                   2,
+		"f":                    "hls",
+		"hls_time":             segment,
+		"hls_playlist_type":    "vod",
+		"hls_segment_filename": filepath.Join(variantDir, "segment_%05d.ts"),
+	}
+}
+
+// parseProbe получение размеров первого видеопотока из вывода ffprobe
+func parseProbe(data []byte) (int, int, error) {
+	probe := struct {
+		Streams []struct {
+			CodecType string `json:"codec_type"`
+			Width     int    `json:"width"`
+			Height    int    `json:"height"`
+		} `json:"streams"`
+	}{}
+	err := json.Unmarshal(data, &probe)
+	if err != nil {
+		return 0, 0, fmt.Errorf("error parsing probe: %w", err)
+	}
+
+	for _, stream := range probe.Streams {
+		if stream.CodecType == "video" && stream.Width > 0 && stream.Height > 0 {
+			return stream.Width, stream.Height, nil
+		}
+	}
+
+	return 0, 0, ErrNoVideo
+}
+
+// validate проверка параметров: названия вариантов используются как имена директорий
+func validate(options Options) error {
+	if len(options.Renditions) == 0 {
+		return fmt.Errorf("no renditions")
+	}
+	if options.SegmentDuration <= 0 {
+		return fmt.Errorf("invalid segment duration %s", options.SegmentDuration)
+	}
+
+	names := make(map[string]bool, len(options.Renditions))
+	for _, rendition := range options.Renditions {
+		if rendition.Name == "" || rendition.Name == "." || rendition.Name == ".." ||
+			strings.ContainsAny(rendition.Name, `/\`) || names[rendition.Name] {
+			return fmt.Errorf("invalid rendition name %q", rendition.Name)
+		}
+		if rendition.Height <= 0 || rendition.VideoBitrate <= 0 || rendition.AudioBitrate <= 0 {
+			return fmt.Errorf("invalid rendition %q", rendition.Name)
+		}
+		names[rendition.Name] = true
+	}
+
+	return nil
+}
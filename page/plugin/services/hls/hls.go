@@ -0,0 +1,127 @@
+// Package hls формирование потоков HLS с несколькими вариантами качества (лестницей битрейтов).
+package hls
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+const (
+	MasterPlaylist  = "master.m3u8" // MasterPlaylist имя мастер-плейлиста
+	VariantPlaylist = "index.m3u8"  // VariantPlaylist имя плейлиста варианта качества
+)
+
+// ErrNoVideo файл не содержит видеопотока
+var ErrNoVideo = errors.New("input has no video stream")
+
+// Transcoder формирование потока HLS из видеофайла
+type Transcoder interface {
+	// Transcode формирует варианты качества и мастер-плейлист в новой временной директории.
+	// Директорию удаляет вызывающий код.
+	Transcode(ctx context.Context, input string, options Options) (*Result, error)
+}
+
+// Rendition параметры варианта качества
+type Rendition struct {
+	Name         string `json:"name"`         // Name название варианта, используется как имя директории, например "720p"
+	Height       int    `json:"height"`       // Height высота кадра, ширина вычисляется с сохранением пропорций
+	VideoBitrate int    `json:"videoBitrate"` // VideoBitrate битрейт видео, кбит/с
+	AudioBitrate int    `json:"audioBitrate"` // AudioBitrate битрейт аудио, кбит/с
+}
+
+// Options параметры формирования потока
+type Options struct {
+	Renditions      []Rendition   // Renditions варианты качества
+	SegmentDuration time.Duration // SegmentDuration длительность сегмента
+}
+
+// DefaultOptions параметры по умолчанию
+var DefaultOptions = Options{
+	Renditions: []Rendition{
+		{Name: "1080p", Height: 1080, VideoBitrate: 5000, AudioBitrate: 192},
+		{Name: "720p", Height: 720, VideoBitrate: 2800, AudioBitrate: 128},
+		{Name: "480p", Height: 480, VideoBitrate: 1400, AudioBitrate: 128},
+		{Name: "360p", Height: 360, VideoBitrate: 800, AudioBitrate: 96},
+	},
+	SegmentDuration: 6 * time.Second,
+}
+
+// Variant сформированный вариант качества
+type Variant struct {
+	Name      string // Name название варианта
+	Width     int    // Width ширина кадра
+	Height    int    // Height высота кадра
+	Bandwidth int    // Bandwidth суммарный битрейт, бит/с
+	Playlist  string // Playlist путь к плейлисту варианта относительно директории результата
+
+	rendition Rendition
+}
+
+// Result результат формирования потока
+type Result struct {
+	Dir      string    // Dir директория с мастер-плейлистом, плейлистами и сегментами вариантов
+	Variants []Variant // Variants сформированные варианты качества
+}
+
+// Plan выбор вариантов качества для видео размером width x height.
+// Варианты выше исходного видео пропускаются; если не подходит ни один, используется
+// вариант с наименьшим битрейтом в исходном размере.
+func Plan(width, height int, renditions []Rendition) []Variant {
+	if width <= 0 || height <= 0 || len(renditions) == 0 {
+		return nil
+	}
+
+	var variants []Variant
+	lowest := renditions[0]
+	for _, rendition := range renditions {
+		if rendition.VideoBitrate < lowest.VideoBitrate {
+			lowest = rendition
+		}
+		if rendition.Height > height {
+			continue
+		}
+		variants = append(variants, newVariant(rendition, width, height, rendition.Height))
+	}
+	if len(variants) == 0 {
+		variants = append(variants, newVariant(lowest, width, height, height))
+	}
+
+	return variants
+}
+
+// WriteMasterPlaylist запись мастер-плейлиста со ссылками на плейлисты вариантов
+func WriteMasterPlaylist(w io.Writer, variants []Variant) error {
+	b := &strings.Builder{}
+	b.WriteString("#EXTM3U\n#EXT-X-VERSION:3\n")
+	for _, variant := range variants {
+		_, _ = fmt.Fprintf(b, "#EXT-X-STREAM-INF:BANDWIDTH=%d,RESOLUTION=%dx%d,NAME=%q\n%s\n",
+			variant.Bandwidth, variant.Width, variant.Height, variant.Name, variant.Playlist)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func newVariant(rendition Rendition, sourceWidth, sourceHeight, height int) Variant {
+	height = even(height)
+	return Variant{
+		Name:      rendition.Name,
+		Width:     even(sourceWidth * height / sourceHeight),
+		Height:    height,
+		Bandwidth: (rendition.VideoBitrate + rendition.AudioBitrate) * 1000,
+		Playlist:  rendition.Name + "/" + VariantPlaylist,
+		rendition: rendition,
+	}
+}
+
+// even округление до четного числа, которого требует кодек H.264
+func even(v int) int {
+	if v < 2 {
+		return 2
+	}
+	return v &^ 1
+}
@@ -0,0 +1,125 @@
+package hls
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestPlan(t *testing.T) {
+	renditions := []Rendition{
+		{Name: "720p", Height: 720, VideoBitrate: 2800, AudioBitrate: 128},
+		{Name: "360p", Height: 360, VideoBitrate: 800, AudioBitrate: 96},
+	}
+	tests := []struct {
+		name   string
+		width  int
+		height int
+		want   []Variant
+	}{
+		{
+			name:   "full ladder",
+			width:  1920,
+			height: 1080,
+			want: []Variant{
+				{Name: "720p", Width: 1280, Height: 720, Bandwidth: 2928000, Playlist: "720p/index.m3u8", rendition: renditions[0]},
+				{Name: "360p", Width: 640, Height: 360, Bandwidth: 896000, Playlist: "360p/index.m3u8", rendition: renditions[1]},
+			},
+		},
+		{
+			name:   "no upscale",
+			width:  720,
+			height: 480,
+			want: []Variant{
+				{Name: "360p", Width: 540, Height: 360, Bandwidth: 896000, Playlist: "360p/index.m3u8", rendition: renditions[1]},
+			},
+		},
+		{
+			name:   "small source",
+			width:  321,
+			height: 241,
+			want: []Variant{
+				{Name: "360p", Width: 318, Height: 240, Bandwidth: 896000, Playlist: "360p/index.m3u8", rendition: renditions[1]},
+			},
+		},
+		{
+			name:   "invalid size",
+			width:  0,
+			height: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Plan(tt.width, tt.height, renditions); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Plan() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteMasterPlaylist(t *testing.T) {
+	buf := &bytes.Buffer{}
+	err := WriteMasterPlaylist(buf, Plan(1280, 720, DefaultOptions.Renditions))
+	if err != nil {
+		t.Fatalf("WriteMasterPlaylist() error = %v", err)
+	}
+
+	want := "#EXTM3U\n#EXT-X-VERSION:3\n" +
+		"#EXT-X-STREAM-INF:BANDWIDTH=2928000,RESOLUTION=1280x720,NAME=\"720p\"\n720p/index.m3u8\n" +
+		"#EXT-X-STREAM-INF:BANDWIDTH=1528000,RESOLUTION=852x480,NAME=\"480p\"\n480p/index.m3u8\n" +
+		"#EXT-X-STREAM-INF:BANDWIDTH=896000,RESOLUTION=640x360,NAME=\"360p\"\n360p/index.m3u8\n"
+	if buf.String() != want {
+		t.Errorf("WriteMasterPlaylist() got = %q, want %q", buf.String(), want)
+	}
+}
+
+func TestParseProbe(t *testing.T) {
+	width, height, err := parseProbe([]byte(`{"streams":[{"codec_type":"audio"},{"codec_type":"video","width":1920,"height":1080}]}`))
+	if err != nil || width != 1920 || height != 1080 {
+		t.Errorf("parseProbe() = %d, %d, %v", width, height, err)
+	}
+
+	_, _, err = parseProbe([]byte(`{"streams":[{"codec_type":"audio"}]}`))
+	if err != ErrNoVideo {
+		t.Errorf("parseProbe() error = %v, want %v", err, ErrNoVideo)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		options Options
+		wantErr bool
+	}{
+		{name: "default", options: DefaultOptions},
+		{name: "no renditions", options: Options{SegmentDuration: time.Second}, wantErr: true},
+		{name: "no segment duration", options: Options{Renditions: DefaultOptions.Renditions}, wantErr: true},
+		{
+			name: "path in name",
+			options: Options{
+				Renditions:      []Rendition{{Name: "../720p", Height: 720, VideoBitrate: 1, AudioBitrate: 1}},
+				SegmentDuration: time.Second,
+			},
+			wantErr: true,
+		},
+		{
+			name: "duplicate name",
+			options: Options{
+				Renditions: []Rendition{
+					{Name: "720p", Height: 720, VideoBitrate: 1, AudioBitrate: 1},
+					{Name: "720p", Height: 480, VideoBitrate: 1, AudioBitrate: 1},
+				},
+				SegmentDuration: time.Second,
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validate(tt.options); (err != nil) != tt.wantErr {
+				t.Errorf("validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
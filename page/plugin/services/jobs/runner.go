@@ -0,0 +1,265 @@
+// Package jobs выполнение фоновых задач из персистентной очереди: аренда задач обработчиками,
+// повторные попытки с экспоненциальной задержкой и перевод в статус failed после исчерпания попыток.
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/aeroideaservices/focus/page/plugin/entity"
+)
+
+// ErrLeaseLost аренда задачи истекла и задача взята другим обработчиком
+var ErrLeaseLost = errors.New("job lease lost")
+
+// Queue персистентная очередь задач
+type Queue interface {
+	// Lease взятие в работу первой готовой задачи одного из типов types; nil, если готовых задач нет
+	Lease(ctx context.Context, worker string, types []string, lease time.Duration) (*entity.Job, error)
+	// ExtendLease продление аренды задачи; ErrLeaseLost, если задача больше не принадлежит обработчику
+	ExtendLease(ctx context.Context, id uuid.UUID, worker string, lease time.Duration) error
+	// Complete завершение задачи с результатом
+	Complete(ctx context.Context, id uuid.UUID, worker string, result []byte) error
+	// Fail завершение попытки с ошибкой: при retryAt == nil задача переходит в статус failed, иначе ставится в очередь повторно
+	Fail(ctx context.Context, id uuid.UUID, worker string, message string, retryAt *time.Time) error
+}
+
+// Handler обработчик задачи, возвращает результат задачи в формате JSON (может быть nil)
+type Handler func(ctx context.Context, job entity.Job) ([]byte, error)
+
+// Config параметры выполнения задач
+type Config struct {
+	Workers      int           // Workers количество одновременно выполняемых задач
+	PollInterval time.Duration // PollInterval интервал опроса очереди, если готовых задач нет
+	Lease        time.Duration // Lease длительность аренды задачи, аренда продлевается каждую треть срока
+	MinBackoff   time.Duration // MinBackoff задержка перед второй попыткой
+	MaxBackoff   time.Duration // MaxBackoff максимальная задержка между попытками
+}
+
+// DefaultConfig параметры по умолчанию
+var DefaultConfig = Config{
+	Workers:      2,
+	PollInterval: 5 * time.Second,
+	Lease:        time.Minute,
+	MinBackoff:   30 * time.Second,
+	MaxBackoff:   time.Hour,
+}
+
+// permanentError ошибка, после которой задача не повторяется
+type permanentError struct {
+	err error
+}
+
+func (e permanentError) Error() string {
+	return e.err.Error()
+}
+
+func (e permanentError) Unwrap() error {
+	return e.err
+}
+
+// Permanent помечает ошибку как неустранимую: задача сразу переходит в статус failed без повторных попыток
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return permanentError{err: err}
+}
+
+// Runner выполнение задач из очереди
+type Runner struct {
+	queue  Queue
+	logger *zap.SugaredLogger
+	config Config
+	worker string
+
+	mu       sync.RWMutex
+	handlers map[string]Handler
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewRunner конструктор
+func NewRunner(queue Queue, logger *zap.SugaredLogger, config Config) *Runner {
+	if config.Workers <= 0 {
+		config.Workers = 1
+	}
+
+	return &Runner{
+		queue:    queue,
+		logger:   logger,
+		config:   config,
+		worker:   uuid.NewString(),
+		handlers: make(map[string]Handler),
+	}
+}
+
+// Register регистрация обработчика задач типа jobType. Задачи берутся в работу только для зарегистрированных типов.
+func (r *Runner) Register(jobType string, handler Handler) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.handlers[jobType] = handler
+}
+
+// Start запуск обработчиков в фоне
+func (r *Runner) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	r.cancel = cancel
+
+	for i := 0; i < r.config.Workers; i++ {
+		r.wg.Add(1)
+		go func(worker string) {
+			defer r.wg.Done()
+			r.work(ctx, worker)
+		}(fmt.Sprintf("%s/%d", r.worker, i))
+	}
+}
+
+// Stop остановка обработчиков с ожиданием их завершения.
+// Контекст выполняемых задач отменяется, задачи будут повторены после истечения аренды.
+func (r *Runner) Stop() {
+	if r.cancel != nil {
+		r.cancel()
+	}
+	r.wg.Wait()
+}
+
+// RunOnce взятие в работу и выполнение одной задачи; false, если готовых задач нет
+func (r *Runner) RunOnce(ctx context.Context, worker string) (bool, error) {
+	types, handlers := r.registered()
+	if len(types) == 0 {
+		return false, nil
+	}
+
+	job, err := r.queue.Lease(ctx, worker, types, r.config.Lease)
+	if err != nil || job == nil {
+		return false, err
+	}
+
+	handler, ok := handlers[job.Type]
+	if !ok {
+		return true, r.queue.Fail(ctx, job.ID, worker, "no handler for job type "+job.Type, nil)
+	}
+
+	result, err := r.handle(ctx, worker, *job, handler)
+	if errors.Is(err, ErrLeaseLost) || (err != nil && ctx.Err() != nil) {
+		// задача будет повторена после истечения аренды
+		return true, err
+	}
+
+	// результат попытки сохраняется и при остановке обработчиков
+	ctx = context.WithoutCancel(ctx)
+	if err == nil {
+		return true, r.queue.Complete(ctx, job.ID, worker, result)
+	}
+
+	r.logger.Errorw("Job attempt failed", "jobId", job.ID, "type", job.Type, "attempt", job.Attempts, "error", err)
+
+	var retryAt *time.Time
+	var permanent permanentError
+	if !errors.As(err, &permanent) && job.Attempts < job.MaxAttempts {
+		at := time.Now().Add(Backoff(job.Attempts, r.config.MinBackoff, r.config.MaxBackoff))
+		retryAt = &at
+	}
+
+	return true, r.queue.Fail(ctx, job.ID, worker, err.Error(), retryAt)
+}
+
+// Backoff задержка перед попыткой, следующей за попыткой attempt: min, 2*min, 4*min... но не больше max
+func Backoff(attempt int, min, max time.Duration) time.Duration {
+	backoff := min
+	for i := 1; i < attempt && backoff < max; i++ {
+		backoff *= 2
+	}
+	if backoff > max {
+		backoff = max
+	}
+
+	return backoff
+}
+
+func (r *Runner) work(ctx context.Context, worker string) {
+	for {
+		leased, err := r.RunOnce(ctx, worker)
+		if err != nil && ctx.Err() == nil {
+			r.logger.Errorw("Error running job", "worker", worker, "error", err)
+		}
+		if leased && err == nil {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(r.config.PollInterval):
+		}
+	}
+}
+
+// handle выполнение задачи с продлением аренды; при потере аренды контекст обработчика отменяется
+func (r *Runner) handle(ctx context.Context, worker string, job entity.Job, handler Handler) (result []byte, err error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	leaseLost := make(chan struct{})
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		ticker := time.NewTicker(r.config.Lease / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				err := r.queue.ExtendLease(ctx, job.ID, worker, r.config.Lease)
+				if errors.Is(err, ErrLeaseLost) {
+					close(leaseLost)
+					cancel()
+					return
+				}
+				if err != nil {
+					r.logger.Warnw("Error extending job lease", "jobId", job.ID, "error", err)
+				}
+			}
+		}
+	}()
+
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("job panicked: %v", p)
+		}
+	}()
+
+	result, err = handler(ctx, job)
+	select {
+	case <-leaseLost:
+		return nil, ErrLeaseLost
+	default:
+		return result, err
+	}
+}
+
+func (r *Runner) registered() ([]string, map[string]Handler) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	types := make([]string, 0, len(r.handlers))
+	handlers := make(map[string]Handler, len(r.handlers))
+	for jobType, handler := range r.handlers {
+		types = append(types, jobType)
+		handlers[jobType] = handler
+	}
+
+	return types, handlers
+}
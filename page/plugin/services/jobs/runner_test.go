@@ -0,0 +1,257 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/aeroideaservices/focus/page/plugin/entity"
+)
+
+// memoryQueue очередь в памяти для тестов
+type memoryQueue struct {
+	mu   sync.Mutex
+	jobs map[uuid.UUID]*entity.Job
+}
+
+func newMemoryQueue(jobs ...entity.Job) *memoryQueue {
+	q := &memoryQueue{jobs: make(map[uuid.UUID]*entity.Job)}
+	for i := range jobs {
+		q.jobs[jobs[i].ID] = &jobs[i]
+	}
+	return q
+}
+
+func (q *memoryQueue) Lease(_ context.Context, worker string, types []string, lease time.Duration) (*entity.Job, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	now := time.Now()
+	for _, job := range q.jobs {
+		if !contains(types, job.Type) || job.Status != entity.JobStatusQueued || job.RunAt.After(now) {
+			continue
+		}
+		until := now.Add(lease)
+		job.Status = entity.JobStatusRunning
+		job.Attempts++
+		job.LockedBy = worker
+		job.LockedUntil = &until
+		leased := *job
+		return &leased, nil
+	}
+
+	return nil, nil
+}
+
+func (q *memoryQueue) ExtendLease(_ context.Context, id uuid.UUID, worker string, lease time.Duration) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	job := q.jobs[id]
+	if job.LockedBy != worker {
+		return ErrLeaseLost
+	}
+	until := time.Now().Add(lease)
+	job.LockedUntil = &until
+	return nil
+}
+
+func (q *memoryQueue) Complete(_ context.Context, id uuid.UUID, _ string, result []byte) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.jobs[id].Status = entity.JobStatusSucceeded
+	q.jobs[id].Result = result
+	return nil
+}
+
+func (q *memoryQueue) Fail(_ context.Context, id uuid.UUID, _ string, message string, retryAt *time.Time) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	job := q.jobs[id]
+	job.LastError = message
+	job.Status = entity.JobStatusFailed
+	if retryAt != nil {
+		job.Status = entity.JobStatusQueued
+		job.RunAt = *retryAt
+	}
+	return nil
+}
+
+func (q *memoryQueue) get(id uuid.UUID) entity.Job {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return *q.jobs[id]
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func newJob(jobType string, maxAttempts int) entity.Job {
+	return entity.Job{ID: uuid.New(), Type: jobType, Status: entity.JobStatusQueued, MaxAttempts: maxAttempts}
+}
+
+func TestRunner_RunOnce(t *testing.T) {
+	errTemporary := errors.New("temporary")
+	tests := []struct {
+		name         string
+		maxAttempts  int
+		handler      Handler
+		runs         int
+		wantStatus   entity.JobStatus
+		wantAttempts int
+		wantError    string
+		wantResult   string
+	}{
+		{
+			name:         "succeeded",
+			maxAttempts:  3,
+			handler:      func(context.Context, entity.Job) ([]byte, error) { return []byte(`{"ok":true}`), nil },
+			runs:         1,
+			wantStatus:   entity.JobStatusSucceeded,
+			wantAttempts: 1,
+			wantResult:   `{"ok":true}`,
+		},
+		{
+			name:         "retried",
+			maxAttempts:  3,
+			handler:      func(context.Context, entity.Job) ([]byte, error) { return nil, errTemporary },
+			runs:         1,
+			wantStatus:   entity.JobStatusQueued,
+			wantAttempts: 1,
+			wantError:    "temporary",
+		},
+		{
+			name:         "dead letter after max attempts",
+			maxAttempts:  2,
+			handler:      func(context.Context, entity.Job) ([]byte, error) { return nil, errTemporary },
+			runs:         3,
+			wantStatus:   entity.JobStatusFailed,
+			wantAttempts: 2,
+			wantError:    "temporary",
+		},
+		{
+			name:         "permanent error",
+			maxAttempts:  3,
+			handler:      func(context.Context, entity.Job) ([]byte, error) { return nil, Permanent(errTemporary) },
+			runs:         1,
+			wantStatus:   entity.JobStatusFailed,
+			wantAttempts: 1,
+			wantError:    "temporary",
+		},
+		{
+			name:         "panic",
+			maxAttempts:  1,
+			handler:      func(context.Context, entity.Job) ([]byte, error) { panic("boom") },
+			runs:         1,
+			wantStatus:   entity.JobStatusFailed,
+			wantAttempts: 1,
+			wantError:    "job panicked: boom",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			job := newJob("test", tt.maxAttempts)
+			queue := newMemoryQueue(job)
+			runner := NewRunner(queue, zap.NewNop().Sugar(), Config{Workers: 1, Lease: time.Minute})
+			runner.Register("test", tt.handler)
+
+			for i := 0; i < tt.runs; i++ {
+				_, _ = runner.RunOnce(context.Background(), "worker")
+			}
+
+			got := queue.get(job.ID)
+			if got.Status != tt.wantStatus || got.Attempts != tt.wantAttempts ||
+				got.LastError != tt.wantError || string(got.Result) != tt.wantResult {
+				t.Errorf("RunOnce() job = %+v", got)
+			}
+		})
+	}
+}
+
+func TestRunner_RunOnce_unregistered(t *testing.T) {
+	job := newJob("other", 1)
+	queue := newMemoryQueue(job)
+	runner := NewRunner(queue, zap.NewNop().Sugar(), DefaultConfig)
+	runner.Register("test", func(context.Context, entity.Job) ([]byte, error) { return nil, nil })
+
+	leased, err := runner.RunOnce(context.Background(), "worker")
+	if leased || err != nil {
+		t.Errorf("RunOnce() = %v, %v", leased, err)
+	}
+	if got := queue.get(job.ID); got.Status != entity.JobStatusQueued {
+		t.Errorf("RunOnce() job status = %v", got.Status)
+	}
+}
+
+func TestRunner_RunOnce_leaseLost(t *testing.T) {
+	job := newJob("test", 3)
+	queue := newMemoryQueue(job)
+	runner := NewRunner(queue, zap.NewNop().Sugar(), Config{Workers: 1, Lease: 30 * time.Millisecond})
+	runner.Register("test", func(ctx context.Context, job entity.Job) ([]byte, error) {
+		queue.mu.Lock()
+		queue.jobs[job.ID].LockedBy = "other"
+		queue.mu.Unlock()
+
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+
+	_, err := runner.RunOnce(context.Background(), "worker")
+	if !errors.Is(err, ErrLeaseLost) {
+		t.Errorf("RunOnce() error = %v, want %v", err, ErrLeaseLost)
+	}
+	if got := queue.get(job.ID); got.Status != entity.JobStatusRunning {
+		t.Errorf("RunOnce() job status = %v", got.Status)
+	}
+}
+
+func TestRunner_Start(t *testing.T) {
+	job := newJob("test", 1)
+	queue := newMemoryQueue(job)
+	runner := NewRunner(queue, zap.NewNop().Sugar(), Config{Workers: 2, PollInterval: time.Millisecond, Lease: time.Minute})
+
+	done := make(chan struct{})
+	runner.Register("test", func(context.Context, entity.Job) ([]byte, error) {
+		close(done)
+		return nil, nil
+	})
+	runner.Start()
+	defer runner.Stop()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("job was not run")
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{attempt: 1, want: time.Second},
+		{attempt: 2, want: 2 * time.Second},
+		{attempt: 3, want: 4 * time.Second},
+		{attempt: 10, want: 10 * time.Second},
+	}
+	for _, tt := range tests {
+		if got := Backoff(tt.attempt, time.Second, 10*time.Second); got != tt.want {
+			t.Errorf("Backoff(%d) = %v, want %v", tt.attempt, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+// Package locales список языков содержимого страниц и порядок замены отсутствующих переводов.
+// Содержимое на языке по умолчанию хранится в полях самих страниц, галерей, карточек и тегов,
+// переводы на остальные языки хранятся отдельно и при отсутствии заменяются переводами по цепочке.
+package locales
+
+import (
+	"errors"
+	"fmt"
+)
+
+// DefaultLocale язык по умолчанию, если список языков не настроен
+const DefaultLocale = "ru"
+
+var ErrUnknownLocale = errors.New("unknown locale") // ErrUnknownLocale язык не входит в настроенный список
+
+// Config настройки языков
+type Config struct {
+	Default   string              // Default язык по умолчанию, на нем заполнены поля сущностей
+	Locales   []string            // Locales все языки содержимого, язык по умолчанию добавляется автоматически
+	Fallbacks map[string][]string // Fallbacks языки, переводы на которые подставляются вместо отсутствующих, по порядку
+}
+
+// Locales настроенные языки содержимого
+type Locales struct {
+	def       string
+	locales   []string
+	fallbacks map[string][]string
+}
+
+// New конструктор, проверяет, что языки замены входят в список языков
+func New(config Config) (*Locales, error) {
+	if config.Default == "" {
+		config.Default = DefaultLocale
+	}
+
+	l := &Locales{def: config.Default, locales: []string{config.Default}, fallbacks: make(map[string][]string)}
+	for _, locale := range config.Locales {
+		if !l.Has(locale) {
+			l.locales = append(l.locales, locale)
+		}
+	}
+	for locale, fallbacks := range config.Fallbacks {
+		if !l.Has(locale) {
+			return nil, fmt.Errorf("%w %s in fallbacks", ErrUnknownLocale, locale)
+		}
+		for _, fallback := range fallbacks {
+			if !l.Has(fallback) {
+				return nil, fmt.Errorf("%w %s in fallbacks of %s", ErrUnknownLocale, fallback, locale)
+			}
+		}
+		l.fallbacks[locale] = fallbacks
+	}
+
+	return l, nil
+}
+
+// Default язык по умолчанию
+func (l Locales) Default() string {
+	return l.def
+}
+
+// List все языки, первым идет язык по умолчанию
+func (l Locales) List() []string {
+	return append([]string(nil), l.locales...)
+}
+
+// Translated языки переводов, все кроме языка по умолчанию
+func (l Locales) Translated() []string {
+	return append([]string(nil), l.locales[1:]...)
+}
+
+// Fallbacks настроенные языки замены
+func (l Locales) Fallbacks() map[string][]string {
+	fallbacks := make(map[string][]string, len(l.fallbacks))
+	for locale, chain := range l.fallbacks {
+		fallbacks[locale] = append([]string(nil), chain...)
+	}
+
+	return fallbacks
+}
+
+// Has входит ли язык в список языков
+func (l Locales) Has(locale string) bool {
+	for _, known := range l.locales {
+		if known == locale {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Chain языки переводов, которые просматриваются для locale по порядку: сам язык, затем языки замены.
+// Цепочка заканчивается на языке по умолчанию, он в цепочку не входит, для него цепочка пустая.
+func (l Locales) Chain(locale string) ([]string, error) {
+	if !l.Has(locale) {
+		return nil, fmt.Errorf("%w %s", ErrUnknownLocale, locale)
+	}
+
+	var chain []string
+	seen := make(map[string]bool)
+	for _, candidate := range append([]string{locale}, l.fallbacks[locale]...) {
+		if candidate == l.def {
+			break
+		}
+		if !seen[candidate] {
+			seen[candidate] = true
+			chain = append(chain, candidate)
+		}
+	}
+
+	return chain, nil
+}
@@ -0,0 +1,48 @@
+package locales
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestLocales_Chain(t *testing.T) {
+	l, err := New(Config{
+		Default:   "ru",
+		Locales:   []string{"en", "kk", "ru", "en-GB"},
+		Fallbacks: map[string][]string{"en-GB": {"en", "ru", "kk"}, "kk": {"kk", "ru"}},
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if got := l.List(); !reflect.DeepEqual(got, []string{"ru", "en", "kk", "en-GB"}) {
+		t.Errorf("List() = %v", got)
+	}
+
+	tests := []struct {
+		locale  string
+		want    []string
+		wantErr error
+	}{
+		{locale: "ru"},
+		{locale: "en", want: []string{"en"}},
+		{locale: "en-GB", want: []string{"en-GB", "en"}},
+		{locale: "kk", want: []string{"kk"}},
+		{locale: "de", wantErr: ErrUnknownLocale},
+	}
+	for _, tt := range tests {
+		t.Run(tt.locale, func(t *testing.T) {
+			got, err := l.Chain(tt.locale)
+			if !errors.Is(err, tt.wantErr) || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Chain() = %v, %v, want %v, %v", got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+
+	if _, err = New(Config{Locales: []string{"en"}, Fallbacks: map[string][]string{"en": {"de"}}}); !errors.Is(err, ErrUnknownLocale) {
+		t.Errorf("New() with unknown fallback error = %v", err)
+	}
+	if l, _ = New(Config{}); l.Default() != DefaultLocale || len(l.Translated()) != 0 {
+		t.Errorf("New() without config = %v, %v", l.Default(), l.Translated())
+	}
+}
@@ -0,0 +1,79 @@
+// Package preview подписанные ссылки предпросмотра неопубликованных страниц.
+// Токен содержит идентификатор записи о токене и время окончания действия, подписанные HMAC-SHA256,
+// поэтому подделанный или просроченный токен отклоняется без обращения к базе данных.
+package preview
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidToken = errors.New("invalid preview token") // ErrInvalidToken токен поврежден или подпись не совпадает
+	ErrExpiredToken = errors.New("preview token expired") // ErrExpiredToken срок действия токена истек
+)
+
+const payloadSize = 16 + 8 // payloadSize идентификатор и время окончания действия в секундах
+
+var encoding = base64.RawURLEncoding
+
+// Signer подпись и проверка токенов предпросмотра
+type Signer struct {
+	secret []byte
+}
+
+// NewSigner конструктор
+func NewSigner(secret []byte) *Signer {
+	return &Signer{secret: secret}
+}
+
+// Sign создание токена для записи id, действующего до expiresAt
+func (s Signer) Sign(id uuid.UUID, expiresAt time.Time) string {
+	payload := make([]byte, payloadSize)
+	copy(payload, id[:])
+	binary.BigEndian.PutUint64(payload[16:], uint64(expiresAt.Unix()))
+
+	return encoding.EncodeToString(payload) + "." + encoding.EncodeToString(s.sign(payload))
+}
+
+// Verify проверка подписи и срока действия токена, возвращает идентификатор записи о токене
+func (s Signer) Verify(token string, now time.Time) (uuid.UUID, error) {
+	encodedPayload, encodedSignature, ok := strings.Cut(token, ".")
+	if !ok {
+		return uuid.Nil, ErrInvalidToken
+	}
+
+	payload, err := encoding.DecodeString(encodedPayload)
+	if err != nil || len(payload) != payloadSize {
+		return uuid.Nil, ErrInvalidToken
+	}
+	signature, err := encoding.DecodeString(encodedSignature)
+	if err != nil || !hmac.Equal(signature, s.sign(payload)) {
+		return uuid.Nil, ErrInvalidToken
+	}
+
+	expiresAt := time.Unix(int64(binary.BigEndian.Uint64(payload[16:])), 0)
+	if !now.Before(expiresAt) {
+		return uuid.Nil, ErrExpiredToken
+	}
+
+	id, err := uuid.FromBytes(payload[:16])
+	if err != nil {
+		return uuid.Nil, ErrInvalidToken
+	}
+
+	return id, nil
+}
+
+func (s Signer) sign(payload []byte) []byte {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
@@ -0,0 +1,44 @@
+package preview
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestSigner_Verify(t *testing.T) {
+	signer := NewSigner([]byte("secret"))
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	id := uuid.New()
+	token := signer.Sign(id, now.Add(time.Hour))
+	tampered := "A" + token[1:]
+	if token[0] == 'A' {
+		tampered = "B" + token[1:]
+	}
+
+	tests := []struct {
+		name    string
+		signer  *Signer
+		token   string
+		now     time.Time
+		wantId  uuid.UUID
+		wantErr error
+	}{
+		{name: "valid", signer: signer, token: token, now: now, wantId: id},
+		{name: "expired", signer: signer, token: token, now: now.Add(time.Hour), wantErr: ErrExpiredToken},
+		{name: "other secret", signer: NewSigner([]byte("other")), token: token, now: now, wantErr: ErrInvalidToken},
+		{name: "tampered", signer: signer, token: tampered, now: now, wantErr: ErrInvalidToken},
+		{name: "no signature", signer: signer, token: token[:32], now: now, wantErr: ErrInvalidToken},
+		{name: "empty", signer: signer, token: "", now: now, wantErr: ErrInvalidToken},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.signer.Verify(tt.token, tt.now)
+			if !errors.Is(err, tt.wantErr) || got != tt.wantId {
+				t.Errorf("Verify() = %v, %v, want %v, %v", got, err, tt.wantId, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,181 @@
+package staticsite
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// ManifestFile имя файла манифеста в каталоге выгрузки
+const ManifestFile = ".focus-static.json"
+
+var ErrInvalidName = errors.New("invalid file name") // ErrInvalidName имя файла выходит за пределы выгрузки
+
+// Manifest состояние выгрузки: хеши данных страниц и файлы скопированных медиа
+type Manifest struct {
+	Templates string            `json:"templates"` // Templates хеш шаблонов, которыми отрисованы страницы
+	Pages     map[string]string `json:"pages"`     // Pages хеши данных страниц по полному пути
+	Media     map[string]string `json:"media"`     // Media ссылки на источники скопированных медиа по идентификатору
+}
+
+// NewManifest пустой манифест
+func NewManifest() *Manifest {
+	return &Manifest{Pages: make(map[string]string), Media: make(map[string]string)}
+}
+
+// Output место записи выгрузки
+type Output interface {
+	Write(name string, data []byte) error
+	Remove(name string) error
+	Manifest() (*Manifest, error) // Manifest манифест предыдущей выгрузки, пустой, если ее нет
+	SaveManifest(manifest *Manifest) error
+	Close() error
+}
+
+// DirOutput выгрузка в каталог.
+// Файлы записываются через временный файл и переименование, чтобы сайт не отдавал недописанные страницы.
+type DirOutput struct {
+	dir string
+}
+
+func NewDirOutput(dir string) (*DirOutput, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	return &DirOutput{dir: dir}, nil
+}
+
+func (o DirOutput) Write(name string, data []byte) error {
+	file, err := o.path(name)
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(file), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(tmp.Name(), 0o644)
+	}
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), file)
+}
+
+// Remove удаление файла и опустевших каталогов над ним
+func (o DirOutput) Remove(name string) error {
+	file, err := o.path(name)
+	if err != nil {
+		return err
+	}
+	if err = os.Remove(file); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	root := filepath.Clean(o.dir)
+	for dir := filepath.Dir(file); dir != root && strings.HasPrefix(dir, root); dir = filepath.Dir(dir) {
+		if os.Remove(dir) != nil {
+			break
+		}
+	}
+
+	return nil
+}
+
+func (o DirOutput) Manifest() (*Manifest, error) {
+	data, err := os.ReadFile(filepath.Join(o.dir, ManifestFile))
+	if errors.Is(err, fs.ErrNotExist) {
+		return NewManifest(), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	manifest := NewManifest()
+	if err = json.Unmarshal(data, manifest); err != nil {
+		return nil, err
+	}
+
+	return manifest, nil
+}
+
+func (o DirOutput) SaveManifest(manifest *Manifest) error {
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return o.Write(ManifestFile, data)
+}
+
+func (o DirOutput) Close() error {
+	return nil
+}
+
+func (o DirOutput) path(name string) (string, error) {
+	name = path.Clean("/" + name)
+	if name == "/" {
+		return "", ErrInvalidName
+	}
+
+	return filepath.Join(o.dir, filepath.FromSlash(name)), nil
+}
+
+// ZipOutput выгрузка в zip-архив, архив всегда содержит выгрузку целиком
+type ZipOutput struct {
+	archive *zip.Writer
+}
+
+func NewZipOutput(w io.Writer) *ZipOutput {
+	return &ZipOutput{archive: zip.NewWriter(w)}
+}
+
+func (o ZipOutput) Write(name string, data []byte) error {
+	name = strings.TrimPrefix(path.Clean("/"+name), "/")
+	if name == "" {
+		return ErrInvalidName
+	}
+
+	writer, err := o.archive.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = writer.Write(data)
+
+	return err
+}
+
+func (o ZipOutput) Remove(string) error {
+	return nil
+}
+
+func (o ZipOutput) Manifest() (*Manifest, error) {
+	return NewManifest(), nil
+}
+
+func (o ZipOutput) SaveManifest(*Manifest) error {
+	return nil
+}
+
+func (o ZipOutput) Close() error {
+	return o.archive.Close()
+}
@@ -0,0 +1,123 @@
+// Package staticsite отрисовка страниц в статические html-файлы.
+// Страница отрисовывается шаблоном страницы, карточки - шаблонами, зарегистрированными по типу карточки.
+// Результат записывается в каталог или zip-архив, манифест с хешами страниц позволяет
+// при повторной выгрузке в каталог перезаписывать только изменившиеся страницы.
+package staticsite
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"html/template"
+	"io"
+	"path"
+	"sort"
+	"strings"
+)
+
+// Templates исходные тексты шаблонов html/template.
+// В шаблонах доступны функции card (отрисовка карточки шаблоном ее типа), pageUrl (адрес страницы по пути)
+// и safeHTML (вывод html без экранирования, только для уже очищенного содержимого).
+type Templates struct {
+	Layout string            // Layout шаблон страницы
+	Cards  map[string]string // Cards шаблоны карточек по типу карточки
+	Funcs  template.FuncMap  // Funcs дополнительные функции шаблонов
+}
+
+// Merge шаблоны t, дополненные шаблонами defaults там, где в t они не заданы
+func (t Templates) Merge(defaults Templates) Templates {
+	merged := Templates{Layout: t.Layout, Cards: make(map[string]string), Funcs: template.FuncMap{}}
+	if merged.Layout == "" {
+		merged.Layout = defaults.Layout
+	}
+	for _, templates := range []Templates{defaults, t} {
+		for cardType, source := range templates.Cards {
+			merged.Cards[cardType] = source
+		}
+		for name, fn := range templates.Funcs {
+			merged.Funcs[name] = fn
+		}
+	}
+
+	return merged
+}
+
+// Renderer отрисовка страниц
+type Renderer struct {
+	layout      *template.Template
+	cards       map[string]*template.Template
+	basePath    string
+	fingerprint string
+}
+
+// NewRenderer разбор шаблонов, basePath - путь на сайте, от которого строятся адреса страниц
+func NewRenderer(templates Templates, basePath string) (*Renderer, error) {
+	r := &Renderer{cards: make(map[string]*template.Template, len(templates.Cards)), basePath: basePath}
+	funcs := template.FuncMap{
+		"card":     r.card,
+		"pageUrl":  r.PageUrl,
+		"safeHTML": func(s string) template.HTML { return template.HTML(s) },
+	}
+	for name, fn := range templates.Funcs {
+		funcs[name] = fn
+	}
+
+	var err error
+	r.layout, err = template.New("layout").Funcs(funcs).Parse(templates.Layout)
+	if err != nil {
+		return nil, err
+	}
+
+	hash := sha256.New()
+	hash.Write([]byte(templates.Layout))
+	cardTypes := make([]string, 0, len(templates.Cards))
+	for cardType := range templates.Cards {
+		cardTypes = append(cardTypes, cardType)
+	}
+	sort.Strings(cardTypes)
+	for _, cardType := range cardTypes {
+		r.cards[cardType], err = template.New(cardType).Funcs(funcs).Parse(templates.Cards[cardType])
+		if err != nil {
+			return nil, err
+		}
+		hash.Write([]byte("\x00" + cardType + "\x00" + templates.Cards[cardType]))
+	}
+	r.fingerprint = hex.EncodeToString(hash.Sum(nil))
+
+	return r, nil
+}
+
+// Fingerprint хеш исходных текстов шаблонов, при изменении шаблонов все страницы отрисовываются заново
+func (r Renderer) Fingerprint() string {
+	return r.fingerprint
+}
+
+// Render отрисовка страницы page шаблоном страницы
+func (r Renderer) Render(w io.Writer, page any) error {
+	return r.layout.Execute(w, page)
+}
+
+// PageUrl адрес страницы с полным путем pagePath
+func (r Renderer) PageUrl(pagePath string) string {
+	return path.Join("/", r.basePath, pagePath) + "/"
+}
+
+// PageFile имя файла страницы с полным путем pagePath в выгрузке
+func PageFile(pagePath string) string {
+	return path.Join(strings.Trim(pagePath, "/"), "index.html")
+}
+
+// card отрисовка карточки шаблоном ее типа, карточка типа без шаблона пропускается
+func (r Renderer) card(cardType string, card any) (template.HTML, error) {
+	tmpl, ok := r.cards[cardType]
+	if !ok {
+		return "", nil
+	}
+
+	var b bytes.Buffer
+	if err := tmpl.Execute(&b, card); err != nil {
+		return "", err
+	}
+
+	return template.HTML(b.String()), nil
+}
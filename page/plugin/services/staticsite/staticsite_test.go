@@ -0,0 +1,122 @@
+package staticsite
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type testCard struct {
+	Type  string
+	Title string
+}
+
+type testPage struct {
+	Path  string
+	Title string
+	Cards []testCard
+}
+
+func TestRenderer_Render(t *testing.T) {
+	templates := Templates{
+		Layout: `<h1>{{.Title}}</h1>{{range .Cards}}{{card .Type .}}{{end}}<a href="{{pageUrl .Path}}">self</a>`,
+		Cards:  map[string]string{"text": `<p>{{.Title}}</p>`},
+	}.Merge(Templates{
+		Layout: `default`,
+		Cards:  map[string]string{"text": `default`, "quote": `<q>{{.Title}}</q>`},
+	})
+
+	renderer, err := NewRenderer(templates, "site")
+	if err != nil {
+		t.Fatalf("NewRenderer() error = %v", err)
+	}
+
+	var b bytes.Buffer
+	err = renderer.Render(&b, testPage{
+		Path:  "about/team",
+		Title: "Team & co",
+		Cards: []testCard{
+			{Type: "text", Title: "<b>hi</b>"}, {Type: "quote", Title: "q"}, {Type: "unknown", Title: "x"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+
+	want := `<h1>Team &amp; co</h1><p>&lt;b&gt;hi&lt;/b&gt;</p><q>q</q><a href="/site/about/team/">self</a>`
+	if got := b.String(); got != want {
+		t.Errorf("Render() = %s, want %s", got, want)
+	}
+
+	other, _ := NewRenderer(Templates{Layout: templates.Layout, Cards: map[string]string{"text": `<p></p>`}}, "site")
+	if renderer.Fingerprint() == other.Fingerprint() {
+		t.Errorf("Fingerprint() must change with templates")
+	}
+}
+
+func TestDirOutput(t *testing.T) {
+	dir := t.TempDir()
+	output, err := NewDirOutput(dir)
+	if err != nil {
+		t.Fatalf("NewDirOutput() error = %v", err)
+	}
+
+	if err = output.Write(PageFile("about/team"), []byte("team")); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "about", "team", "index.html"))
+	if err != nil || string(data) != "team" {
+		t.Fatalf("written file = %q, %v", data, err)
+	}
+
+	if err = output.Write("../escape.html", []byte("x")); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if _, err = os.Stat(filepath.Join(dir, "escape.html")); err != nil {
+		t.Errorf("file outside of output must be written inside it: %v", err)
+	}
+
+	manifest, err := output.Manifest()
+	if err != nil || len(manifest.Pages) != 0 {
+		t.Fatalf("Manifest() without previous export = %+v, %v", manifest, err)
+	}
+	manifest.Pages["about/team"] = "hash"
+	if err = output.SaveManifest(manifest); err != nil {
+		t.Fatalf("SaveManifest() error = %v", err)
+	}
+	if manifest, err = output.Manifest(); err != nil || manifest.Pages["about/team"] != "hash" {
+		t.Fatalf("Manifest() = %+v, %v", manifest, err)
+	}
+
+	if err = output.Remove(PageFile("about/team")); err != nil {
+		t.Fatalf("Remove() error = %v", err)
+	}
+	if _, err = os.Stat(filepath.Join(dir, "about")); !os.IsNotExist(err) {
+		t.Errorf("empty directories must be removed, stat error = %v", err)
+	}
+}
+
+func TestZipOutput(t *testing.T) {
+	var b bytes.Buffer
+	output := NewZipOutput(&b)
+	if err := output.Write(PageFile("/about/"), []byte("about")); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if err := output.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	archive, err := zip.NewReader(bytes.NewReader(b.Bytes()), int64(b.Len()))
+	if err != nil || len(archive.File) != 1 || archive.File[0].Name != "about/index.html" {
+		t.Fatalf("archive = %+v, %v", archive, err)
+	}
+	reader, _ := archive.File[0].Open()
+	data, _ := io.ReadAll(reader)
+	if !strings.EqualFold(string(data), "about") {
+		t.Errorf("archived file = %q", data)
+	}
+}
@@ -0,0 +1,68 @@
+package transcriber
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Fake детерминированный распознаватель для тестов и локальной разработки, не обращается к внешним сервисам.
+// Возвращает заданный текст, разбитый на слова равной длительности.
+type Fake struct {
+	Text         string        // Text возвращаемый текст
+	WordDuration time.Duration // WordDuration длительность каждого слова
+	Err          error         // Err ошибка, возвращаемая вместо результата
+
+	mu    sync.Mutex
+	calls []FakeCall
+}
+
+// FakeCall параметры вызова Fake.Transcribe
+type FakeCall struct {
+	Audio   Audio
+	Options Options
+}
+
+// NewFake конструктор
+func NewFake(text string, wordDuration time.Duration) *Fake {
+	return &Fake{Text: text, WordDuration: wordDuration}
+}
+
+// Transcribe возвращает заданный текст; при отмене контекста возвращается ошибка контекста
+func (f *Fake) Transcribe(ctx context.Context, audio Audio, options Options) (*Transcript, error) {
+	f.mu.Lock()
+	f.calls = append(f.calls, FakeCall{Audio: audio, Options: options})
+	f.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if f.Err != nil {
+		return nil, f.Err
+	}
+
+	texts := strings.Fields(f.Text)
+	if len(texts) == 0 {
+		return nil, ErrEmptyTranscript
+	}
+
+	words := make([]Word, len(texts))
+	for i, text := range texts {
+		words[i] = Word{
+			Start: time.Duration(i) * f.WordDuration,
+			End:   time.Duration(i+1) * f.WordDuration,
+			Text:  text,
+		}
+	}
+
+	return &Transcript{Text: strings.Join(texts, " "), Words: words}, nil
+}
+
+// Calls получение параметров всех вызовов Transcribe
+func (f *Fake) Calls() []FakeCall {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return append([]FakeCall(nil), f.calls...)
+}
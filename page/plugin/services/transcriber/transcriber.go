@@ -0,0 +1,109 @@
+package transcriber
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aeroideaservices/focus/services/errors"
+)
+
+const maxErrorBodyLen = 512
+
+var (
+	ErrEmptyTranscript = errors.BadRequest.New("no words in transcript").T("video.subtitles.empty")
+	ErrNoAudio         = errors.BadRequest.New("audio url or file is required").T("video.subtitles.no-audio")
+)
+
+// Transcriber сервис распознавания речи
+type Transcriber interface {
+	// Transcribe распознавание речи в аудиофайле. Ожидание результата прерывается при отмене контекста.
+	Transcribe(ctx context.Context, audio Audio, options Options) (*Transcript, error)
+}
+
+// Audio аудиофайл для распознавания. Провайдеры используют либо ссылку на файл, либо его содержимое.
+type Audio struct {
+	Url      string        // Url публичная ссылка на аудиофайл
+	Filename string        // Filename название файла
+	Encoding string        // Encoding формат аудио (MP3, OGG_OPUS, LINEAR16_PCM)
+	File     io.ReadSeeker // File содержимое аудиофайла
+}
+
+// Options настройки распознавания
+type Options struct {
+	Language        string // Language язык речи в формате ru-RU
+	ProfanityFilter bool   // ProfanityFilter маскирование ненормативной лексики
+	LiteratureText  bool   // LiteratureText нормализация текста (числа, знаки препинания)
+}
+
+// DefaultOptions настройки распознавания по умолчанию
+var DefaultOptions = Options{
+	Language:       "ru-RU",
+	LiteratureText: true,
+}
+
+// Transcript результат распознавания
+type Transcript struct {
+	Text  string // Text полный текст
+	Words []Word // Words распознанные слова с временными метками
+}
+
+// Word распознанное слово
+type Word struct {
+	Start time.Duration
+	End   time.Duration
+	Text  string
+}
+
+// FormatTime форматирование временной метки в секундах ("1.5s")
+func FormatTime(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'f', -1, 64) + "s"
+}
+
+// languageCode получение кода языка ISO-639-1 из кода вида ru-RU
+func languageCode(language string) string {
+	code, _, _ := strings.Cut(language, "-")
+	return strings.ToLower(code)
+}
+
+// parseSeconds разбор временной метки вида "1.5s" или "1.5"
+func parseSeconds(value string) (time.Duration, error) {
+	seconds, err := strconv.ParseFloat(strings.TrimSuffix(value, "s"), 64)
+	if err != nil {
+		return 0, errors.NoType.Wrapf(err, "error parsing time %q", value)
+	}
+
+	return time.Duration(seconds * float64(time.Second)), nil
+}
+
+// joinWords сборка полного текста из слов
+func joinWords(words []Word) string {
+	texts := make([]string, len(words))
+	for i, word := range words {
+		texts[i] = word.Text
+	}
+
+	return strings.Join(texts, " ")
+}
+
+// doJSON выполнение запроса и разбор JSON-ответа, ответы с кодом не 2xx возвращаются как ошибка
+func doJSON(client *http.Client, req *http.Request, result any) error {
+	res, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < http.StatusOK || res.StatusCode >= http.StatusMultipleChoices {
+		body, _ := io.ReadAll(io.LimitReader(res.Body, maxErrorBodyLen))
+		return fmt.Errorf("unexpected status %d: %s", res.StatusCode, bytes.TrimSpace(body))
+	}
+
+	return json.NewDecoder(res.Body).Decode(result)
+}
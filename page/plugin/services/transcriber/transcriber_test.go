@@ -0,0 +1,256 @@
+package transcriber
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestYandex_Transcribe(t *testing.T) {
+	var polls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Api-Key key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/recognize":
+			request := yandexRecognitionRequest{}
+			_ = json.NewDecoder(r.Body).Decode(&request)
+			spec := request.Config.Specification
+			if request.Audio.Uri != "https://cdn/audio.mp3" || spec.LanguageCode != "en-US" || !spec.ProfanityFilter {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			_, _ = fmt.Fprint(w, `{"id":"op1","done":false}`)
+		case r.Method == http.MethodGet && r.URL.Path == "/operations/op1":
+			if atomic.AddInt32(&polls, 1) < 2 {
+				_, _ = fmt.Fprint(w, `{"id":"op1","done":false}`)
+				return
+			}
+			_, _ = fmt.Fprint(w, `{"id":"op1","done":true,"response":{"chunks":[
+				{"channelTag":"1","alternatives":[{"text":"Hello world","words":[
+					{"startTime":"0.5s","endTime":"1s","word":"Hello"},
+					{"startTime":"1.2s","endTime":"1.75s","word":"world"}]}]},
+				{"channelTag":"2","alternatives":[{"text":"echo","words":[
+					{"startTime":"0s","endTime":"1s","word":"echo"}]}]}]}}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	yandex := NewYandex(YandexConfig{
+		ApiKey:       "key",
+		RecognizeUrl: server.URL + "/recognize",
+		OperationUrl: server.URL + "/operations/",
+		PollInterval: time.Millisecond,
+	})
+	got, err := yandex.Transcribe(
+		context.Background(),
+		Audio{Url: "https://cdn/audio.mp3"},
+		Options{Language: "en-US", ProfanityFilter: true},
+	)
+	if err != nil {
+		t.Fatalf("Transcribe() error = %v", err)
+	}
+
+	want := &Transcript{
+		Text: "Hello world",
+		Words: []Word{
+			{Start: 500 * time.Millisecond, End: time.Second, Text: "Hello"},
+			{Start: 1200 * time.Millisecond, End: 1750 * time.Millisecond, Text: "world"},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Transcribe() got = %v, want %v", got, want)
+	}
+}
+
+func TestYandex_Transcribe_errors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/recognize":
+			_, _ = fmt.Fprint(w, `{"id":"op1","done":false}`)
+		case "/operations/op1":
+			_, _ = fmt.Fprint(w, `{"id":"op1","done":true,"error":{"code":3,"message":"bad audio"}}`)
+		case "/forbidden":
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = fmt.Fprint(w, `{"message":"invalid api key"}`)
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name         string
+		recognizeUrl string
+		pollInterval time.Duration
+		timeout      time.Duration
+		audio        Audio
+		wantErr      string
+	}{
+		{
+			name:    "no url",
+			wantErr: "audio url or file is required",
+		},
+		{
+			name:         "unexpected status",
+			recognizeUrl: server.URL + "/forbidden",
+			audio:        Audio{Url: "https://cdn/audio.mp3"},
+			wantErr:      "unexpected status 403",
+		},
+		{
+			name:         "operation error",
+			recognizeUrl: server.URL + "/recognize",
+			pollInterval: time.Millisecond,
+			audio:        Audio{Url: "https://cdn/audio.mp3"},
+			wantErr:      "recognition failed: 3 bad audio",
+		},
+		{
+			name:         "cancelled",
+			recognizeUrl: server.URL + "/recognize",
+			pollInterval: time.Hour,
+			timeout:      10 * time.Millisecond,
+			audio:        Audio{Url: "https://cdn/audio.mp3"},
+			wantErr:      "recognition cancelled",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.timeout != 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, tt.timeout)
+				defer cancel()
+			}
+			yandex := NewYandex(YandexConfig{
+				RecognizeUrl: tt.recognizeUrl,
+				OperationUrl: server.URL + "/operations/",
+				PollInterval: tt.pollInterval,
+			})
+			_, err := yandex.Transcribe(ctx, tt.audio, DefaultOptions)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Transcribe() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestWhisper_Transcribe(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+		want     *Transcript
+		wantErr  bool
+	}{
+		{
+			name:     "words",
+			response: `{"text":" Привет мир","words":[{"word":" Привет","start":0,"end":0.5},{"word":" мир","start":0.5,"end":1.25}]}`,
+			want: &Transcript{
+				Text: "Привет мир",
+				Words: []Word{
+					{Start: 0, End: 500 * time.Millisecond, Text: "Привет"},
+					{Start: 500 * time.Millisecond, End: 1250 * time.Millisecond, Text: "мир"},
+				},
+			},
+		},
+		{
+			name:     "segments",
+			response: `{"text":"","segments":[{"text":" Привет мир","start":1,"end":2}]}`,
+			want: &Transcript{
+				Text:  "Привет мир",
+				Words: []Word{{Start: time.Second, End: 2 * time.Second, Text: "Привет мир"}},
+			},
+		},
+		{
+			name:     "empty",
+			response: `{"text":""}`,
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != whisperTranscriptionsPath || r.Header.Get("Authorization") != "Bearer secret" {
+					w.WriteHeader(http.StatusNotFound)
+					return
+				}
+				file, header, err := r.FormFile("file")
+				if err != nil || header.Filename != "audio.mp3" || r.FormValue("language") != "ru" {
+					w.WriteHeader(http.StatusBadRequest)
+					return
+				}
+				_ = file.Close()
+				_, _ = fmt.Fprint(w, tt.response)
+			}))
+			defer server.Close()
+
+			whisper := NewWhisper(WhisperConfig{Url: server.URL + "/", ApiKey: "secret"})
+			got, err := whisper.Transcribe(
+				context.Background(),
+				Audio{Filename: "audio.mp3", File: strings.NewReader("audio")},
+				DefaultOptions,
+			)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Transcribe() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Transcribe() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFake_Transcribe(t *testing.T) {
+	fake := NewFake(" one  two three ", time.Second)
+	got, err := fake.Transcribe(context.Background(), Audio{Url: "url"}, DefaultOptions)
+	if err != nil {
+		t.Fatalf("Transcribe() error = %v", err)
+	}
+
+	want := &Transcript{
+		Text: "one two three",
+		Words: []Word{
+			{Start: 0, End: time.Second, Text: "one"},
+			{Start: time.Second, End: 2 * time.Second, Text: "two"},
+			{Start: 2 * time.Second, End: 3 * time.Second, Text: "three"},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Transcribe() got = %v, want %v", got, want)
+	}
+	if calls := fake.Calls(); len(calls) != 1 || calls[0].Audio.Url != "url" {
+		t.Errorf("Calls() = %v", calls)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err = fake.Transcribe(ctx, Audio{}, DefaultOptions); err != context.Canceled {
+		t.Errorf("Transcribe() error = %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestFormatTime(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{d: 0, want: "0s"},
+		{d: 1500 * time.Millisecond, want: "1.5s"},
+		{d: 90 * time.Second, want: "90s"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if got := FormatTime(tt.d); got != tt.want {
+				t.Errorf("FormatTime() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
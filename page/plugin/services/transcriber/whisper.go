@@ -0,0 +1,178 @@
+package transcriber
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/aeroideaservices/focus/services/errors"
+)
+
+const (
+	whisperTranscriptionsPath = "/v1/audio/transcriptions"
+	whisperDefaultModel       = "whisper-1"
+	whisperDefaultFilename    = "audio.mp3"
+)
+
+// WhisperConfig настройки self-hosted сервера распознавания с Whisper-совместимым API
+// (POST /v1/audio/transcriptions, например faster-whisper-server или whisper.cpp server)
+type WhisperConfig struct {
+	Url        string // Url адрес сервера
+	Model      string // Model название модели
+	ApiKey     string // ApiKey ключ доступа, если сервер его требует
+	HttpClient *http.Client
+}
+
+// Whisper распознавание речи через Whisper-совместимый сервер (синхронная загрузка файла).
+// Фильтрация ненормативной лексики сервером не поддерживается, опция ProfanityFilter игнорируется.
+type Whisper struct {
+	config WhisperConfig
+}
+
+// NewWhisper конструктор
+func NewWhisper(config WhisperConfig) *Whisper {
+	config.Url = strings.TrimSuffix(config.Url, "/")
+	if config.Model == "" {
+		config.Model = whisperDefaultModel
+	}
+	if config.HttpClient == nil {
+		config.HttpClient = http.DefaultClient
+	}
+
+	return &Whisper{config: config}
+}
+
+type whisperWord struct {
+	Word  string  `json:"word"`
+	Start float64 `json:"start"`
+	End   float64 `json:"end"`
+}
+
+type whisperResponse struct {
+	Text     string        `json:"text"`
+	Words    []whisperWord `json:"words"`
+	Segments []struct {
+		Text  string        `json:"text"`
+		Start float64       `json:"start"`
+		End   float64       `json:"end"`
+		Words []whisperWord `json:"words"`
+	} `json:"segments"`
+}
+
+// Transcribe загрузка файла на сервер и получение результата
+func (w Whisper) Transcribe(ctx context.Context, audio Audio, options Options) (*Transcript, error) {
+	if audio.File == nil {
+		return nil, ErrNoAudio
+	}
+	_, err := audio.File.Seek(0, io.SeekStart)
+	if err != nil {
+		return nil, errors.NoType.Wrap(err, "error seeking audio file")
+	}
+
+	body, contentType, err := w.form(audio, options)
+	if err != nil {
+		return nil, errors.NoType.Wrap(err, "error creating transcription request")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.config.Url+whisperTranscriptionsPath, body)
+	if err != nil {
+		return nil, errors.NoType.Wrap(err, "error creating transcription request")
+	}
+	req.Header.Set("Content-Type", contentType)
+	if w.config.ApiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+w.config.ApiKey)
+	}
+
+	res := &whisperResponse{}
+	err = doJSON(w.config.HttpClient, req, res)
+	if err != nil {
+		return nil, errors.NoType.Wrap(err, "error transcribing audio")
+	}
+
+	return w.transcript(res)
+}
+
+// form формирование multipart-запроса с файлом и параметрами распознавания
+func (w Whisper) form(audio Audio, options Options) (io.Reader, string, error) {
+	filename := audio.Filename
+	if filename == "" {
+		filename = whisperDefaultFilename
+	}
+
+	buf := &bytes.Buffer{}
+	writer := multipart.NewWriter(buf)
+	part, err := writer.CreateFormFile("file", filepath.Base(filename))
+	if err != nil {
+		return nil, "", err
+	}
+	_, err = io.Copy(part, audio.File)
+	if err != nil {
+		return nil, "", err
+	}
+
+	fields := [][2]string{
+		{"model", w.config.Model},
+		{"response_format", "verbose_json"},
+		{"timestamp_granularities[]", "word"},
+		{"timestamp_granularities[]", "segment"},
+	}
+	if options.Language != "" {
+		fields = append(fields, [2]string{"language", languageCode(options.Language)})
+	}
+	for _, field := range fields {
+		err = writer.WriteField(field[0], field[1])
+		if err != nil {
+			return nil, "", err
+		}
+	}
+
+	err = writer.Close()
+	if err != nil {
+		return nil, "", err
+	}
+
+	return buf, writer.FormDataContentType(), nil
+}
+
+// transcript сборка результата, если сервер не вернул слова верхнего уровня, используются слова сегментов,
+// а при их отсутствии - сегменты целиком
+func (w Whisper) transcript(res *whisperResponse) (*Transcript, error) {
+	rawWords := res.Words
+	if len(rawWords) == 0 {
+		for _, segment := range res.Segments {
+			if len(segment.Words) != 0 {
+				rawWords = append(rawWords, segment.Words...)
+				continue
+			}
+			rawWords = append(rawWords, whisperWord{Word: segment.Text, Start: segment.Start, End: segment.End})
+		}
+	}
+
+	var words []Word
+	for _, word := range rawWords {
+		text := strings.TrimSpace(word.Word)
+		if text == "" {
+			continue
+		}
+		words = append(words, Word{
+			Start: time.Duration(word.Start * float64(time.Second)),
+			End:   time.Duration(word.End * float64(time.Second)),
+			Text:  text,
+		})
+	}
+	if len(words) == 0 {
+		return nil, ErrEmptyTranscript
+	}
+
+	text := strings.TrimSpace(res.Text)
+	if text == "" {
+		text = joinWords(words)
+	}
+
+	return &Transcript{Text: text, Words: words}, nil
+}
@@ -0,0 +1,195 @@
+package transcriber
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/aeroideaservices/focus/services/errors"
+)
+
+const (
+	yandexRecognizeUrl   = "https://transcribe.api.cloud.yandex.net/speech/stt/v2/longRunningRecognize"
+	yandexOperationUrl   = "https://operation.api.cloud.yandex.net/operations/"
+	yandexPollInterval   = 30 * time.Second
+	yandexAudioEncoding  = "MP3"
+	yandexMainChannelTag = "1"
+)
+
+// YandexConfig настройки Yandex SpeechKit
+type YandexConfig struct {
+	ApiKey       string        // ApiKey API-ключ сервисного аккаунта
+	RecognizeUrl string        // RecognizeUrl адрес запуска асинхронного распознавания
+	OperationUrl string        // OperationUrl адрес получения статуса операции
+	PollInterval time.Duration // PollInterval интервал опроса статуса операции
+	HttpClient   *http.Client
+}
+
+// Yandex распознавание речи через Yandex SpeechKit (асинхронное распознавание по ссылке на файл)
+type Yandex struct {
+	config YandexConfig
+}
+
+// NewYandex конструктор
+func NewYandex(config YandexConfig) *Yandex {
+	if config.RecognizeUrl == "" {
+		config.RecognizeUrl = yandexRecognizeUrl
+	}
+	if config.OperationUrl == "" {
+		config.OperationUrl = yandexOperationUrl
+	}
+	if config.PollInterval == 0 {
+		config.PollInterval = yandexPollInterval
+	}
+	if config.HttpClient == nil {
+		config.HttpClient = http.DefaultClient
+	}
+
+	return &Yandex{config: config}
+}
+
+type yandexRecognitionRequest struct {
+	Config struct {
+		Specification yandexSpecification `json:"specification"`
+	} `json:"config"`
+	Audio struct {
+		Uri string `json:"uri"`
+	} `json:"audio"`
+}
+
+type yandexSpecification struct {
+	LanguageCode    string `json:"languageCode,omitempty"`
+	ProfanityFilter bool   `json:"profanityFilter"`
+	LiteratureText  bool   `json:"literature_text"`
+	AudioEncoding   string `json:"audioEncoding"`
+	RawResults      bool   `json:"rawResults"`
+}
+
+type yandexOperation struct {
+	Id    string `json:"id"`
+	Done  bool   `json:"done"`
+	Error *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+	Response struct {
+		Chunks []struct {
+			ChannelTag   string `json:"channelTag"`
+			Alternatives []struct {
+				Text  string `json:"text"`
+				Words []struct {
+					StartTime string `json:"startTime"`
+					EndTime   string `json:"endTime"`
+					Word      string `json:"word"`
+				} `json:"words"`
+			} `json:"alternatives"`
+		} `json:"chunks"`
+	} `json:"response"`
+}
+
+// Transcribe запуск распознавания и ожидание результата
+func (y Yandex) Transcribe(ctx context.Context, audio Audio, options Options) (*Transcript, error) {
+	if audio.Url == "" {
+		return nil, ErrNoAudio
+	}
+
+	request := yandexRecognitionRequest{}
+	request.Config.Specification = yandexSpecification{
+		LanguageCode:    options.Language,
+		ProfanityFilter: options.ProfanityFilter,
+		LiteratureText:  options.LiteratureText,
+		AudioEncoding:   audio.Encoding,
+	}
+	if request.Config.Specification.AudioEncoding == "" {
+		request.Config.Specification.AudioEncoding = yandexAudioEncoding
+	}
+	request.Audio.Uri = audio.Url
+
+	body, err := json.Marshal(request)
+	if err != nil {
+		return nil, errors.NoType.Wrap(err, "error marshalling recognition request")
+	}
+
+	operation := &yandexOperation{}
+	err = y.do(ctx, http.MethodPost, y.config.RecognizeUrl, bytes.NewReader(body), operation)
+	if err != nil {
+		return nil, errors.NoType.Wrap(err, "error starting recognition")
+	}
+
+	operation, err = y.wait(ctx, operation)
+	if err != nil {
+		return nil, err
+	}
+
+	return y.transcript(operation)
+}
+
+// wait опрос статуса операции до ее завершения или отмены контекста
+func (y Yandex) wait(ctx context.Context, operation *yandexOperation) (*yandexOperation, error) {
+	ticker := time.NewTicker(y.config.PollInterval)
+	defer ticker.Stop()
+
+	for !operation.Done {
+		select {
+		case <-ctx.Done():
+			return nil, errors.NoType.Wrap(ctx.Err(), "recognition cancelled")
+		case <-ticker.C:
+		}
+
+		id := operation.Id
+		operation = &yandexOperation{}
+		err := y.do(ctx, http.MethodGet, y.config.OperationUrl+id, nil, operation)
+		if err != nil {
+			return nil, errors.NoType.Wrap(err, "error getting recognition operation")
+		}
+	}
+
+	if operation.Error != nil {
+		return nil, errors.NoType.Newf("recognition failed: %d %s", operation.Error.Code, operation.Error.Message)
+	}
+
+	return operation, nil
+}
+
+// transcript сборка результата из ответа основного канала
+func (y Yandex) transcript(operation *yandexOperation) (*Transcript, error) {
+	var words []Word
+	var texts []string
+	for _, chunk := range operation.Response.Chunks {
+		if chunk.ChannelTag != yandexMainChannelTag || len(chunk.Alternatives) == 0 {
+			continue
+		}
+		texts = append(texts, chunk.Alternatives[0].Text)
+		for _, w := range chunk.Alternatives[0].Words {
+			start, err := parseSeconds(w.StartTime)
+			if err != nil {
+				return nil, err
+			}
+			end, err := parseSeconds(w.EndTime)
+			if err != nil {
+				return nil, err
+			}
+			words = append(words, Word{Start: start, End: end, Text: w.Word})
+		}
+	}
+	if len(words) == 0 {
+		return nil, ErrEmptyTranscript
+	}
+
+	return &Transcript{Text: strings.Join(texts, " "), Words: words}, nil
+}
+
+func (y Yandex) do(ctx context.Context, method string, url string, body io.Reader, result any) error {
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Api-Key "+y.config.ApiKey)
+
+	return doJSON(y.config.HttpClient, req, result)
+}
@@ -2,6 +2,7 @@ package postgres
 
 import (
 	"fmt"
+	"github.com/aeroideaservices/focus/page/plugin/services/cardtypes"
 	"github.com/aeroideaservices/focus/page/postgres/repositories"
 	"github.com/sarulabs/di/v2"
 
@@ -16,7 +17,7 @@ var Definitions = []di.Def{
 			if dialector := db.Dialector.Name(); dialector != "postgres" {
 				return nil, fmt.Errorf("focus.page.repositories.page does not support connection %s", dialector)
 			}
-			return repositories.NewPageRepository(db), nil
+			return repositories.NewPageRepository(db, ctn.Get("focus.page.cardTypes").(*cardtypes.Registry)), nil
 		},
 	},
 	{
@@ -28,7 +29,7 @@ var Definitions = []di.Def{
 					"focus.gallery.repositories.gallery does not support connection %s", dialector,
 				)
 			}
-			return repositories.NewGalleryRepository(db), nil
+			return repositories.NewGalleryRepository(db, ctn.Get("focus.page.cardTypes").(*cardtypes.Registry)), nil
 		},
 	},
 	{
@@ -43,6 +44,16 @@ var Definitions = []di.Def{
 			return repositories.NewTagRepository(db), nil
 		},
 	},
+	{
+		Name: "focus.page.repositories.user",
+		Build: func(ctn di.Container) (interface{}, error) {
+			db := ctn.Get("focus.db").(*gorm.DB)
+			if dialector := db.Dialector.Name(); dialector != "postgres" {
+				return nil, fmt.Errorf("focus.page.repositories.user does not support connection %s", dialector)
+			}
+			return repositories.NewUserRepository(db), nil
+		},
+	},
 	{
 		Name: "focus.card.repositories.card",
 		Build: func(ctn di.Container) (interface{}, error) {
@@ -52,7 +63,87 @@ var Definitions = []di.Def{
 					"focus.configurations.repositories.configurations does not support connection %s", dialector,
 				)
 			}
-			return repositories.NewCardRepository(db), nil
+			return repositories.NewCardRepository(db, ctn.Get("focus.page.cardTypes").(*cardtypes.Registry)), nil
+		},
+	},
+	{
+		Name: "focus.page.repositories.job",
+		Build: func(ctn di.Container) (interface{}, error) {
+			db := ctn.Get("focus.db").(*gorm.DB)
+			if dialector := db.Dialector.Name(); dialector != "postgres" {
+				return nil, fmt.Errorf("focus.page.repositories.job does not support connection %s", dialector)
+			}
+			return repositories.NewJobRepository(db), nil
+		},
+	},
+	{
+		Name: "focus.page.repositories.publication",
+		Build: func(ctn di.Container) (interface{}, error) {
+			db := ctn.Get("focus.db").(*gorm.DB)
+			if dialector := db.Dialector.Name(); dialector != "postgres" {
+				return nil, fmt.Errorf("focus.page.repositories.publication does not support connection %s", dialector)
+			}
+			return repositories.NewPublicationRepository(db), nil
+		},
+	},
+	{
+		Name: "focus.page.repositories.previewToken",
+		Build: func(ctn di.Container) (interface{}, error) {
+			db := ctn.Get("focus.db").(*gorm.DB)
+			if dialector := db.Dialector.Name(); dialector != "postgres" {
+				return nil, fmt.Errorf("focus.page.repositories.previewToken does not support connection %s", dialector)
+			}
+			return repositories.NewPreviewTokenRepository(db), nil
+		},
+	},
+	{
+		Name: "focus.page.repositories.translation",
+		Build: func(ctn di.Container) (interface{}, error) {
+			db := ctn.Get("focus.db").(*gorm.DB)
+			if dialector := db.Dialector.Name(); dialector != "postgres" {
+				return nil, fmt.Errorf("focus.page.repositories.translation does not support connection %s", dialector)
+			}
+			return repositories.NewTranslationRepository(db), nil
+		},
+	},
+	{
+		Name: "focus.page.repositories.transfer",
+		Build: func(ctn di.Container) (interface{}, error) {
+			db := ctn.Get("focus.db").(*gorm.DB)
+			if dialector := db.Dialector.Name(); dialector != "postgres" {
+				return nil, fmt.Errorf("focus.page.repositories.transfer does not support connection %s", dialector)
+			}
+			return repositories.NewTransferRepository(db, ctn.Get("focus.page.cardTypes").(*cardtypes.Registry)), nil
+		},
+	},
+	{
+		Name: "focus.page.repositories.search",
+		Build: func(ctn di.Container) (interface{}, error) {
+			db := ctn.Get("focus.db").(*gorm.DB)
+			if dialector := db.Dialector.Name(); dialector != "postgres" {
+				return nil, fmt.Errorf("focus.page.repositories.search does not support connection %s", dialector)
+			}
+			return repositories.NewSearchRepository(db), nil
+		},
+	},
+	{
+		Name: "focus.page.repositories.transactor",
+		Build: func(ctn di.Container) (interface{}, error) {
+			db := ctn.Get("focus.db").(*gorm.DB)
+			if dialector := db.Dialector.Name(); dialector != "postgres" {
+				return nil, fmt.Errorf("focus.page.repositories.transactor does not support connection %s", dialector)
+			}
+			return repositories.NewTransactor(db), nil
+		},
+	},
+	{
+		Name: "focus.page.repositories.snapshot",
+		Build: func(ctn di.Container) (interface{}, error) {
+			db := ctn.Get("focus.db").(*gorm.DB)
+			if dialector := db.Dialector.Name(); dialector != "postgres" {
+				return nil, fmt.Errorf("focus.page.repositories.snapshot does not support connection %s", dialector)
+			}
+			return repositories.NewSnapshotRepository(db), nil
 		},
 	},
 }
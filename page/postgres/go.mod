@@ -7,6 +7,7 @@ require (
 	github.com/aeroideaservices/focus/services/errors v1.0.0
 	github.com/google/uuid v1.6.0
 	github.com/jackc/pgx/v5 v5.5.5
+	github.com/sarulabs/di/v2 v2.4.2
 	gorm.io/gorm v1.25.9
 )
 
@@ -22,7 +23,6 @@ require (
 	github.com/jmespath/go-jmespath v0.4.0 // indirect
 	github.com/lib/pq v1.10.6 // indirect
 	github.com/pkg/errors v0.9.1 // indirect
-	github.com/sarulabs/di/v2 v2.4.2 // indirect
 	github.com/u2takey/ffmpeg-go v0.5.0 // indirect
 	github.com/u2takey/go-utils v0.3.1 // indirect
 	gitlab.aeroidea.ru/internal-projects/focus/forms/plugin v0.1.6 // indirect
@@ -4,6 +4,7 @@ import (
 	"context"
 	media_entity "github.com/aeroideaservices/focus/media/plugin/entity"
 	"github.com/aeroideaservices/focus/page/plugin/entity"
+	"github.com/aeroideaservices/focus/page/plugin/services/cardtypes"
 	"github.com/aeroideaservices/focus/services/errors"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgconn"
@@ -12,17 +13,20 @@ import (
 )
 
 type CardRepository struct {
-	db *gorm.DB
+	db        *gorm.DB
+	cardTypes *cardtypes.Registry
 }
 
-func NewCardRepository(db *gorm.DB) *CardRepository {
+func NewCardRepository(db *gorm.DB, cardTypes *cardtypes.Registry) *CardRepository {
 	return &CardRepository{
-		db: db,
+		db:        db,
+		cardTypes: cardTypes,
 	}
 }
 
 const (
-	UniqueViolationErr = "23505"
+	UniqueViolationErr     = "23505"
+	ForeignKeyViolationErr = "23503"
 )
 
 func (r *CardRepository) GetListWithSearch(ctx context.Context, searchValue string, name string) (
@@ -30,7 +34,7 @@ func (r *CardRepository) GetListWithSearch(ctx context.Context, searchValue stri
 ) {
 	var cards []entity.Card
 
-	db := r.db.WithContext(ctx).Model(entity.Card{}).
+	db := conn(ctx, r.db).Model(entity.Card{}).
 		Preload("HtmlCard").
 		Preload("VideoCard").Preload("VideoCard.Video").Preload("VideoCard.VideoLite").Preload("VideoCard.VideoPreview").Preload("VideoCard.VideoPreviewBlur").
 		//Preload(
@@ -58,13 +62,18 @@ func (r *CardRepository) GetListWithSearch(ctx context.Context, searchValue stri
 	if err != nil {
 		return nil, err
 	}
+
+	err = loadCustomCards(conn(ctx, r.db), r.cardTypes, cardPointers(cards))
+	if err != nil {
+		return nil, err
+	}
 	return cards, nil
 }
 
 func (r *CardRepository) GetById(ctx context.Context, cardId uuid.UUID) (*entity.Card, error) {
 	card := &entity.Card{}
 
-	db := r.db.WithContext(ctx).Model(entity.Card{}).Where("id", cardId).Preload("HtmlCard").
+	db := conn(ctx, r.db).Model(entity.Card{}).Where("id", cardId).Preload("HtmlCard").
 		Preload("VideoCard").Preload("VideoCard.Video").Preload("VideoCard.VideoLite").Preload("VideoCard.VideoPreview").Preload("VideoCard.VideoPreviewBlur").
 		Preload("RegularCard").Preload("RegularCard.Video").Preload("RegularCard.VideoLite").Preload("RegularCard.VideoPreview").Preload("RegularCard.VideoPreviewBlur").
 		Preload("RegularCard.User").Preload("RegularCard.User.Picture").
@@ -82,13 +91,18 @@ func (r *CardRepository) GetById(ctx context.Context, cardId uuid.UUID) (*entity
 		return nil, err
 	}
 
+	err = loadCustomCards(conn(ctx, r.db), r.cardTypes, []*entity.Card{card})
+	if err != nil {
+		return nil, err
+	}
+
 	return card, nil
 }
 
 func (r *CardRepository) GetByIdWithoutAssociate(ctx context.Context, cardId uuid.UUID) (*entity.Card, error) {
 	card := &entity.Card{}
 
-	db := r.db.WithContext(ctx).Omit(clause.Associations).Model(entity.Card{}).Where("id", cardId)
+	db := conn(ctx, r.db).Omit(clause.Associations).Model(entity.Card{}).Where("id", cardId)
 	err := db.First(card).Error
 
 	if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -101,9 +115,83 @@ func (r *CardRepository) GetByIdWithoutAssociate(ctx context.Context, cardId uui
 	return card, nil
 }
 
+// LoadRelations загрузка медиа, спикеров, тегов и формы карточки по идентификаторам в ее полях.
+// Используется для карточки, собранной из черновика, связи которой могут отличаться от сохраненных.
+func (r *CardRepository) LoadRelations(ctx context.Context, card *entity.Card) error {
+	db := conn(ctx, r.db)
+	type relation struct {
+		owner any
+		name  string
+		dest  any
+	}
+	var relations []relation
+	var users []**entity.User
+	switch {
+	case card.RegularCard != nil:
+		regular := card.RegularCard
+		relations = append(relations,
+			relation{regular, "Video", &regular.Video},
+			relation{regular, "VideoLite", &regular.VideoLite},
+			relation{regular, "VideoPreview", &regular.VideoPreview},
+			relation{regular, "VideoPreviewBlur", &regular.VideoPreviewBlur},
+			relation{regular, "User", &regular.User},
+		)
+		for i := range regular.RegularCardsTags {
+			tag := &regular.RegularCardsTags[i]
+			relations = append(relations, relation{tag, "Tag", &tag.Tag})
+		}
+		users = append(users, &regular.User)
+	case card.VideoCard != nil:
+		video := card.VideoCard
+		relations = append(relations,
+			relation{video, "Video", &video.Video},
+			relation{video, "VideoLite", &video.VideoLite},
+			relation{video, "VideoPreview", &video.VideoPreview},
+			relation{video, "VideoPreviewBlur", &video.VideoPreviewBlur},
+		)
+	case card.PhotoCard != nil:
+		relations = append(relations, relation{card.PhotoCard, "Picture", &card.PhotoCard.Picture})
+	case card.FormCard != nil:
+		form := card.FormCard
+		relations = append(relations, relation{form, "Form", &form.Form}, relation{form, "User", &form.User})
+		for i := range form.FormCardsTags {
+			tag := &form.FormCardsTags[i]
+			relations = append(relations, relation{tag, "Tag", &tag.Tag})
+		}
+		users = append(users, &form.User)
+	}
+
+	for _, rel := range relations {
+		if err := loadBelongsTo(db, rel.owner, rel.name, rel.dest); err != nil {
+			return err
+		}
+	}
+	// фото спикера загружается после самого спикера
+	for _, user := range users {
+		if *user == nil {
+			continue
+		}
+		if err := loadBelongsTo(db, *user, "Picture", &(*user).Picture); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// loadBelongsTo загрузка связанной сущности по внешнему ключу в полях owner, без нее dest не меняется
+func loadBelongsTo(db *gorm.DB, owner any, name string, dest any) error {
+	association := db.Model(owner).Association(name)
+	if count := association.Count(); count == 0 || association.Error != nil {
+		return association.Error
+	}
+
+	return association.Find(dest)
+}
+
 func (r *CardRepository) GetListByGalleryId(ctx context.Context, galleryId uuid.UUID) ([]entity.Card, error) {
 	var cards []entity.Card
-	db := r.db.WithContext(ctx).Select("cards.*, galleries_cards.position").Table("galleries_cards").
+	db := conn(ctx, r.db).Select("cards.*, galleries_cards.position").Table("galleries_cards").
 		Joins("left join cards on galleries_cards.card_id=cards.id").
 		Where("gallery_id=?", galleryId).
 		Preload("HtmlCard").
@@ -123,13 +211,18 @@ func (r *CardRepository) GetListByGalleryId(ctx context.Context, galleryId uuid.
 		}
 	}
 
+	err := loadCustomCards(conn(ctx, r.db), r.cardTypes, cardPointers(cards))
+	if err != nil {
+		return nil, err
+	}
+
 	return cards, nil
 }
 
 //func (r *CardRepository) Create(
 //	ctx context.Context, card *entity.Card, galleriesCards []entity.GalleriesCards,
 //) (*uuid.UUID, error) {
-//	tx := r.db.WithContext(ctx).Begin()
+//	tx := conn(ctx, r.db).Begin()
 //
 //	if card.VideoCard != nil && card.VideoCard.VideoId != nil {
 //		var videoMedia media_entity.Media
@@ -218,7 +311,7 @@ func (r *CardRepository) GetListByGalleryId(ctx context.Context, galleryId uuid.
 //	}
 //
 //	if len(galleriesCards) != 0 {
-//		err := r.db.WithContext(ctx).Omit(clause.Associations).Create(&galleriesCards).Error
+//		err := conn(ctx, r.db).Omit(clause.Associations).Create(&galleriesCards).Error
 //		if err != nil {
 //			return nil, err
 //		}
@@ -230,7 +323,7 @@ func (r *CardRepository) GetListByGalleryId(ctx context.Context, galleryId uuid.
 func (r *CardRepository) Create(
 	ctx context.Context, card *entity.Card, galleriesCards []entity.GalleriesCards,
 ) (*uuid.UUID, error) {
-	tx := r.db.WithContext(ctx).Begin()
+	tx := conn(ctx, r.db).Begin()
 
 	defer func() {
 		if r := recover(); r != nil {
@@ -278,6 +371,10 @@ func (r *CardRepository) Create(
 		}
 	}
 
+	if err = createCustomCard(tx, r.cardTypes, card); err != nil {
+		return nil, err
+	}
+
 	if err = r.createCard(tx, card); err != nil {
 		return nil, err
 	}
@@ -287,7 +384,7 @@ func (r *CardRepository) Create(
 	}
 
 	if len(galleriesCards) != 0 {
-		if err = r.db.WithContext(ctx).Omit(clause.Associations).Create(&galleriesCards).Error; err != nil {
+		if err = conn(ctx, r.db).Omit(clause.Associations).Create(&galleriesCards).Error; err != nil {
 			return nil, err
 		}
 	}
@@ -295,6 +392,27 @@ func (r *CardRepository) Create(
 	return &card.ID, nil
 }
 
+// Clone создание копии карточки с ее типизированной частью и связями с тегами в одной транзакции
+func (r *CardRepository) Clone(ctx context.Context, card *entity.Card) error {
+	err := conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		return createCard(tx, r.cardTypes, card)
+	})
+	if err != nil {
+		return cloneError(err, "card")
+	}
+
+	return nil
+}
+
+func cardPointers(cards []entity.Card) []*entity.Card {
+	pointers := make([]*entity.Card, len(cards))
+	for i := range cards {
+		pointers[i] = &cards[i]
+	}
+
+	return pointers
+}
+
 func (r *CardRepository) getMedia(tx *gorm.DB, mediaId uuid.UUID) (*media_entity.Media, error) {
 	var media media_entity.Media
 	if err := tx.First(&media, mediaId).Error; err != nil {
@@ -304,6 +422,10 @@ func (r *CardRepository) getMedia(tx *gorm.DB, mediaId uuid.UUID) (*media_entity
 }
 
 func (r *CardRepository) createRegularCard(tx *gorm.DB, card *entity.Card) error {
+	if err := lockUser(tx, card.RegularCard.UserId); err != nil {
+		return err
+	}
+
 	if card.RegularCard.VideoId != nil {
 		videoMedia, err := r.getMedia(tx, *card.RegularCard.VideoId)
 		if err != nil {
@@ -355,6 +477,9 @@ func (r *CardRepository) createPhotoCard(tx *gorm.DB, card *entity.Card) error {
 }
 
 func (r *CardRepository) createFormCard(tx *gorm.DB, card *entity.Card) error {
+	if err := lockUser(tx, card.FormCard.UserId); err != nil {
+		return err
+	}
 	if err := tx.Omit(clause.Associations).Create(card.FormCard).Error; err != nil {
 		return err
 	}
@@ -370,6 +495,19 @@ func (r *CardRepository) createCard(tx *gorm.DB, card *entity.Card) error {
 
 func (r *CardRepository) Update(
 	ctx context.Context, card *entity.Card, galleriesCards []entity.GalleriesCards,
+) error {
+	if customType := r.cardTypes.Custom(card.Type); customType != nil {
+		return r.updateCustomCard(ctx, customType, card, galleriesCards)
+	}
+
+	// спикер карточки блокируется до записи привязки (см. lockUser), поэтому изменение идет в транзакции
+	return NewTransactor(r.db).Transaction(ctx, func(ctx context.Context) error {
+		return r.updateCard(ctx, card, galleriesCards)
+	})
+}
+
+func (r *CardRepository) updateCard(
+	ctx context.Context, card *entity.Card, galleriesCards []entity.GalleriesCards,
 ) error {
 	internalCardId, err := r.getInternalId(ctx, card)
 	if err != nil {
@@ -378,7 +516,7 @@ func (r *CardRepository) Update(
 
 	switch card.Type {
 	default:
-		err := r.db.WithContext(ctx).Session(&gorm.Session{FullSaveAssociations: true}).
+		err := conn(ctx, r.db).Session(&gorm.Session{FullSaveAssociations: true}).
 			Updates(
 				card,
 			).Error
@@ -390,7 +528,7 @@ func (r *CardRepository) Update(
 		card.HtmlCard.ID = uuid.Must(uuid.Parse(internalCardId))
 		card.HtmlCardId = &card.HtmlCard.ID
 
-		err = r.db.WithContext(ctx).Session(&gorm.Session{FullSaveAssociations: true}).
+		err = conn(ctx, r.db).Session(&gorm.Session{FullSaveAssociations: true}).
 			Updates(
 				card,
 			).Error
@@ -401,7 +539,7 @@ func (r *CardRepository) Update(
 		card.VideoCard.ID = uuid.Must(uuid.Parse(internalCardId))
 		card.VideoCardId = &card.VideoCard.ID
 
-		err = r.db.WithContext(ctx).Session(&gorm.Session{FullSaveAssociations: true}).
+		err = conn(ctx, r.db).Session(&gorm.Session{FullSaveAssociations: true}).
 			Updates(
 				card,
 			).Error
@@ -412,7 +550,7 @@ func (r *CardRepository) Update(
 		card.PhotoCard.ID = uuid.Must(uuid.Parse(internalCardId))
 		card.PhotoCardId = &card.PhotoCard.ID
 
-		err = r.db.WithContext(ctx).Session(&gorm.Session{FullSaveAssociations: true}).
+		err = conn(ctx, r.db).Session(&gorm.Session{FullSaveAssociations: true}).
 			Updates(
 				card,
 			).Error
@@ -422,12 +560,15 @@ func (r *CardRepository) Update(
 	case "form":
 		card.FormCard.ID = uuid.Must(uuid.Parse(internalCardId))
 		card.FormCardId = &card.FormCard.ID
+		if err = lockUser(conn(ctx, r.db), card.FormCard.UserId); err != nil {
+			return err
+		}
 
 		for i, _ := range card.FormCard.FormCardsTags {
 			card.FormCard.FormCardsTags[i].FormCardID = card.FormCard.ID
 		}
 
-		err = r.db.WithContext(ctx).Session(&gorm.Session{FullSaveAssociations: true}).Omit("FormCard.FormCardsTags").
+		err = conn(ctx, r.db).Session(&gorm.Session{FullSaveAssociations: true}).Omit("FormCard.FormCardsTags").
 			Updates(
 				card,
 				//map[string]interface{}{
@@ -441,17 +582,20 @@ func (r *CardRepository) Update(
 		if err != nil {
 			return err
 		}
-		err = r.db.WithContext(ctx).Omit(clause.Associations).Create(card.FormCard.FormCardsTags).Error
+		err = conn(ctx, r.db).Omit(clause.Associations).Create(card.FormCard.FormCardsTags).Error
 
 	case "regular":
 		card.RegularCard.ID = uuid.Must(uuid.Parse(internalCardId))
 		card.RegularCardId = &card.RegularCard.ID
+		if err = lockUser(conn(ctx, r.db), card.RegularCard.UserId); err != nil {
+			return err
+		}
 
 		for i, _ := range card.RegularCard.RegularCardsTags {
 			card.RegularCard.RegularCardsTags[i].RegularCardID = card.RegularCard.ID
 		}
 
-		err = r.db.WithContext(ctx).Session(&gorm.Session{FullSaveAssociations: true}).Omit("RegularCard.RegularCardsTags").
+		err = conn(ctx, r.db).Session(&gorm.Session{FullSaveAssociations: true}).Omit("RegularCard.RegularCardsTags").
 			Updates(
 				card,
 				//map[string]interface{}{
@@ -465,27 +609,45 @@ func (r *CardRepository) Update(
 		if err != nil {
 			return err
 		}
-		err = r.db.WithContext(ctx).Omit(clause.Associations).Create(card.RegularCard.RegularCardsTags).Error
+		err = conn(ctx, r.db).Omit(clause.Associations).Create(card.RegularCard.RegularCardsTags).Error
 	}
 
 	if len(galleriesCards) != 0 {
-		err := r.db.WithContext(ctx).Omit(clause.Associations).Create(&galleriesCards).Error
+		err := conn(ctx, r.db).Omit(clause.Associations).Create(&galleriesCards).Error
 		if err != nil {
 			return err
 		}
 	}
 
-	//err := r.db.WithContext(ctx).Omit(clause.Associations).Create(&galleriesCards).Error
+	//err := conn(ctx, r.db).Omit(clause.Associations).Create(&galleriesCards).Error
 
-	//err = r.db.WithContext(ctx).Omit(clause.Associations).Create(card.RegularCard.RegularCardsTags).Error
+	//err = conn(ctx, r.db).Omit(clause.Associations).Create(card.RegularCard.RegularCardsTags).Error
 
 	return nil
 }
 
+// updateCustomCard изменение карточки зарегистрированного приложением типа вместе с ее данными
+func (r *CardRepository) updateCustomCard(
+	ctx context.Context, customType *cardtypes.Type, card *entity.Card, galleriesCards []entity.GalleriesCards,
+) error {
+	return conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := saveCustomCard(tx, customType, card); err != nil {
+			return err
+		}
+		if err := tx.Omit(clause.Associations).Updates(card).Error; err != nil {
+			return err
+		}
+		if len(galleriesCards) != 0 {
+			return tx.Omit(clause.Associations).Create(&galleriesCards).Error
+		}
+		return nil
+	})
+}
+
 func (r *CardRepository) getInternalId(ctx context.Context, card *entity.Card) (string, error) {
 	var htmlCardId string
 	selectString := card.Type + "_card_id"
-	err := r.db.WithContext(ctx).Select(selectString).Model(entity.Card{}).Where("id = ?", card.ID).
+	err := conn(ctx, r.db).Select(selectString).Model(entity.Card{}).Where("id = ?", card.ID).
 		Find(&htmlCardId).Error
 	if err != nil {
 		return "", err
@@ -493,68 +655,76 @@ func (r *CardRepository) getInternalId(ctx context.Context, card *entity.Card) (
 	return htmlCardId, err
 }
 
+// Delete удаление карточки вместе с ее переводами
 func (r *CardRepository) Delete(ctx context.Context, cardId uuid.UUID) error {
+	return NewTransactor(r.db).Transaction(ctx, func(ctx context.Context) error {
+		err := r.UpdatePositionBeforeDelete(ctx, cardId)
+		if err != nil {
+			return err
+		}
 
-	err := r.UpdatePositionBeforeDelete(ctx, cardId)
-	if err != nil {
-		return err
-	}
-
-	err = r.db.WithContext(ctx).Where("id = ?", cardId).Delete(entity.Card{}).Error
+		err = conn(ctx, r.db).Where("id = ?", cardId).Delete(entity.Card{}).Error
+		if err != nil {
+			return err
+		}
 
-	return err
+		return deleteTranslations(conn(ctx, r.db), entity.TranslationTypeCard, cardId)
+	})
 }
 
 func (r *CardRepository) UpdatePositionBeforeDelete(ctx context.Context, cardId uuid.UUID) error {
 	var galleriesCard []entity.GalleriesCards
 
-	err := r.db.WithContext(ctx).Clauses(clause.Returning{}).Where("card_id = ?", cardId).Delete(&galleriesCard).Error
+	err := conn(ctx, r.db).Clauses(clause.Returning{}).Where("card_id = ?", cardId).Delete(&galleriesCard).Error
 
 	for _, cards := range galleriesCard {
-		err = r.db.WithContext(ctx).Table("galleries_cards").Where("gallery_id = ?", cards.GalleryID).Where(
+		err = conn(ctx, r.db).Table("galleries_cards").Where("gallery_id = ?", cards.GalleryID).Where(
 			"position > ?", cards.Position,
 		).Update("position", gorm.Expr("position - 1")).Error
 	}
 	return err
 }
 
+// PatchUser смена спикера карточки, спикер блокируется до записи привязки (см. lockUser)
 func (r *CardRepository) PatchUser(ctx context.Context, card *entity.Card) error {
-	//var cardType string
 	newUserId := card.RegularCard.UserId
-	err := r.db.WithContext(ctx).Select("type", "regular_card_id", "form_card_id").Model(entity.Card{}).Where(
-		"id = ?", card.ID,
-	).Find(card).Error
-
-	if err != nil {
-		return err
-	}
-
-	if card.RegularCardId != nil {
-		err = r.db.WithContext(ctx).Model(entity.RegularCard{}).Where("id = ?", card.RegularCardId).
-			Updates(
-				map[string]interface{}{
-					"user_id": newUserId,
-				},
-			).Error
+	return conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		err := tx.Select("type", "regular_card_id", "form_card_id").Model(entity.Card{}).Where(
+			"id = ?", card.ID,
+		).Find(card).Error
+		if err != nil {
+			return err
+		}
 
-	}
-	if card.FormCardId != nil {
-		err = r.db.WithContext(ctx).Model(entity.FormCard{}).Where("id = ?", card.FormCardId).
-			Updates(
-				map[string]interface{}{
-					"user_id": newUserId,
-				},
-			).Error
+		if err = lockUser(tx, newUserId); err != nil {
+			return err
+		}
 
-	}
+		if card.RegularCardId != nil {
+			err = tx.Model(entity.RegularCard{}).Where("id = ?", card.RegularCardId).
+				Updates(
+					map[string]interface{}{
+						"user_id": newUserId,
+					},
+				).Error
+		}
+		if card.FormCardId != nil {
+			err = tx.Model(entity.FormCard{}).Where("id = ?", card.FormCardId).
+				Updates(
+					map[string]interface{}{
+						"user_id": newUserId,
+					},
+				).Error
+		}
 
-	return err
+		return err
+	})
 }
 
 func (r *CardRepository) PatchPreviewText(ctx context.Context, card *entity.Card) error {
 	//var cardType string
 
-	err := r.db.WithContext(ctx).Select("type", "regular_card_id").Model(entity.Card{}).Where(
+	err := conn(ctx, r.db).Select("type", "regular_card_id").Model(entity.Card{}).Where(
 		"id = ?", card.ID,
 	).Find(card).Error
 
@@ -563,7 +733,7 @@ func (r *CardRepository) PatchPreviewText(ctx context.Context, card *entity.Card
 	}
 
 	if card.RegularCardId != nil {
-		err = r.db.WithContext(ctx).Model(entity.RegularCard{}).Where("id = ?", card.RegularCardId).
+		err = conn(ctx, r.db).Model(entity.RegularCard{}).Where("id = ?", card.RegularCardId).
 			Updates(
 				map[string]interface{}{
 					"preview_text": card.RegularCard.PreviewText,
@@ -577,7 +747,7 @@ func (r *CardRepository) PatchPreviewText(ctx context.Context, card *entity.Card
 func (r *CardRepository) PatchDetailText(ctx context.Context, card *entity.Card) error {
 	//var cardType string
 	//newUserId := card.RegularCard.UserId
-	err := r.db.WithContext(ctx).Select("type", "regular_card_id").Model(entity.Card{}).Where(
+	err := conn(ctx, r.db).Select("type", "regular_card_id").Model(entity.Card{}).Where(
 		"id = ?", card.ID,
 	).Find(card).Error
 
@@ -586,7 +756,7 @@ func (r *CardRepository) PatchDetailText(ctx context.Context, card *entity.Card)
 	}
 
 	if card.RegularCardId != nil {
-		err = r.db.WithContext(ctx).Model(entity.RegularCard{}).Where("id = ?", card.RegularCardId).
+		err = conn(ctx, r.db).Model(entity.RegularCard{}).Where("id = ?", card.RegularCardId).
 			Updates(
 				map[string]interface{}{
 					"detail_text": card.RegularCard.DetailText,
@@ -598,7 +768,7 @@ func (r *CardRepository) PatchDetailText(ctx context.Context, card *entity.Card)
 }
 
 func (r *CardRepository) PatchLearnMoreUrl(ctx context.Context, card *entity.Card) error {
-	err := r.db.WithContext(ctx).Select("type", "regular_card_id", "form_card_id").Model(entity.Card{}).Where(
+	err := conn(ctx, r.db).Select("type", "regular_card_id", "form_card_id").Model(entity.Card{}).Where(
 		"id = ?", card.ID,
 	).Find(card).Error
 
@@ -607,7 +777,7 @@ func (r *CardRepository) PatchLearnMoreUrl(ctx context.Context, card *entity.Car
 	}
 
 	if card.RegularCardId != nil {
-		return r.db.WithContext(ctx).Model(entity.RegularCard{}).Where("id = ?", card.RegularCardId).
+		return conn(ctx, r.db).Model(entity.RegularCard{}).Where("id = ?", card.RegularCardId).
 			Updates(
 				map[string]interface{}{
 					"learn_more_url": card.RegularCard.LearnMoreUrl,
@@ -615,7 +785,7 @@ func (r *CardRepository) PatchLearnMoreUrl(ctx context.Context, card *entity.Car
 			).Error
 	}
 	if card.FormCardId != nil {
-		return r.db.WithContext(ctx).Model(entity.FormCard{}).Where("id = ?", card.FormCardId).
+		return conn(ctx, r.db).Model(entity.FormCard{}).Where("id = ?", card.FormCardId).
 			Updates(
 				map[string]interface{}{
 					"learn_more_url": card.FormCard.LearnMoreUrl,
@@ -632,12 +802,12 @@ func (r *CardRepository) PatchTags(ctx context.Context, cardId uuid.UUID, tags [
 	//}
 	var err error
 	for _, tag := range tags {
-		err = r.db.WithContext(ctx).Model(entity.Tag{}).Where("id = ?", tag.ID).Updates(&tag).Error
+		err = conn(ctx, r.db).Model(entity.Tag{}).Where("id = ?", tag.ID).Updates(&tag).Error
 		if err != nil {
 			return err
 		}
 	}
-	//err = r.db.WithContext(ctx).Model(entity.Tag{}).Updates(&tags).Error
+	//err = conn(ctx, r.db).Model(entity.Tag{}).Updates(&tags).Error
 
 	return err
 }
@@ -658,7 +828,7 @@ func (r *CardRepository) CreateRegularTags(ctx context.Context, cardId uuid.UUID
 				},
 			)
 		}
-		err = r.db.WithContext(ctx).Omit(clause.Associations).Create(&regularCardsTags).Error
+		err = conn(ctx, r.db).Omit(clause.Associations).Create(&regularCardsTags).Error
 	}
 	if card.FormCardId != nil {
 		var formCardsTags []entity.FormCardsTags
@@ -670,7 +840,7 @@ func (r *CardRepository) CreateRegularTags(ctx context.Context, cardId uuid.UUID
 				},
 			)
 		}
-		err = r.db.WithContext(ctx).Omit(clause.Associations).Create(&formCardsTags).Error
+		err = conn(ctx, r.db).Omit(clause.Associations).Create(&formCardsTags).Error
 	}
 
 	if pgErr, ok := err.(*pgconn.PgError); ok {
@@ -696,13 +866,13 @@ func (r *CardRepository) DeleteRegularTags(ctx context.Context, cardId uuid.UUID
 	}
 
 	if card.RegularCardId != nil {
-		err = r.db.WithContext(ctx).Clauses(clause.Returning{}).
+		err = conn(ctx, r.db).Clauses(clause.Returning{}).
 			Where("regular_card_id = ?", card.RegularCardId).
 			Where("tag_id in ?", tagIds).
 			Delete(&entity.RegularCardsTags{}).Error
 	}
 	if card.FormCardId != nil {
-		err = r.db.WithContext(ctx).Clauses(clause.Returning{}).
+		err = conn(ctx, r.db).Clauses(clause.Returning{}).
 			Where("form_card_id = ?", card.FormCardId).
 			Where("tag_id in ?", tagIds).
 			Delete(&entity.FormCardsTags{}).Error
@@ -713,14 +883,14 @@ func (r *CardRepository) DeleteRegularTags(ctx context.Context, cardId uuid.UUID
 
 func (r *CardRepository) GetRegularCardId(ctx context.Context, cardId uuid.UUID) (string, error) {
 	var regularCardId string
-	err := r.db.WithContext(ctx).Select("regular_card_id").Model(&entity.Card{}).Where("id = ?", cardId).
+	err := conn(ctx, r.db).Select("regular_card_id").Model(&entity.Card{}).Where("id = ?", cardId).
 		Find(&regularCardId).Error
 	return regularCardId, err
 }
 
 func (r *CardRepository) GetInternalCardId(ctx context.Context, cardId uuid.UUID) (*entity.Card, error) {
 	card := &entity.Card{}
-	err := r.db.WithContext(ctx).Select("type", "regular_card_id", "form_card_id").Model(entity.Card{}).Where(
+	err := conn(ctx, r.db).Select("type", "regular_card_id", "form_card_id").Model(entity.Card{}).Where(
 		"id = ?", cardId,
 	).Find(card).Error
 	return card, err
@@ -732,7 +902,7 @@ func (r *CardRepository) UpdateInverted(ctx context.Context, cardID uuid.UUID, i
 		return err
 	}
 
-	err = r.db.WithContext(ctx).Model(&entity.RegularCard{}).Where("id = ?", regularCardId).
+	err = conn(ctx, r.db).Model(&entity.RegularCard{}).Where("id = ?", regularCardId).
 		Updates(
 			map[string]interface{}{
 				"inverted": *inverted,
@@ -742,7 +912,7 @@ func (r *CardRepository) UpdateInverted(ctx context.Context, cardID uuid.UUID, i
 }
 
 func (r *CardRepository) UpdatePublish(ctx context.Context, cardID uuid.UUID, publish *bool) error {
-	err := r.db.WithContext(ctx).Model(&entity.Card{}).Where("id = ?", cardID).
+	err := conn(ctx, r.db).Model(&entity.Card{}).Where("id = ?", cardID).
 		Updates(
 			map[string]interface{}{
 				"is_published": *publish,
@@ -754,13 +924,13 @@ func (r *CardRepository) UpdatePublish(ctx context.Context, cardID uuid.UUID, pu
 func (r *CardRepository) GetLastPositionInGalley(ctx context.Context, galleryID uuid.UUID) (int, error) {
 	var result int
 	var count int64
-	r.db.WithContext(ctx).Table("galleries_cards").Where(
+	conn(ctx, r.db).Table("galleries_cards").Where(
 		"gallery_id = ?", galleryID,
 	).Count(&count)
 	if count == 0 {
 		return 0, nil
 	}
-	err := r.db.WithContext(ctx).Table("galleries_cards").Where(
+	err := conn(ctx, r.db).Table("galleries_cards").Where(
 		"gallery_id = ?", galleryID,
 	).Select("max(position)").Row().Scan(&result)
 
@@ -771,7 +941,7 @@ func (r *CardRepository) CheckLink(ctx context.Context, card *entity.Card, tagID
 
 	if card.RegularCardId != nil {
 		regularTags := &entity.RegularCardsTags{}
-		err := r.db.WithContext(ctx).Omit(clause.Associations).Model(entity.RegularCardsTags{}).
+		err := conn(ctx, r.db).Omit(clause.Associations).Model(entity.RegularCardsTags{}).
 			Where("regular_card_id = ?", card.RegularCardId).
 			Where("tag_id = ?", tagID).
 			First(regularTags).Error
@@ -781,7 +951,7 @@ func (r *CardRepository) CheckLink(ctx context.Context, card *entity.Card, tagID
 	}
 	if card.FormCardId != nil {
 		formTags := &entity.FormCardsTags{}
-		err := r.db.WithContext(ctx).Omit(clause.Associations).Model(entity.FormCardsTags{}).
+		err := conn(ctx, r.db).Omit(clause.Associations).Model(entity.FormCardsTags{}).
 			Where("form_card_id = ?", card.FormCardId).
 			Where("tag_id = ?", tagID).
 			First(formTags).Error
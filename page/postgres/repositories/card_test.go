@@ -0,0 +1,79 @@
+package repositories
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/aeroideaservices/focus/page/plugin/entity"
+	"github.com/google/uuid"
+)
+
+func TestCardRepository_LoadRelations(t *testing.T) {
+	videoID, userID, pictureID, tagID := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	card := &entity.Card{
+		ID:   uuid.New(),
+		Type: "regular",
+		RegularCard: &entity.RegularCard{
+			VideoId:          &videoID,
+			UserId:           &userID,
+			RegularCardsTags: []entity.RegularCardsTags{{TagID: tagID}},
+		},
+	}
+
+	db, fake := newFakeGorm(t, func(query string, args []interface{}) (fakeRows, error) {
+		if strings.HasPrefix(query, "SELECT count(*)") {
+			// без идентификатора связи условие IN (NULL) ничего не находит
+			return fakeRows{Columns: []string{"count"}, Values: [][]driver.Value{{int64(len(args))}}}, nil
+		}
+		// идентификаторы передаются значениями и указателями, как в полях сущностей
+		switch fmt.Sprint(args[0]) {
+		case videoID.String():
+			return fakeRows{Columns: []string{"id", "filename"}, Values: [][]driver.Value{
+				{videoID.String(), "video.mp4"},
+			}}, nil
+		case userID.String():
+			return fakeRows{Columns: []string{"id", "first_name", "picture_id"}, Values: [][]driver.Value{
+				{userID.String(), "Анна", pictureID.String()},
+			}}, nil
+		case pictureID.String():
+			return fakeRows{Columns: []string{"id", "filename"}, Values: [][]driver.Value{
+				{pictureID.String(), "anna.jpg"},
+			}}, nil
+		case tagID.String():
+			return fakeRows{Columns: []string{"id", "text"}, Values: [][]driver.Value{{tagID.String(), "go"}}}, nil
+		}
+		return fakeRows{}, nil
+	})
+
+	err := NewCardRepository(db, nil).LoadRelations(context.Background(), card)
+	if err != nil {
+		t.Fatalf("LoadRelations() error = %v", err)
+	}
+
+	regular := card.RegularCard
+	if regular.Video == nil || regular.Video.Id != videoID || regular.Video.Filename != "video.mp4" {
+		t.Errorf("video = %+v, want media %s", regular.Video, videoID)
+	}
+	if regular.VideoLite != nil {
+		t.Errorf("video lite = %+v, want nil without id", regular.VideoLite)
+	}
+	if regular.User == nil || regular.User.FirstName != "Анна" {
+		t.Fatalf("user = %+v, want user %s", regular.User, userID)
+	}
+	if regular.User.Picture == nil || regular.User.Picture.Filename != "anna.jpg" {
+		t.Errorf("user picture = %+v, want media %s", regular.User.Picture, pictureID)
+	}
+	if tag := regular.RegularCardsTags[0].Tag; tag.ID != tagID || tag.Text != "go" {
+		t.Errorf("tag = %+v, want tag %s", tag, tagID)
+	}
+
+	// связи без идентификатора только подсчитываются
+	for _, query := range fake.Queries() {
+		if len(query.Args) == 0 && !strings.HasPrefix(query.SQL, "SELECT count(*)") {
+			t.Errorf("relation without id is loaded: %s", query.SQL)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package repositories
+
+import (
+	"github.com/aeroideaservices/focus/page/plugin/entity"
+	"github.com/aeroideaservices/focus/page/plugin/services/cardtypes"
+	"github.com/aeroideaservices/focus/services/errors"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// createCustomCard создание данных карточки зарегистрированного приложением типа в таблице типа
+func createCustomCard(tx *gorm.DB, cardTypes *cardtypes.Registry, card *entity.Card) error {
+	customType := cardTypes.Custom(card.Type)
+	if customType == nil || card.CustomCard == nil {
+		return nil
+	}
+
+	return tx.Table(customType.Table).Create(card.CustomCard).Error
+}
+
+// saveCustomCard сохранение данных карточки зарегистрированного приложением типа.
+// Если у карточки еще нет данных, они создаются и привязываются к карточке.
+func saveCustomCard(tx *gorm.DB, customType *cardtypes.Type, card *entity.Card) error {
+	current := &entity.Card{}
+	err := tx.Select("custom_card_id").Where("id = ?", card.ID).First(current).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return errors.NotFound.Wrapf(err, "card with id %s not found", card.ID)
+	}
+	if err != nil {
+		return err
+	}
+
+	if current.CustomCardId == nil {
+		customCardId := uuid.New()
+		cardtypes.SetID(card.CustomCard, customCardId)
+		card.CustomCardId = &customCardId
+		return tx.Table(customType.Table).Create(card.CustomCard).Error
+	}
+
+	cardtypes.SetID(card.CustomCard, *current.CustomCardId)
+	card.CustomCardId = current.CustomCardId
+	return tx.Table(customType.Table).Save(card.CustomCard).Error
+}
+
+// loadCustomCards загрузка данных карточек зарегистрированных приложением типов, по запросу на каждый тип
+func loadCustomCards(db *gorm.DB, cardTypes *cardtypes.Registry, cards []*entity.Card) error {
+	byType := make(map[string][]*entity.Card)
+	for _, card := range cards {
+		if card != nil && card.CustomCardId != nil && cardTypes.Custom(card.Type) != nil {
+			byType[card.Type] = append(byType[card.Type], card)
+		}
+	}
+
+	for code, typeCards := range byType {
+		customType := cardTypes.Custom(code)
+		ids := make([]uuid.UUID, len(typeCards))
+		for i, card := range typeCards {
+			ids[i] = *card.CustomCardId
+		}
+
+		rows := customType.NewSlice()
+		err := db.Table(customType.Table).Where("id IN ?", ids).Find(rows).Error
+		if err != nil {
+			return errors.NoType.Wrapf(err, "error getting %s cards", code)
+		}
+
+		byId := make(map[uuid.UUID]any, len(ids))
+		for _, row := range cardtypes.Items(rows) {
+			byId[cardtypes.ID(row)] = row
+		}
+		for _, card := range typeCards {
+			card.CustomCard = byId[*card.CustomCardId]
+		}
+	}
+
+	return nil
+}
+
+// galleryCards карточки галереи для загрузки данных
+func galleryCards(gallery *entity.Gallery) []*entity.Card {
+	cards := make([]*entity.Card, 0, len(gallery.GalleriesCards))
+	for _, galleriesCard := range gallery.GalleriesCards {
+		cards = append(cards, galleriesCard.Card)
+	}
+
+	return cards
+}
+
+// pageCards карточки всех галерей страницы для загрузки данных
+func pageCards(page *entity.Page) []*entity.Card {
+	var cards []*entity.Card
+	for i := range page.PagesGalleries {
+		cards = append(cards, galleryCards(&page.PagesGalleries[i].Gallery)...)
+	}
+
+	return cards
+}
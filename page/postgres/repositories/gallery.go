@@ -5,6 +5,7 @@ import (
 	media_entity "github.com/aeroideaservices/focus/media/plugin/entity"
 	"github.com/aeroideaservices/focus/page/plugin/actions"
 	"github.com/aeroideaservices/focus/page/plugin/entity"
+	"github.com/aeroideaservices/focus/page/plugin/services/cardtypes"
 	"github.com/aeroideaservices/focus/services/errors"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgconn"
@@ -14,19 +15,21 @@ import (
 )
 
 type GalleryRepository struct {
-	db *gorm.DB
+	db        *gorm.DB
+	cardTypes *cardtypes.Registry
 }
 
-func NewGalleryRepository(db *gorm.DB) *GalleryRepository {
+func NewGalleryRepository(db *gorm.DB, cardTypes *cardtypes.Registry) *GalleryRepository {
 	return &GalleryRepository{
-		db: db,
+		db:        db,
+		cardTypes: cardTypes,
 	}
 }
 
 func (r *GalleryRepository) GetByCode(ctx context.Context, code string) (*entity.Gallery, error) {
 	gallery := &entity.Gallery{}
 
-	db := r.db.WithContext(ctx).Model(entity.Gallery{}).Where("code", code)
+	db := conn(ctx, r.db).Model(entity.Gallery{}).Where("code", code)
 
 	err := db.First(gallery).Error
 
@@ -42,7 +45,7 @@ func (r *GalleryRepository) GetByCode(ctx context.Context, code string) (*entity
 func (r *GalleryRepository) GetById(ctx context.Context, id uuid.UUID) (*entity.Gallery, error) {
 	gallery := &entity.Gallery{}
 
-	db := r.db.WithContext(ctx).Model(entity.Gallery{}).
+	db := conn(ctx, r.db).Model(entity.Gallery{}).
 		Preload(
 			"GalleriesCards", func(db *gorm.DB) *gorm.DB {
 				return db.Order("galleries_cards.position ASC")
@@ -66,13 +69,18 @@ func (r *GalleryRepository) GetById(ctx context.Context, id uuid.UUID) (*entity.
 	if err != nil {
 		return nil, err
 	}
+
+	err = loadCustomCards(conn(ctx, r.db), r.cardTypes, galleryCards(gallery))
+	if err != nil {
+		return nil, err
+	}
 	return gallery, nil
 }
 
 func (r *GalleryRepository) GetByIdWithoutAssociate(ctx context.Context, id uuid.UUID) (*entity.Gallery, error) {
 	gallery := &entity.Gallery{}
 
-	db := r.db.WithContext(ctx).Omit(clause.Associations).Model(entity.Gallery{}).
+	db := conn(ctx, r.db).Omit(clause.Associations).Model(entity.Gallery{}).
 		Where("id", id)
 	err := db.First(gallery).Error
 
@@ -87,7 +95,7 @@ func (r *GalleryRepository) GetByIdWithoutAssociate(ctx context.Context, id uuid
 
 func (r *GalleryRepository) GetListByPageId(ctx context.Context, pageId uuid.UUID) ([]entity.Gallery, error) {
 	var galleries []entity.Gallery
-	db := r.db.WithContext(ctx).Select("galleries.*, pages_galleries.position").Table("pages_galleries").
+	db := conn(ctx, r.db).Select("galleries.*, pages_galleries.position").Table("pages_galleries").
 		Joins("left join galleries on pages_galleries.gallery_id=galleries.id").
 		Where("pages_id=?", pageId).
 		Find(&galleries)
@@ -107,7 +115,7 @@ func (r *GalleryRepository) GetListWithSearch(ctx context.Context, searchValue s
 ) {
 	var galleries []entity.Gallery
 
-	db := r.db.WithContext(ctx).Model(entity.Gallery{}).
+	db := conn(ctx, r.db).Model(entity.Gallery{}).
 		Preload(
 			"GalleriesCards", func(db *gorm.DB) *gorm.DB {
 				return db.Order("galleries_cards.position ASC")
@@ -136,7 +144,7 @@ func (r *GalleryRepository) GetListWithSearch(ctx context.Context, searchValue s
 }
 
 func (r *GalleryRepository) Create(ctx context.Context, gallery *entity.Gallery) (*uuid.UUID, error) {
-	tx := r.db.WithContext(ctx).Begin()
+	tx := conn(ctx, r.db).Begin()
 
 	if err := tx.Omit(clause.Associations).Create(gallery).Error; err != nil {
 		tx.Rollback()
@@ -172,6 +180,10 @@ func (r *GalleryRepository) Create(ctx context.Context, gallery *entity.Gallery)
 			galleriesCard.Card.RegularCard.VideoPreview = &previewMedia
 		}
 		if galleriesCard.Card.RegularCard != nil {
+			if err := lockUser(tx, galleriesCard.Card.RegularCard.UserId); err != nil {
+				tx.Rollback()
+				return nil, err
+			}
 			if err := tx.Omit(clause.Associations).Create(galleriesCard.Card.RegularCard).Error; err != nil {
 				tx.Rollback()
 				return nil, err
@@ -217,6 +229,10 @@ func (r *GalleryRepository) Create(ctx context.Context, gallery *entity.Gallery)
 		}
 
 		if galleriesCard.Card.FormCard != nil {
+			if err := lockUser(tx, galleriesCard.Card.FormCard.UserId); err != nil {
+				tx.Rollback()
+				return nil, err
+			}
 			if err := tx.Omit(clause.Associations).Create(galleriesCard.Card.FormCard).Error; err != nil {
 				tx.Rollback()
 				return nil, err
@@ -231,6 +247,11 @@ func (r *GalleryRepository) Create(ctx context.Context, gallery *entity.Gallery)
 			}
 		}
 
+		if err := createCustomCard(tx, r.cardTypes, galleriesCard.Card); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+
 		if err := tx.Omit(clause.Associations).Create(&galleriesCard.Card).Error; err != nil {
 			tx.Rollback()
 			return nil, err
@@ -251,7 +272,7 @@ func (r *GalleryRepository) Create(ctx context.Context, gallery *entity.Gallery)
 }
 
 func (r *GalleryRepository) Update(ctx context.Context, gallery *entity.Gallery) error {
-	tx := r.db.WithContext(ctx).Begin()
+	tx := conn(ctx, r.db).Begin()
 
 	if err := tx.Omit(clause.Associations).Model(gallery).Where("id = ?", gallery.ID).
 		Updates(
@@ -296,6 +317,10 @@ func (r *GalleryRepository) Update(ctx context.Context, gallery *entity.Gallery)
 			galleriesCard.Card.RegularCard.VideoPreview = &previewMedia
 		}
 		if galleriesCard.Card.RegularCard != nil {
+			if err := lockUser(tx, galleriesCard.Card.RegularCard.UserId); err != nil {
+				tx.Rollback()
+				return err
+			}
 			if err := tx.Omit(clause.Associations).Create(galleriesCard.Card.RegularCard).Error; err != nil {
 				tx.Rollback()
 				return err
@@ -341,6 +366,10 @@ func (r *GalleryRepository) Update(ctx context.Context, gallery *entity.Gallery)
 		}
 
 		if galleriesCard.Card.FormCard != nil {
+			if err := lockUser(tx, galleriesCard.Card.FormCard.UserId); err != nil {
+				tx.Rollback()
+				return err
+			}
 			if err := tx.Omit(clause.Associations).Create(galleriesCard.Card.FormCard).Error; err != nil {
 				tx.Rollback()
 				return err
@@ -355,6 +384,11 @@ func (r *GalleryRepository) Update(ctx context.Context, gallery *entity.Gallery)
 			}
 		}
 
+		if err := createCustomCard(tx, r.cardTypes, galleriesCard.Card); err != nil {
+			tx.Rollback()
+			return err
+		}
+
 		if err := tx.Omit(clause.Associations).Create(&galleriesCard.Card).Error; err != nil {
 			tx.Rollback()
 			return err
@@ -373,8 +407,34 @@ func (r *GalleryRepository) Update(ctx context.Context, gallery *entity.Gallery)
 	return nil
 }
 
+// Clone создание копии галереи в одной транзакции.
+// При deep создаются и копии карточек, иначе копия ссылается на существующие карточки.
+func (r *GalleryRepository) Clone(ctx context.Context, gallery *entity.Gallery, deep bool) error {
+	err := conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := createGallery(tx, gallery); err != nil {
+			return err
+		}
+		for _, galleriesCard := range gallery.GalleriesCards {
+			if deep {
+				if err := createCard(tx, r.cardTypes, galleriesCard.Card); err != nil {
+					return err
+				}
+			}
+			if err := createGalleriesCard(tx, &galleriesCard); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return cloneError(err, "gallery")
+	}
+
+	return nil
+}
+
 func (r *GalleryRepository) UpdatePublish(ctx context.Context, galleryID uuid.UUID, publish *bool) error {
-	err := r.db.WithContext(ctx).Model(&entity.Gallery{}).Where("id = ?", galleryID).
+	err := conn(ctx, r.db).Model(&entity.Gallery{}).Where("id = ?", galleryID).
 		Updates(
 			map[string]interface{}{
 				"is_published": *publish,
@@ -384,7 +444,7 @@ func (r *GalleryRepository) UpdatePublish(ctx context.Context, galleryID uuid.UU
 }
 
 func (r *GalleryRepository) UpdateHidden(ctx context.Context, galleryID uuid.UUID, hidden *bool) error {
-	err := r.db.WithContext(ctx).Model(&entity.Gallery{}).Where("id = ?", galleryID).
+	err := conn(ctx, r.db).Model(&entity.Gallery{}).Where("id = ?", galleryID).
 		Updates(
 			map[string]interface{}{
 				"hidden": *hidden,
@@ -394,7 +454,7 @@ func (r *GalleryRepository) UpdateHidden(ctx context.Context, galleryID uuid.UUI
 }
 
 func (r *GalleryRepository) PatchName(ctx context.Context, gallery *entity.Gallery) error {
-	err := r.db.WithContext(ctx).Model(gallery).Where("id = ?", gallery.ID).
+	err := conn(ctx, r.db).Model(gallery).Where("id = ?", gallery.ID).
 		Updates(
 			map[string]interface{}{
 				"name": gallery.Name,
@@ -404,7 +464,7 @@ func (r *GalleryRepository) PatchName(ctx context.Context, gallery *entity.Galle
 }
 
 func (r *GalleryRepository) PatchCardPosition(ctx context.Context, dto *actions.PatchCardPosition) error {
-	tx := r.db.WithContext(ctx).Begin()
+	tx := conn(ctx, r.db).Begin()
 
 	err := r.UpdateCardsPositionAfterPatch(ctx, dto, tx)
 	if err != nil {
@@ -427,6 +487,18 @@ func (r *GalleryRepository) PatchCardPosition(ctx context.Context, dto *actions.
 	return err
 }
 
+// ReorderCards установка порядка всех карточек галереи в одной транзакции
+func (r *GalleryRepository) ReorderCards(ctx context.Context, galleryID uuid.UUID, cardIDs []uuid.UUID) error {
+	return conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		return reorder(tx, "galleries_cards", "gallery_id", "card_id", galleryID, cardIDs)
+	})
+}
+
+// RepairCardsPositions перенумерация позиций карточек всех галерей
+func (r *GalleryRepository) RepairCardsPositions(ctx context.Context) (int64, error) {
+	return normalizePositions(conn(ctx, r.db), "galleries_cards", "gallery_id", "card_id")
+}
+
 func (r *GalleryRepository) UpdateCardsPositionAfterPatch(
 	ctx context.Context, dto *actions.PatchCardPosition, tx *gorm.DB,
 ) error {
@@ -454,7 +526,7 @@ func (r *GalleryRepository) UpdateCardsPositionAfterPatch(
 func (r *GalleryRepository) CreateGalleriesCards(ctx context.Context, galleriesCards []entity.GalleriesCards) (
 	error, bool,
 ) {
-	err := r.db.WithContext(ctx).Omit(clause.Associations).Create(&galleriesCards).Error
+	err := conn(ctx, r.db).Omit(clause.Associations).Create(&galleriesCards).Error
 
 	if pgErr, ok := err.(*pgconn.PgError); ok {
 		if pgErr.Code == UniqueViolationErr {
@@ -475,7 +547,7 @@ func (r *GalleryRepository) DeleteGalleriesCards(ctx context.Context, galleryID
 		}
 	}
 
-	err := r.db.WithContext(ctx).Clauses(clause.Returning{}).
+	err := conn(ctx, r.db).Clauses(clause.Returning{}).
 		Where("gallery_id = ?", galleryID).
 		Where("card_id in ?", cardIDs).
 		Delete(&galleriesCards).Error
@@ -498,7 +570,7 @@ func (r *GalleryRepository) UpdateCardsPositionAfterDelete(
 	)
 
 	for _, gallery := range galleriesCards {
-		err = r.db.WithContext(ctx).Table("galleries_cards").Where("gallery_id = ?", galleryID).Where(
+		err = conn(ctx, r.db).Table("galleries_cards").Where("gallery_id = ?", galleryID).Where(
 			"position > ?", gallery.Position,
 		).Update("position", gorm.Expr("position - 1")).Error
 	}
@@ -508,30 +580,40 @@ func (r *GalleryRepository) UpdateCardsPositionAfterDelete(
 func (r *GalleryRepository) GetLastPosition(ctx context.Context, galleryID uuid.UUID) (int, error) {
 	var result int
 	var count int64
-	r.db.WithContext(ctx).Table("galleries_cards").Where(
+	conn(ctx, r.db).Table("galleries_cards").Where(
 		"gallery_id = ?", galleryID,
 	).Count(&count)
 	if count == 0 {
 		return 0, nil
 	}
-	err := r.db.WithContext(ctx).Table("galleries_cards").Where(
+	err := conn(ctx, r.db).Table("galleries_cards").Where(
 		"gallery_id = ?", galleryID,
 	).Select("max(position)").Row().Scan(&result)
 
 	return result, err
 }
 
+// DeleteList удаление галерей вместе с их переводами
 func (r *GalleryRepository) DeleteList(ctx context.Context, galleryIds []uuid.UUID) error {
-	err := r.UpdatePositionBeforeDelete(ctx, galleryIds)
+	return NewTransactor(r.db).Transaction(ctx, func(ctx context.Context) error {
+		err := r.UpdatePositionBeforeDelete(ctx, galleryIds)
+		if err != nil {
+			return err
+		}
 
-	err = r.db.WithContext(ctx).Where("id in ?", galleryIds).Delete(entity.Gallery{}).Error
-	return err
+		err = conn(ctx, r.db).Where("id in ?", galleryIds).Delete(entity.Gallery{}).Error
+		if err != nil {
+			return err
+		}
+
+		return deleteTranslations(conn(ctx, r.db), entity.TranslationTypeGallery, galleryIds...)
+	})
 }
 
 func (r *GalleryRepository) UpdatePositionBeforeDelete(ctx context.Context, galleryIds []uuid.UUID) error {
 	var pagesGalleries []entity.PagesGalleries
 
-	err := r.db.WithContext(ctx).Clauses(clause.Returning{}).Where(
+	err := conn(ctx, r.db).Clauses(clause.Returning{}).Where(
 		"gallery_id in ?", galleryIds,
 	).Delete(&pagesGalleries).Error
 
@@ -542,7 +624,7 @@ func (r *GalleryRepository) UpdatePositionBeforeDelete(ctx context.Context, gall
 	)
 
 	for _, galleries := range pagesGalleries {
-		err = r.db.WithContext(ctx).Table("pages_galleries").Where("pages_id = ?", galleries.PagesID).Where(
+		err = conn(ctx, r.db).Table("pages_galleries").Where("pages_id = ?", galleries.PagesID).Where(
 			"position > ?", galleries.Position,
 		).Update("position", gorm.Expr("position - 1")).Error
 	}
@@ -551,7 +633,7 @@ func (r *GalleryRepository) UpdatePositionBeforeDelete(ctx context.Context, gall
 
 func (r *GalleryRepository) CheckLink(ctx context.Context, galleryID uuid.UUID, cardID uuid.UUID) bool {
 	galleriesCard := &entity.GalleriesCards{}
-	err := r.db.WithContext(ctx).Omit(clause.Associations).Model(entity.GalleriesCards{}).
+	err := conn(ctx, r.db).Omit(clause.Associations).Model(entity.GalleriesCards{}).
 		Where("gallery_id = ?", galleryID).
 		Where("card_id = ?", cardID).
 		First(galleriesCard).Error
@@ -0,0 +1,204 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/aeroideaservices/focus/page/plugin/actions"
+	"github.com/aeroideaservices/focus/page/plugin/entity"
+	"github.com/aeroideaservices/focus/page/plugin/services/jobs"
+	"github.com/aeroideaservices/focus/services/errors"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// JobRepository очередь фоновых задач на postgres.
+// Задача берется в работу под блокировкой строки (FOR UPDATE SKIP LOCKED), поэтому
+// несколько экземпляров приложения могут разбирать одну очередь.
+type JobRepository struct {
+	db *gorm.DB
+}
+
+func NewJobRepository(db *gorm.DB) *JobRepository {
+	return &JobRepository{
+		db: db,
+	}
+}
+
+func (r *JobRepository) Enqueue(ctx context.Context, jobs ...entity.Job) error {
+	err := conn(ctx, r.db).Create(&jobs).Error
+	if err != nil {
+		return errors.NoType.Wrap(err, "error creating jobs")
+	}
+
+	return nil
+}
+
+func (r *JobRepository) GetById(ctx context.Context, id uuid.UUID) (*entity.Job, error) {
+	job := &entity.Job{}
+	err := conn(ctx, r.db).Where("id = ?", id).First(job).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.NotFound.Wrapf(err, "job with id %s not found", id)
+	}
+	if err != nil {
+		return nil, errors.NoType.Wrap(err, "error getting job")
+	}
+
+	return job, nil
+}
+
+func (r *JobRepository) GetList(ctx context.Context, filter actions.JobFilter) ([]entity.Job, int64, error) {
+	db := conn(ctx, r.db).Model(&entity.Job{})
+	if filter.Status != "" {
+		db = db.Where("status = ?", filter.Status)
+	}
+	if filter.Type != "" {
+		db = db.Where("type = ?", filter.Type)
+	}
+
+	var total int64
+	err := db.Count(&total).Error
+	if err != nil {
+		return nil, 0, errors.NoType.Wrap(err, "error counting jobs")
+	}
+
+	var list []entity.Job
+	err = db.Order("updated_at DESC").Limit(filter.Limit).Offset(filter.Offset).Find(&list).Error
+	if err != nil {
+		return nil, 0, errors.NoType.Wrap(err, "error getting jobs")
+	}
+
+	return list, total, nil
+}
+
+// Retry повторная постановка в очередь задачи в статусе failed с обнулением счетчика попыток
+func (r *JobRepository) Retry(ctx context.Context, id uuid.UUID) error {
+	res := conn(ctx, r.db).Model(&entity.Job{}).
+		Where("id = ? AND status = ?", id, entity.JobStatusFailed).
+		Updates(map[string]interface{}{
+			"status":       entity.JobStatusQueued,
+			"attempts":     0,
+			"run_at":       time.Now(),
+			"locked_by":    "",
+			"locked_until": nil,
+			"finished_at":  nil,
+		})
+	if res.Error != nil {
+		return errors.NoType.Wrap(res.Error, "error retrying job")
+	}
+	if res.RowsAffected == 0 {
+		return errors.Conflict.Newf("job %s is not failed", id)
+	}
+
+	return nil
+}
+
+// Lease взятие в работу готовой задачи: ожидающей в очереди либо с истекшей арендой.
+// Задачи с истекшей арендой и исчерпанными попытками переводятся в статус failed.
+func (r *JobRepository) Lease(ctx context.Context, worker string, types []string, lease time.Duration) (*entity.Job, error) {
+	var job *entity.Job
+	err := conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		err := tx.Model(&entity.Job{}).
+			Where("status = ? AND locked_until < ? AND attempts >= max_attempts", entity.JobStatusRunning, now).
+			Updates(map[string]interface{}{
+				"status":       entity.JobStatusFailed,
+				"last_error":   "job lease expired",
+				"locked_by":    "",
+				"locked_until": nil,
+				"finished_at":  now,
+			}).Error
+		if err != nil {
+			return err
+		}
+
+		candidate := &entity.Job{}
+		err = tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("type IN ?", types).
+			Where(
+				"(status = ? AND run_at <= ?) OR (status = ? AND locked_until < ?)",
+				entity.JobStatusQueued, now, entity.JobStatusRunning, now,
+			).
+			Order("run_at").
+			First(candidate).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		lockedUntil := now.Add(lease)
+		candidate.Status = entity.JobStatusRunning
+		candidate.Attempts++
+		candidate.LockedBy = worker
+		candidate.LockedUntil = &lockedUntil
+		err = tx.Model(candidate).Select("status", "attempts", "locked_by", "locked_until", "updated_at").
+			Updates(candidate).Error
+		if err != nil {
+			return err
+		}
+		job = candidate
+
+		return nil
+	})
+	if err != nil {
+		return nil, errors.NoType.Wrap(err, "error leasing job")
+	}
+
+	return job, nil
+}
+
+func (r *JobRepository) ExtendLease(ctx context.Context, id uuid.UUID, worker string, lease time.Duration) error {
+	return r.updateLeased(ctx, id, worker, map[string]interface{}{
+		"locked_until": time.Now().Add(lease),
+	})
+}
+
+func (r *JobRepository) Complete(ctx context.Context, id uuid.UUID, worker string, result []byte) error {
+	values := map[string]interface{}{
+		"status":       entity.JobStatusSucceeded,
+		"last_error":   "",
+		"locked_by":    "",
+		"locked_until": nil,
+		"finished_at":  time.Now(),
+	}
+	if result != nil {
+		values["result"] = result
+	}
+
+	return r.updateLeased(ctx, id, worker, values)
+}
+
+func (r *JobRepository) Fail(ctx context.Context, id uuid.UUID, worker string, message string, retryAt *time.Time) error {
+	values := map[string]interface{}{
+		"last_error":   message,
+		"locked_by":    "",
+		"locked_until": nil,
+	}
+	if retryAt != nil {
+		values["status"] = entity.JobStatusQueued
+		values["run_at"] = *retryAt
+	} else {
+		values["status"] = entity.JobStatusFailed
+		values["finished_at"] = time.Now()
+	}
+
+	return r.updateLeased(ctx, id, worker, values)
+}
+
+// updateLeased изменение задачи, которая арендована обработчиком worker
+func (r *JobRepository) updateLeased(ctx context.Context, id uuid.UUID, worker string, values map[string]interface{}) error {
+	res := conn(ctx, r.db).Model(&entity.Job{}).
+		Where("id = ? AND status = ? AND locked_by = ?", id, entity.JobStatusRunning, worker).
+		Updates(values)
+	if res.Error != nil {
+		return errors.NoType.Wrap(res.Error, "error updating job")
+	}
+	if res.RowsAffected == 0 {
+		return jobs.ErrLeaseLost
+	}
+
+	return nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"github.com/aeroideaservices/focus/page/plugin/actions"
 	"github.com/aeroideaservices/focus/page/plugin/entity"
+	"github.com/aeroideaservices/focus/page/plugin/services/cardtypes"
 	"github.com/aeroideaservices/focus/services/errors"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgconn"
@@ -13,12 +14,14 @@ import (
 )
 
 type PageRepository struct {
-	db *gorm.DB
+	db        *gorm.DB
+	cardTypes *cardtypes.Registry
 }
 
-func NewPageRepository(db *gorm.DB) *PageRepository {
+func NewPageRepository(db *gorm.DB, cardTypes *cardtypes.Registry) *PageRepository {
 	return &PageRepository{
-		db: db,
+		db:        db,
+		cardTypes: cardTypes,
 	}
 }
 
@@ -26,7 +29,7 @@ func (r *PageRepository) GetById(ctx context.Context, id uuid.UUID) (
 	*entity.Page, error,
 ) {
 	page := &entity.Page{}
-	db := r.db.WithContext(ctx).Model(entity.Page{}).
+	db := conn(ctx, r.db).Model(entity.Page{}).
 		Preload(
 			"PagesGalleries", func(db *gorm.DB) *gorm.DB {
 				return db.Order("pages_galleries.position ASC")
@@ -59,12 +62,114 @@ func (r *PageRepository) GetById(ctx context.Context, id uuid.UUID) (
 	if err != nil {
 		return nil, err
 	}
+
+	err = loadCustomCards(conn(ctx, r.db), r.cardTypes, pageCards(page))
+	if err != nil {
+		return nil, err
+	}
+	return page, nil
+}
+
+// GetPublishedByCode получение опубликованной страницы с опубликованными галереями и карточками в порядке позиций
+func (r *PageRepository) GetPublishedByCode(ctx context.Context, code string) (*entity.Page, error) {
+	page, err := r.getTree(ctx, true, "code = ?", code)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.NotFound.Wrapf(err, "page with code %s not found", code)
+	}
+
+	return page, err
+}
+
+// GetByCode получение страницы со всеми галереями и карточками в порядке позиций, независимо от публикации
+func (r *PageRepository) GetByCode(ctx context.Context, code string) (*entity.Page, error) {
+	page, err := r.getTree(ctx, false, "code = ?", code)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.NotFound.Wrapf(err, "page with code %s not found", code)
+	}
+
+	return page, err
+}
+
+// GetPublishedByPath получение опубликованной страницы по полному пути
+func (r *PageRepository) GetPublishedByPath(ctx context.Context, path string) (*entity.Page, error) {
+	page, err := r.getTree(ctx, true, pathCondition, path, path)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.NotFound.Wrapf(err, "page with path %s not found", path)
+	}
+
+	return page, err
+}
+
+// GetByPath получение страницы по полному пути, независимо от публикации
+func (r *PageRepository) GetByPath(ctx context.Context, path string) (*entity.Page, error) {
+	page, err := r.getTree(ctx, false, pathCondition, path, path)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.NotFound.Wrapf(err, "page with path %s not found", path)
+	}
+
+	return page, err
+}
+
+// pathCondition условие поиска по пути.
+// У корневых страниц, созданных до появления иерархии, путь не заполнен и совпадает с кодом.
+const pathCondition = "(path = ? OR (path = '' AND parent_id IS NULL AND code = ?))"
+
+func (r *PageRepository) getTree(ctx context.Context, published bool, query string, args ...any) (*entity.Page, error) {
+	galleriesJoin := "JOIN galleries ON galleries.id = pages_galleries.gallery_id"
+	cardsJoin := "JOIN cards ON cards.id = galleries_cards.card_id"
+	if published {
+		galleriesJoin += " AND galleries.is_published IS TRUE"
+		cardsJoin += " AND cards.is_published IS TRUE"
+	}
+
+	page := &entity.Page{}
+	db := conn(ctx, r.db).Model(entity.Page{}).
+		Preload(
+			"PagesGalleries", func(db *gorm.DB) *gorm.DB {
+				return db.Select("pages_galleries.*").Joins(galleriesJoin).Order("pages_galleries.position ASC")
+			},
+		).
+		Preload("PagesGalleries.Gallery").
+		Preload(
+			"PagesGalleries.Gallery.GalleriesCards", func(db *gorm.DB) *gorm.DB {
+				return db.Select("galleries_cards.*").Joins(cardsJoin).Order("galleries_cards.position ASC")
+			},
+		).
+		Preload("PagesGalleries.Gallery.GalleriesCards.Card").
+		Preload("PagesGalleries.Gallery.GalleriesCards.Card.HtmlCard").
+		Preload("PagesGalleries.Gallery.GalleriesCards.Card.VideoCard.Video").
+		Preload("PagesGalleries.Gallery.GalleriesCards.Card.VideoCard.VideoLite").
+		Preload("PagesGalleries.Gallery.GalleriesCards.Card.VideoCard.VideoPreview").
+		Preload("PagesGalleries.Gallery.GalleriesCards.Card.VideoCard.VideoPreviewBlur").
+		Preload("PagesGalleries.Gallery.GalleriesCards.Card.RegularCard.Video").
+		Preload("PagesGalleries.Gallery.GalleriesCards.Card.RegularCard.VideoLite").
+		Preload("PagesGalleries.Gallery.GalleriesCards.Card.RegularCard.VideoPreview").
+		Preload("PagesGalleries.Gallery.GalleriesCards.Card.RegularCard.VideoPreviewBlur").
+		Preload("PagesGalleries.Gallery.GalleriesCards.Card.RegularCard.User.Picture").
+		Preload("PagesGalleries.Gallery.GalleriesCards.Card.RegularCard.RegularCardsTags.Tag").
+		Preload("PagesGalleries.Gallery.GalleriesCards.Card.PhotoCard.Picture").
+		Preload("PagesGalleries.Gallery.GalleriesCards.Card.FormCard.Form").
+		Preload("PagesGalleries.Gallery.GalleriesCards.Card.FormCard.User.Picture").
+		Preload("PagesGalleries.Gallery.GalleriesCards.Card.FormCard.FormCardsTags.Tag").
+		Where(query, args...)
+	if published {
+		db = db.Scopes(pageIsPublished)
+	}
+	err := db.First(page).Error
+	if err != nil {
+		return nil, err
+	}
+
+	err = loadCustomCards(conn(ctx, r.db), r.cardTypes, pageCards(page))
+	if err != nil {
+		return nil, err
+	}
 	return page, nil
 }
 
 func (r *PageRepository) GetByIdWithoutAssociate(ctx context.Context, id uuid.UUID) (*entity.Page, error) {
 	page := &entity.Page{}
-	db := r.db.WithContext(ctx).Omit(clause.Associations).Model(entity.Page{}).Where("id", id)
+	db := conn(ctx, r.db).Omit(clause.Associations).Model(entity.Page{}).Where("id", id)
 	err := db.First(page).Error
 
 	if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -76,11 +181,59 @@ func (r *PageRepository) GetByIdWithoutAssociate(ctx context.Context, id uuid.UU
 	return page, nil
 }
 
+// GetListByPaths получение страниц без связей по полным путям в порядке вложенности
+func (r *PageRepository) GetListByPaths(ctx context.Context, paths []string, allowInactive bool) (
+	[]entity.Page, error,
+) {
+	var pages []entity.Page
+	if len(paths) == 0 {
+		return pages, nil
+	}
+
+	db := conn(ctx, r.db).Omit(clause.Associations).Model(entity.Page{}).
+		Where("(path IN ? OR (path = '' AND parent_id IS NULL AND code IN ?))", paths, paths)
+	if !allowInactive {
+		db = db.Scopes(pageIsPublished)
+	}
+	err := db.Find(&pages).Error
+	if err != nil {
+		return nil, errors.NoType.Wrap(err, "error getting pages by paths")
+	}
+
+	sort.Slice(
+		pages, func(i, j int) bool {
+			return pages[i].Depth() < pages[j].Depth()
+		},
+	)
+	return pages, nil
+}
+
+// GetDescendantPaths полные пути всех вложенных страниц
+func (r *PageRepository) GetDescendantPaths(ctx context.Context, path string) ([]string, error) {
+	var paths []string
+	err := conn(ctx, r.db).Model(entity.Page{}).Scopes(descendantsOf(path)).Pluck("path", &paths).Error
+	if err != nil {
+		return nil, errors.NoType.Wrap(err, "error getting descendant pages")
+	}
+
+	return paths, nil
+}
+
+func (r *PageRepository) HasChildren(ctx context.Context, pageID uuid.UUID) (bool, error) {
+	var count int64
+	err := conn(ctx, r.db).Model(entity.Page{}).Where("parent_id = ?", pageID).Count(&count).Error
+	if err != nil {
+		return false, errors.NoType.Wrap(err, "error counting child pages")
+	}
+
+	return count > 0, nil
+}
+
 func (r *PageRepository) GetList(
 	ctx context.Context, fields []string, allowInactive bool,
 ) (list []actions.PageShort, err error) {
-	//db := r.db.WithContext(ctx).Model(page_entity.PageShort{})
-	db := r.db.WithContext(ctx).Select(fields).Order("pages.sort ASC").Model(entity.Page{})
+	//db := conn(ctx, r.db).Model(page_entity.PageShort{})
+	db := conn(ctx, r.db).Select(fields).Order("pages.sort ASC").Model(entity.Page{})
 
 	if !allowInactive {
 		db = db.Scopes(pageIsPublished)
@@ -92,7 +245,7 @@ func (r *PageRepository) GetList(
 }
 
 //func (r *PageRepository) Create(ctx context.Context, page *entity.Page) (*uuid.UUID, error) {
-//	tx := r.db.WithContext(ctx).Begin()
+//	tx := conn(ctx, r.db).Begin()
 //
 //	if err := tx.Omit(clause.Associations).Create(page).Error; err != nil {
 //		tx.Rollback()
@@ -181,14 +334,14 @@ func (r *PageRepository) GetList(
 //}
 
 func (r *PageRepository) Create(ctx context.Context, page *entity.Page) (*uuid.UUID, error) {
-	tx := r.db.WithContext(ctx).Begin()
+	tx := conn(ctx, r.db).Begin()
 
 	if err := createPage(tx, page); err != nil {
 		tx.Rollback()
 		return nil, err
 	}
 
-	if err := createPagesGalleries(tx, page); err != nil {
+	if err := createPagesGalleries(tx, r.cardTypes, page); err != nil {
 		tx.Rollback()
 		return nil, err
 	}
@@ -200,11 +353,44 @@ func (r *PageRepository) Create(ctx context.Context, page *entity.Page) (*uuid.U
 	return &page.ID, nil
 }
 
+// Clone создание копии страницы в одной транзакции.
+// При deep создаются и вложенные галереи с карточками, иначе копия ссылается на существующие галереи.
+func (r *PageRepository) Clone(ctx context.Context, page *entity.Page, deep bool) error {
+	err := conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := createPage(tx, page); err != nil {
+			return err
+		}
+		if deep {
+			return createPagesGalleries(tx, r.cardTypes, page)
+		}
+		for _, pagesGallery := range page.PagesGalleries {
+			if err := createPagesGallery(tx, &pagesGallery); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return cloneError(err, "page")
+	}
+
+	return nil
+}
+
+// cloneError ошибка создания копии, нарушение уникальности кода возвращается как конфликт
+func cloneError(err error, what string) error {
+	if pgErr, ok := err.(*pgconn.PgError); ok && pgErr.Code == UniqueViolationErr {
+		return errors.Conflict.Wrapf(err, "%s with such code already exists", what)
+	}
+
+	return errors.NoType.Wrapf(err, "error cloning %s", what)
+}
+
 func createPage(tx *gorm.DB, page *entity.Page) error {
 	return tx.Omit(clause.Associations).Create(page).Error
 }
 
-func createPagesGalleries(tx *gorm.DB, page *entity.Page) error {
+func createPagesGalleries(tx *gorm.DB, cardTypes *cardtypes.Registry, page *entity.Page) error {
 	for _, pagesGallery := range page.PagesGalleries {
 		if err := createGallery(tx, &pagesGallery.Gallery); err != nil {
 			return err
@@ -212,7 +398,7 @@ func createPagesGalleries(tx *gorm.DB, page *entity.Page) error {
 		if err := createPagesGallery(tx, &pagesGallery); err != nil {
 			return err
 		}
-		if err := createGalleriesCards(tx, &pagesGallery.Gallery); err != nil {
+		if err := createGalleriesCards(tx, cardTypes, &pagesGallery.Gallery); err != nil {
 			return err
 		}
 	}
@@ -227,9 +413,9 @@ func createPagesGallery(tx *gorm.DB, pagesGallery *entity.PagesGalleries) error
 	return tx.Omit(clause.Associations).Create(pagesGallery).Error
 }
 
-func createGalleriesCards(tx *gorm.DB, gallery *entity.Gallery) error {
+func createGalleriesCards(tx *gorm.DB, cardTypes *cardtypes.Registry, gallery *entity.Gallery) error {
 	for _, galleriesCard := range gallery.GalleriesCards {
-		if err := createCard(tx, galleriesCard.Card); err != nil {
+		if err := createCard(tx, cardTypes, galleriesCard.Card); err != nil {
 			return err
 		}
 		if err := createGalleriesCard(tx, &galleriesCard); err != nil {
@@ -243,7 +429,15 @@ func createGalleriesCard(tx *gorm.DB, galleriesCard *entity.GalleriesCards) erro
 	return tx.Omit(clause.Associations).Create(galleriesCard).Error
 }
 
-func createCard(tx *gorm.DB, card *entity.Card) error {
+func createCard(tx *gorm.DB, cardTypes *cardtypes.Registry, card *entity.Card) error {
+	if err := createCardData(tx, cardTypes, card); err != nil {
+		return err
+	}
+	return tx.Omit(clause.Associations).Create(card).Error
+}
+
+// createCardData создание типизированной части карточки
+func createCardData(tx *gorm.DB, cardTypes *cardtypes.Registry, card *entity.Card) error {
 	switch {
 	case card.RegularCard != nil:
 		if err := createRegularCard(tx, card.RegularCard); err != nil {
@@ -265,11 +459,18 @@ func createCard(tx *gorm.DB, card *entity.Card) error {
 		if err := createFormCard(tx, card.FormCard); err != nil {
 			return err
 		}
+	default:
+		if err := createCustomCard(tx, cardTypes, card); err != nil {
+			return err
+		}
 	}
-	return tx.Omit(clause.Associations).Create(card).Error
+	return nil
 }
 
 func createRegularCard(tx *gorm.DB, regularCard *entity.RegularCard) error {
+	if err := lockUser(tx, regularCard.UserId); err != nil {
+		return err
+	}
 	if err := tx.Omit(clause.Associations).Create(regularCard).Error; err != nil {
 		return err
 	}
@@ -305,6 +506,9 @@ func createPhotoCard(tx *gorm.DB, photoCard *entity.PhotoCard) error {
 }
 
 func createFormCard(tx *gorm.DB, formCard *entity.FormCard) error {
+	if err := lockUser(tx, formCard.UserId); err != nil {
+		return err
+	}
 	if err := tx.Omit(clause.Associations).Create(formCard).Error; err != nil {
 		return err
 	}
@@ -318,21 +522,99 @@ func createFormCard(tx *gorm.DB, formCard *entity.FormCard) error {
 	return nil
 }
 
+// Delete удаление страницы вместе с ее переводами
 func (r *PageRepository) Delete(ctx context.Context, pageId uuid.UUID) error {
-	err := r.db.WithContext(ctx).Where("id = ?", pageId).Delete(entity.Page{}).Error
-	return err
+	return conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("id = ?", pageId).Delete(entity.Page{}).Error
+		if err != nil {
+			return err
+		}
+
+		return deleteTranslations(tx, entity.TranslationTypePage, pageId)
+	})
 }
 
+// PatchProperties обновление заполненных свойств страницы.
+// При изменении пути в той же транзакции пересчитываются пути вложенных страниц.
 func (r *PageRepository) PatchProperties(ctx context.Context, page *entity.Page) error {
-	err := r.db.WithContext(ctx).Model(page).Where("id = ?", page.ID).
-		Updates(
-			page,
-		).Error
+	return conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		var oldPath string
+		if page.Path != "" {
+			current, err := getPagePath(tx, page.ID)
+			if err != nil {
+				return err
+			}
+			oldPath = current.FullPath()
+		}
 
-	return err
+		err := tx.Model(page).Where("id = ?", page.ID).Updates(page).Error
+		if err != nil {
+			return err
+		}
+
+		if oldPath == "" || oldPath == page.Path {
+			return nil
+		}
+		return rewriteDescendantPaths(tx, oldPath, page.Path)
+	})
+}
+
+// Move перенос страницы под другого родителя с пересчетом путей вложенных страниц в одной транзакции
+func (r *PageRepository) Move(ctx context.Context, pageID uuid.UUID, parentID *uuid.UUID, path string) error {
+	return conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		current, err := getPagePath(tx, pageID)
+		if err != nil {
+			return err
+		}
+
+		err = tx.Model(&entity.Page{}).Where("id = ?", pageID).
+			Updates(
+				map[string]interface{}{
+					"parent_id": parentID,
+					"path":      path,
+				},
+			).Error
+		if err != nil {
+			return err
+		}
+
+		return rewriteDescendantPaths(tx, current.FullPath(), path)
+	})
+}
+
+func getPagePath(tx *gorm.DB, pageID uuid.UUID) (*entity.Page, error) {
+	page := &entity.Page{}
+	err := tx.Select("id", "code", "path").Where("id = ?", pageID).First(page).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.NotFound.Wrapf(err, "pages with id %s not found", pageID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return page, nil
+}
+
+// rewriteDescendantPaths замена префикса oldPath на newPath в путях вложенных страниц
+func rewriteDescendantPaths(tx *gorm.DB, oldPath string, newPath string) error {
+	err := tx.Model(&entity.Page{}).Scopes(descendantsOf(oldPath)).
+		Update("path", gorm.Expr("? || substr(path, char_length(?) + 1)", newPath, oldPath)).Error
+	if err != nil {
+		return errors.NoType.Wrap(err, "error updating descendant pages paths")
+	}
+
+	return nil
+}
+
+// descendantsOf страницы, путь которых начинается с пути path и разделителя
+func descendantsOf(path string) func(db *gorm.DB) *gorm.DB {
+	prefix := path + entity.PagePathSeparator
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("LEFT(path, char_length(?)) = ?", prefix, prefix)
+	}
 }
 func (r *PageRepository) UpdatePublish(ctx context.Context, pageId uuid.UUID, publish *bool) error {
-	err := r.db.WithContext(ctx).Model(&entity.Page{}).Where("id = ?", pageId).
+	err := conn(ctx, r.db).Model(&entity.Page{}).Where("id = ?", pageId).
 		Updates(
 			map[string]interface{}{
 				"is_published": publish,
@@ -342,7 +624,7 @@ func (r *PageRepository) UpdatePublish(ctx context.Context, pageId uuid.UUID, pu
 }
 
 func (r *PageRepository) PatchGalleryPosition(ctx context.Context, dto *actions.PatchGalleryPosition) error {
-	tx := r.db.WithContext(ctx).Begin()
+	tx := conn(ctx, r.db).Begin()
 
 	err2 := r.UpdateGalleriesPositionAfterPatch(ctx, dto, tx)
 	if err2 != nil {
@@ -365,6 +647,18 @@ func (r *PageRepository) PatchGalleryPosition(ctx context.Context, dto *actions.
 	return err
 }
 
+// ReorderGalleries установка порядка всех галерей страницы в одной транзакции
+func (r *PageRepository) ReorderGalleries(ctx context.Context, pageID uuid.UUID, galleryIDs []uuid.UUID) error {
+	return conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		return reorder(tx, "pages_galleries", "pages_id", "gallery_id", pageID, galleryIDs)
+	})
+}
+
+// RepairGalleriesPositions перенумерация позиций галерей всех страниц
+func (r *PageRepository) RepairGalleriesPositions(ctx context.Context) (int64, error) {
+	return normalizePositions(conn(ctx, r.db), "pages_galleries", "pages_id", "gallery_id")
+}
+
 func (r *PageRepository) UpdateGalleriesPositionAfterPatch(
 	ctx context.Context, dto *actions.PatchGalleryPosition, tx *gorm.DB,
 ) error {
@@ -398,7 +692,7 @@ func pageIsPublished(db *gorm.DB) *gorm.DB {
 func (r *PageRepository) CreatePagesGalleries(ctx context.Context, pagesGalleries []entity.PagesGalleries) (
 	error, bool,
 ) {
-	err := r.db.WithContext(ctx).Omit(clause.Associations).Create(&pagesGalleries).Error
+	err := conn(ctx, r.db).Omit(clause.Associations).Create(&pagesGalleries).Error
 
 	if pgErr, ok := err.(*pgconn.PgError); ok {
 		if pgErr.Code == UniqueViolationErr {
@@ -418,7 +712,7 @@ func (r *PageRepository) DeletePagesGalleries(ctx context.Context, pageID uuid.U
 	}
 
 	var pagesGalleries []entity.PagesGalleries
-	err := r.db.WithContext(ctx).Clauses(clause.Returning{}).
+	err := conn(ctx, r.db).Clauses(clause.Returning{}).
 		Where("pages_id = ?", pageID).
 		Where("gallery_id in ?", galleryIDs).
 		Delete(&pagesGalleries).Error
@@ -440,7 +734,7 @@ func (r *PageRepository) UpdateGalleriesPositionAfterDelete(
 	)
 
 	for _, gallery := range pagesGalleries {
-		err = r.db.WithContext(ctx).Table("pages_galleries").Where("pages_id = ?", pageID).Where(
+		err = conn(ctx, r.db).Table("pages_galleries").Where("pages_id = ?", pageID).Where(
 			"position > ?", gallery.Position,
 		).Update("position", gorm.Expr("position - 1")).Error
 	}
@@ -450,13 +744,13 @@ func (r *PageRepository) UpdateGalleriesPositionAfterDelete(
 func (r *PageRepository) GetLastPosition(ctx context.Context, pageID uuid.UUID) (int, error) {
 	var result int
 	var count int64
-	r.db.WithContext(ctx).Table("pages_galleries").Where(
+	conn(ctx, r.db).Table("pages_galleries").Where(
 		"pages_id = ?", pageID,
 	).Count(&count)
 	if count == 0 {
 		return 0, nil
 	}
-	err := r.db.WithContext(ctx).Table("pages_galleries").Where(
+	err := conn(ctx, r.db).Table("pages_galleries").Where(
 		"pages_id = ?", pageID,
 	).Select("max(position)").Row().Scan(&result)
 
@@ -469,7 +763,7 @@ func (r *PageRepository) GetLastPosition(ctx context.Context, pageID uuid.UUID)
 
 func (r *PageRepository) CheckLink(ctx context.Context, pageID uuid.UUID, galleryID uuid.UUID) bool {
 	pagesGalleries := &entity.PagesGalleries{}
-	err := r.db.WithContext(ctx).Omit(clause.Associations).Model(entity.PagesGalleries{}).
+	err := conn(ctx, r.db).Omit(clause.Associations).Model(entity.PagesGalleries{}).
 		Where("pages_id = ?", pageID).
 		Where("gallery_id = ?", galleryID).
 		First(pagesGalleries).Error
@@ -0,0 +1,76 @@
+package repositories
+
+import (
+	"fmt"
+
+	"github.com/aeroideaservices/focus/services/errors"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// reorder установка позиций 1..n в порядке ids для связей владельца ownerID в таблице table.
+// Связи блокируются до конца транзакции, ids должны быть перестановкой текущего набора связей,
+// иначе набор изменился параллельно и возвращается конфликт.
+func reorder(tx *gorm.DB, table string, ownerColumn string, itemColumn string, ownerID uuid.UUID, ids []uuid.UUID) error {
+	var current []uuid.UUID
+	err := tx.Table(table).Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where(ownerColumn+" = ?", ownerID).
+		Pluck(itemColumn, &current).Error
+	if err != nil {
+		return errors.NoType.Wrapf(err, "error locking %s", table)
+	}
+
+	if !isPermutation(current, ids) {
+		return errors.Conflict.Newf(
+			"order must contain every linked item exactly once: %d linked, %d given", len(current), len(ids),
+		)
+	}
+
+	for i, id := range ids {
+		err = tx.Table(table).
+			Where(ownerColumn+" = ?", ownerID).
+			Where(itemColumn+" = ?", id).
+			Update("position", i+1).Error
+		if err != nil {
+			return errors.NoType.Wrapf(err, "error updating %s positions", table)
+		}
+	}
+
+	return nil
+}
+
+// normalizePositions перенумерация позиций связей каждого владельца в 1..n с сохранением порядка.
+// Повторы позиций упорядочиваются по идентификатору, возвращается количество исправленных связей.
+func normalizePositions(tx *gorm.DB, table string, ownerColumn string, itemColumn string) (int64, error) {
+	result := tx.Exec(fmt.Sprintf(
+		`UPDATE %[1]s AS t SET position = o.rn
+		FROM (SELECT %[2]s, %[3]s, row_number() OVER (PARTITION BY %[2]s ORDER BY position, %[3]s) AS rn FROM %[1]s) AS o
+		WHERE t.%[2]s = o.%[2]s AND t.%[3]s = o.%[3]s AND t.position IS DISTINCT FROM o.rn`,
+		table, ownerColumn, itemColumn,
+	))
+	if result.Error != nil {
+		return 0, errors.NoType.Wrapf(result.Error, "error normalizing %s positions", table)
+	}
+
+	return result.RowsAffected, nil
+}
+
+func isPermutation(current []uuid.UUID, ids []uuid.UUID) bool {
+	if len(current) != len(ids) {
+		return false
+	}
+
+	counts := make(map[uuid.UUID]int, len(current))
+	for _, id := range current {
+		counts[id]++
+	}
+	for _, id := range ids {
+		if counts[id] == 0 {
+			return false
+		}
+		counts[id]--
+	}
+
+	return true
+}
@@ -0,0 +1,75 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/aeroideaservices/focus/page/plugin/entity"
+	"github.com/aeroideaservices/focus/services/errors"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type PreviewTokenRepository struct {
+	db *gorm.DB
+}
+
+func NewPreviewTokenRepository(db *gorm.DB) *PreviewTokenRepository {
+	return &PreviewTokenRepository{
+		db: db,
+	}
+}
+
+func (r *PreviewTokenRepository) Create(ctx context.Context, token *entity.PreviewToken) error {
+	err := conn(ctx, r.db).Create(token).Error
+	if err != nil {
+		return errors.NoType.Wrap(err, "error creating preview token")
+	}
+
+	return nil
+}
+
+func (r *PreviewTokenRepository) GetById(ctx context.Context, id uuid.UUID) (*entity.PreviewToken, error) {
+	token := &entity.PreviewToken{}
+	err := conn(ctx, r.db).Where("id = ?", id).First(token).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.NotFound.Wrapf(err, "preview token with id %s not found", id)
+	}
+	if err != nil {
+		return nil, errors.NoType.Wrap(err, "error getting preview token")
+	}
+
+	return token, nil
+}
+
+func (r *PreviewTokenRepository) GetList(
+	ctx context.Context, entityType entity.DraftType, entityId uuid.UUID,
+) ([]entity.PreviewToken, error) {
+	var list []entity.PreviewToken
+	err := conn(ctx, r.db).
+		Where("entity_type = ? AND entity_id = ?", entityType, entityId).
+		Order("created_at DESC").
+		Find(&list).Error
+	if err != nil {
+		return nil, errors.NoType.Wrap(err, "error getting preview tokens")
+	}
+
+	return list, nil
+}
+
+func (r *PreviewTokenRepository) Revoke(ctx context.Context, id uuid.UUID, revokedBy string) error {
+	res := conn(ctx, r.db).Model(&entity.PreviewToken{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Updates(map[string]interface{}{
+			"revoked_at": time.Now(),
+			"revoked_by": revokedBy,
+		})
+	if res.Error != nil {
+		return errors.NoType.Wrap(res.Error, "error revoking preview token")
+	}
+	if res.RowsAffected == 0 {
+		return errors.NotFound.Newf("active preview token with id %s not found", id)
+	}
+
+	return nil
+}
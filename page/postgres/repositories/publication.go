@@ -0,0 +1,159 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/aeroideaservices/focus/page/plugin/actions"
+	"github.com/aeroideaservices/focus/page/plugin/entity"
+	"github.com/aeroideaservices/focus/services/errors"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// publicationTypes типы сущностей, поддерживающих черновики и отложенную публикацию
+var publicationTypes = []entity.DraftType{entity.DraftTypePage, entity.DraftTypeGallery, entity.DraftTypeCard}
+
+type PublicationRepository struct {
+	db *gorm.DB
+}
+
+func NewPublicationRepository(db *gorm.DB) *PublicationRepository {
+	return &PublicationRepository{
+		db: db,
+	}
+}
+
+func (r *PublicationRepository) GetDraft(ctx context.Context, entityType entity.DraftType, id uuid.UUID) (
+	*entity.Draft, error,
+) {
+	draft := &entity.Draft{}
+	err := conn(ctx, r.db).
+		Where("entity_type = ? AND entity_id = ?", entityType, id).
+		First(draft).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.NotFound.Wrapf(err, "draft of %s with id %s not found", entityType, id)
+	}
+	if err != nil {
+		return nil, errors.NoType.Wrap(err, "error getting draft")
+	}
+
+	return draft, nil
+}
+
+func (r *PublicationRepository) SaveDraft(ctx context.Context, draft *entity.Draft) error {
+	err := conn(ctx, r.db).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "entity_type"}, {Name: "entity_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"data", "updated_at"}),
+		}).
+		Create(draft).Error
+	if err != nil {
+		return errors.NoType.Wrap(err, "error saving draft")
+	}
+
+	return nil
+}
+
+func (r *PublicationRepository) DeleteDraft(ctx context.Context, entityType entity.DraftType, id uuid.UUID) error {
+	err := conn(ctx, r.db).
+		Where("entity_type = ? AND entity_id = ?", entityType, id).
+		Delete(&entity.Draft{}).Error
+	if err != nil {
+		return errors.NoType.Wrap(err, "error deleting draft")
+	}
+
+	return nil
+}
+
+// UpdatePublish изменение статуса публикации со сбросом выполненного запланированного действия
+func (r *PublicationRepository) UpdatePublish(
+	ctx context.Context, entityType entity.DraftType, id uuid.UUID, publish bool,
+) error {
+	values := map[string]interface{}{"is_published": publish}
+	if publish {
+		values["publish_at"] = nil
+	} else {
+		values["unpublish_at"] = nil
+	}
+
+	return r.update(ctx, entityType, id, values)
+}
+
+func (r *PublicationRepository) Schedule(
+	ctx context.Context, entityType entity.DraftType, id uuid.UUID, publishAt, unpublishAt *time.Time,
+) error {
+	return r.update(ctx, entityType, id, map[string]interface{}{
+		"publish_at":   publishAt,
+		"unpublish_at": unpublishAt,
+	})
+}
+
+// GetScheduled получение запланированных действий, время которых наступило.
+// Если наступило время и публикации, и снятия с публикации, публикация возвращается первой.
+func (r *PublicationRepository) GetScheduled(ctx context.Context, now time.Time) ([]actions.ScheduledPublication, error) {
+	var scheduled []actions.ScheduledPublication
+	for _, entityType := range publicationTypes {
+		model, err := publicationModel(entityType)
+		if err != nil {
+			return nil, err
+		}
+
+		var rows []struct {
+			ID          uuid.UUID
+			PublishAt   *time.Time
+			UnpublishAt *time.Time
+		}
+		err = conn(ctx, r.db).Model(model).
+			Select("id", "publish_at", "unpublish_at").
+			Where("publish_at <= ? OR unpublish_at <= ?", now, now).
+			Scan(&rows).Error
+		if err != nil {
+			return nil, errors.NoType.Wrapf(err, "error getting scheduled %s publications", entityType)
+		}
+
+		for _, row := range rows {
+			if row.PublishAt != nil && !row.PublishAt.After(now) {
+				scheduled = append(scheduled, actions.ScheduledPublication{EntityType: entityType, ID: row.ID, Publish: true})
+			}
+			if row.UnpublishAt != nil && !row.UnpublishAt.After(now) {
+				scheduled = append(scheduled, actions.ScheduledPublication{EntityType: entityType, ID: row.ID})
+			}
+		}
+	}
+
+	return scheduled, nil
+}
+
+func (r *PublicationRepository) update(
+	ctx context.Context, entityType entity.DraftType, id uuid.UUID, values map[string]interface{},
+) error {
+	model, err := publicationModel(entityType)
+	if err != nil {
+		return err
+	}
+
+	res := conn(ctx, r.db).Model(model).Where("id = ?", id).Updates(values)
+	if res.Error != nil {
+		return errors.NoType.Wrapf(res.Error, "error updating %s publication", entityType)
+	}
+	if res.RowsAffected == 0 {
+		return errors.NotFound.Newf("%s with id %s not found", entityType, id)
+	}
+
+	return nil
+}
+
+func publicationModel(entityType entity.DraftType) (interface{}, error) {
+	switch entityType {
+	case entity.DraftTypePage:
+		return &entity.Page{}, nil
+	case entity.DraftTypeGallery:
+		return &entity.Gallery{}, nil
+	case entity.DraftTypeCard:
+		return &entity.Card{}, nil
+	}
+
+	return nil, errors.BadRequest.Newf("unknown entity type %s", entityType)
+}
@@ -0,0 +1,204 @@
+package repositories
+
+import (
+	"context"
+	"strings"
+
+	"github.com/aeroideaservices/focus/page/plugin/actions"
+	"github.com/aeroideaservices/focus/services/errors"
+	"gorm.io/gorm"
+)
+
+// SearchSchema столбцы search_vector с текстом сущностей для поиска и GIN индексы по ним.
+// Миграций в модуле нет, поэтому DDL выполняется приложением в своей миграции до первого поиска.
+// Заголовок входит в вектор с весом A, остальной текст - с весом B, из html карточек удаляется разметка.
+const SearchSchema = `
+ALTER TABLE pages ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+	setweight(to_tsvector('russian', COALESCE(NULLIF(title, ''), name, '')), 'A') ||
+	setweight(to_tsvector('english', COALESCE(NULLIF(title, ''), name, '')), 'A') ||
+	setweight(to_tsvector('russian', COALESCE(name, '') || ' ' || COALESCE(title, '') || ' ' ||
+		COALESCE(description, '') || ' ' || COALESCE(title_seo, '') || ' ' || COALESCE(description_seo, '') || ' ' ||
+		COALESCE(keywords, '')), 'B') ||
+	setweight(to_tsvector('english', COALESCE(name, '') || ' ' || COALESCE(title, '') || ' ' ||
+		COALESCE(description, '') || ' ' || COALESCE(title_seo, '') || ' ' || COALESCE(description_seo, '') || ' ' ||
+		COALESCE(keywords, '')), 'B')
+) STORED;
+CREATE INDEX IF NOT EXISTS pages_search_vector_idx ON pages USING GIN (search_vector);
+
+ALTER TABLE galleries ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+	setweight(to_tsvector('russian', COALESCE(name, '')), 'A') ||
+	setweight(to_tsvector('english', COALESCE(name, '')), 'A') ||
+	setweight(to_tsvector('russian', COALESCE(name, '') || ' ' || COALESCE(code, '')), 'B') ||
+	setweight(to_tsvector('english', COALESCE(name, '') || ' ' || COALESCE(code, '')), 'B')
+) STORED;
+CREATE INDEX IF NOT EXISTS galleries_search_vector_idx ON galleries USING GIN (search_vector);
+
+ALTER TABLE cards ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+	setweight(to_tsvector('russian', COALESCE(NULLIF(title, ''), name, '')), 'A') ||
+	setweight(to_tsvector('english', COALESCE(NULLIF(title, ''), name, '')), 'A') ||
+	setweight(to_tsvector('russian',
+		COALESCE(name, '') || ' ' || COALESCE(title, '') || ' ' || COALESCE(description, '')), 'B') ||
+	setweight(to_tsvector('english',
+		COALESCE(name, '') || ' ' || COALESCE(title, '') || ' ' || COALESCE(description, '')), 'B')
+) STORED;
+CREATE INDEX IF NOT EXISTS cards_search_vector_idx ON cards USING GIN (search_vector);
+
+ALTER TABLE regular_cards ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+	setweight(to_tsvector('russian', COALESCE(preview_text, '') || ' ' || COALESCE(detail_text, '')), 'B') ||
+	setweight(to_tsvector('english', COALESCE(preview_text, '') || ' ' || COALESCE(detail_text, '')), 'B')
+) STORED;
+CREATE INDEX IF NOT EXISTS regular_cards_search_vector_idx ON regular_cards USING GIN (search_vector);
+
+ALTER TABLE html_cards ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+	setweight(to_tsvector('russian', regexp_replace(COALESCE(html, ''), '<[^>]*>', ' ', 'g')), 'B') ||
+	setweight(to_tsvector('english', regexp_replace(COALESCE(html, ''), '<[^>]*>', ' ', 'g')), 'B')
+) STORED;
+CREATE INDEX IF NOT EXISTS html_cards_search_vector_idx ON html_cards USING GIN (search_vector);
+
+ALTER TABLE tags ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+	setweight(to_tsvector('russian', COALESCE(text, '')), 'A') ||
+	setweight(to_tsvector('english', COALESCE(text, '')), 'A') ||
+	setweight(to_tsvector('russian', COALESCE(text, '') || ' ' || COALESCE(link, '')), 'B') ||
+	setweight(to_tsvector('english', COALESCE(text, '') || ' ' || COALESCE(link, '')), 'B')
+) STORED;
+CREATE INDEX IF NOT EXISTS tags_search_vector_idx ON tags USING GIN (search_vector);
+`
+
+// searchDocuments запросы найденных сущностей по типам: заголовок, код, весь текст сущности для фрагментов
+// и вектор для ранжирования. Отбор идет по индексированным столбцам search_vector (см. SearchSchema),
+// запрос поиска берется из q. Данные карточек зарегистрированных приложением типов не ищутся.
+var searchDocuments = map[string]string{
+	actions.SearchTypePage: `SELECT 'page' AS entity_type, p.id, p.path AS code, p.is_published,
+		COALESCE(NULLIF(p.title, ''), p.name) AS title,
+		concat_ws(' ', p.name, p.title, p.description, p.title_seo, p.description_seo, p.keywords) AS body,
+		p.search_vector
+		FROM pages p
+		WHERE p.search_vector @@ (SELECT query FROM q)`,
+	actions.SearchTypeGallery: `SELECT 'gallery' AS entity_type, g.id, g.code, g.is_published, g.name AS title,
+		concat_ws(' ', g.name, g.code) AS body, g.search_vector
+		FROM galleries g
+		WHERE g.search_vector @@ (SELECT query FROM q)`,
+	actions.SearchTypeCard: `SELECT 'card' AS entity_type, c.id, c.code, c.is_published,
+		COALESCE(NULLIF(c.title, ''), c.name) AS title,
+		concat_ws(' ', c.name, c.title, c.description, rc.preview_text, rc.detail_text,
+			regexp_replace(hc.html, '<[^>]*>', ' ', 'g')) AS body,
+		c.search_vector || COALESCE(rc.search_vector, ''::tsvector) || COALESCE(hc.search_vector, ''::tsvector)
+			AS search_vector
+		FROM cards c
+		LEFT JOIN regular_cards rc ON rc.id = c.regular_card_id
+		LEFT JOIN html_cards hc ON hc.id = c.html_card_id
+		WHERE c.id IN (
+			SELECT id FROM cards WHERE search_vector @@ (SELECT query FROM q)
+			UNION SELECT cr.id FROM cards cr JOIN regular_cards r ON r.id = cr.regular_card_id
+				WHERE r.search_vector @@ (SELECT query FROM q)
+			UNION SELECT ch.id FROM cards ch JOIN html_cards h ON h.id = ch.html_card_id
+				WHERE h.search_vector @@ (SELECT query FROM q)
+		)`,
+	actions.SearchTypeTag: `SELECT 'tag' AS entity_type, t.id, t.link AS code, true AS is_published, t.text AS title,
+		concat_ws(' ', t.text, t.link) AS body, t.search_vector
+		FROM tags t
+		WHERE t.search_vector @@ (SELECT query FROM q)`,
+}
+
+// searchTypes порядок типов в запросе
+var searchTypes = []string{
+	actions.SearchTypePage, actions.SearchTypeGallery, actions.SearchTypeCard, actions.SearchTypeTag,
+}
+
+// searchQueryCTE запрос поиска по текстам на русском и английском
+const searchQueryCTE = `q AS (
+		SELECT websearch_to_tsquery('russian', @query) || websearch_to_tsquery('english', @query) AS query
+	)`
+
+// searchQuery поиск по индексированным векторам, заголовок весит больше остального текста.
+// Фрагменты выделяются по экранированному тексту, чтобы в них не попала разметка из содержимого.
+const searchQuery = `WITH ` + searchQueryCTE + `,
+	docs AS (%s),
+	matched AS (
+		SELECT docs.*, ts_rank_cd(docs.search_vector, q.query) AS rank
+		FROM docs, q
+	)
+	SELECT matched.entity_type, matched.id, matched.code, matched.title, matched.rank, matched.is_published,
+		ts_headline(
+			'russian',
+			replace(replace(replace(matched.body, '&', '&amp;'), '<', '&lt;'), '>', '&gt;'),
+			q.query,
+			'StartSel=<mark>, StopSel=</mark>, MaxWords=30, MinWords=10, MaxFragments=2'
+		) AS highlight,
+		count(*) OVER () AS total
+	FROM matched, q
+	ORDER BY matched.rank DESC, matched.entity_type, matched.id
+	LIMIT @limit OFFSET @offset`
+
+type SearchRepository struct {
+	db *gorm.DB
+}
+
+func NewSearchRepository(db *gorm.DB) *SearchRepository {
+	return &SearchRepository{
+		db: db,
+	}
+}
+
+// Search полнотекстовый поиск по сущностям типов dto.Types, без типов - по всем
+func (r *SearchRepository) Search(ctx context.Context, dto actions.SearchRequest) (
+	[]actions.SearchResult, int64, error,
+) {
+	types := dto.Types
+	if len(types) == 0 {
+		types = searchTypes
+	}
+
+	documents := make([]string, 0, len(types))
+	for _, entityType := range searchTypes {
+		for _, requested := range types {
+			if requested == entityType {
+				documents = append(documents, searchDocuments[entityType])
+			}
+		}
+	}
+	if len(documents) == 0 {
+		return nil, 0, errors.BadRequest.New("unknown search types")
+	}
+
+	var rows []struct {
+		actions.SearchResult
+		Total int64
+	}
+	err := conn(ctx, r.db).Raw(
+		strings.Replace(searchQuery, "%s", strings.Join(documents, "\n\t\tUNION ALL\n\t\t"), 1),
+		map[string]interface{}{"query": dto.Query, "limit": dto.Limit, "offset": dto.Offset},
+	).Scan(&rows).Error
+	if err != nil {
+		return nil, 0, errors.NoType.Wrap(err, "error searching content")
+	}
+
+	var total int64
+	results := make([]actions.SearchResult, len(rows))
+	for i, row := range rows {
+		results[i], total = row.SearchResult, row.Total
+	}
+	if len(rows) == 0 && dto.Offset > 0 {
+		// за пределами выборки количество не вычисляется окном, поэтому запрашивается отдельно
+		total, err = r.count(ctx, documents, dto.Query)
+		if err != nil {
+			return nil, 0, err
+		}
+	}
+
+	return results, total, nil
+}
+
+func (r *SearchRepository) count(ctx context.Context, documents []string, query string) (int64, error) {
+	var total int64
+	err := conn(ctx, r.db).Raw(
+		`WITH `+searchQueryCTE+`
+		SELECT count(*) FROM (`+strings.Join(documents, " UNION ALL ")+`) AS docs`,
+		map[string]interface{}{"query": query},
+	).Scan(&total).Error
+	if err != nil {
+		return 0, errors.NoType.Wrap(err, "error counting search results")
+	}
+
+	return total, nil
+}
@@ -0,0 +1,84 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/aeroideaservices/focus/page/plugin/entity"
+	"github.com/aeroideaservices/focus/services/errors"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type SnapshotRepository struct {
+	db *gorm.DB
+}
+
+func NewSnapshotRepository(db *gorm.DB) *SnapshotRepository {
+	return &SnapshotRepository{
+		db: db,
+	}
+}
+
+func (r *SnapshotRepository) Create(ctx context.Context, snapshot *entity.Snapshot) error {
+	err := conn(ctx, r.db).Create(snapshot).Error
+	if err != nil {
+		return errors.NoType.Wrap(err, "error creating page snapshot")
+	}
+
+	return nil
+}
+
+// GetList снимки страницы без данных, начиная с последнего
+func (r *SnapshotRepository) GetList(ctx context.Context, pageID uuid.UUID, limit int, offset int) (
+	[]entity.Snapshot, int64, error,
+) {
+	db := conn(ctx, r.db).Model(&entity.Snapshot{}).Where("page_id = ?", pageID)
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, errors.NoType.Wrap(err, "error counting page snapshots")
+	}
+
+	var snapshots []entity.Snapshot
+	err := db.Omit("data").Order("created_at DESC, id").Limit(limit).Offset(offset).Find(&snapshots).Error
+	if err != nil {
+		return nil, 0, errors.NoType.Wrap(err, "error getting page snapshots")
+	}
+
+	return snapshots, total, nil
+}
+
+func (r *SnapshotRepository) GetById(ctx context.Context, pageID uuid.UUID, id uuid.UUID) (*entity.Snapshot, error) {
+	snapshot := &entity.Snapshot{}
+	err := conn(ctx, r.db).Where("id = ? AND page_id = ?", id, pageID).First(snapshot).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.NotFound.Wrapf(err, "page snapshot with id %s not found", id)
+	}
+	if err != nil {
+		return nil, errors.NoType.Wrap(err, "error getting page snapshot")
+	}
+
+	return snapshot, nil
+}
+
+// GetPageIds страницы, на которых выводится галерея или карточка
+func (r *SnapshotRepository) GetPageIds(ctx context.Context, entityType entity.DraftType, id uuid.UUID) (
+	[]uuid.UUID, error,
+) {
+	db := conn(ctx, r.db).Table("pages_galleries pg").Distinct("pg.pages_id")
+	switch entityType {
+	case entity.DraftTypeGallery:
+		db = db.Where("pg.gallery_id = ?", id)
+	case entity.DraftTypeCard:
+		db = db.Joins("JOIN galleries_cards gc ON gc.gallery_id = pg.gallery_id").Where("gc.card_id = ?", id)
+	default:
+		return nil, errors.BadRequest.Newf("unknown entity type %s", entityType)
+	}
+
+	var pageIds []uuid.UUID
+	if err := db.Pluck("pg.pages_id", &pageIds).Error; err != nil {
+		return nil, errors.NoType.Wrap(err, "error getting pages of entity")
+	}
+
+	return pageIds, nil
+}
@@ -2,10 +2,15 @@ package repositories
 
 import (
 	"context"
+	"fmt"
+	"strings"
+
+	"github.com/aeroideaservices/focus/page/plugin/actions"
 	"github.com/aeroideaservices/focus/page/plugin/entity"
 	"github.com/aeroideaservices/focus/services/errors"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type TagRepository struct {
@@ -19,7 +24,7 @@ func NewTagRepository(db *gorm.DB) *TagRepository {
 }
 
 func (r *TagRepository) UpdateIsDetailLink(ctx context.Context, tagID uuid.UUID, value *bool) error {
-	err := r.db.WithContext(ctx).Model(&entity.Tag{}).Where("id = ?", tagID).
+	err := conn(ctx, r.db).Model(&entity.Tag{}).Where("id = ?", tagID).
 		Updates(
 			map[string]interface{}{
 				"is_primary": *value,
@@ -33,7 +38,7 @@ func (r *TagRepository) GetListWithSearch(ctx context.Context, searchValue strin
 ) {
 	var tags []entity.Tag
 
-	db := r.db.WithContext(ctx).Model(entity.Tag{}).
+	db := conn(ctx, r.db).Model(entity.Tag{}).
 		Where("lower(text) LIKE lower(?)", "%"+searchValue+"%")
 	err := db.Find(&tags).Error
 
@@ -60,7 +65,7 @@ func (r *TagRepository) GetListWithSearch(ctx context.Context, searchValue strin
 func (r *TagRepository) GetById(ctx context.Context, tagId uuid.UUID) (*entity.Tag, error) {
 	tag := &entity.Tag{}
 
-	db := r.db.WithContext(ctx).Model(entity.Tag{}).Where("id", tagId)
+	db := conn(ctx, r.db).Model(entity.Tag{}).Where("id", tagId)
 
 	err := db.First(tag).Error
 
@@ -79,7 +84,7 @@ func (r *TagRepository) GetCardIdsByTagId(ctx context.Context, tagId uuid.UUID)
 	var formCardIds []uuid.UUID
 	var cardIds []uuid.UUID
 
-	err := r.db.WithContext(ctx).Select("regular_card_id").Model(entity.RegularCardsTags{}).Where(
+	err := conn(ctx, r.db).Select("regular_card_id").Model(entity.RegularCardsTags{}).Where(
 		"tag_id = ?", tagId,
 	).Scan(&regularCardIds).Error
 
@@ -87,7 +92,7 @@ func (r *TagRepository) GetCardIdsByTagId(ctx context.Context, tagId uuid.UUID)
 		return nil, err
 	}
 
-	err = r.db.WithContext(ctx).Select("form_card_id").Model(entity.FormCardsTags{}).Where(
+	err = conn(ctx, r.db).Select("form_card_id").Model(entity.FormCardsTags{}).Where(
 		"tag_id = ?", tagId,
 	).Scan(&formCardIds).Error
 
@@ -95,7 +100,7 @@ func (r *TagRepository) GetCardIdsByTagId(ctx context.Context, tagId uuid.UUID)
 		return nil, err
 	}
 
-	err = r.db.WithContext(ctx).Select("id").Model(entity.Card{}).Where(
+	err = conn(ctx, r.db).Select("id").Model(entity.Card{}).Where(
 		"regular_card_id in ?", regularCardIds,
 	).Or("form_card_id in ?", formCardIds).Scan(&cardIds).Error
 
@@ -106,16 +111,238 @@ func (r *TagRepository) GetCardIdsByTagId(ctx context.Context, tagId uuid.UUID)
 }
 
 func (r *TagRepository) Create(ctx context.Context, tag *entity.Tag) (*uuid.UUID, error) {
-	err := r.db.WithContext(ctx).Create(tag).Error
+	err := conn(ctx, r.db).Create(tag).Error
 	return &tag.ID, err
 }
 
 func (r *TagRepository) Update(ctx context.Context, tag *entity.Tag) error {
-	err := r.db.WithContext(ctx).Updates(tag).Error
+	err := conn(ctx, r.db).Updates(tag).Error
 	return err
 }
 
+// Delete удаление тега вместе с его переводами
 func (r *TagRepository) Delete(ctx context.Context, tagId uuid.UUID) error {
-	err := r.db.WithContext(ctx).Where("id = ?", tagId).Delete(entity.Tag{}).Error
-	return err
+	return conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("id = ?", tagId).Delete(entity.Tag{}).Error
+		if err != nil {
+			return err
+		}
+
+		return deleteTranslations(tx, entity.TranslationTypeTag, tagId)
+	})
+}
+
+// tagsSort сортировки списка тегов, повторы упорядочиваются по идентификатору
+var tagsSort = map[string]string{
+	"":       "lower(tags.text), tags.id",
+	"text":   "lower(tags.text), tags.id",
+	"-text":  "lower(tags.text) DESC, tags.id",
+	"usage":  "COALESCE(r.cards, 0) + COALESCE(f.cards, 0), lower(tags.text), tags.id",
+	"-usage": "COALESCE(r.cards, 0) + COALESCE(f.cards, 0) DESC, lower(tags.text), tags.id",
+}
+
+// GetListWithUsage теги с количеством карточек, в которых они указаны
+func (r *TagRepository) GetListWithUsage(ctx context.Context, filter actions.TagFilter) (
+	[]actions.TagUsage, int64, error,
+) {
+	db := conn(ctx, r.db).Table("tags")
+	if query := strings.TrimSpace(filter.Query); query != "" {
+		db = db.Where("lower(tags.text) LIKE lower(?) OR lower(tags.link) LIKE lower(?)", "%"+query+"%", "%"+query+"%")
+	}
+
+	var total int64
+	err := db.Count(&total).Error
+	if err != nil {
+		return nil, 0, errors.NoType.Wrap(err, "error counting tags")
+	}
+
+	order, ok := tagsSort[filter.Sort]
+	if !ok {
+		return nil, 0, errors.BadRequest.Newf("unknown tags sort %s", filter.Sort)
+	}
+
+	var list []actions.TagUsage
+	err = tagUsage(db).Order(order).Limit(filter.Limit).Offset(filter.Offset).Scan(&list).Error
+	if err != nil {
+		return nil, 0, errors.NoType.Wrap(err, "error getting tags")
+	}
+
+	return list, total, nil
+}
+
+// GetUsage тег с количеством карточек, в которых он указан
+func (r *TagRepository) GetUsage(ctx context.Context, tagId uuid.UUID) (*actions.TagUsage, error) {
+	var list []actions.TagUsage
+	err := tagUsage(conn(ctx, r.db).Table("tags")).Where("tags.id = ?", tagId).Scan(&list).Error
+	if err != nil {
+		return nil, errors.NoType.Wrap(err, "error getting tag")
+	}
+	if len(list) == 0 {
+		return nil, errors.NotFound.Newf("tag with id %s not found", tagId)
+	}
+
+	return &list[0], nil
+}
+
+func tagUsage(db *gorm.DB) *gorm.DB {
+	return db.
+		Select(
+			"tags.id, tags.text, tags.link, " +
+				"COALESCE(r.cards, 0) AS regular_cards, COALESCE(f.cards, 0) AS form_cards",
+		).
+		Joins(
+			"LEFT JOIN (SELECT tag_id, count(*) AS cards FROM regular_cards_tags GROUP BY tag_id) AS r " +
+				"ON r.tag_id = tags.id",
+		).
+		Joins(
+			"LEFT JOIN (SELECT tag_id, count(*) AS cards FROM form_cards_tags GROUP BY tag_id) AS f " +
+				"ON f.tag_id = tags.id",
+		)
+}
+
+// Merge объединение тегов в одной транзакции, возвращает количество карточек, привязанных к тегу dto.TargetID.
+// Связи карточек, уже привязанных к тегу dto.TargetID, не дублируются.
+func (r *TagRepository) Merge(ctx context.Context, dto actions.MergeTagsRequest) (int64, error) {
+	var linked int64
+	err := conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		var ids []uuid.UUID
+		err := tx.Model(&entity.Tag{}).Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id IN ?", append([]uuid.UUID{dto.TargetID}, dto.SourceIDs...)).
+			Pluck("id", &ids).Error
+		if err != nil {
+			return err
+		}
+		if len(ids) != len(dto.SourceIDs)+1 {
+			return errors.NotFound.New("one of tags not found")
+		}
+
+		for _, link := range []struct{ table, column string }{
+			{"regular_cards_tags", "regular_card_id"},
+			{"form_cards_tags", "form_card_id"},
+		} {
+			res := tx.Exec(fmt.Sprintf(
+				`INSERT INTO %[1]s (%[2]s, tag_id) SELECT DISTINCT %[2]s, ? FROM %[1]s WHERE tag_id IN ?
+				ON CONFLICT DO NOTHING`,
+				link.table, link.column,
+			), dto.TargetID, dto.SourceIDs)
+			if res.Error != nil {
+				return res.Error
+			}
+			linked += res.RowsAffected
+
+			err = tx.Exec("DELETE FROM "+link.table+" WHERE tag_id IN ?", dto.SourceIDs).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		if err = mergeDraftTags(tx, dto.SourceIDs, dto.TargetID); err != nil {
+			return err
+		}
+		if err = mergeTagTranslations(tx, dto.SourceIDs, dto.TargetID); err != nil {
+			return err
+		}
+
+		updates := map[string]interface{}{}
+		if dto.Text != nil {
+			updates["text"] = *dto.Text
+		}
+		if dto.Link != nil {
+			updates["link"] = *dto.Link
+		}
+		if len(updates) > 0 {
+			err = tx.Model(&entity.Tag{}).Where("id = ?", dto.TargetID).Updates(updates).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		err = tx.Where("id IN ?", dto.SourceIDs).Delete(&entity.Tag{}).Error
+		if err != nil {
+			return err
+		}
+
+		return deleteTranslations(tx, entity.TranslationTypeTag, dto.SourceIDs...)
+	})
+	if err != nil && errors.GetType(err) == errors.NoType {
+		return 0, errors.NoType.Wrap(err, "error merging tags")
+	}
+
+	return linked, err
+}
+
+// mergeDraftTags замена тегов sources на тег target в черновиках карточек
+func mergeDraftTags(tx *gorm.DB, sources []uuid.UUID, target uuid.UUID) error {
+	patterns := make([]string, len(sources))
+	sourcesSet := make(map[uuid.UUID]bool, len(sources))
+	for i, source := range sources {
+		patterns[i] = "%" + source.String() + "%"
+		sourcesSet[source] = true
+	}
+
+	// без WithoutParentheses gorm подставляет срез как запись ('%a%','%b%'), а не как элементы массива
+	likeAny := clause.Expr{
+		SQL: "data::text LIKE ANY (ARRAY[?])", Vars: []interface{}{patterns}, WithoutParentheses: true,
+	}
+
+	var drafts []entity.Draft
+	err := tx.Where("entity_type = ?", entity.DraftTypeCard).
+		Where(likeAny).
+		Find(&drafts).Error
+	if err != nil {
+		return err
+	}
+
+	for _, draft := range drafts {
+		replaced, err := draft.ReplaceTags(sourcesSet, target)
+		if err != nil {
+			return errors.NoType.Wrapf(err, "error decoding card %s draft", draft.EntityID)
+		}
+		if !replaced {
+			continue
+		}
+		err = tx.Model(&draft).Update("data", draft.Data).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// mergeTagTranslations перенос переводов тегов sources на тег target для языков, на которые он не переведен,
+// остальные переводы тегов sources удаляются
+func mergeTagTranslations(tx *gorm.DB, sources []uuid.UUID, target uuid.UUID) error {
+	var translations []entity.Translation
+	err := tx.Where("entity_type = ?", entity.TranslationTypeTag).
+		Where("entity_id IN ?", append([]uuid.UUID{target}, sources...)).
+		Order("updated_at DESC").
+		Find(&translations).Error
+	if err != nil {
+		return err
+	}
+
+	translated := make(map[string]bool)
+	for _, translation := range translations {
+		if translation.EntityID == target {
+			translated[translation.Locale] = true
+		}
+	}
+	for _, translation := range translations {
+		if translation.EntityID == target || translated[translation.Locale] {
+			continue
+		}
+		translated[translation.Locale] = true
+		err = tx.Model(&entity.Translation{}).
+			Where("entity_type = ?", translation.EntityType).
+			Where("entity_id = ? AND locale = ?", translation.EntityID, translation.Locale).
+			Update("entity_id", target).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Where("entity_type = ?", entity.TranslationTypeTag).
+		Where("entity_id IN ?", sources).
+		Delete(&entity.Translation{}).Error
 }
@@ -0,0 +1,86 @@
+package repositories
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/aeroideaservices/focus/page/plugin/actions"
+	"github.com/aeroideaservices/focus/page/plugin/entity"
+	"github.com/google/uuid"
+)
+
+func TestTagRepository_MergeTwoSources(t *testing.T) {
+	target, first, second, other := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	cardID := uuid.New()
+	draftData := fmt.Sprintf(`{"regularCard":{"tags":["%s","%s","%s"]}}`, first, other, second)
+
+	db, fake := newFakeGorm(t, func(query string, args []interface{}) (fakeRows, error) {
+		switch {
+		case strings.Contains(query, `FROM "tags"`):
+			return fakeRows{Columns: []string{"id"}, Values: [][]driver.Value{
+				{target.String()}, {first.String()}, {second.String()},
+			}}, nil
+		case strings.Contains(query, `FROM "drafts"`):
+			// Postgres не сравнивает text с записью, в которую gorm превращает срез в скобках
+			if strings.Contains(query, "ARRAY[(") {
+				return fakeRows{}, fmt.Errorf("operator does not exist: text ~~ record")
+			}
+			return fakeRows{
+				Columns: []string{"entity_type", "entity_id", "data"},
+				Values:  [][]driver.Value{{string(entity.DraftTypeCard), cardID.String(), []byte(draftData)}},
+			}, nil
+		}
+		return fakeRows{}, nil
+	})
+
+	linked, err := NewTagRepository(db).Merge(context.Background(), actions.MergeTagsRequest{
+		TargetID: target, SourceIDs: []uuid.UUID{first, second},
+	})
+	if err != nil {
+		t.Fatalf("Merge() error = %v", err)
+	}
+	if linked != 2 {
+		t.Errorf("Merge() linked = %d, want 2", linked)
+	}
+
+	var draftsQuery, draftUpdate *fakeQuery
+	queries := fake.Queries()
+	for i := range queries {
+		switch {
+		case strings.HasPrefix(queries[i].SQL, "SELECT") && strings.Contains(queries[i].SQL, `FROM "drafts"`):
+			draftsQuery = &queries[i]
+		case strings.HasPrefix(queries[i].SQL, `UPDATE "drafts"`):
+			draftUpdate = &queries[i]
+		}
+	}
+
+	if draftsQuery == nil {
+		t.Fatalf("drafts are not queried, queries = %+v", queries)
+	}
+	if !strings.Contains(draftsQuery.SQL, "LIKE ANY (ARRAY[$2,$3])") {
+		t.Errorf("drafts query = %s", draftsQuery.SQL)
+	}
+	wantPatterns := []interface{}{"%" + first.String() + "%", "%" + second.String() + "%"}
+	if len(draftsQuery.Args) != 3 || draftsQuery.Args[1] != wantPatterns[0] || draftsQuery.Args[2] != wantPatterns[1] {
+		t.Errorf("drafts query args = %v, want patterns %v", draftsQuery.Args, wantPatterns)
+	}
+
+	if draftUpdate == nil {
+		t.Fatalf("draft is not updated, queries = %+v", queries)
+	}
+	var data struct {
+		RegularCard struct {
+			Tags []uuid.UUID `json:"tags"`
+		} `json:"regularCard"`
+	}
+	if err = json.Unmarshal(draftUpdate.Args[0].(json.RawMessage), &data); err != nil {
+		t.Fatalf("updated draft data error = %v", err)
+	}
+	if tags := data.RegularCard.Tags; len(tags) != 2 || tags[0] != target || tags[1] != other {
+		t.Errorf("updated draft tags = %v, want [%s %s]", tags, target, other)
+	}
+}
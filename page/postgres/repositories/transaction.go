@@ -0,0 +1,39 @@
+package repositories
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+// txKey ключ транзакции в контексте
+type txKey struct{}
+
+// Transactor выполнение действий нескольких репозиториев в одной транзакции.
+// Транзакция передается через контекст, репозитории получают подключение функцией conn,
+// их собственные транзакции внутри нее выполняются как вложенные (через точки сохранения).
+type Transactor struct {
+	db *gorm.DB
+}
+
+func NewTransactor(db *gorm.DB) *Transactor {
+	return &Transactor{
+		db: db,
+	}
+}
+
+// Transaction выполнение fn в транзакции, ошибка fn откатывает все изменения
+func (t *Transactor) Transaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return conn(ctx, t.db).Transaction(func(tx *gorm.DB) error {
+		return fn(context.WithValue(ctx, txKey{}, tx))
+	})
+}
+
+// conn подключение с транзакцией из контекста, без нее - db
+func conn(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if tx, ok := ctx.Value(txKey{}).(*gorm.DB); ok {
+		return tx.WithContext(ctx)
+	}
+
+	return db.WithContext(ctx)
+}
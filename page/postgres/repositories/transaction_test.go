@@ -0,0 +1,49 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/aeroideaservices/focus/page/plugin/entity"
+	"github.com/google/uuid"
+)
+
+func TestTransactor_Transaction(t *testing.T) {
+	db, fake := newFakeGorm(t, nil)
+	transactor, publications := NewTransactor(db), NewPublicationRepository(db)
+	id := uuid.New()
+	errPublish := errors.New("publish failed")
+
+	err := transactor.Transaction(context.Background(), func(ctx context.Context) error {
+		if err := publications.UpdatePublish(ctx, entity.DraftTypeCard, id, true); err != nil {
+			return err
+		}
+		if err := publications.DeleteDraft(ctx, entity.DraftTypeCard, id); err != nil {
+			return err
+		}
+		return errPublish
+	})
+	if !errors.Is(err, errPublish) {
+		t.Fatalf("Transaction() error = %v, want %v", err, errPublish)
+	}
+
+	if err = publications.DeleteDraft(context.Background(), entity.DraftTypeCard, id); err != nil {
+		t.Fatalf("DeleteDraft() error = %v", err)
+	}
+
+	// запрос вне транзакции Transactor выполняется в собственной транзакции gorm
+	if txs := fake.Txs(); !reflect.DeepEqual(txs, []string{"BEGIN", "ROLLBACK", "BEGIN", "COMMIT"}) {
+		t.Errorf("transactions = %v, want [BEGIN ROLLBACK BEGIN COMMIT]", txs)
+	}
+	queries := fake.Queries()
+	if len(queries) != 3 {
+		t.Fatalf("queries = %+v", queries)
+	}
+	for i, want := range []int{1, 1, 2} {
+		if queries[i].Tx != want {
+			t.Errorf("query %q transaction = %d, want %d", queries[i].SQL, queries[i].Tx, want)
+		}
+	}
+}
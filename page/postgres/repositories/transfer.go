@@ -0,0 +1,234 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/aeroideaservices/focus/page/plugin/actions"
+	"github.com/aeroideaservices/focus/page/plugin/entity"
+	"github.com/aeroideaservices/focus/page/plugin/services/cardtypes"
+	"github.com/aeroideaservices/focus/services/errors"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Колонки, которые импорт обновляет у существующих сущностей.
+// Публикация, ее расписание и сортировка страницы принадлежат окружению и при импорте не меняются.
+var (
+	importPageColumns = []string{
+		"name", "title", "description", "title_seo", "description_seo", "keywords", "og_type", "updated_at",
+	}
+	importGalleryColumns = []string{"name", "hidden"}
+	importCardColumns    = []string{
+		"name", "type", "title", "description", "og_type", "regular_card_id", "video_card_id", "html_card_id",
+		"photo_card_id", "form_card_id", "custom_card_id",
+	}
+)
+
+// TransferRepository запись страниц, перенесенных из другого окружения
+type TransferRepository struct {
+	db        *gorm.DB
+	cardTypes *cardtypes.Registry
+}
+
+func NewTransferRepository(db *gorm.DB, cardTypes *cardtypes.Registry) *TransferRepository {
+	return &TransferRepository{
+		db:        db,
+		cardTypes: cardTypes,
+	}
+}
+
+// GetCardsByCodes карточки с кодами codes
+func (r *TransferRepository) GetCardsByCodes(ctx context.Context, codes []string) ([]entity.Card, error) {
+	var cards []entity.Card
+	if len(codes) == 0 {
+		return cards, nil
+	}
+
+	err := conn(ctx, r.db).Select("id", "code", "type").Where("code IN ?", codes).Find(&cards).Error
+	if err != nil {
+		return nil, errors.NoType.Wrap(err, "error getting cards")
+	}
+
+	return cards, nil
+}
+
+// GetTagsByTexts теги с текстами texts
+func (r *TransferRepository) GetTagsByTexts(ctx context.Context, texts []string) ([]entity.Tag, error) {
+	var tags []entity.Tag
+	if len(texts) == 0 {
+		return tags, nil
+	}
+
+	err := conn(ctx, r.db).Where("text IN ?", texts).Find(&tags).Error
+	if err != nil {
+		return nil, errors.NoType.Wrap(err, "error getting tags")
+	}
+
+	return tags, nil
+}
+
+// GetUsersByLastNames спикеры с фамилиями lastNames
+func (r *TransferRepository) GetUsersByLastNames(ctx context.Context, lastNames []string) ([]entity.User, error) {
+	var users []entity.User
+	if len(lastNames) == 0 {
+		return users, nil
+	}
+
+	err := conn(ctx, r.db).Where("last_name IN ?", lastNames).Find(&users).Error
+	if err != nil {
+		return nil, errors.NoType.Wrap(err, "error getting users")
+	}
+
+	return users, nil
+}
+
+// Import запись страницы в одной транзакции.
+// Существующие карточки получают новую типизированную часть, старая удаляется,
+// у существующих галерей и страницы связи заменяются связями из data.
+func (r *TransferRepository) Import(ctx context.Context, data actions.ImportData) error {
+	err := conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		for i := range data.Users {
+			if err := tx.Omit(clause.Associations).Create(&data.Users[i]).Error; err != nil {
+				return err
+			}
+		}
+		for i := range data.Tags {
+			if err := tx.Create(&data.Tags[i]).Error; err != nil {
+				return err
+			}
+		}
+
+		page := data.Page
+		imported := make(map[uuid.UUID]bool)
+		for _, pagesGallery := range page.PagesGalleries {
+			if err := r.importGallery(tx, &pagesGallery.Gallery, data.Update, imported); err != nil {
+				return err
+			}
+		}
+
+		if data.Update[page.ID] {
+			if err := tx.Model(&page).Select(importPageColumns).Updates(&page).Error; err != nil {
+				return err
+			}
+			if err := tx.Where("pages_id = ?", page.ID).Delete(&entity.PagesGalleries{}).Error; err != nil {
+				return err
+			}
+		} else if err := createPage(tx, &page); err != nil {
+			return err
+		}
+		for _, pagesGallery := range page.PagesGalleries {
+			if err := createPagesGallery(tx, &pagesGallery); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return importError(err)
+	}
+
+	return nil
+}
+
+// importGallery запись галереи и ее карточек, карточка из нескольких галерей записывается один раз
+func (r *TransferRepository) importGallery(
+	tx *gorm.DB, gallery *entity.Gallery, update map[uuid.UUID]bool, imported map[uuid.UUID]bool,
+) error {
+	for _, galleriesCard := range gallery.GalleriesCards {
+		card := galleriesCard.Card
+		if imported[card.ID] {
+			continue
+		}
+		imported[card.ID] = true
+
+		var err error
+		if update[card.ID] {
+			err = r.replaceCard(tx, card)
+		} else {
+			err = createCard(tx, r.cardTypes, card)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if update[gallery.ID] {
+		if err := tx.Model(gallery).Select(importGalleryColumns).Updates(gallery).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("gallery_id = ?", gallery.ID).Delete(&entity.GalleriesCards{}).Error; err != nil {
+			return err
+		}
+	} else if err := createGallery(tx, gallery); err != nil {
+		return err
+	}
+	for _, galleriesCard := range gallery.GalleriesCards {
+		if err := createGalleriesCard(tx, &galleriesCard); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// replaceCard обновление существующей карточки с заменой типизированной части
+func (r *TransferRepository) replaceCard(tx *gorm.DB, card *entity.Card) error {
+	current := &entity.Card{}
+	err := tx.Where("id = ?", card.ID).First(current).Error
+	if err != nil {
+		return err
+	}
+
+	if err = createCardData(tx, r.cardTypes, card); err != nil {
+		return err
+	}
+	if err = tx.Model(card).Select(importCardColumns).Updates(card).Error; err != nil {
+		return err
+	}
+
+	return r.deleteCardData(tx, current)
+}
+
+// deleteCardData удаление типизированной части карточки и ее связей с тегами
+func (r *TransferRepository) deleteCardData(tx *gorm.DB, card *entity.Card) error {
+	var err error
+	switch {
+	case card.RegularCardId != nil:
+		err = tx.Where("regular_card_id = ?", card.RegularCardId).Delete(&entity.RegularCardsTags{}).Error
+		if err == nil {
+			err = tx.Where("id = ?", card.RegularCardId).Delete(&entity.RegularCard{}).Error
+		}
+	case card.VideoCardId != nil:
+		err = tx.Where("id = ?", card.VideoCardId).Delete(&entity.VideoCard{}).Error
+	case card.HtmlCardId != nil:
+		err = tx.Where("id = ?", card.HtmlCardId).Delete(&entity.HtmlCard{}).Error
+	case card.PhotoCardId != nil:
+		err = tx.Where("id = ?", card.PhotoCardId).Delete(&entity.PhotoCard{}).Error
+	case card.FormCardId != nil:
+		err = tx.Where("form_card_id = ?", card.FormCardId).Delete(&entity.FormCardsTags{}).Error
+		if err == nil {
+			err = tx.Where("id = ?", card.FormCardId).Delete(&entity.FormCard{}).Error
+		}
+	case card.CustomCardId != nil:
+		if customType := r.cardTypes.Custom(card.Type); customType != nil {
+			err = tx.Table(customType.Table).Where("id = ?", card.CustomCardId).Delete(customType.New()).Error
+		}
+	}
+
+	return err
+}
+
+// importError ошибка записи страницы, нарушение уникальности возвращается как конфликт
+func importError(err error) error {
+	if pgErr, ok := err.(*pgconn.PgError); ok && pgErr.Code == UniqueViolationErr {
+		return errors.Conflict.Wrapf(err, "imported page conflicts with existing data, detail: %s", pgErr.Detail)
+	}
+	if errors.GetType(err) != errors.NoType {
+		return err
+	}
+
+	return errors.NoType.Wrap(err, "error importing page")
+}
@@ -0,0 +1,51 @@
+package repositories
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/aeroideaservices/focus/page/plugin/actions"
+	"github.com/aeroideaservices/focus/page/plugin/entity"
+	"github.com/google/uuid"
+)
+
+func TestTransferRepository_ImportKeepsPublication(t *testing.T) {
+	galleryID := uuid.New()
+	page := entity.Page{ID: uuid.New(), Code: "about", Path: "about", Title: "About", IsPublished: true, Sort: 5}
+	page.PagesGalleries = []entity.PagesGalleries{{
+		PagesID:   &page.ID,
+		GalleryID: &galleryID,
+		Gallery:   entity.Gallery{ID: galleryID, Code: "main", Name: "Main", IsPublished: true},
+	}}
+	db, fake := newFakeGorm(t, nil)
+
+	err := NewTransferRepository(db, nil).Import(context.Background(), actions.ImportData{
+		Page:   page,
+		Update: map[uuid.UUID]bool{page.ID: true, galleryID: true},
+	})
+	if err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+
+	var updates []string
+	for _, query := range fake.Queries() {
+		if strings.HasPrefix(query.SQL, "UPDATE") {
+			updates = append(updates, query.SQL)
+		}
+	}
+	if len(updates) != 2 {
+		t.Fatalf("updates = %v, want gallery and page", updates)
+	}
+	// публикация, ее расписание и сортировка остаются как в окружении
+	for _, update := range updates {
+		for _, column := range []string{"is_published", "publish_at", "unpublish_at", "sort"} {
+			if strings.Contains(update, `"`+column+`"`) {
+				t.Errorf("import updates %s: %s", column, update)
+			}
+		}
+	}
+	if !strings.Contains(updates[1], `"title"`) {
+		t.Errorf("page content is not updated: %s", updates[1])
+	}
+}
@@ -0,0 +1,113 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/aeroideaservices/focus/page/plugin/entity"
+	"github.com/aeroideaservices/focus/services/errors"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type TranslationRepository struct {
+	db *gorm.DB
+}
+
+func NewTranslationRepository(db *gorm.DB) *TranslationRepository {
+	return &TranslationRepository{
+		db: db,
+	}
+}
+
+// GetList переводы сущностей entityIDs на языки locales
+func (r *TranslationRepository) GetList(ctx context.Context, entityIDs []uuid.UUID, locales []string) (
+	[]entity.Translation, error,
+) {
+	var translations []entity.Translation
+	if len(entityIDs) == 0 || len(locales) == 0 {
+		return translations, nil
+	}
+
+	err := conn(ctx, r.db).
+		Where("entity_id IN ?", entityIDs).
+		Where("locale IN ?", locales).
+		Order("locale").
+		Find(&translations).Error
+	if err != nil {
+		return nil, errors.NoType.Wrap(err, "error getting translations")
+	}
+
+	return translations, nil
+}
+
+func (r *TranslationRepository) Save(ctx context.Context, translation *entity.Translation) error {
+	err := conn(ctx, r.db).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "entity_type"}, {Name: "entity_id"}, {Name: "locale"}},
+			DoUpdates: clause.AssignmentColumns([]string{"fields", "updated_at"}),
+		}).
+		Create(translation).Error
+	if err != nil {
+		return errors.NoType.Wrap(err, "error saving translation")
+	}
+
+	return nil
+}
+
+// Copy копирование переводов сущностей на их копии, ids - идентификаторы копий по идентификаторам исходных сущностей
+func (r *TranslationRepository) Copy(ctx context.Context, ids map[uuid.UUID]uuid.UUID) error {
+	sourceIDs := make([]uuid.UUID, 0, len(ids))
+	for sourceID, cloneID := range ids {
+		if sourceID != cloneID {
+			sourceIDs = append(sourceIDs, sourceID)
+		}
+	}
+	if len(sourceIDs) == 0 {
+		return nil
+	}
+
+	var translations []entity.Translation
+	err := conn(ctx, r.db).Where("entity_id IN ?", sourceIDs).Find(&translations).Error
+	if err != nil {
+		return errors.NoType.Wrap(err, "error getting translations to copy")
+	}
+	if len(translations) == 0 {
+		return nil
+	}
+
+	for i := range translations {
+		translations[i].EntityID = ids[translations[i].EntityID]
+		translations[i].CreatedAt, translations[i].UpdatedAt = time.Time{}, time.Time{}
+	}
+	err = conn(ctx, r.db).Create(&translations).Error
+	if err != nil {
+		return errors.NoType.Wrap(err, "error copying translations")
+	}
+
+	return nil
+}
+
+func (r *TranslationRepository) Delete(
+	ctx context.Context, entityType entity.TranslationType, entityID uuid.UUID, locale string,
+) error {
+	err := conn(ctx, r.db).
+		Where("entity_type = ? AND entity_id = ? AND locale = ?", entityType, entityID, locale).
+		Delete(&entity.Translation{}).Error
+	if err != nil {
+		return errors.NoType.Wrap(err, "error deleting translation")
+	}
+
+	return nil
+}
+
+// deleteTranslations удаление переводов удаляемых сущностей, выполняется в транзакции их удаления
+func deleteTranslations(tx *gorm.DB, entityType entity.TranslationType, entityIDs ...uuid.UUID) error {
+	err := tx.Where("entity_type = ? AND entity_id IN ?", entityType, entityIDs).Delete(&entity.Translation{}).Error
+	if err != nil {
+		return errors.NoType.Wrap(err, "error deleting translations")
+	}
+
+	return nil
+}
@@ -0,0 +1,77 @@
+package repositories
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/aeroideaservices/focus/page/plugin/entity"
+	"github.com/google/uuid"
+)
+
+func TestTranslationRepository_Copy(t *testing.T) {
+	source, clone, shared := uuid.New(), uuid.New(), uuid.New()
+
+	db, fake := newFakeGorm(t, func(query string, args []interface{}) (fakeRows, error) {
+		if strings.Contains(query, `FROM "translations"`) {
+			return fakeRows{
+				Columns: []string{"entity_type", "entity_id", "locale", "fields"},
+				Values: [][]driver.Value{
+					{string(entity.TranslationTypeCard), source.String(), "en", `{"title":"Card"}`},
+				},
+			}, nil
+		}
+		return fakeRows{}, nil
+	})
+
+	// карточка, на которую копия ссылается без копирования, не получает повторных переводов
+	ids := map[uuid.UUID]uuid.UUID{source: clone, shared: shared}
+	err := NewTranslationRepository(db).Copy(context.Background(), ids)
+	if err != nil {
+		t.Fatalf("Copy() error = %v", err)
+	}
+
+	var selectQuery, insert *fakeQuery
+	queries := fake.Queries()
+	for i := range queries {
+		switch {
+		case strings.HasPrefix(queries[i].SQL, "SELECT"):
+			selectQuery = &queries[i]
+		case strings.HasPrefix(queries[i].SQL, "INSERT"):
+			insert = &queries[i]
+		}
+	}
+	if selectQuery == nil || len(selectQuery.Args) != 1 || fmt.Sprint(selectQuery.Args[0]) != source.String() {
+		t.Fatalf("translations query = %+v, want translations of %s", selectQuery, source)
+	}
+	if insert == nil {
+		t.Fatalf("translations are not copied, queries = %+v", queries)
+	}
+	if fmt.Sprint(insert.Args[1]) != clone.String() {
+		t.Errorf("copied translation entity = %v, want %s", insert.Args[1], clone)
+	}
+}
+
+func TestTagRepository_DeleteTranslations(t *testing.T) {
+	tagID := uuid.New()
+	db, fake := newFakeGorm(t, nil)
+
+	if err := NewTagRepository(db).Delete(context.Background(), tagID); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+
+	var deleted []string
+	for _, query := range fake.Queries() {
+		if query.Tx != 1 {
+			t.Errorf("query outside of transaction: %s", query.SQL)
+		}
+		if strings.HasPrefix(query.SQL, "DELETE") {
+			deleted = append(deleted, query.SQL)
+		}
+	}
+	if len(deleted) != 2 || !strings.Contains(deleted[1], `"translations"`) {
+		t.Errorf("deletes = %v, want tag and its translations", deleted)
+	}
+}
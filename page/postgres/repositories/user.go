@@ -2,13 +2,21 @@ package repositories
 
 import (
 	"context"
+	"strings"
+
 	"github.com/aeroideaservices/focus/media/plugin/entity"
+	"github.com/aeroideaservices/focus/page/plugin/actions"
 	user_entity "github.com/aeroideaservices/focus/page/plugin/entity"
 	"github.com/aeroideaservices/focus/services/errors"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// likeEscaper экранирование спецсимволов LIKE в строке поиска, чтобы % и _ искались как обычные символы
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
 type UserRepository struct {
 	db *gorm.DB
 }
@@ -21,7 +29,7 @@ func NewUserRepository(db *gorm.DB) *UserRepository {
 
 func (r *UserRepository) GetById(ctx context.Context, userId uuid.UUID) (*user_entity.User, error) {
 	user := &user_entity.User{}
-	db := r.db.WithContext(ctx).Model(user_entity.User{}).Where("id", userId)
+	db := conn(ctx, r.db).Model(user_entity.User{}).Where("id", userId)
 
 	//if !allowInactive {
 	//	db.Scopes()
@@ -35,8 +43,165 @@ func (r *UserRepository) GetById(ctx context.Context, userId uuid.UUID) (*user_e
 		return nil, err
 	}
 
-	db = r.db.WithContext(ctx).Model(entity.Media{}).Table("media").Where("id", user.PictureId)
+	db = conn(ctx, r.db).Model(entity.Media{}).Table("media").Where("id", user.PictureId)
 	db.First(&user.Picture)
 
 	return user, nil
 }
+
+// GetList спикеры с поиском по имени, фамилии и должности, упорядоченные по фамилии и имени
+func (r *UserRepository) GetList(ctx context.Context, filter actions.UserFilter) ([]user_entity.User, int64, error) {
+	db := conn(ctx, r.db).Model(&user_entity.User{})
+	if query := strings.TrimSpace(filter.Query); query != "" {
+		db = db.Where(
+			"lower(first_name || ' ' || last_name || ' ' || position) LIKE lower(?)",
+			"%"+likeEscaper.Replace(query)+"%",
+		)
+	}
+
+	var total int64
+	err := db.Count(&total).Error
+	if err != nil {
+		return nil, 0, errors.NoType.Wrap(err, "error counting users")
+	}
+
+	var list []user_entity.User
+	err = db.Preload("Picture").Order("last_name, first_name, id").
+		Limit(filter.Limit).Offset(filter.Offset).Find(&list).Error
+	if err != nil {
+		return nil, 0, errors.NoType.Wrap(err, "error getting users")
+	}
+
+	return list, total, nil
+}
+
+func (r *UserRepository) Create(ctx context.Context, user *user_entity.User) error {
+	err := conn(ctx, r.db).Omit("Picture").Create(user).Error
+	if err != nil {
+		return errors.NoType.Wrap(err, "error creating user")
+	}
+
+	return nil
+}
+
+// Update обновление всех полей спикера, в том числе сброс картинки
+func (r *UserRepository) Update(ctx context.Context, user *user_entity.User) error {
+	err := conn(ctx, r.db).Model(user).
+		Select("first_name", "last_name", "position", "picture_id").
+		Updates(user).Error
+	if err != nil {
+		return errors.NoType.Wrap(err, "error updating user")
+	}
+
+	return nil
+}
+
+// Delete удаление спикера, не привязанного к карточкам и их черновикам.
+// Привязка проверяется в той же транзакции, что и удаление, строка спикера блокируется до проверки,
+// поэтому параллельная привязка к карточке (см. lockUser) дожидается удаления или удаление видит ее.
+func (r *UserRepository) Delete(ctx context.Context, userId uuid.UUID) error {
+	err := conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		var ids []uuid.UUID
+		err := tx.Model(&user_entity.User{}).Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", userId).
+			Pluck("id", &ids).Error
+		if err != nil {
+			return err
+		}
+		if len(ids) == 0 {
+			return errors.NotFound.Newf("user with id %s not found", userId)
+		}
+
+		count, err := countUserCards(tx, userId)
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			return actions.ErrUserInUse
+		}
+
+		count, err = countUserDrafts(tx, userId)
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			return actions.ErrUserInUse
+		}
+
+		return tx.Where("id = ?", userId).Delete(&user_entity.User{}).Error
+	})
+	if pgErr, ok := err.(*pgconn.PgError); ok && pgErr.Code == ForeignKeyViolationErr {
+		return actions.ErrUserInUse
+	}
+	if err != nil && errors.GetType(err) == errors.NoType {
+		return errors.NoType.Wrap(err, "error deleting user")
+	}
+
+	return err
+}
+
+// GetCards карточки, в которых указан спикер
+func (r *UserRepository) GetCards(ctx context.Context, userId uuid.UUID) ([]user_entity.Card, error) {
+	var cards []user_entity.Card
+	err := userCards(conn(ctx, r.db), userId).Select("cards.*").Order("cards.title, cards.id").Find(&cards).Error
+	if err != nil {
+		return nil, errors.NoType.Wrap(err, "error getting user cards")
+	}
+
+	return cards, nil
+}
+
+// lockUser блокировка спикера, указываемого в карточке, до конца транзакции tx.
+// Блокировка ключа не мешает изменять спикера, но не дает удалить его, пока привязка не записана.
+func lockUser(tx *gorm.DB, userId *uuid.UUID) error {
+	if userId == nil {
+		return nil
+	}
+
+	var ids []uuid.UUID
+	err := tx.Model(&user_entity.User{}).Clauses(clause.Locking{Strength: "KEY SHARE"}).
+		Where("id = ?", *userId).
+		Pluck("id", &ids).Error
+	if err != nil {
+		return errors.NoType.Wrap(err, "error locking user")
+	}
+	if len(ids) == 0 {
+		return errors.NotFound.Newf("user with id %s not found", *userId)
+	}
+
+	return nil
+}
+
+func countUserCards(db *gorm.DB, userId uuid.UUID) (int64, error) {
+	var count int64
+	err := userCards(db, userId).Count(&count).Error
+	if err != nil {
+		return 0, errors.NoType.Wrap(err, "error counting user cards")
+	}
+
+	return count, nil
+}
+
+// countUserDrafts количество черновиков карточек, в которых указан спикер
+func countUserDrafts(db *gorm.DB, userId uuid.UUID) (int64, error) {
+	var count int64
+	err := db.Model(&user_entity.Draft{}).
+		Where("entity_type = ?", user_entity.DraftTypeCard).
+		Where(
+			"(data->'regularCard'->>'userId' = ? OR data->'formCard'->>'userId' = ?)",
+			userId.String(), userId.String(),
+		).
+		Count(&count).Error
+	if err != nil {
+		return 0, errors.NoType.Wrap(err, "error counting user card drafts")
+	}
+
+	return count, nil
+}
+
+func userCards(db *gorm.DB, userId uuid.UUID) *gorm.DB {
+	return db.Model(&user_entity.Card{}).
+		Joins("LEFT JOIN regular_cards ON regular_cards.id = cards.regular_card_id").
+		Joins("LEFT JOIN form_cards ON form_cards.id = cards.form_card_id").
+		Where("regular_cards.user_id = ? OR form_cards.user_id = ?", userId, userId)
+}
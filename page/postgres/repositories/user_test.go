@@ -0,0 +1,108 @@
+package repositories
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/aeroideaservices/focus/page/plugin/actions"
+	"github.com/aeroideaservices/focus/page/plugin/entity"
+	"github.com/aeroideaservices/focus/services/errors"
+	"github.com/google/uuid"
+)
+
+func TestUserRepository_DeleteLinkedToDraft(t *testing.T) {
+	userID := uuid.New()
+
+	db, fake := newFakeGorm(t, func(query string, args []interface{}) (fakeRows, error) {
+		if strings.Contains(query, `FROM "users"`) {
+			return fakeRows{Columns: []string{"id"}, Values: [][]driver.Value{{userID.String()}}}, nil
+		}
+		var count int64
+		// спикер указан только в черновике карточки
+		if strings.Contains(query, `FROM "drafts"`) {
+			count = 1
+		}
+		return fakeRows{Columns: []string{"count"}, Values: [][]driver.Value{{count}}}, nil
+	})
+
+	err := NewUserRepository(db).Delete(context.Background(), userID)
+	if !errors.Is(err, actions.ErrUserInUse) {
+		t.Fatalf("Delete() error = %v, want %v", err, actions.ErrUserInUse)
+	}
+
+	queries := fake.Queries()
+	if len(queries) == 0 || !strings.Contains(queries[0].SQL, "FOR UPDATE") {
+		t.Errorf("user is not locked before counting links, queries = %+v", queries)
+	}
+	for _, query := range queries {
+		if strings.HasPrefix(query.SQL, "DELETE") {
+			t.Errorf("user linked to draft is deleted: %s", query.SQL)
+		}
+	}
+	if txs := fake.Txs(); len(txs) != 2 || txs[1] != "ROLLBACK" {
+		t.Errorf("transactions = %v, want rolled back", txs)
+	}
+}
+
+func TestCardRepository_PatchUserLocksUser(t *testing.T) {
+	userID, regularID := uuid.New(), uuid.New()
+
+	db, fake := newFakeGorm(t, func(query string, args []interface{}) (fakeRows, error) {
+		switch {
+		case strings.Contains(query, `FROM "users"`):
+			return fakeRows{Columns: []string{"id"}, Values: [][]driver.Value{{userID.String()}}}, nil
+		case strings.Contains(query, `FROM "cards"`):
+			return fakeRows{
+				Columns: []string{"type", "regular_card_id", "form_card_id"},
+				Values:  [][]driver.Value{{"regular", regularID.String(), nil}},
+			}, nil
+		}
+		return fakeRows{}, nil
+	})
+
+	card := &entity.Card{ID: uuid.New(), RegularCard: &entity.RegularCard{UserId: &userID}}
+	if err := NewCardRepository(db, nil).PatchUser(context.Background(), card); err != nil {
+		t.Fatalf("PatchUser() error = %v", err)
+	}
+
+	var locked, updated bool
+	for _, query := range fake.Queries() {
+		if query.Tx != 1 {
+			t.Errorf("query outside of transaction: %s", query.SQL)
+		}
+		switch {
+		case strings.Contains(query.SQL, `FROM "users"`):
+			locked = strings.Contains(query.SQL, "FOR KEY SHARE") && !updated
+		case strings.HasPrefix(query.SQL, `UPDATE "regular_cards"`):
+			updated = true
+		}
+	}
+	if !locked || !updated {
+		t.Errorf("user is not locked before assignment, queries = %+v", fake.Queries())
+	}
+}
+
+func TestUserRepository_GetListEscapesQuery(t *testing.T) {
+	db, fake := newFakeGorm(t, func(query string, args []interface{}) (fakeRows, error) {
+		if strings.Contains(query, "count(") {
+			return fakeRows{Columns: []string{"count"}, Values: [][]driver.Value{{int64(0)}}}, nil
+		}
+		return fakeRows{}, nil
+	})
+
+	_, _, err := NewUserRepository(db).GetList(context.Background(), actions.UserFilter{Query: `50%_off\`})
+	if err != nil {
+		t.Fatalf("GetList() error = %v", err)
+	}
+
+	queries := fake.Queries()
+	if len(queries) == 0 || len(queries[0].Args) != 1 {
+		t.Fatalf("queries = %+v, want search query", queries)
+	}
+	if got, want := fmt.Sprint(queries[0].Args[0]), `%50\%\_off\\%`; got != want {
+		t.Errorf("pattern = %s, want %s", got, want)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/aeroideaservices/focus/page/rest/services"
 	middleware "github.com/aeroideaservices/focus/services/gin-middleware"
 	"github.com/sarulabs/di/v2"
+	"time"
 )
 
 var Definitions = []di.Def{
@@ -45,6 +46,15 @@ var Definitions = []di.Def{
 			return handlers.NewTagHandler(tagUseCase, errorHandler, validator), nil
 		},
 	},
+	{
+		Name: "focus.page.handlers.user",
+		Build: func(ctn di.Container) (interface{}, error) {
+			userUseCase := ctn.Get("focus.page.actions.user").(*actions.UserUseCase)
+			validator := ctn.Get("focus.validator").(services.Validator)
+			errorHandler := ctn.Get("focus.errorHandler").(*middleware.ErrorHandler)
+			return handlers.NewUserHandler(userUseCase, errorHandler, validator), nil
+		},
+	},
 	{
 		Name: "focus.page.handlers.video",
 		Build: func(ctn di.Container) (interface{}, error) {
@@ -54,6 +64,101 @@ var Definitions = []di.Def{
 			return handlers.NewVideoHandler(videoUseCase, errorHandler, validator), nil
 		},
 	},
+	{
+		Name: "focus.page.handlers.publication",
+		Build: func(ctn di.Container) (interface{}, error) {
+			publicationUseCase := ctn.Get("focus.page.actions.publication").(*actions.PublicationUseCase)
+			validator := ctn.Get("focus.validator").(services.Validator)
+			errorHandler := ctn.Get("focus.errorHandler").(*middleware.ErrorHandler)
+			// планировщик отложенных публикаций запускается вместе с маршрутами публикации
+			_ = ctn.Get("focus.page.publication.scheduler")
+			return handlers.NewPublicationHandler(publicationUseCase, errorHandler, validator), nil
+		},
+	},
+	{
+		Name: "focus.page.handlers.preview",
+		Build: func(ctn di.Container) (interface{}, error) {
+			previewUseCase := ctn.Get("focus.page.actions.preview").(*actions.PreviewUseCase)
+			validator := ctn.Get("focus.validator").(services.Validator)
+			errorHandler := ctn.Get("focus.errorHandler").(*middleware.ErrorHandler)
+			return handlers.NewPreviewHandler(previewUseCase, errorHandler, validator), nil
+		},
+	},
+	{
+		Name: "focus.page.handlers.clone",
+		Build: func(ctn di.Container) (interface{}, error) {
+			cloneUseCase := ctn.Get("focus.page.actions.clone").(*actions.CloneUseCase)
+			validator := ctn.Get("focus.validator").(services.Validator)
+			errorHandler := ctn.Get("focus.errorHandler").(*middleware.ErrorHandler)
+			return handlers.NewCloneHandler(cloneUseCase, errorHandler, validator), nil
+		},
+	},
+	{
+		Name: "focus.page.handlers.translation",
+		Build: func(ctn di.Container) (interface{}, error) {
+			translationUseCase := ctn.Get("focus.page.actions.translation").(*actions.TranslationUseCase)
+			validator := ctn.Get("focus.validator").(services.Validator)
+			errorHandler := ctn.Get("focus.errorHandler").(*middleware.ErrorHandler)
+			return handlers.NewTranslationHandler(translationUseCase, errorHandler, validator), nil
+		},
+	},
+	{
+		Name: "focus.page.handlers.transfer",
+		Build: func(ctn di.Container) (interface{}, error) {
+			transferUseCase := ctn.Get("focus.page.actions.transfer").(*actions.TransferUseCase)
+			validator := ctn.Get("focus.validator").(services.Validator)
+			errorHandler := ctn.Get("focus.errorHandler").(*middleware.ErrorHandler)
+			return handlers.NewTransferHandler(transferUseCase, errorHandler, validator), nil
+		},
+	},
+	{
+		Name: "focus.page.handlers.search",
+		Build: func(ctn di.Container) (interface{}, error) {
+			searchUseCase := ctn.Get("focus.page.actions.search").(*actions.SearchUseCase)
+			validator := ctn.Get("focus.validator").(services.Validator)
+			errorHandler := ctn.Get("focus.errorHandler").(*middleware.ErrorHandler)
+			return handlers.NewSearchHandler(searchUseCase, errorHandler, validator), nil
+		},
+	},
+	{
+		Name: "focus.page.handlers.static",
+		Build: func(ctn di.Container) (interface{}, error) {
+			staticUseCase := ctn.Get("focus.page.actions.static").(*actions.StaticExportUseCase)
+			validator := ctn.Get("focus.validator").(services.Validator)
+			errorHandler := ctn.Get("focus.errorHandler").(*middleware.ErrorHandler)
+			return handlers.NewStaticHandler(staticUseCase, errorHandler, validator), nil
+		},
+	},
+	{
+		Name: "focus.page.handlers.snapshot",
+		Build: func(ctn di.Container) (interface{}, error) {
+			snapshotUseCase := ctn.Get("focus.page.actions.snapshot").(*actions.SnapshotUseCase)
+			validator := ctn.Get("focus.validator").(services.Validator)
+			errorHandler := ctn.Get("focus.errorHandler").(*middleware.ErrorHandler)
+			return handlers.NewSnapshotHandler(snapshotUseCase, errorHandler, validator), nil
+		},
+	},
+	{
+		Name: "focus.page.handlers.delivery",
+		Build: func(ctn di.Container) (interface{}, error) {
+			deliveryUseCase := ctn.Get("focus.page.actions.delivery").(*actions.DeliveryUseCase)
+			validator := ctn.Get("focus.validator").(services.Validator)
+			errorHandler := ctn.Get("focus.errorHandler").(*middleware.ErrorHandler)
+			maxAge := time.Minute
+			if maxAgeI, err := ctn.SafeGet("focus.page.delivery.maxAge"); err == nil {
+				maxAge = maxAgeI.(time.Duration)
+			}
+			return handlers.NewDeliveryHandler(deliveryUseCase, errorHandler, validator, maxAge), nil
+		},
+	},
+	{
+		Name: "focus.page.delivery.router",
+		Build: func(ctn di.Container) (interface{}, error) {
+			deliveryHandler := ctn.Get("focus.page.handlers.delivery").(*handlers.DeliveryHandler)
+			errorHandler := ctn.Get("focus.errorHandler").(services.ErrorHandler)
+			return NewDeliveryRouter(deliveryHandler, errorHandler), nil
+		},
+	},
 	{
 		Name: "focus.page.router",
 		Build: func(ctn di.Container) (interface{}, error) {
@@ -63,8 +168,27 @@ var Definitions = []di.Def{
 			tagHandleer := ctn.Get("focus.page.handlers.tag").(*handlers.TagHandler)
 			//optHandler := ctn.Get("focus.configurations.handlers.options").(*handlers.)
 			videoHandler := ctn.Get("focus.page.handlers.video").(*handlers.VideoHandler)
+			publicationHandler := ctn.Get("focus.page.handlers.publication").(*handlers.PublicationHandler)
+			previewHandler := ctn.Get("focus.page.handlers.preview").(*handlers.PreviewHandler)
+			cloneHandler := ctn.Get("focus.page.handlers.clone").(*handlers.CloneHandler)
+			translationHandler := ctn.Get("focus.page.handlers.translation").(*handlers.TranslationHandler)
+			transferHandler := ctn.Get("focus.page.handlers.transfer").(*handlers.TransferHandler)
+			userHandler := ctn.Get("focus.page.handlers.user").(*handlers.UserHandler)
+			searchHandler := ctn.Get("focus.page.handlers.search").(*handlers.SearchHandler)
+			staticHandler := ctn.Get("focus.page.handlers.static").(*handlers.StaticHandler)
+			snapshotHandler := ctn.Get("focus.page.handlers.snapshot").(*handlers.SnapshotHandler)
 			errorHandler := ctn.Get("focus.errorHandler").(services.ErrorHandler)
-			return NewRouter(pageHandler, galleryHandler, cardHandler, tagHandleer, videoHandler, errorHandler), nil
+			// по умолчанию изменения страниц, галерей и карточек сохраняются в черновик
+			// и применяются только публикацией, focus.page.draftEdits = false отключает черновики
+			draftEdits := true
+			if draftEditsI, err := ctn.SafeGet("focus.page.draftEdits"); err == nil {
+				draftEdits = draftEditsI.(bool)
+			}
+			return NewRouter(
+				pageHandler, galleryHandler, cardHandler, tagHandleer, videoHandler, publicationHandler, previewHandler,
+				cloneHandler, translationHandler, transferHandler, userHandler, searchHandler, staticHandler,
+				snapshotHandler, errorHandler, draftEdits,
+			), nil
 		},
 	},
 }
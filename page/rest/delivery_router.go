@@ -0,0 +1,27 @@
+package rest
+
+import (
+	"github.com/aeroideaservices/focus/page/rest/handlers"
+	"github.com/aeroideaservices/focus/page/rest/services"
+	"github.com/gin-gonic/gin"
+)
+
+// DeliveryRouter публичные маршруты опубликованных страниц, регистрируются отдельно от маршрутов администрирования
+type DeliveryRouter struct {
+	deliveryHandler *handlers.DeliveryHandler
+	errorHandler    services.ErrorHandler
+}
+
+func NewDeliveryRouter(deliveryHandler *handlers.DeliveryHandler, errorHandler services.ErrorHandler) *DeliveryRouter {
+	return &DeliveryRouter{
+		deliveryHandler: deliveryHandler,
+		errorHandler:    errorHandler,
+	}
+}
+
+func (r *DeliveryRouter) SetRoutes(routerGroup *gin.RouterGroup) {
+	pages := routerGroup.Group("pages")
+	pages.Use(r.errorHandler.Handle)
+	pages.GET("/:code", r.deliveryHandler.GetPage)
+	pages.GET("/by-path/*path", r.deliveryHandler.GetPageByPath)
+}
@@ -39,6 +39,11 @@ func (h CardHandler) GetList(c *gin.Context) {
 	c.JSON(http.StatusOK, pages)
 }
 
+// GetTypes список типов карточек, включая зарегистрированные приложением, с описанием полей для формы
+func (h CardHandler) GetTypes(c *gin.Context) {
+	c.JSON(http.StatusOK, h.cardUseCase.GetTypes())
+}
+
 func (h CardHandler) Create(c *gin.Context) {
 	request := &actions.CreateCardRequest{}
 	if err := c.ShouldBindJSON(request); err != nil {
@@ -113,7 +118,7 @@ func (h CardHandler) Update(c *gin.Context) {
 
 	request.ID = cardId
 
-	err = h.cardUseCase.Update(c, request)
+	resp, err := h.cardUseCase.Update(c, request)
 	if err != nil {
 		if pgErr, ok := err.(*pgconn.PgError); ok {
 			if pgErr.Code == UniqueViolationErr {
@@ -131,6 +136,10 @@ func (h CardHandler) Update(c *gin.Context) {
 		_ = c.Error(err)
 		return
 	}
+	if resp.Sanitized != nil {
+		c.JSON(http.StatusOK, resp)
+		return
+	}
 	c.JSON(http.StatusOK, "success")
 }
 
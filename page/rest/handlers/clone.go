@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"context"
+
+	"github.com/aeroideaservices/focus/page/plugin/actions"
+	"github.com/aeroideaservices/focus/page/rest/services"
+	"github.com/aeroideaservices/focus/services/errors"
+	middleware "github.com/aeroideaservices/focus/services/gin-middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"net/http"
+)
+
+// CloneHandler копирование страниц, галерей и карточек
+type CloneHandler struct {
+	cloneUseCase *actions.CloneUseCase
+	errorHandler *middleware.ErrorHandler
+	validator    services.Validator
+}
+
+func NewCloneHandler(
+	cloneUseCase *actions.CloneUseCase, errorHandler *middleware.ErrorHandler, validator services.Validator,
+) *CloneHandler {
+	return &CloneHandler{
+		cloneUseCase: cloneUseCase,
+		errorHandler: errorHandler,
+		validator:    validator,
+	}
+}
+
+func (h CloneHandler) ClonePage(c *gin.Context) {
+	h.clone(c, "page-id", h.cloneUseCase.ClonePage)
+}
+
+func (h CloneHandler) CloneGallery(c *gin.Context) {
+	h.clone(c, "gallery-id", h.cloneUseCase.CloneGallery)
+}
+
+func (h CloneHandler) CloneCard(c *gin.Context) {
+	h.clone(c, "card-id", h.cloneUseCase.CloneCard)
+}
+
+func (h CloneHandler) clone(
+	c *gin.Context, param string,
+	clone func(ctx context.Context, dto actions.CloneRequest) (*actions.CloneResponse, error),
+) {
+	id, err := uuid.Parse(c.Param(param))
+	if err != nil {
+		_ = c.Error(errors.NotFound.Wrap(err, "uuid parsing error"))
+		return
+	}
+
+	dto := actions.CloneRequest{}
+	if c.Request.ContentLength != 0 {
+		if err = c.ShouldBindJSON(&dto); err != nil {
+			_ = c.Error(errors.BadRequest.Wrap(err, "error converting request body to action"))
+			return
+		}
+	}
+	dto.ID = id
+
+	err = h.validator.Validate(c, dto)
+	if err != nil {
+		_ = c.Error(errors.BadRequest.Wrap(err, "error validating"))
+		return
+	}
+
+	resp, err := clone(c, dto)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	c.JSON(http.StatusCreated, resp)
+}
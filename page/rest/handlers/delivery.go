@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"github.com/aeroideaservices/focus/page/plugin/actions"
+	"github.com/aeroideaservices/focus/page/rest/services"
+	"github.com/aeroideaservices/focus/services/errors"
+	middleware "github.com/aeroideaservices/focus/services/gin-middleware"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// previewTokenHeader заголовок с токеном предпросмотра, альтернатива параметру запроса preview
+const previewTokenHeader = "X-Preview-Token"
+
+// DeliveryHandler публичный API опубликованных страниц, только чтение
+type DeliveryHandler struct {
+	deliveryUseCase *actions.DeliveryUseCase
+	errorHandler    *middleware.ErrorHandler
+	validator       services.Validator
+	maxAge          time.Duration
+}
+
+func NewDeliveryHandler(
+	deliveryUseCase *actions.DeliveryUseCase, errorHandler *middleware.ErrorHandler, validator services.Validator,
+	maxAge time.Duration,
+) *DeliveryHandler {
+	return &DeliveryHandler{
+		deliveryUseCase: deliveryUseCase,
+		errorHandler:    errorHandler,
+		validator:       validator,
+		maxAge:          maxAge,
+	}
+}
+
+func (h DeliveryHandler) GetPage(c *gin.Context) {
+	h.getPage(c, actions.GetDeliveryPageRequest{Code: c.Param("code")})
+}
+
+// GetPageByPath получение страницы по полному пути, например /pages/by-path/about/team/leadership
+func (h DeliveryHandler) GetPageByPath(c *gin.Context) {
+	h.getPage(c, actions.GetDeliveryPageRequest{Path: strings.Trim(c.Param("path"), "/")})
+}
+
+func (h DeliveryHandler) getPage(c *gin.Context, dto actions.GetDeliveryPageRequest) {
+	dto.Locale = c.Query("locale")
+	dto.PreviewToken = c.Query("preview")
+	if dto.PreviewToken == "" {
+		dto.PreviewToken = c.GetHeader(previewTokenHeader)
+	}
+
+	err := h.validator.Validate(c, dto)
+	if err != nil {
+		_ = c.Error(errors.NotFound.Wrap(err, "page not found"))
+		return
+	}
+
+	page, err := h.deliveryUseCase.GetPage(c, dto)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	c.Header("Content-Language", page.Locale)
+
+	// предпросмотр не должен попадать в кеши
+	if dto.PreviewToken != "" {
+		c.Header("Cache-Control", "no-store")
+		c.JSON(http.StatusOK, page)
+		return
+	}
+
+	err = services.CachedJSON(c, page, h.maxAge)
+	if err != nil {
+		_ = c.Error(errors.NoType.Wrap(err, "error encoding page"))
+	}
+}
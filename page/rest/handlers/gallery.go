@@ -198,6 +198,35 @@ func (h GalleryHandler) PatchCardPosition(c *gin.Context) {
 	c.JSON(http.StatusOK, "success")
 }
 
+func (h GalleryHandler) ReorderCards(c *gin.Context) {
+	request := &actions.ReorderCardsRequest{}
+	if err := c.ShouldBindJSON(request); err != nil {
+		_ = c.Error(errors.BadRequest.Wrap(err, "error converting request body to action"))
+		return
+	}
+
+	galleryId, err := uuid.Parse(c.Params.ByName("gallery-id"))
+	if err != nil {
+		_ = c.Error(errors.NotFound.Wrap(err, "uuid parsing error"))
+		return
+	}
+
+	request.GalleryID = galleryId
+
+	err = h.validator.Validate(c, request)
+	if err != nil {
+		_ = c.Error(errors.BadRequest.Wrap(err, "error validating"))
+		return
+	}
+
+	err = h.galleryUseCase.ReorderCards(c, request)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, "success")
+}
+
 func (h GalleryHandler) LinkCards(c *gin.Context) {
 	galleryId, err := uuid.Parse(c.Params.ByName("gallery-id"))
 	if err != nil {
@@ -113,6 +113,43 @@ func (h PageHandler) GetList(c *gin.Context) {
 	c.JSON(http.StatusOK, pages)
 }
 
+// GetTree дерево страниц
+func (h PageHandler) GetTree(c *gin.Context) {
+	tree, err := h.pageUseCase.GetTree(c)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, tree)
+}
+
+// Move перемещение страницы вместе с вложенными страницами под другого родителя
+func (h PageHandler) Move(c *gin.Context) {
+	request := actions.MovePageRequest{}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		_ = c.Error(errors.BadRequest.Wrap(err, "error converting request body to action"))
+		return
+	}
+
+	pageId, err := uuid.Parse(c.Params.ByName("page-id"))
+	if err != nil {
+		_ = c.Error(errors.NotFound.Wrap(err, "uuid parsing error"))
+		return
+	}
+	request.ID = pageId
+
+	err = h.pageUseCase.Move(c, request)
+	if err != nil {
+		if pgErr, ok := err.(*pgconn.PgError); ok && pgErr.Code == UniqueViolationErr {
+			_ = c.Error(errors.Conflict.Wrap(err, "page with such path already exists"))
+			return
+		}
+		_ = c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, "success")
+}
+
 func (h PageHandler) PatchProperties(c *gin.Context) {
 	request := &actions.PatchPageRequest{}
 	if err := c.ShouldBindJSON(request); err != nil {
@@ -185,6 +222,44 @@ func (h PageHandler) PatchGalleryPosition(c *gin.Context) {
 	c.JSON(http.StatusOK, "success")
 }
 
+func (h PageHandler) ReorderGalleries(c *gin.Context) {
+	request := &actions.ReorderGalleriesRequest{}
+	if err := c.ShouldBindJSON(request); err != nil {
+		_ = c.Error(errors.BadRequest.Wrap(err, "error converting request body to action"))
+		return
+	}
+
+	pageId, err := uuid.Parse(c.Params.ByName("page-id"))
+	if err != nil {
+		_ = c.Error(errors.NotFound.Wrap(err, "uuid parsing error"))
+		return
+	}
+
+	request.PageID = pageId
+
+	err = h.validator.Validate(c, request)
+	if err != nil {
+		_ = c.Error(errors.BadRequest.Wrap(err, "error validating"))
+		return
+	}
+
+	err = h.pageUseCase.ReorderGalleries(c, request)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, "success")
+}
+
+func (h PageHandler) RepairPositions(c *gin.Context) {
+	response, err := h.pageUseCase.RepairPositions(c)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, response)
+}
+
 func (h PageHandler) LinkGalleries(c *gin.Context) {
 	pageId, err := uuid.Parse(c.Params.ByName("page-id"))
 	if err != nil {
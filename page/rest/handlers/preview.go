@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"github.com/aeroideaservices/focus/page/plugin/actions"
+	"github.com/aeroideaservices/focus/page/plugin/entity"
+	"github.com/aeroideaservices/focus/page/rest/services"
+	"github.com/aeroideaservices/focus/services/errors"
+	middleware "github.com/aeroideaservices/focus/services/gin-middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"net/http"
+)
+
+// PreviewHandler выдача и отзыв ссылок предпросмотра неопубликованных страниц, галерей и карточек
+type PreviewHandler struct {
+	previewUseCase *actions.PreviewUseCase
+	errorHandler   *middleware.ErrorHandler
+	validator      services.Validator
+}
+
+func NewPreviewHandler(
+	previewUseCase *actions.PreviewUseCase, errorHandler *middleware.ErrorHandler, validator services.Validator,
+) *PreviewHandler {
+	return &PreviewHandler{
+		previewUseCase: previewUseCase,
+		errorHandler:   errorHandler,
+		validator:      validator,
+	}
+}
+
+func (h PreviewHandler) Create(entityType entity.DraftType, param string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		request, ok := publicationRequest(c, entityType, param)
+		if !ok {
+			return
+		}
+
+		dto := actions.CreatePreviewTokenRequest{}
+		if c.Request.ContentLength != 0 {
+			if err := c.ShouldBindJSON(&dto); err != nil {
+				_ = c.Error(errors.BadRequest.Wrap(err, "error converting request body to action"))
+				return
+			}
+		}
+		dto.EntityType, dto.ID = request.EntityType, request.ID
+		dto.CreatedBy, dto.CreatedByName = c.GetString("user-id"), c.GetString("user-full-name")
+
+		err := h.validator.Validate(c, dto)
+		if err != nil {
+			_ = c.Error(errors.BadRequest.Wrap(err, "error validating"))
+			return
+		}
+
+		token, err := h.previewUseCase.Create(c, dto)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+		c.JSON(http.StatusCreated, token)
+	}
+}
+
+func (h PreviewHandler) GetList(entityType entity.DraftType, param string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		request, ok := publicationRequest(c, entityType, param)
+		if !ok {
+			return
+		}
+
+		tokens, err := h.previewUseCase.GetList(c, request)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, tokens)
+	}
+}
+
+func (h PreviewHandler) Revoke(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("token-id"))
+	if err != nil {
+		_ = c.Error(errors.NotFound.Wrap(err, "uuid parsing error"))
+		return
+	}
+
+	err = h.previewUseCase.Revoke(c, actions.RevokePreviewTokenRequest{ID: id, RevokedBy: c.GetString("user-id")})
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, "success")
+}
@@ -0,0 +1,213 @@
+package handlers
+
+import (
+	"encoding/json"
+	"github.com/aeroideaservices/focus/page/plugin/actions"
+	"github.com/aeroideaservices/focus/page/plugin/entity"
+	"github.com/aeroideaservices/focus/page/rest/services"
+	"github.com/aeroideaservices/focus/services/errors"
+	middleware "github.com/aeroideaservices/focus/services/gin-middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"net/http"
+)
+
+// PublicationHandler черновики и публикация страниц, галерей и карточек.
+// Обработчики создаются для типа сущности и названия параметра маршрута с ее идентификатором.
+type PublicationHandler struct {
+	publicationUseCase *actions.PublicationUseCase
+	errorHandler       *middleware.ErrorHandler
+	validator          services.Validator
+}
+
+func NewPublicationHandler(
+	publicationUseCase *actions.PublicationUseCase, errorHandler *middleware.ErrorHandler,
+	validator services.Validator,
+) *PublicationHandler {
+	return &PublicationHandler{
+		publicationUseCase: publicationUseCase,
+		errorHandler:       errorHandler,
+		validator:          validator,
+	}
+}
+
+func (h PublicationHandler) GetDraft(entityType entity.DraftType, param string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		request, ok := publicationRequest(c, entityType, param)
+		if !ok {
+			return
+		}
+
+		draft, err := h.publicationUseCase.GetDraft(c, request)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, draft)
+	}
+}
+
+func (h PublicationHandler) SaveDraft(entityType entity.DraftType, param string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		request, ok := publicationRequest(c, entityType, param)
+		if !ok {
+			return
+		}
+
+		dto := actions.SaveDraftRequest{}
+		if err := c.ShouldBindJSON(&dto); err != nil {
+			_ = c.Error(errors.BadRequest.Wrap(err, "error converting request body to action"))
+			return
+		}
+		dto.EntityType, dto.ID = request.EntityType, request.ID
+
+		err := h.validator.Validate(c, dto)
+		if err != nil {
+			_ = c.Error(errors.BadRequest.Wrap(err, "error validating"))
+			return
+		}
+
+		err = h.publicationUseCase.SaveDraft(c, dto)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, "success")
+	}
+}
+
+// SaveEdit сохранение тела запроса на изменение сущности в ее черновик, используется в режиме редактирования
+// через черновики вместо обработчиков, изменяющих опубликованную сущность
+func (h PublicationHandler) SaveEdit(entityType entity.DraftType, param string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		request, ok := publicationRequest(c, entityType, param)
+		if !ok {
+			return
+		}
+
+		data, err := c.GetRawData()
+		if err != nil {
+			_ = c.Error(errors.BadRequest.Wrap(err, "error converting request body to action"))
+			return
+		}
+		if !json.Valid(data) {
+			_ = c.Error(errors.BadRequest.New("error converting request body to action"))
+			return
+		}
+		dto := actions.SaveDraftRequest{EntityType: request.EntityType, ID: request.ID, Data: data}
+
+		err = h.validator.Validate(c, dto)
+		if err != nil {
+			_ = c.Error(errors.BadRequest.Wrap(err, "error validating"))
+			return
+		}
+
+		err = h.publicationUseCase.SaveEdit(c, dto)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, "success")
+	}
+}
+
+func (h PublicationHandler) DeleteDraft(entityType entity.DraftType, param string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		request, ok := publicationRequest(c, entityType, param)
+		if !ok {
+			return
+		}
+
+		err := h.publicationUseCase.DeleteDraft(c, request)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, "success")
+	}
+}
+
+func (h PublicationHandler) Publish(entityType entity.DraftType, param string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		request, ok := publicationRequest(c, entityType, param)
+		if !ok {
+			return
+		}
+
+		err := h.publicationUseCase.Publish(c, request)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, "success")
+	}
+}
+
+func (h PublicationHandler) Unpublish(entityType entity.DraftType, param string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		request, ok := publicationRequest(c, entityType, param)
+		if !ok {
+			return
+		}
+
+		err := h.publicationUseCase.Unpublish(c, request)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, "success")
+	}
+}
+
+func (h PublicationHandler) Schedule(entityType entity.DraftType, param string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		request, ok := publicationRequest(c, entityType, param)
+		if !ok {
+			return
+		}
+
+		dto := actions.ScheduleRequest{}
+		if err := c.ShouldBindJSON(&dto); err != nil {
+			_ = c.Error(errors.BadRequest.Wrap(err, "error converting request body to action"))
+			return
+		}
+		dto.EntityType, dto.ID = request.EntityType, request.ID
+
+		err := h.publicationUseCase.Schedule(c, dto)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, "success")
+	}
+}
+
+// Unpublished удаление обработчиком handler только неопубликованной сущности,
+// используется в режиме редактирования через черновики
+func (h PublicationHandler) Unpublished(
+	entityType entity.DraftType, param string, handler gin.HandlerFunc,
+) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		request, ok := publicationRequest(c, entityType, param)
+		if !ok {
+			return
+		}
+
+		err := h.publicationUseCase.CheckUnpublished(c, request)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+		handler(c)
+	}
+}
+
+func publicationRequest(c *gin.Context, entityType entity.DraftType, param string) (actions.PublicationRequest, bool) {
+	id, err := uuid.Parse(c.Param(param))
+	if err != nil {
+		_ = c.Error(errors.NotFound.Wrap(err, "uuid parsing error"))
+		return actions.PublicationRequest{}, false
+	}
+
+	return actions.PublicationRequest{EntityType: entityType, ID: id}, true
+}
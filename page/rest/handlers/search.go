@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/aeroideaservices/focus/page/plugin/actions"
+	"github.com/aeroideaservices/focus/page/rest/services"
+	"github.com/aeroideaservices/focus/services/errors"
+	middleware "github.com/aeroideaservices/focus/services/gin-middleware"
+	"github.com/gin-gonic/gin"
+)
+
+// SearchHandler поиск по страницам, галереям, карточкам и тегам
+type SearchHandler struct {
+	searchUseCase *actions.SearchUseCase
+	errorHandler  *middleware.ErrorHandler
+	validator     services.Validator
+}
+
+func NewSearchHandler(
+	searchUseCase *actions.SearchUseCase, errorHandler *middleware.ErrorHandler, validator services.Validator,
+) *SearchHandler {
+	return &SearchHandler{
+		searchUseCase: searchUseCase,
+		errorHandler:  errorHandler,
+		validator:     validator,
+	}
+}
+
+// Search поиск, типы сущностей передаются повторяющимся параметром types
+func (h SearchHandler) Search(c *gin.Context) {
+	var request actions.SearchRequest
+	if err := c.ShouldBindQuery(&request); err != nil {
+		_ = c.Error(errors.BadRequest.Wrap(err, "error validating"))
+		return
+	}
+
+	err := h.validator.Validate(c, request)
+	if err != nil {
+		_ = c.Error(errors.BadRequest.Wrap(err, "error validating"))
+		return
+	}
+
+	resp, err := h.searchUseCase.Search(c, request)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	"github.com/aeroideaservices/focus/page/plugin/actions"
+	"github.com/aeroideaservices/focus/page/rest/services"
+	"github.com/aeroideaservices/focus/services/errors"
+	middleware "github.com/aeroideaservices/focus/services/gin-middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"net/http"
+)
+
+// SnapshotHandler снимки страниц, их сравнение и восстановление в черновики
+type SnapshotHandler struct {
+	snapshotUseCase *actions.SnapshotUseCase
+	errorHandler    *middleware.ErrorHandler
+	validator       services.Validator
+}
+
+func NewSnapshotHandler(
+	snapshotUseCase *actions.SnapshotUseCase, errorHandler *middleware.ErrorHandler, validator services.Validator,
+) *SnapshotHandler {
+	return &SnapshotHandler{
+		snapshotUseCase: snapshotUseCase,
+		errorHandler:    errorHandler,
+		validator:       validator,
+	}
+}
+
+func (h SnapshotHandler) GetList(c *gin.Context) {
+	pageId, err := uuid.Parse(c.Param("page-id"))
+	if err != nil {
+		_ = c.Error(errors.NotFound.Wrap(err, "uuid parsing error"))
+		return
+	}
+
+	dto := actions.SnapshotFilter{}
+	if err = c.ShouldBindQuery(&dto); err != nil {
+		_ = c.Error(errors.BadRequest.Wrap(err, "error validating"))
+		return
+	}
+	dto.PageID = pageId
+
+	err = h.validator.Validate(c, dto)
+	if err != nil {
+		_ = c.Error(errors.BadRequest.Wrap(err, "error validating"))
+		return
+	}
+
+	snapshots, err := h.snapshotUseCase.GetList(c, dto)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, snapshots)
+}
+
+func (h SnapshotHandler) Create(c *gin.Context) {
+	pageId, err := uuid.Parse(c.Param("page-id"))
+	if err != nil {
+		_ = c.Error(errors.NotFound.Wrap(err, "uuid parsing error"))
+		return
+	}
+
+	dto := actions.CreateSnapshotRequest{}
+	if c.Request.ContentLength != 0 {
+		if err = c.ShouldBindJSON(&dto); err != nil {
+			_ = c.Error(errors.BadRequest.Wrap(err, "error converting request body to action"))
+			return
+		}
+	}
+	dto.PageID = pageId
+
+	err = h.validator.Validate(c, dto)
+	if err != nil {
+		_ = c.Error(errors.BadRequest.Wrap(err, "error validating"))
+		return
+	}
+
+	snapshot, err := h.snapshotUseCase.Create(c, dto)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	c.JSON(http.StatusCreated, snapshot)
+}
+
+func (h SnapshotHandler) GetById(c *gin.Context) {
+	request, ok := snapshotRequest(c)
+	if !ok {
+		return
+	}
+
+	snapshot, err := h.snapshotUseCase.GetById(c, request)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, snapshot)
+}
+
+func (h SnapshotHandler) Diff(c *gin.Context) {
+	pageId, err := uuid.Parse(c.Param("page-id"))
+	if err != nil {
+		_ = c.Error(errors.NotFound.Wrap(err, "uuid parsing error"))
+		return
+	}
+
+	dto := actions.DiffSnapshotsRequest{}
+	if err = c.ShouldBindQuery(&dto); err != nil {
+		_ = c.Error(errors.BadRequest.Wrap(err, "error validating"))
+		return
+	}
+	dto.PageID = pageId
+
+	err = h.validator.Validate(c, dto)
+	if err != nil {
+		_ = c.Error(errors.BadRequest.Wrap(err, "error validating"))
+		return
+	}
+
+	diff, err := h.snapshotUseCase.Diff(c, dto)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, diff)
+}
+
+func (h SnapshotHandler) Restore(c *gin.Context) {
+	request, ok := snapshotRequest(c)
+	if !ok {
+		return
+	}
+
+	resp, err := h.snapshotUseCase.Restore(c, request)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func snapshotRequest(c *gin.Context) (actions.GetSnapshotRequest, bool) {
+	pageId, err := uuid.Parse(c.Param("page-id"))
+	if err != nil {
+		_ = c.Error(errors.NotFound.Wrap(err, "uuid parsing error"))
+		return actions.GetSnapshotRequest{}, false
+	}
+	id, err := uuid.Parse(c.Param("snapshot-id"))
+	if err != nil {
+		_ = c.Error(errors.NotFound.Wrap(err, "uuid parsing error"))
+		return actions.GetSnapshotRequest{}, false
+	}
+
+	return actions.GetSnapshotRequest{PageID: pageId, ID: id}, true
+}
@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"net/http"
+	"os"
+
+	"github.com/aeroideaservices/focus/page/plugin/actions"
+	"github.com/aeroideaservices/focus/page/rest/services"
+	"github.com/aeroideaservices/focus/services/errors"
+	middleware "github.com/aeroideaservices/focus/services/gin-middleware"
+	"github.com/gin-gonic/gin"
+)
+
+// StaticHandler статическая выгрузка опубликованных страниц
+type StaticHandler struct {
+	staticUseCase *actions.StaticExportUseCase
+	errorHandler  *middleware.ErrorHandler
+	validator     services.Validator
+}
+
+func NewStaticHandler(
+	staticUseCase *actions.StaticExportUseCase, errorHandler *middleware.ErrorHandler, validator services.Validator,
+) *StaticHandler {
+	return &StaticHandler{
+		staticUseCase: staticUseCase,
+		errorHandler:  errorHandler,
+		validator:     validator,
+	}
+}
+
+// ExportZip скачивание архива с выгрузкой.
+// Архив собирается во временном файле, чтобы ошибка сборки вернулась ответом, а не оборванным файлом.
+func (h StaticHandler) ExportZip(c *gin.Context) {
+	tmp, err := os.CreateTemp("", "static-export-*.zip")
+	if err != nil {
+		_ = c.Error(errors.NoType.Wrap(err, "error creating temporary file"))
+		return
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	if _, err = h.staticUseCase.ExportZip(c, tmp); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.FileAttachment(tmp.Name(), "static.zip")
+}
+
+// ExportDir выгрузка в каталог на сервере, по умолчанию полная
+func (h StaticHandler) ExportDir(c *gin.Context) {
+	dto := actions.StaticExportRequest{}
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&dto); err != nil {
+			_ = c.Error(errors.BadRequest.Wrap(err, "error converting request body to action"))
+			return
+		}
+	}
+
+	err := h.validator.Validate(c, dto)
+	if err != nil {
+		_ = c.Error(errors.BadRequest.Wrap(err, "error validating"))
+		return
+	}
+
+	resp, err := h.staticUseCase.ExportDir(c, dto)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
@@ -95,3 +95,69 @@ func (h TagHandler) Delete(c *gin.Context) {
 	}
 	c.JSON(http.StatusOK, "success")
 }
+
+// GetListWithUsage список тегов с количеством использований, с поиском, сортировкой и постраничной выборкой
+func (h TagHandler) GetListWithUsage(c *gin.Context) {
+	var filter actions.TagFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		_ = c.Error(errors.BadRequest.Wrap(err, "error validating"))
+		return
+	}
+
+	err := h.validator.Validate(c, filter)
+	if err != nil {
+		_ = c.Error(errors.BadRequest.Wrap(err, "error validating"))
+		return
+	}
+
+	list, err := h.tagUseCase.GetListWithUsage(c, filter)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+func (h TagHandler) GetUsage(c *gin.Context) {
+	tagId, err := uuid.Parse(c.Params.ByName("tag-id"))
+	if err != nil {
+		_ = c.Error(errors.NotFound.Wrap(err, "uuid parsing error"))
+		return
+	}
+
+	tag, err := h.tagUseCase.GetUsage(c, tagId)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, tag)
+}
+
+// Merge объединение тегов sourceIds с тегом из пути
+func (h TagHandler) Merge(c *gin.Context) {
+	tagId, err := uuid.Parse(c.Params.ByName("tag-id"))
+	if err != nil {
+		_ = c.Error(errors.NotFound.Wrap(err, "uuid parsing error"))
+		return
+	}
+
+	request := actions.MergeTagsRequest{}
+	if err = c.ShouldBindJSON(&request); err != nil {
+		_ = c.Error(errors.BadRequest.Wrap(err, "error converting request body to action"))
+		return
+	}
+	request.TargetID = tagId
+
+	err = h.validator.Validate(c, request)
+	if err != nil {
+		_ = c.Error(errors.BadRequest.Wrap(err, "error validating"))
+		return
+	}
+
+	resp, err := h.tagUseCase.Merge(c, request)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
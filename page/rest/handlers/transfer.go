@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"net/http"
+	"os"
+	"strconv"
+
+	"github.com/aeroideaservices/focus/page/plugin/actions"
+	"github.com/aeroideaservices/focus/page/rest/services"
+	"github.com/aeroideaservices/focus/services/errors"
+	middleware "github.com/aeroideaservices/focus/services/gin-middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// TransferHandler экспорт и импорт страниц между окружениями
+type TransferHandler struct {
+	transferUseCase *actions.TransferUseCase
+	errorHandler    *middleware.ErrorHandler
+	validator       services.Validator
+}
+
+func NewTransferHandler(
+	transferUseCase *actions.TransferUseCase, errorHandler *middleware.ErrorHandler, validator services.Validator,
+) *TransferHandler {
+	return &TransferHandler{
+		transferUseCase: transferUseCase,
+		errorHandler:    errorHandler,
+		validator:       validator,
+	}
+}
+
+// Export скачивание архива страницы.
+// Архив собирается во временном файле, чтобы ошибка сборки вернулась ответом, а не оборванным файлом.
+func (h TransferHandler) Export(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("page-id"))
+	if err != nil {
+		_ = c.Error(errors.NotFound.Wrap(err, "uuid parsing error"))
+		return
+	}
+
+	tmp, err := os.CreateTemp("", "page-export-*.zip")
+	if err != nil {
+		_ = c.Error(errors.NoType.Wrap(err, "error creating temporary file"))
+		return
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	fileName, err := h.transferUseCase.Export(c, actions.ExportPageRequest{ID: id}, tmp)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.FileAttachment(tmp.Name(), fileName)
+}
+
+// Import загрузка архива страницы.
+// С dryRun=true или при конфликтах возвращается только отчет, иначе страница записывается.
+func (h TransferHandler) Import(c *gin.Context) {
+	file, err := c.FormFile("file")
+	if err != nil {
+		_ = c.Error(errors.BadRequest.Wrap(err, "error getting form file"))
+		return
+	}
+
+	fo, err := file.Open()
+	if err != nil {
+		_ = c.Error(errors.BadRequest.Wrap(err, "error opening form file"))
+		return
+	}
+	defer func() { _ = fo.Close() }()
+
+	dto := actions.ImportPageRequest{Archive: fo, Size: file.Size}
+	if stringFolderId, ok := c.GetPostForm("folderId"); ok && stringFolderId != "" {
+		folderId, err := uuid.Parse(stringFolderId)
+		if err != nil {
+			_ = c.Error(errors.BadRequest.Wrap(err, "error parsing uuid"))
+			return
+		}
+		dto.FolderID = &folderId
+	}
+	if dryRun, ok := c.GetPostForm("dryRun"); ok && dryRun != "" {
+		dto.DryRun, err = strconv.ParseBool(dryRun)
+		if err != nil {
+			_ = c.Error(errors.BadRequest.Wrap(err, "error parsing dryRun"))
+			return
+		}
+	}
+
+	err = h.validator.Validate(c, dto)
+	if err != nil {
+		_ = c.Error(errors.BadRequest.Wrap(err, "error validating"))
+		return
+	}
+
+	report, err := h.transferUseCase.Import(c, dto)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	status := http.StatusOK
+	if report.Applied {
+		status = http.StatusCreated
+	} else if len(report.Conflicts) > 0 {
+		status = http.StatusConflict
+	}
+	c.JSON(status, report)
+}
@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"github.com/aeroideaservices/focus/page/plugin/actions"
+	"github.com/aeroideaservices/focus/page/plugin/entity"
+	"github.com/aeroideaservices/focus/page/rest/services"
+	"github.com/aeroideaservices/focus/services/errors"
+	middleware "github.com/aeroideaservices/focus/services/gin-middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"net/http"
+)
+
+// TranslationHandler переводы страниц, галерей, карточек и тегов.
+// Обработчики создаются для типа сущности и названия параметра маршрута с ее идентификатором.
+type TranslationHandler struct {
+	translationUseCase *actions.TranslationUseCase
+	errorHandler       *middleware.ErrorHandler
+	validator          services.Validator
+}
+
+func NewTranslationHandler(
+	translationUseCase *actions.TranslationUseCase, errorHandler *middleware.ErrorHandler,
+	validator services.Validator,
+) *TranslationHandler {
+	return &TranslationHandler{
+		translationUseCase: translationUseCase,
+		errorHandler:       errorHandler,
+		validator:          validator,
+	}
+}
+
+func (h TranslationHandler) GetLocales(c *gin.Context) {
+	c.JSON(http.StatusOK, h.translationUseCase.GetLocales())
+}
+
+func (h TranslationHandler) GetStatus(c *gin.Context) {
+	pageId, err := uuid.Parse(c.Param("page-id"))
+	if err != nil {
+		_ = c.Error(errors.NotFound.Wrap(err, "uuid parsing error"))
+		return
+	}
+
+	status, err := h.translationUseCase.GetStatus(c, pageId)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, status)
+}
+
+func (h TranslationHandler) GetList(entityType entity.TranslationType, param string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		request, ok := translationRequest(c, entityType, param)
+		if !ok {
+			return
+		}
+
+		translations, err := h.translationUseCase.GetList(c, request)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, translations)
+	}
+}
+
+func (h TranslationHandler) Save(entityType entity.TranslationType, param string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		request, ok := translationRequest(c, entityType, param)
+		if !ok {
+			return
+		}
+
+		dto := actions.SaveTranslationRequest{}
+		if err := c.ShouldBindJSON(&dto); err != nil {
+			_ = c.Error(errors.BadRequest.Wrap(err, "error converting request body to action"))
+			return
+		}
+		dto.TranslationRequest = request
+
+		err := h.validator.Validate(c, dto)
+		if err != nil {
+			_ = c.Error(errors.BadRequest.Wrap(err, "error validating"))
+			return
+		}
+
+		err = h.translationUseCase.Save(c, dto)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, "success")
+	}
+}
+
+func (h TranslationHandler) Delete(entityType entity.TranslationType, param string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		request, ok := translationRequest(c, entityType, param)
+		if !ok {
+			return
+		}
+
+		err := h.translationUseCase.Delete(c, request)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, "success")
+	}
+}
+
+func translationRequest(c *gin.Context, entityType entity.TranslationType, param string) (
+	actions.TranslationRequest, bool,
+) {
+	id, err := uuid.Parse(c.Param(param))
+	if err != nil {
+		_ = c.Error(errors.NotFound.Wrap(err, "uuid parsing error"))
+		return actions.TranslationRequest{}, false
+	}
+
+	return actions.TranslationRequest{EntityType: entityType, ID: id, Locale: c.Param("locale")}, true
+}
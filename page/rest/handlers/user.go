@@ -0,0 +1,190 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/aeroideaservices/focus/page/plugin/actions"
+	"github.com/aeroideaservices/focus/page/rest/services"
+	"github.com/aeroideaservices/focus/services/errors"
+	middleware "github.com/aeroideaservices/focus/services/gin-middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// UserHandler управление спикерами карточек
+type UserHandler struct {
+	userUseCase  *actions.UserUseCase
+	errorHandler *middleware.ErrorHandler
+	validator    services.Validator
+}
+
+func NewUserHandler(
+	userUseCase *actions.UserUseCase, errorHandler *middleware.ErrorHandler, validator services.Validator,
+) *UserHandler {
+	return &UserHandler{
+		userUseCase:  userUseCase,
+		errorHandler: errorHandler,
+		validator:    validator,
+	}
+}
+
+func (h UserHandler) GetList(c *gin.Context) {
+	var filter actions.UserFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		_ = c.Error(errors.BadRequest.Wrap(err, "error validating"))
+		return
+	}
+
+	err := h.validator.Validate(c, filter)
+	if err != nil {
+		_ = c.Error(errors.BadRequest.Wrap(err, "error validating"))
+		return
+	}
+
+	list, err := h.userUseCase.GetList(c, filter)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+func (h UserHandler) GetById(c *gin.Context) {
+	userId, err := uuid.Parse(c.Params.ByName("user-id"))
+	if err != nil {
+		_ = c.Error(errors.NotFound.Wrap(err, "uuid parsing error"))
+		return
+	}
+
+	user, err := h.userUseCase.GetById(c, userId)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, user)
+}
+
+func (h UserHandler) Create(c *gin.Context) {
+	request := actions.SaveUserRequest{}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		_ = c.Error(errors.BadRequest.Wrap(err, "error converting request body to action"))
+		return
+	}
+
+	err := h.validator.Validate(c, request)
+	if err != nil {
+		_ = c.Error(errors.BadRequest.Wrap(err, "error validating"))
+		return
+	}
+
+	resp, err := h.userUseCase.Create(c, request)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	c.JSON(http.StatusCreated, resp)
+}
+
+func (h UserHandler) Update(c *gin.Context) {
+	userId, err := uuid.Parse(c.Params.ByName("user-id"))
+	if err != nil {
+		_ = c.Error(errors.NotFound.Wrap(err, "uuid parsing error"))
+		return
+	}
+
+	request := actions.SaveUserRequest{}
+	if err = c.ShouldBindJSON(&request); err != nil {
+		_ = c.Error(errors.BadRequest.Wrap(err, "error converting request body to action"))
+		return
+	}
+	request.ID = userId
+
+	err = h.validator.Validate(c, request)
+	if err != nil {
+		_ = c.Error(errors.BadRequest.Wrap(err, "error validating"))
+		return
+	}
+
+	err = h.userUseCase.Update(c, request)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, "success")
+}
+
+func (h UserHandler) Delete(c *gin.Context) {
+	userId, err := uuid.Parse(c.Params.ByName("user-id"))
+	if err != nil {
+		_ = c.Error(errors.NotFound.Wrap(err, "uuid parsing error"))
+		return
+	}
+
+	err = h.userUseCase.Delete(c, userId)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, "success")
+}
+
+// UploadPicture загрузка картинки спикера файлом формы file, в папку медиа folderId
+func (h UserHandler) UploadPicture(c *gin.Context) {
+	userId, err := uuid.Parse(c.Params.ByName("user-id"))
+	if err != nil {
+		_ = c.Error(errors.NotFound.Wrap(err, "uuid parsing error"))
+		return
+	}
+
+	file, err := c.FormFile("file")
+	if err != nil {
+		_ = c.Error(errors.BadRequest.Wrap(err, "error getting form file"))
+		return
+	}
+
+	fo, err := file.Open()
+	if err != nil {
+		_ = c.Error(errors.BadRequest.Wrap(err, "error opening form file"))
+		return
+	}
+	defer func() { _ = fo.Close() }()
+
+	request := actions.UploadUserPictureRequest{ID: userId, Filename: file.Filename, Size: file.Size, File: fo}
+	if stringFolderId, ok := c.GetPostForm("folderId"); ok && stringFolderId != "" {
+		folderId, err := uuid.Parse(stringFolderId)
+		if err != nil {
+			_ = c.Error(errors.BadRequest.Wrap(err, "error parsing uuid"))
+			return
+		}
+		request.FolderId = &folderId
+	}
+
+	err = h.validator.Validate(c, request)
+	if err != nil {
+		_ = c.Error(errors.BadRequest.Wrap(err, "error validating"))
+		return
+	}
+
+	user, err := h.userUseCase.UploadPicture(c, request)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, user)
+}
+
+// GetCards карточки, в которых указан спикер
+func (h UserHandler) GetCards(c *gin.Context) {
+	userId, err := uuid.Parse(c.Params.ByName("user-id"))
+	if err != nil {
+		_ = c.Error(errors.NotFound.Wrap(err, "uuid parsing error"))
+		return
+	}
+
+	cards, err := h.userUseCase.GetCards(c, userId)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, cards)
+}
@@ -90,6 +90,28 @@ func (h VideoHandler) GenerateSubtitles(c *gin.Context) {
 	c.JSON(http.StatusOK, "success")
 }
 
+// GenerateStreams постановка видео в очередь на формирование потоков HLS
+func (h VideoHandler) GenerateStreams(c *gin.Context) {
+	var mediaStrIds []string
+	if err := c.ShouldBindJSON(&mediaStrIds); err != nil {
+		_ = c.Error(errors.BadRequest.Wrap(err, "error validating"))
+		return
+	}
+
+	mediaIds, err := services.GetIdsFromStrings(mediaStrIds)
+	if err != nil {
+		_ = c.Error(errors.NotFound.Wrap(err, "uuid parsing error"))
+		return
+	}
+
+	err = h.videoUseCase.GenerateStreams(c, mediaIds)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	c.JSON(http.StatusAccepted, "success")
+}
+
 func (h VideoHandler) UpdateSubtitles(c *gin.Context) {
 	mediaId, err := uuid.Parse(c.Params.ByName("media-id"))
 	if err != nil {
@@ -111,3 +133,57 @@ func (h VideoHandler) UpdateSubtitles(c *gin.Context) {
 	}
 	c.JSON(http.StatusOK, "success")
 }
+
+// GetJob получение задачи обработки видео
+func (h VideoHandler) GetJob(c *gin.Context) {
+	jobId, err := uuid.Parse(c.Params.ByName("job-id"))
+	if err != nil {
+		_ = c.Error(errors.NotFound.Wrap(err, "uuid parsing error"))
+		return
+	}
+
+	job, err := h.videoUseCase.GetJob(c, jobId)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, job)
+}
+
+// GetJobs получение списка задач обработки видео, по умолчанию задач в статусе failed
+func (h VideoHandler) GetJobs(c *gin.Context) {
+	var filter actions.JobFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		_ = c.Error(errors.BadRequest.Wrap(err, "error validating"))
+		return
+	}
+
+	err := h.validator.Validate(c, filter)
+	if err != nil {
+		_ = c.Error(errors.BadRequest.Wrap(err, "error validating"))
+		return
+	}
+
+	list, err := h.videoUseCase.GetJobs(c, filter)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+// RetryJob повторная постановка в очередь задачи в статусе failed
+func (h VideoHandler) RetryJob(c *gin.Context) {
+	jobId, err := uuid.Parse(c.Params.ByName("job-id"))
+	if err != nil {
+		_ = c.Error(errors.NotFound.Wrap(err, "uuid parsing error"))
+		return
+	}
+
+	err = h.videoUseCase.RetryJob(c, jobId)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	c.JSON(http.StatusAccepted, "success")
+}
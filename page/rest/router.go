@@ -1,33 +1,58 @@
 package rest
 
 import (
+	"github.com/aeroideaservices/focus/page/plugin/actions"
+	"github.com/aeroideaservices/focus/page/plugin/entity"
 	"github.com/aeroideaservices/focus/page/rest/handlers"
 	"github.com/aeroideaservices/focus/page/rest/services"
 	"github.com/gin-gonic/gin"
 )
 
 type Router struct {
-	pageHandler    *handlers.PageHandler
-	galleryHandler *handlers.GalleryHandler
-	cardHandler    *handlers.CardHandler
-	tagHandler     *handlers.TagHandler
-	videoHandler   *handlers.VideoHandler
-	errorHandler   services.ErrorHandler
+	pageHandler        *handlers.PageHandler
+	galleryHandler     *handlers.GalleryHandler
+	cardHandler        *handlers.CardHandler
+	tagHandler         *handlers.TagHandler
+	videoHandler       *handlers.VideoHandler
+	publicationHandler *handlers.PublicationHandler
+	previewHandler     *handlers.PreviewHandler
+	cloneHandler       *handlers.CloneHandler
+	translationHandler *handlers.TranslationHandler
+	transferHandler    *handlers.TransferHandler
+	userHandler        *handlers.UserHandler
+	searchHandler      *handlers.SearchHandler
+	staticHandler      *handlers.StaticHandler
+	snapshotHandler    *handlers.SnapshotHandler
+	errorHandler       services.ErrorHandler
+	draftEdits         bool // draftEdits режим редактирования через черновики
 }
 
 func NewRouter(
 	pageHandler *handlers.PageHandler, galleryHandler *handlers.GalleryHandler,
 	cardHandler *handlers.CardHandler, tagHandler *handlers.TagHandler,
-	videoHandler *handlers.VideoHandler,
-	errorHandler services.ErrorHandler,
+	videoHandler *handlers.VideoHandler, publicationHandler *handlers.PublicationHandler,
+	previewHandler *handlers.PreviewHandler, cloneHandler *handlers.CloneHandler,
+	translationHandler *handlers.TranslationHandler, transferHandler *handlers.TransferHandler,
+	userHandler *handlers.UserHandler, searchHandler *handlers.SearchHandler, staticHandler *handlers.StaticHandler,
+	snapshotHandler *handlers.SnapshotHandler, errorHandler services.ErrorHandler, draftEdits bool,
 ) *Router {
 	return &Router{
-		pageHandler:    pageHandler,
-		galleryHandler: galleryHandler,
-		cardHandler:    cardHandler,
-		tagHandler:     tagHandler,
-		videoHandler:   videoHandler,
-		errorHandler:   errorHandler,
+		pageHandler:        pageHandler,
+		galleryHandler:     galleryHandler,
+		cardHandler:        cardHandler,
+		tagHandler:         tagHandler,
+		videoHandler:       videoHandler,
+		publicationHandler: publicationHandler,
+		previewHandler:     previewHandler,
+		cloneHandler:       cloneHandler,
+		translationHandler: translationHandler,
+		transferHandler:    transferHandler,
+		userHandler:        userHandler,
+		searchHandler:      searchHandler,
+		staticHandler:      staticHandler,
+		snapshotHandler:    snapshotHandler,
+		errorHandler:       errorHandler,
+		draftEdits:         draftEdits,
 	}
 }
 
@@ -36,12 +61,32 @@ func (r *Router) SetRoutes(routerGroup *gin.RouterGroup) {
 	pages.Use(r.errorHandler.Handle)
 	pages.POST("", r.pageHandler.Create)
 	pages.GET("", r.pageHandler.GetList)
+	pages.GET("/tree", r.pageHandler.GetTree)
+	pages.GET("/locales", r.translationHandler.GetLocales)
+	pages.GET("/search", r.searchHandler.Search)
+	pages.GET("/static/archive", r.staticHandler.ExportZip)
+	pages.POST("/static", r.staticHandler.ExportDir)
 	pages.GET("/:page-id", r.pageHandler.GetById)
-	pages.DELETE("/:page-id", r.pageHandler.Delete)
-	pages.PATCH("/:page-id/galleries/:gallery-id", r.pageHandler.PatchGalleryPosition)
-	pages.PATCH("/:page-id/galleries/link", r.pageHandler.LinkGalleries)
-	pages.PATCH("/:page-id/galleries/unlink", r.pageHandler.UnlinkGalleries)
-	pages.PATCH("/:page-id/properties", r.pageHandler.PatchProperties)
+	pages.DELETE("/:page-id", r.unpublished(r.pageHandler.Delete, entity.DraftTypePage, "page-id"))
+	pages.PATCH("/:page-id/galleries/:gallery-id", r.live(r.pageHandler.PatchGalleryPosition))
+	pages.PUT("/:page-id/galleries/order", r.live(r.pageHandler.ReorderGalleries))
+	pages.PATCH("/:page-id/galleries/link", r.live(r.pageHandler.LinkGalleries))
+	pages.PATCH("/:page-id/galleries/unlink", r.live(r.pageHandler.UnlinkGalleries))
+	pages.PATCH("/:page-id/properties", r.edit(r.pageHandler.PatchProperties, entity.DraftTypePage, "page-id"))
+	pages.PATCH("/:page-id/parent", r.live(r.pageHandler.Move))
+	pages.POST("/:page-id/clone", r.cloneHandler.ClonePage)
+	pages.POST("/positions/repair", r.pageHandler.RepairPositions)
+	pages.POST("/import", r.transferHandler.Import)
+	pages.GET("/:page-id/export", r.transferHandler.Export)
+	pages.GET("/:page-id/snapshots", r.snapshotHandler.GetList)
+	pages.POST("/:page-id/snapshots", r.snapshotHandler.Create)
+	pages.GET("/:page-id/snapshots/diff", r.snapshotHandler.Diff)
+	pages.GET("/:page-id/snapshots/:snapshot-id", r.snapshotHandler.GetById)
+	pages.POST("/:page-id/snapshots/:snapshot-id/restore", r.snapshotHandler.Restore)
+	r.setPublicationRoutes(pages, entity.DraftTypePage, "page-id")
+	r.setTranslationRoutes(pages, entity.TranslationTypePage, "page-id")
+	pages.GET("/:page-id/translations/status", r.translationHandler.GetStatus)
+	pages.DELETE("/preview-tokens/:token-id", r.previewHandler.Revoke)
 
 	galleries := pages.Group("galleries")
 
@@ -49,35 +94,113 @@ func (r *Router) SetRoutes(routerGroup *gin.RouterGroup) {
 	galleries.POST("", r.galleryHandler.Create)
 	galleries.DELETE("", r.galleryHandler.DeleteList)
 	galleries.GET("/:gallery-id", r.galleryHandler.GetById)
-	galleries.PUT("/:gallery-id", r.galleryHandler.Update)
-	galleries.PATCH("/:gallery-id/name", r.galleryHandler.PatchName)
-	galleries.PATCH("/:gallery-id/card/:card-id", r.galleryHandler.PatchCardPosition)
-	galleries.PATCH("/:gallery-id/card/link", r.galleryHandler.LinkCards)
-	galleries.PATCH("/:gallery-id/card/unlink", r.galleryHandler.UnlinkCards)
+	galleries.PUT("/:gallery-id", r.edit(r.galleryHandler.Update, entity.DraftTypeGallery, "gallery-id"))
+	galleries.PATCH("/:gallery-id/name", r.live(r.galleryHandler.PatchName))
+	galleries.PATCH("/:gallery-id/card/:card-id", r.live(r.galleryHandler.PatchCardPosition))
+	galleries.PUT("/:gallery-id/card/order", r.live(r.galleryHandler.ReorderCards))
+	galleries.PATCH("/:gallery-id/card/link", r.live(r.galleryHandler.LinkCards))
+	galleries.PATCH("/:gallery-id/card/unlink", r.live(r.galleryHandler.UnlinkCards))
+	galleries.POST("/:gallery-id/clone", r.cloneHandler.CloneGallery)
+	r.setPublicationRoutes(galleries, entity.DraftTypeGallery, "gallery-id")
+	r.setTranslationRoutes(galleries, entity.TranslationTypeGallery, "gallery-id")
 
 	cards := pages.Group("cards")
 
 	cards.GET("", r.cardHandler.GetList)
 	cards.POST("", r.cardHandler.Create)
+	cards.GET("/types", r.cardHandler.GetTypes)
 	cards.GET("/:card-id", r.cardHandler.GetById)
-	cards.PUT("/:card-id", r.cardHandler.Update)
-	cards.DELETE("/:card-id", r.cardHandler.Delete)
-	cards.PATCH("/:card-id/user", r.cardHandler.PatchUser)
-	cards.PATCH("/:card-id/previewtext", r.cardHandler.PatchPreviewText)
-	cards.PATCH("/:card-id/detailtext", r.cardHandler.PatchDetailText)
-	cards.PATCH("/:card-id/learn-more-url", r.cardHandler.PatchLearnMoreUrl)
-	cards.PATCH("/:card-id/tags", r.cardHandler.PatchTags)
-	cards.PATCH("/:card-id/tags/link", r.cardHandler.LinkTags)
-	cards.PATCH("/:card-id/tags/unlink", r.cardHandler.UnlinkTags)
+	cards.PUT("/:card-id", r.edit(r.cardHandler.Update, entity.DraftTypeCard, "card-id"))
+	cards.DELETE("/:card-id", r.unpublished(r.cardHandler.Delete, entity.DraftTypeCard, "card-id"))
+	cards.PATCH("/:card-id/user", r.live(r.cardHandler.PatchUser))
+	cards.PATCH("/:card-id/previewtext", r.live(r.cardHandler.PatchPreviewText))
+	cards.PATCH("/:card-id/detailtext", r.live(r.cardHandler.PatchDetailText))
+	cards.PATCH("/:card-id/learn-more-url", r.live(r.cardHandler.PatchLearnMoreUrl))
+	cards.PATCH("/:card-id/tags", r.live(r.cardHandler.PatchTags))
+	cards.PATCH("/:card-id/tags/link", r.live(r.cardHandler.LinkTags))
+	cards.PATCH("/:card-id/tags/unlink", r.live(r.cardHandler.UnlinkTags))
+	cards.POST("/:card-id/clone", r.cloneHandler.CloneCard)
+	r.setPublicationRoutes(cards, entity.DraftTypeCard, "card-id")
+	r.setTranslationRoutes(cards, entity.TranslationTypeCard, "card-id")
 
 	tags := pages.Group("tags")
 
 	tags.GET("", r.tagHandler.GetList)
 	tags.POST("", r.tagHandler.Create)
-	tags.PUT("/:tag-id", r.tagHandler.Update)
+	tags.PUT("/:tag-id", r.live(r.tagHandler.Update))
 	tags.DELETE("/:tag-id", r.tagHandler.Delete)
+	tags.GET("/usage", r.tagHandler.GetListWithUsage)
+	tags.GET("/:tag-id/usage", r.tagHandler.GetUsage)
+	tags.POST("/:tag-id/merge", r.tagHandler.Merge)
+	r.setTranslationRoutes(tags, entity.TranslationTypeTag, "tag-id")
+
+	users := pages.Group("users")
+	users.GET("", r.userHandler.GetList)
+	users.POST("", r.userHandler.Create)
+	users.GET("/:user-id", r.userHandler.GetById)
+	users.PUT("/:user-id", r.userHandler.Update)
+	users.DELETE("/:user-id", r.userHandler.Delete)
+	users.POST("/:user-id/picture", r.userHandler.UploadPicture)
+	users.GET("/:user-id/cards", r.userHandler.GetCards)
 
 	pages.POST("/video/upload", r.videoHandler.Create)
 	pages.POST("/video/generate/subtitles", r.videoHandler.GenerateSubtitles)
+	pages.POST("/video/generate/streams", r.videoHandler.GenerateStreams)
 	pages.PUT("/video/:media-id/subtitles", r.videoHandler.UpdateSubtitles)
+	pages.GET("/video/jobs", r.videoHandler.GetJobs)
+	pages.GET("/video/jobs/:job-id", r.videoHandler.GetJob)
+	pages.POST("/video/jobs/:job-id/retry", r.videoHandler.RetryJob)
+}
+
+// edit изменение сущности: в режиме редактирования через черновики сохраняется в черновик,
+// иначе изменяет опубликованную сущность обработчиком handler
+func (r *Router) edit(handler gin.HandlerFunc, entityType entity.DraftType, param string) gin.HandlerFunc {
+	if r.draftEdits {
+		return r.publicationHandler.SaveEdit(entityType, param)
+	}
+
+	return handler
+}
+
+// live изменение опубликованных данных в обход черновика, в режиме редактирования через черновики запрещено:
+// состав галерей страницы и карточек галереи задается в черновике полями galleryIds и cardIds,
+// а перемещение страниц и изменение тегов, у которых нет черновиков, недоступно
+func (r *Router) live(handler gin.HandlerFunc) gin.HandlerFunc {
+	if r.draftEdits {
+		return func(c *gin.Context) {
+			_ = c.Error(actions.ErrDraftEdits)
+		}
+	}
+
+	return handler
+}
+
+// unpublished удаление сущности, в режиме редактирования через черновики доступно только после снятия с публикации
+func (r *Router) unpublished(handler gin.HandlerFunc, entityType entity.DraftType, param string) gin.HandlerFunc {
+	if r.draftEdits {
+		return r.publicationHandler.Unpublished(entityType, param, handler)
+	}
+
+	return handler
+}
+
+// setPublicationRoutes маршруты черновика, публикации и предпросмотра сущности типа entityType
+func (r *Router) setPublicationRoutes(group *gin.RouterGroup, entityType entity.DraftType, param string) {
+	path := "/:" + param
+	group.GET(path+"/draft", r.publicationHandler.GetDraft(entityType, param))
+	group.PUT(path+"/draft", r.publicationHandler.SaveDraft(entityType, param))
+	group.DELETE(path+"/draft", r.publicationHandler.DeleteDraft(entityType, param))
+	group.POST(path+"/publish", r.publicationHandler.Publish(entityType, param))
+	group.POST(path+"/unpublish", r.publicationHandler.Unpublish(entityType, param))
+	group.PUT(path+"/schedule", r.publicationHandler.Schedule(entityType, param))
+	group.POST(path+"/preview-tokens", r.previewHandler.Create(entityType, param))
+	group.GET(path+"/preview-tokens", r.previewHandler.GetList(entityType, param))
+}
+
+// setTranslationRoutes маршруты переводов сущности типа entityType
+func (r *Router) setTranslationRoutes(group *gin.RouterGroup, entityType entity.TranslationType, param string) {
+	path := "/:" + param + "/translations"
+	group.GET(path, r.translationHandler.GetList(entityType, param))
+	group.PUT(path+"/:locale", r.translationHandler.Save(entityType, param))
+	group.DELETE(path+"/:locale", r.translationHandler.Delete(entityType, param))
 }
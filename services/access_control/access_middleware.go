@@ -33,7 +33,8 @@ func (m AccessMiddleware) CheckAccess(c *gin.Context) {
 		return
 	}
 
-	role := NewRole("", strings.Split(claims.Scope, " "))
+	scopes := strings.Split(claims.Scope, " ")
+	role := NewRole("", scopes)
 
 	action := m.newAction(c)
 	if role == nil || !role.HasAccess(action) {
@@ -42,6 +43,7 @@ func (m AccessMiddleware) CheckAccess(c *gin.Context) {
 	}
 
 	c.Set("role", role)
+	c.Set("scopes", scopes)
 	c.Set("user-full-name", strings.TrimSpace(claims.LastName+" "+claims.FirstName+" "+claims.MiddleName))
 	c.Set("user-id", claims.Subject)
 	c.Next()
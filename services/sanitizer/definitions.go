@@ -0,0 +1,16 @@
+package sanitizer
+
+import "github.com/sarulabs/di/v2"
+
+var Definitions = []di.Def{
+	{
+		Name: "focus.sanitizer",
+		Build: func(ctn di.Container) (interface{}, error) {
+			var policies []Policy
+			if policiesI, _ := ctn.SafeGet("focus.sanitizer.policies"); policiesI != nil {
+				policies = policiesI.([]Policy)
+			}
+			return NewSanitizer(policies...), nil
+		},
+	},
+}
@@ -0,0 +1,8 @@
+module github.com/aeroideaservices/focus/services/sanitizer
+
+go 1.18
+
+require (
+	github.com/sarulabs/di/v2 v2.4.2
+	golang.org/x/net v0.25.0
+)
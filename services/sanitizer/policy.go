@@ -0,0 +1,75 @@
+package sanitizer
+
+const (
+	PolicyText    = "text"    // PolicyText только форматирование текста
+	PolicyWysiwyg = "wysiwyg" // PolicyWysiwyg разметка визуального редактора: текст, ссылки, списки, таблицы, изображения
+	PolicyEmbed   = "embed"   // PolicyEmbed разметка визуального редактора и встраиваемые видео и плееры
+)
+
+// Policy разрешающая политика очистки html.
+// Все, что не разрешено политикой явно, удаляется.
+type Policy struct {
+	Name        string              // Name код политики
+	Tags        []string            // Tags разрешенные теги
+	Attributes  map[string][]string // Attributes разрешенные атрибуты по тегам, атрибуты по ключу "*" разрешены для всех тегов
+	URLSchemes  []string            // URLSchemes разрешенные схемы ссылок, относительные ссылки разрешены всегда
+	IframeHosts []string            // IframeHosts хосты, с которых разрешено встраивание iframe
+}
+
+var textTags = []string{"p", "br", "span", "b", "strong", "i", "em", "u", "s", "sub", "sup"}
+
+var wysiwygTags = append([]string{
+	"a", "div", "h1", "h2", "h3", "h4", "h5", "h6", "blockquote", "pre", "code", "hr", "ul", "ol", "li",
+	"table", "thead", "tbody", "tfoot", "tr", "th", "td", "caption", "img", "figure", "figcaption",
+}, textTags...)
+
+var wysiwygAttributes = map[string][]string{
+	"*":   {"class", "title"},
+	"a":   {"href", "target", "rel"},
+	"img": {"src", "alt", "width", "height"},
+	"th":  {"colspan", "rowspan"},
+	"td":  {"colspan", "rowspan"},
+	"ol":  {"start"},
+}
+
+// DefaultPolicies политики, доступные без настройки
+func DefaultPolicies() []Policy {
+	return []Policy{
+		{
+			Name: PolicyText,
+			Tags: textTags,
+		},
+		{
+			Name:       PolicyWysiwyg,
+			Tags:       wysiwygTags,
+			Attributes: wysiwygAttributes,
+			URLSchemes: []string{"http", "https", "mailto", "tel"},
+		},
+		{
+			Name: PolicyEmbed,
+			Tags: append([]string{"iframe", "video", "audio", "source"}, wysiwygTags...),
+			Attributes: merge(wysiwygAttributes, map[string][]string{
+				"iframe": {"src", "width", "height", "allow", "allowfullscreen", "frameborder", "loading"},
+				"video":  {"src", "poster", "width", "height", "controls", "autoplay", "muted", "loop", "playsinline"},
+				"audio":  {"src", "controls", "loop", "muted"},
+				"source": {"src", "type"},
+			}),
+			URLSchemes: []string{"http", "https", "mailto", "tel"},
+			IframeHosts: []string{
+				"www.youtube.com", "youtube.com", "www.youtube-nocookie.com", "player.vimeo.com",
+				"rutube.ru", "vk.com", "vkvideo.ru",
+			},
+		},
+	}
+}
+
+func merge(attributes ...map[string][]string) map[string][]string {
+	merged := make(map[string][]string)
+	for _, attrs := range attributes {
+		for tag, names := range attrs {
+			merged[tag] = append(merged[tag], names...)
+		}
+	}
+
+	return merged
+}
@@ -0,0 +1,264 @@
+// Package sanitizer очистка html, введенного редакторами, по разрешающим политикам.
+// Результат очистки сопровождается отчетом об удаленных тегах и атрибутах, который возвращается редактору.
+package sanitizer
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+var ErrUnknownPolicy = errors.New("unknown sanitize policy") // ErrUnknownPolicy политика не зарегистрирована
+
+type Reason string
+
+const (
+	ReasonTag        Reason = "tag"        // ReasonTag тег не разрешен политикой
+	ReasonAttribute  Reason = "attribute"  // ReasonAttribute атрибут не разрешен политикой
+	ReasonURLScheme  Reason = "urlScheme"  // ReasonURLScheme схема ссылки не разрешена политикой
+	ReasonIframeHost Reason = "iframeHost" // ReasonIframeHost встраивание с хоста не разрешено политикой
+	ReasonComment    Reason = "comment"    // ReasonComment html-комментарий
+)
+
+// maxValueLength ограничение длины значения удаленного атрибута в отчете
+const maxValueLength = 100
+
+// dropContent теги, которые удаляются вместе с содержимым
+var dropContent = map[string]bool{
+	"script": true, "style": true, "iframe": true, "object": true, "noscript": true, "noembed": true,
+	"noframes": true, "template": true, "svg": true, "math": true, "textarea": true, "select": true,
+	"title": true, "head": true, "xmp": true, "plaintext": true,
+}
+
+// urlAttributes атрибуты, значения которых являются ссылками
+var urlAttributes = map[string]bool{
+	"href": true, "src": true, "cite": true, "action": true, "formaction": true, "poster": true,
+	"background": true, "longdesc": true, "data": true,
+}
+
+// Removal удаленный тег или атрибут
+type Removal struct {
+	Field     string `json:"field,omitempty"`     // Field код поля, в котором производилась очистка
+	Tag       string `json:"tag"`                 // Tag тег
+	Attribute string `json:"attribute,omitempty"` // Attribute атрибут, если удален только атрибут
+	Value     string `json:"value,omitempty"`     // Value значение удаленного атрибута
+	Reason    Reason `json:"reason"`              // Reason причина удаления
+	Count     int    `json:"count"`               // Count количество одинаковых удалений
+}
+
+// Report отчет об очистке
+type Report struct {
+	Removed []Removal `json:"removed"`
+}
+
+// Empty ничего не было удалено
+func (r Report) Empty() bool {
+	return len(r.Removed) == 0
+}
+
+// Add добавление отчета об очистке поля
+func (r *Report) Add(field string, report Report) {
+	for _, removal := range report.Removed {
+		removal.Field = field
+		r.add(removal)
+	}
+}
+
+func (r *Report) add(removal Removal) {
+	if removal.Count == 0 {
+		removal.Count = 1
+	}
+	for i, removed := range r.Removed {
+		if removed.Field == removal.Field && removed.Tag == removal.Tag &&
+			removed.Attribute == removal.Attribute && removed.Reason == removal.Reason {
+			r.Removed[i].Count += removal.Count
+			return
+		}
+	}
+	if len(removal.Value) > maxValueLength {
+		removal.Value = removal.Value[:maxValueLength]
+	}
+
+	r.Removed = append(r.Removed, removal)
+}
+
+// Sanitizer очистка html по зарегистрированным политикам
+type Sanitizer struct {
+	policies map[string]*policy
+}
+
+// NewSanitizer конструктор.
+// Регистрирует политики по умолчанию и переданные политики, политика с существующим кодом заменяет прежнюю.
+func NewSanitizer(policies ...Policy) *Sanitizer {
+	s := &Sanitizer{policies: make(map[string]*policy)}
+	for _, p := range append(DefaultPolicies(), policies...) {
+		s.policies[p.Name] = newPolicy(p)
+	}
+
+	return s
+}
+
+// Has зарегистрирована ли политика
+func (s Sanitizer) Has(policyName string) bool {
+	_, ok := s.policies[policyName]
+	return ok
+}
+
+// Sanitize очистка html по политике с отчетом об удаленном
+func (s Sanitizer) Sanitize(policyName string, input string) (string, Report, error) {
+	p, ok := s.policies[policyName]
+	if !ok {
+		return "", Report{}, fmt.Errorf("%w: %s", ErrUnknownPolicy, policyName)
+	}
+
+	output, report := p.sanitize(input)
+	return output, report, nil
+}
+
+type policy struct {
+	tags        map[string]bool
+	attributes  map[string]map[string]bool
+	schemes     map[string]bool
+	iframeHosts map[string]bool
+}
+
+func newPolicy(p Policy) *policy {
+	compiled := &policy{
+		tags:        set(p.Tags),
+		attributes:  make(map[string]map[string]bool, len(p.Attributes)),
+		schemes:     set(p.URLSchemes),
+		iframeHosts: set(p.IframeHosts),
+	}
+	for tag, names := range p.Attributes {
+		compiled.attributes[strings.ToLower(tag)] = set(names)
+	}
+
+	return compiled
+}
+
+func (p policy) sanitize(input string) (string, Report) {
+	var output strings.Builder
+	var report Report
+	// skip тег, содержимое которого пропускается до закрывающего тега
+	var skip string
+	var depth int
+
+	tokenizer := html.NewTokenizer(strings.NewReader(input))
+	for {
+		tokenType := tokenizer.Next()
+		if tokenType == html.ErrorToken {
+			break
+		}
+		token := tokenizer.Token()
+
+		if skip != "" {
+			switch {
+			case tokenType == html.StartTagToken && token.Data == skip:
+				depth++
+			case tokenType == html.EndTagToken && token.Data == skip:
+				depth--
+				if depth == 0 {
+					skip = ""
+				}
+			}
+			continue
+		}
+
+		switch tokenType {
+		case html.TextToken:
+			output.WriteString(html.EscapeString(token.Data))
+		case html.StartTagToken, html.SelfClosingTagToken:
+			reason := p.checkTag(token)
+			if reason != "" {
+				report.add(Removal{Tag: token.Data, Value: attribute(token, "src"), Reason: reason})
+				if tokenType == html.StartTagToken && dropContent[token.Data] {
+					skip, depth = token.Data, 1
+				}
+				continue
+			}
+			token.Attr = p.filterAttributes(token, &report)
+			output.WriteString(token.String())
+		case html.EndTagToken:
+			if p.tags[token.Data] {
+				output.WriteString(token.String())
+			}
+		case html.CommentToken:
+			report.add(Removal{Reason: ReasonComment})
+		}
+	}
+
+	return output.String(), report
+}
+
+// checkTag причина удаления тега, пустая строка, если тег разрешен
+func (p policy) checkTag(token html.Token) Reason {
+	if !p.tags[token.Data] {
+		return ReasonTag
+	}
+	if token.Data != "iframe" {
+		return ""
+	}
+
+	src, err := url.Parse(strings.TrimSpace(attribute(token, "src")))
+	if err != nil || !p.schemes[strings.ToLower(src.Scheme)] || !p.iframeHosts[strings.ToLower(src.Hostname())] {
+		return ReasonIframeHost
+	}
+
+	return ""
+}
+
+func (p policy) filterAttributes(token html.Token, report *Report) []html.Attribute {
+	attrs := make([]html.Attribute, 0, len(token.Attr))
+	for _, attr := range token.Attr {
+		removal := Removal{Tag: token.Data, Attribute: attr.Key}
+		switch {
+		case attr.Namespace != "" || strings.HasPrefix(attr.Key, "on") || !p.attributeAllowed(token.Data, attr.Key):
+			removal.Reason = ReasonAttribute
+		case urlAttributes[attr.Key] && !p.urlAllowed(attr.Val):
+			removal.Reason, removal.Value = ReasonURLScheme, attr.Val
+		default:
+			attrs = append(attrs, attr)
+			continue
+		}
+		report.add(removal)
+	}
+
+	return attrs
+}
+
+func (p policy) attributeAllowed(tag string, name string) bool {
+	return p.attributes[tag][name] || p.attributes["*"][name]
+}
+
+// urlAllowed относительные ссылки разрешены, абсолютные - только с разрешенной схемой.
+// Ссылки с управляющими символами не разбираются и удаляются.
+func (p policy) urlAllowed(value string) bool {
+	u, err := url.Parse(strings.TrimSpace(value))
+	if err != nil {
+		return false
+	}
+
+	return u.Scheme == "" || p.schemes[strings.ToLower(u.Scheme)]
+}
+
+func attribute(token html.Token, name string) string {
+	for _, attr := range token.Attr {
+		if attr.Key == name {
+			return attr.Val
+		}
+	}
+
+	return ""
+}
+
+func set(values []string) map[string]bool {
+	result := make(map[string]bool, len(values))
+	for _, value := range values {
+		result[strings.ToLower(value)] = true
+	}
+
+	return result
+}
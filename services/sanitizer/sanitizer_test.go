@@ -0,0 +1,141 @@
+package sanitizer
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSanitizer_Sanitize(t *testing.T) {
+	tests := []struct {
+		name        string
+		policy      string
+		input       string
+		want        string
+		wantRemoved []Removal
+	}{
+		{
+			name:   "allowed markup is kept",
+			policy: PolicyWysiwyg,
+			input:  `<p class="lead">Hello, <a href="https://example.com" target="_blank">world</a></p>`,
+			want:   `<p class="lead">Hello, <a href="https://example.com" target="_blank">world</a></p>`,
+		},
+		{
+			name:        "script is removed with content",
+			policy:      PolicyWysiwyg,
+			input:       `<p>a</p><script>alert("x")</script><p>b</p>`,
+			want:        `<p>a</p><p>b</p>`,
+			wantRemoved: []Removal{{Tag: "script", Reason: ReasonTag, Count: 1}},
+		},
+		{
+			name:        "unknown tag is unwrapped",
+			policy:      PolicyText,
+			input:       `<font color="red">text</font>`,
+			want:        `text`,
+			wantRemoved: []Removal{{Tag: "font", Reason: ReasonTag, Count: 1}},
+		},
+		{
+			name:   "event handlers and style are removed",
+			policy: PolicyWysiwyg,
+			input:  `<img src="/a.png" onerror="alert(1)" style="x"><img src="/b.png" onerror="alert(2)">`,
+			want:   `<img src="/a.png"><img src="/b.png">`,
+			wantRemoved: []Removal{
+				{Tag: "img", Attribute: "onerror", Reason: ReasonAttribute, Count: 2},
+				{Tag: "img", Attribute: "style", Reason: ReasonAttribute, Count: 1},
+			},
+		},
+		{
+			name:   "javascript links are removed",
+			policy: PolicyWysiwyg,
+			input:  `<a href=" JavaScript:alert(1)">x</a><a href="java&#x09;script:alert(1)">y</a><a href="/page">z</a>`,
+			want:   `<a>x</a><a>y</a><a href="/page">z</a>`,
+			wantRemoved: []Removal{
+				{Tag: "a", Attribute: "href", Value: " JavaScript:alert(1)", Reason: ReasonURLScheme, Count: 2},
+			},
+		},
+		{
+			name:   "iframe from allowed host",
+			policy: PolicyEmbed,
+			input:  `<iframe src="https://www.youtube.com/embed/x" allowfullscreen></iframe>`,
+			want:   `<iframe src="https://www.youtube.com/embed/x" allowfullscreen=""></iframe>`,
+		},
+		{
+			name:   "iframe from other host is removed with content",
+			policy: PolicyEmbed,
+			input:  `<iframe src="https://evil.example/embed">fallback</iframe><p>text</p>`,
+			want:   `<p>text</p>`,
+			wantRemoved: []Removal{
+				{Tag: "iframe", Value: "https://evil.example/embed", Reason: ReasonIframeHost, Count: 1},
+			},
+		},
+		{
+			name:        "iframe is not allowed in wysiwyg",
+			policy:      PolicyWysiwyg,
+			input:       `<iframe src="https://www.youtube.com/embed/x"></iframe>`,
+			want:        ``,
+			wantRemoved: []Removal{{Tag: "iframe", Value: "https://www.youtube.com/embed/x", Reason: ReasonTag, Count: 1}},
+		},
+		{
+			name:        "comments are removed, text is escaped",
+			policy:      PolicyText,
+			input:       `<!-- <script> -->1 &lt; 2 &amp; <b>3</b>`,
+			want:        `1 &lt; 2 &amp; <b>3</b>`,
+			wantRemoved: []Removal{{Reason: ReasonComment, Count: 1}},
+		},
+	}
+	s := NewSanitizer()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, report, err := s.Sanitize(tt.policy, tt.input)
+			if err != nil {
+				t.Fatalf("Sanitize() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Sanitize() got = %q, want %q", got, tt.want)
+			}
+			if len(report.Removed) != len(tt.wantRemoved) {
+				t.Fatalf("Sanitize() removed = %+v, want %+v", report.Removed, tt.wantRemoved)
+			}
+			for i := range tt.wantRemoved {
+				if report.Removed[i] != tt.wantRemoved[i] {
+					t.Errorf("Sanitize() removed[%d] = %+v, want %+v", i, report.Removed[i], tt.wantRemoved[i])
+				}
+			}
+		})
+	}
+}
+
+func TestSanitizer_Policies(t *testing.T) {
+	s := NewSanitizer(Policy{Name: PolicyText, Tags: []string{"b"}}, Policy{Name: "plain"})
+
+	got, _, err := s.Sanitize(PolicyText, "<b>a</b><i>b</i>")
+	if err != nil || got != "<b>a</b>b" {
+		t.Errorf("Sanitize() with overridden policy = %q, %v", got, err)
+	}
+	got, _, err = s.Sanitize("plain", "<b>a</b>")
+	if err != nil || got != "a" {
+		t.Errorf("Sanitize() with custom policy = %q, %v", got, err)
+	}
+	if _, _, err = s.Sanitize("unknown", "a"); !errors.Is(err, ErrUnknownPolicy) {
+		t.Errorf("Sanitize() with unknown policy error = %v, want ErrUnknownPolicy", err)
+	}
+}
+
+func TestReport_Add(t *testing.T) {
+	report := Report{}
+	report.Add("text", Report{Removed: []Removal{{Tag: "script", Reason: ReasonTag, Count: 1}}})
+	report.Add("text", Report{Removed: []Removal{{Tag: "script", Reason: ReasonTag, Count: 2}}})
+	report.Add("description", Report{Removed: []Removal{{Tag: "script", Reason: ReasonTag, Count: 1}}})
+
+	want := []Removal{
+		{Field: "text", Tag: "script", Reason: ReasonTag, Count: 3},
+		{Field: "description", Tag: "script", Reason: ReasonTag, Count: 1},
+	}
+	if len(report.Removed) != len(want) {
+		t.Fatalf("Add() removed = %+v, want %+v", report.Removed, want)
+	}
+	for i := range want {
+		if report.Removed[i] != want[i] {
+			t.Errorf("Add() removed[%d] = %+v, want %+v", i, report.Removed[i], want[i])
+		}
+	}
+}
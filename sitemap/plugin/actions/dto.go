@@ -0,0 +1,87 @@
+package actions
+
+import (
+	"encoding/xml"
+)
+
+// Xmlns пространство имен протокола sitemaps.org
+const Xmlns = "http://www.sitemaps.org/schemas/sitemap/0.9"
+
+// MaxUrls ограничение протокола на количество адресов в одном файле карты сайта
+const MaxUrls = 50000
+
+type GetSitemap struct {
+	Host string `json:"host" validate:"required"` // Host домен из запроса
+	Part int    `json:"part" validate:"min=0"`    // Part номер файла карты сайта начиная с 1, 0 - основной файл
+}
+
+type GetRobots struct {
+	Host string `json:"host" validate:"required"` // Host домен из запроса
+}
+
+// Document файл карты сайта: список адресов или индекс файлов, если адресов больше MaxUrls
+type Document struct {
+	UrlSet *UrlSet
+	Index  *SitemapIndex
+}
+
+// Marshal xml файла карты сайта с заголовком
+func (d Document) Marshal() ([]byte, error) {
+	var value any = d.UrlSet
+	if d.Index != nil {
+		value = d.Index
+	}
+
+	data, err := xml.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte(xml.Header), data...), nil
+}
+
+type UrlSet struct {
+	XMLName xml.Name `xml:"urlset"`
+	Xmlns   string   `xml:"xmlns,attr"`
+	Urls    []UrlDto `xml:"url"`
+}
+
+type UrlDto struct {
+	Loc        string `xml:"loc"`
+	LastMod    string `xml:"lastmod,omitempty"`
+	ChangeFreq string `xml:"changefreq,omitempty"`
+	Priority   string `xml:"priority,omitempty"`
+}
+
+type SitemapIndex struct {
+	XMLName  xml.Name     `xml:"sitemapindex"`
+	Xmlns    string       `xml:"xmlns,attr"`
+	Sitemaps []SitemapDto `xml:"sitemap"`
+}
+
+type SitemapDto struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+// Config настройки карты сайта
+type Config struct {
+	Scheme  string // Scheme схема адресов, по умолчанию https
+	MaxUrls int    // MaxUrls максимальное количество адресов в одном файле, по умолчанию и не более MaxUrls
+	PartUrl string // PartUrl путь к файлу карты сайта с номером, по умолчанию /sitemaps/%d.xml
+	Robots  Robots // Robots правила robots.txt
+}
+
+// Robots правила robots.txt, ссылка на карту сайта добавляется автоматически
+type Robots struct {
+	Groups []RobotsGroup // Groups группы правил, без групп индексация разрешена всем
+	Extra  []string      // Extra дополнительные директивы в конце файла, например Clean-param
+}
+
+// RobotsGroup группа правил для роботов
+type RobotsGroup struct {
+	UserAgents []string // UserAgents роботы, к которым применяются правила, без роботов - *
+	Allow      []string
+	Disallow   []string
+	CrawlDelay int // CrawlDelay задержка между запросами в секундах, 0 - не выводится
+}
@@ -0,0 +1,17 @@
+package actions
+
+import (
+	"context"
+	"github.com/aeroideaservices/focus/sitemap/plugin/entity"
+)
+
+// Source источник адресов карты сайта, например опубликованные страницы или элементы моделей
+type Source interface {
+	// Urls адреса для домена domain, домен определяется по запросу к карте сайта
+	Urls(ctx context.Context, domain string) ([]entity.Url, error)
+}
+
+// DomainsProvider список доменов сайта, для каждого домена строится своя карта сайта
+type DomainsProvider interface {
+	Hosts(ctx context.Context) ([]string, error)
+}
@@ -0,0 +1,235 @@
+package actions
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aeroideaservices/focus/services/errors"
+	"github.com/aeroideaservices/focus/sitemap/plugin/entity"
+)
+
+// Sitemap построение карты сайта и robots.txt из адресов источников.
+// Для каждого домена карта строится отдельно, адреса источников дополняются доменом из запроса.
+type Sitemap struct {
+	sources         []Source
+	domainsProvider DomainsProvider
+	config          Config
+}
+
+// NewSitemap конструктор.
+// Без domainsProvider карта строится для любого домена из запроса.
+func NewSitemap(sources []Source, domainsProvider DomainsProvider, config Config) *Sitemap {
+	if config.Scheme == "" {
+		config.Scheme = "https"
+	}
+	if config.MaxUrls <= 0 || config.MaxUrls > MaxUrls {
+		config.MaxUrls = MaxUrls
+	}
+	if config.PartUrl == "" {
+		config.PartUrl = "/sitemaps/%d.xml"
+	}
+
+	return &Sitemap{
+		sources:         sources,
+		domainsProvider: domainsProvider,
+		config:          config,
+	}
+}
+
+// Get получение файла карты сайта.
+// Основной файл содержит все адреса, а если их больше MaxUrls - индекс файлов с номерами.
+func (s Sitemap) Get(ctx context.Context, action GetSitemap) (*Document, error) {
+	host, err := s.resolveHost(ctx, action.Host)
+	if err != nil {
+		return nil, err
+	}
+
+	urls, err := s.collect(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+
+	parts := (len(urls) + s.config.MaxUrls - 1) / s.config.MaxUrls
+	if action.Part == 0 && parts > 1 {
+		return &Document{Index: s.index(host, urls, parts)}, nil
+	}
+	if action.Part == 0 {
+		return &Document{UrlSet: newUrlSet(urls)}, nil
+	}
+	if action.Part > parts {
+		return nil, errors.NotFound.Newf("sitemap part %d not found", action.Part)
+	}
+
+	from := (action.Part - 1) * s.config.MaxUrls
+	to := from + s.config.MaxUrls
+	if to > len(urls) {
+		to = len(urls)
+	}
+
+	return &Document{UrlSet: newUrlSet(urls[from:to])}, nil
+}
+
+// Robots получение robots.txt со ссылкой на карту сайта домена
+func (s Sitemap) Robots(ctx context.Context, action GetRobots) (string, error) {
+	host, err := s.resolveHost(ctx, action.Host)
+	if err != nil {
+		return "", err
+	}
+
+	groups := s.config.Robots.Groups
+	if len(groups) == 0 {
+		groups = []RobotsGroup{{Disallow: []string{""}}}
+	}
+
+	var b strings.Builder
+	for _, group := range groups {
+		userAgents := group.UserAgents
+		if len(userAgents) == 0 {
+			userAgents = []string{"*"}
+		}
+		for _, userAgent := range userAgents {
+			b.WriteString("User-agent: " + userAgent + "\n")
+		}
+		for _, path := range group.Allow {
+			b.WriteString("Allow: " + path + "\n")
+		}
+		for _, path := range group.Disallow {
+			b.WriteString(strings.TrimSpace("Disallow: "+path) + "\n")
+		}
+		if group.CrawlDelay > 0 {
+			b.WriteString("Crawl-delay: " + strconv.Itoa(group.CrawlDelay) + "\n")
+		}
+		b.WriteString("\n")
+	}
+	for _, line := range s.config.Robots.Extra {
+		b.WriteString(line + "\n")
+	}
+	b.WriteString("Sitemap: " + s.absolute(host, "/sitemap.xml") + "\n")
+
+	return b.String(), nil
+}
+
+// resolveHost домен карты сайта по домену из запроса.
+// Домены сравниваются без учета регистра, схемы и порта, неизвестный домен - ошибка NotFound.
+func (s Sitemap) resolveHost(ctx context.Context, requestHost string) (string, error) {
+	requestHost = normalizeHost(requestHost)
+	if s.domainsProvider == nil {
+		return requestHost, nil
+	}
+
+	hosts, err := s.domainsProvider.Hosts(ctx)
+	if err != nil {
+		return "", err
+	}
+	for _, host := range hosts {
+		host = normalizeHost(host)
+		if host == requestHost || hostname(host) == hostname(requestHost) {
+			return host, nil
+		}
+	}
+
+	return "", errors.NotFound.Newf("sitemap for domain %s not found", requestHost)
+}
+
+// collect адреса всех источников для домена без повторов, в порядке источников
+func (s Sitemap) collect(ctx context.Context, host string) ([]UrlDto, error) {
+	var urls []UrlDto
+	seen := make(map[string]bool)
+	for _, source := range s.sources {
+		sourceUrls, err := source.Urls(ctx, host)
+		if err != nil {
+			return nil, err
+		}
+		for _, u := range sourceUrls {
+			loc := s.absolute(host, u.Loc)
+			if seen[loc] {
+				continue
+			}
+			seen[loc] = true
+			urls = append(urls, newUrlDto(loc, u))
+		}
+	}
+
+	return urls, nil
+}
+
+func (s Sitemap) index(host string, urls []UrlDto, parts int) *SitemapIndex {
+	index := &SitemapIndex{Xmlns: Xmlns, Sitemaps: make([]SitemapDto, 0, parts)}
+	for part := 1; part <= parts; part++ {
+		from := (part - 1) * s.config.MaxUrls
+		to := from + s.config.MaxUrls
+		if to > len(urls) {
+			to = len(urls)
+		}
+		index.Sitemaps = append(index.Sitemaps, SitemapDto{
+			Loc:     s.absolute(host, fmt.Sprintf(s.config.PartUrl, part)),
+			LastMod: lastMod(urls[from:to]),
+		})
+	}
+
+	return index
+}
+
+// absolute абсолютный адрес пути на домене, абсолютные адреса не изменяются
+func (s Sitemap) absolute(host string, loc string) string {
+	if strings.HasPrefix(loc, "http://") || strings.HasPrefix(loc, "https://") {
+		return loc
+	}
+
+	u := url.URL{Scheme: s.config.Scheme, Host: host, Path: "/" + strings.TrimLeft(loc, "/")}
+	return u.String()
+}
+
+func newUrlSet(urls []UrlDto) *UrlSet {
+	if urls == nil {
+		urls = []UrlDto{}
+	}
+
+	return &UrlSet{Xmlns: Xmlns, Urls: urls}
+}
+
+func newUrlDto(loc string, u entity.Url) UrlDto {
+	dto := UrlDto{Loc: loc, ChangeFreq: string(u.ChangeFreq)}
+	if u.LastMod != nil {
+		dto.LastMod = u.LastMod.UTC().Format(time.RFC3339)
+	}
+	if u.Priority > 0 {
+		dto.Priority = strconv.FormatFloat(u.Priority, 'f', 1, 64)
+	}
+
+	return dto
+}
+
+// lastMod наибольшее время изменения адресов, время в формате RFC3339 сравнивается как строка
+func lastMod(urls []UrlDto) string {
+	var last string
+	for _, u := range urls {
+		if u.LastMod > last {
+			last = u.LastMod
+		}
+	}
+
+	return last
+}
+
+func normalizeHost(host string) string {
+	host = strings.ToLower(strings.TrimSpace(host))
+	if i := strings.Index(host, "://"); i >= 0 {
+		host = host[i+3:]
+	}
+
+	return strings.TrimRight(host, "/")
+}
+
+func hostname(host string) string {
+	if name, _, err := net.SplitHostPort(host); err == nil {
+		return name
+	}
+
+	return host
+}
@@ -0,0 +1,102 @@
+package actions
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aeroideaservices/focus/services/errors"
+	"github.com/aeroideaservices/focus/sitemap/plugin/entity"
+)
+
+type sourceFunc func(ctx context.Context, domain string) ([]entity.Url, error)
+
+func (f sourceFunc) Urls(ctx context.Context, domain string) ([]entity.Url, error) {
+	return f(ctx, domain)
+}
+
+type hosts []string
+
+func (h hosts) Hosts(context.Context) ([]string, error) {
+	return h, nil
+}
+
+func TestSitemap_Get(t *testing.T) {
+	modified := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	source := sourceFunc(func(_ context.Context, domain string) ([]entity.Url, error) {
+		return []entity.Url{
+			{Loc: "about", LastMod: &modified, Priority: 0.8},
+			{Loc: "/about/team"},
+			{Loc: "/about"},
+			{Loc: "https://cdn.example.com/page"},
+		}, nil
+	})
+	ctx := context.Background()
+
+	s := NewSitemap([]Source{source}, hosts{"https://Example.com/"}, Config{MaxUrls: 2})
+
+	doc, err := s.Get(ctx, GetSitemap{Host: "example.com:443"})
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if doc.Index == nil || len(doc.Index.Sitemaps) != 2 {
+		t.Fatalf("Get() must return index of 2 parts, got %+v", doc)
+	}
+	if got := doc.Index.Sitemaps[0]; got.Loc != "https://example.com/sitemaps/1.xml" || got.LastMod != "2024-05-01T12:00:00Z" {
+		t.Errorf("Get() index[0] = %+v", got)
+	}
+
+	doc, err = s.Get(ctx, GetSitemap{Host: "example.com", Part: 2})
+	if err != nil {
+		t.Fatalf("Get() part error = %v", err)
+	}
+	if len(doc.UrlSet.Urls) != 1 || doc.UrlSet.Urls[0].Loc != "https://cdn.example.com/page" {
+		t.Errorf("Get() part 2 = %+v", doc.UrlSet.Urls)
+	}
+
+	if _, err = s.Get(ctx, GetSitemap{Host: "example.com", Part: 3}); errors.GetType(err) != errors.NotFound {
+		t.Errorf("Get() of missing part error = %v, want NotFound", err)
+	}
+	if _, err = s.Get(ctx, GetSitemap{Host: "other.com"}); errors.GetType(err) != errors.NotFound {
+		t.Errorf("Get() for unknown domain error = %v, want NotFound", err)
+	}
+
+	doc, err = NewSitemap([]Source{source}, nil, Config{}).Get(ctx, GetSitemap{Host: "site.ru"})
+	if err != nil {
+		t.Fatalf("Get() without domains error = %v", err)
+	}
+	if doc.UrlSet == nil || len(doc.UrlSet.Urls) != 3 {
+		t.Fatalf("Get() without domains = %+v", doc)
+	}
+	if got := doc.UrlSet.Urls[0]; got.Loc != "https://site.ru/about" || got.Priority != "0.8" {
+		t.Errorf("Get() url[0] = %+v", got)
+	}
+
+	data, err := doc.Marshal()
+	if err != nil || !strings.Contains(string(data), `<urlset xmlns="`+Xmlns+`"><url><loc>https://site.ru/about</loc>`) {
+		t.Errorf("Marshal() = %s, %v", data, err)
+	}
+}
+
+func TestSitemap_Robots(t *testing.T) {
+	s := NewSitemap(nil, nil, Config{Robots: Robots{
+		Groups: []RobotsGroup{{Disallow: []string{"/admin"}, CrawlDelay: 2}, {UserAgents: []string{"Yandex"}, Allow: []string{"/"}}},
+		Extra:  []string{"Clean-param: utm_source"},
+	}})
+
+	got, err := s.Robots(context.Background(), GetRobots{Host: "site.ru"})
+	if err != nil {
+		t.Fatalf("Robots() error = %v", err)
+	}
+	want := "User-agent: *\nDisallow: /admin\nCrawl-delay: 2\n\nUser-agent: Yandex\nAllow: /\n\n" +
+		"Clean-param: utm_source\nSitemap: https://site.ru/sitemap.xml\n"
+	if got != want {
+		t.Errorf("Robots() = %q, want %q", got, want)
+	}
+
+	got, _ = NewSitemap(nil, nil, Config{}).Robots(context.Background(), GetRobots{Host: "site.ru"})
+	if !strings.HasPrefix(got, "User-agent: *\nDisallow:\n") {
+		t.Errorf("Robots() without rules = %q", got)
+	}
+}
@@ -0,0 +1,35 @@
+package plugin
+
+import (
+	"github.com/aeroideaservices/focus/sitemap/plugin/actions"
+	"github.com/sarulabs/di/v2"
+)
+
+var Definitions = []di.Def{
+	{
+		Name: "focus.sitemap.actions.sitemap",
+		Build: func(ctn di.Container) (interface{}, error) {
+			// источники адресов подключаются приложением, например focus.page.sitemap.source и focus.models.sitemap.source
+			var sources []actions.Source
+			if sourcesI, _ := ctn.SafeGet("focus.sitemap.sources"); sourcesI != nil {
+				sources = sourcesI.([]actions.Source)
+			}
+
+			// карта сайта строится только для доменов из списка,
+			// по умолчанию - для доменов меню focus.menu.actions.domains, без списка - для любого домена из запроса
+			var domainsProvider actions.DomainsProvider
+			if domainsI, _ := ctn.SafeGet("focus.sitemap.domains"); domainsI != nil {
+				domainsProvider = domainsI.(actions.DomainsProvider)
+			} else if domainsI, _ = ctn.SafeGet("focus.menu.actions.domains"); domainsI != nil {
+				domainsProvider = domainsI.(actions.DomainsProvider)
+			}
+
+			var config actions.Config
+			if configI, _ := ctn.SafeGet("focus.sitemap.config"); configI != nil {
+				config = configI.(actions.Config)
+			}
+
+			return actions.NewSitemap(sources, domainsProvider, config), nil
+		},
+	},
+}
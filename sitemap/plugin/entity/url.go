@@ -0,0 +1,24 @@
+package entity
+
+import "time"
+
+// ChangeFreq вероятная частота изменения страницы
+type ChangeFreq string
+
+const (
+	ChangeFreqAlways  ChangeFreq = "always"
+	ChangeFreqHourly  ChangeFreq = "hourly"
+	ChangeFreqDaily   ChangeFreq = "daily"
+	ChangeFreqWeekly  ChangeFreq = "weekly"
+	ChangeFreqMonthly ChangeFreq = "monthly"
+	ChangeFreqYearly  ChangeFreq = "yearly"
+	ChangeFreqNever   ChangeFreq = "never"
+)
+
+// Url адрес карты сайта
+type Url struct {
+	Loc        string     // Loc путь от корня домена или абсолютный адрес
+	LastMod    *time.Time // LastMod время последнего изменения, nil - не выводится
+	ChangeFreq ChangeFreq // ChangeFreq частота изменения, пустая - не выводится
+	Priority   float64    // Priority приоритет от 0 до 1, 0 - не выводится
+}
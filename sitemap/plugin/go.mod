@@ -0,0 +1,10 @@
+module github.com/aeroideaservices/focus/sitemap/plugin
+
+go 1.18
+
+require (
+	github.com/aeroideaservices/focus/services/errors v1.0.0
+	github.com/sarulabs/di/v2 v2.4.2
+)
+
+require github.com/pkg/errors v0.9.1 // indirect
@@ -0,0 +1,41 @@
+package rest
+
+import (
+	"github.com/aeroideaservices/focus/sitemap/plugin/actions"
+	"github.com/aeroideaservices/focus/sitemap/rest/handlers"
+	"github.com/aeroideaservices/focus/sitemap/rest/services"
+	"github.com/sarulabs/di/v2"
+	"net"
+	"time"
+)
+
+var Definitions = []di.Def{
+	{
+		Name: "focus.sitemap.handlers.sitemap",
+		Build: func(ctn di.Container) (interface{}, error) {
+			sitemap := ctn.Get("focus.sitemap.actions.sitemap").(*actions.Sitemap)
+			validator := ctn.Get("focus.validator").(services.Validator)
+			maxAge := time.Hour
+			if maxAgeI, err := ctn.SafeGet("focus.sitemap.maxAge"); err == nil {
+				maxAge = maxAgeI.(time.Duration)
+			}
+			// X-Forwarded-Host учитывается только от доверенных прокси, например []string{"10.0.0.0/8"}
+			var trustedProxies []*net.IPNet
+			if proxiesI, err := ctn.SafeGet("focus.sitemap.trustedProxies"); err == nil {
+				trustedProxies, err = handlers.ParseTrustedProxies(proxiesI.([]string))
+				if err != nil {
+					return nil, err
+				}
+			}
+			return handlers.NewSitemapHandler(sitemap, validator, maxAge, trustedProxies), nil
+		},
+	},
+	{
+		Name: "focus.sitemap.router",
+		Build: func(ctn di.Container) (interface{}, error) {
+			sitemapHandler := ctn.Get("focus.sitemap.handlers.sitemap").(*handlers.SitemapHandler)
+			errorHandler := ctn.Get("focus.errorHandler").(services.ErrorHandler)
+			return NewRouter(sitemapHandler, errorHandler), nil
+		},
+	},
+}
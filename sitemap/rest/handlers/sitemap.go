@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"fmt"
+	"github.com/aeroideaservices/focus/services/errors"
+	"github.com/aeroideaservices/focus/sitemap/plugin/actions"
+	"github.com/aeroideaservices/focus/sitemap/rest/services"
+	"github.com/gin-gonic/gin"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const PartParam = "part"
+
+// SitemapHandler публичные sitemap.xml и robots.txt, домен определяется по запросу.
+// Заголовок X-Forwarded-Host учитывается только в запросах от доверенных прокси.
+type SitemapHandler struct {
+	sitemap        *actions.Sitemap
+	validator      services.Validator
+	maxAge         time.Duration
+	trustedProxies []*net.IPNet
+}
+
+func NewSitemapHandler(
+	sitemap *actions.Sitemap, validator services.Validator, maxAge time.Duration, trustedProxies []*net.IPNet,
+) *SitemapHandler {
+	return &SitemapHandler{sitemap: sitemap, validator: validator, maxAge: maxAge, trustedProxies: trustedProxies}
+}
+
+// ParseTrustedProxies разбор адресов доверенных прокси: IP-адресов или подсетей в нотации CIDR
+func ParseTrustedProxies(proxies []string) ([]*net.IPNet, error) {
+	networks := make([]*net.IPNet, 0, len(proxies))
+	for _, proxy := range proxies {
+		if !strings.Contains(proxy, "/") {
+			ip := net.ParseIP(proxy)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid trusted proxy %s", proxy)
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			networks = append(networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+
+		_, network, err := net.ParseCIDR(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %s: %w", proxy, err)
+		}
+		networks = append(networks, network)
+	}
+
+	return networks, nil
+}
+
+// Get основной файл карты сайта
+func (h SitemapHandler) Get(c *gin.Context) {
+	h.get(c, actions.GetSitemap{Host: h.host(c)})
+}
+
+// GetPart файл карты сайта с номером, например /sitemaps/2.xml
+func (h SitemapHandler) GetPart(c *gin.Context) {
+	part, err := strconv.Atoi(strings.TrimSuffix(c.Param(PartParam), ".xml"))
+	if err != nil || part < 1 {
+		_ = c.Error(errors.NotFound.New("sitemap part not found"))
+		return
+	}
+
+	h.get(c, actions.GetSitemap{Host: h.host(c), Part: part})
+}
+
+func (h SitemapHandler) get(c *gin.Context, action actions.GetSitemap) {
+	if err := h.validator.Validate(c, action); err != nil {
+		_ = c.Error(errors.NotFound.Wrap(err, "sitemap not found"))
+		return
+	}
+
+	document, err := h.sitemap.Get(c, action)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	body, err := document.Marshal()
+	if err != nil {
+		_ = c.Error(errors.NoType.Wrap(err, "error encoding sitemap"))
+		return
+	}
+
+	h.cache(c)
+	c.Data(http.StatusOK, "application/xml; charset=utf-8", body)
+}
+
+// Robots robots.txt со ссылкой на карту сайта домена
+func (h SitemapHandler) Robots(c *gin.Context) {
+	action := actions.GetRobots{Host: h.host(c)}
+	if err := h.validator.Validate(c, action); err != nil {
+		_ = c.Error(errors.NotFound.Wrap(err, "robots.txt not found"))
+		return
+	}
+
+	robots, err := h.sitemap.Robots(c, action)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	h.cache(c)
+	c.Data(http.StatusOK, "text/plain; charset=utf-8", []byte(robots))
+}
+
+func (h SitemapHandler) cache(c *gin.Context) {
+	c.Header("Cache-Control", fmt.Sprintf("public, max-age=%d", int(h.maxAge.Seconds())))
+}
+
+// host домен запроса, за доверенным прокси - из заголовка X-Forwarded-Host
+func (h SitemapHandler) host(c *gin.Context) string {
+	forwarded := c.GetHeader("X-Forwarded-Host")
+	if forwarded != "" && h.trustedProxy(c) {
+		return strings.TrimSpace(strings.Split(forwarded, ",")[0])
+	}
+
+	return c.Request.Host
+}
+
+// trustedProxy запрос пришел от доверенного прокси
+func (h SitemapHandler) trustedProxy(c *gin.Context) bool {
+	ip := net.ParseIP(c.RemoteIP())
+	if ip == nil {
+		return false
+	}
+	for _, network := range h.trustedProxies {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,29 @@
+package rest
+
+import (
+	"github.com/aeroideaservices/focus/sitemap/rest/handlers"
+	"github.com/aeroideaservices/focus/sitemap/rest/services"
+	"github.com/gin-gonic/gin"
+)
+
+// Router публичные маршруты карты сайта, регистрируются в корне сайта
+type Router struct {
+	sitemapHandler *handlers.SitemapHandler
+	errorHandler   services.ErrorHandler
+}
+
+func NewRouter(sitemapHandler *handlers.SitemapHandler, errorHandler services.ErrorHandler) *Router {
+	return &Router{
+		sitemapHandler: sitemapHandler,
+		errorHandler:   errorHandler,
+	}
+}
+
+func (r *Router) SetRoutes(routerGroup *gin.RouterGroup) {
+	sitemap := routerGroup.Group("")
+	sitemap.Use(r.errorHandler.Handle) // отлов ошибок
+
+	sitemap.GET("/sitemap.xml", r.sitemapHandler.Get)
+	sitemap.GET("/sitemaps/:"+handlers.PartParam, r.sitemapHandler.GetPart)
+	sitemap.GET("/robots.txt", r.sitemapHandler.Robots)
+}